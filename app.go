@@ -7,18 +7,28 @@ import (
 	"insyra-insights/i18n"
 	"insyra-insights/services"
 	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// appTitle 視窗標題的基本文字
+const appTitle = "Insyra Insights"
+
 // App struct
 type App struct {
-	ctx         context.Context
-	dataService *services.DataTableService
+	ctx          context.Context
+	dataService  *services.DataTableService
+	statsService *services.StatisticsService
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
+	dataService := services.NewDataTableService()
 	return &App{
-		dataService: services.NewDataTableService(),
+		dataService:  dataService,
+		statsService: services.NewStatisticsService(dataService),
 	}
 }
 
@@ -31,6 +41,7 @@ func (a *App) startup(ctx context.Context) {
 	if err := config.Load(); err != nil {
 		log.Printf("載入設定檔失敗: %v", err)
 	}
+	a.dataService.SetUndoHistoryLimit(config.GetUndoHistoryLimit())
 
 	// 根據設定設置語言
 	language := config.Get(config.Language)
@@ -38,6 +49,65 @@ func (a *App) startup(ctx context.Context) {
 		i18n.SetLanguage(language)
 		log.Printf("設定語言為: %s", language)
 	}
+
+	// 未儲存狀態改變時更新視窗標題並通知前端
+	a.dataService.SetDirtyStateListener(func(state services.DirtyState) {
+		runtime.WindowSetTitle(a.ctx, windowTitle(state))
+		runtime.EventsEmit(a.ctx, services.DirtyStateChangedEvent, state)
+	})
+}
+
+// beforeClose 關閉視窗前若有未儲存的變更，詢問使用者是否放棄變更；回傳 true 表示取消關閉
+func (a *App) beforeClose(ctx context.Context) bool {
+	if !a.dataService.HasUnsavedChanges() {
+		return false
+	}
+	result, err := runtime.MessageDialog(ctx, runtime.MessageDialogOptions{
+		Type:    runtime.QuestionDialog,
+		Title:   i18n.T("file_operations.unsaved_changes"),
+		Message: i18n.T("file_operations.discard_and_close"),
+	})
+	if err != nil {
+		log.Printf("顯示關閉確認對話框失敗: %v", err)
+		return true
+	}
+	return result != "Yes"
+}
+
+// windowTitle 依專案路徑與未儲存狀態產生視窗標題，例如 "* data.insa - Insyra Insights"
+func windowTitle(state services.DirtyState) string {
+	title := appTitle
+	if state.ProjectPath != "" {
+		title = filepath.Base(state.ProjectPath) + " - " + title
+	}
+	if state.HasUnsavedChanges {
+		title = "* " + title
+	}
+	return title
+}
+
+// succeeded 將只回傳錯誤的服務方法轉為 (bool, error)，讓前端仍可用回傳值判斷是否成功
+func succeeded(err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// errorPayload 回傳給前端的錯誤內容，在 ServiceError 之外附上目前語言的訊息
+type errorPayload struct {
+	*services.ServiceError
+	LocalizedMessage string `json:"localizedMessage"`
+}
+
+// formatError 作為 Wails 的 ErrorFormatter，將方法回傳的錯誤轉為結構化內容
+func formatError(err error) any {
+	e := services.AsServiceError(err)
+	localized := i18n.T(e.MessageKey)
+	if strings.HasPrefix(localized, "??") {
+		localized = e.Message
+	}
+	return errorPayload{ServiceError: e, LocalizedMessage: localized}
 }
 
 // I18n 相關方法
@@ -81,48 +151,48 @@ func (a *App) GetParamValue(key string) string {
 }
 
 // LoadTable 載入資料表
-func (a *App) LoadTable(tableName string, filePath string) bool {
-	return a.dataService.LoadTable(tableName, filePath)
+func (a *App) LoadTable(tableName string, filePath string) (bool, error) {
+	return succeeded(a.dataService.LoadTable(tableName, filePath))
 }
 
 // CreateEmptyTable 創建空白資料表
-func (a *App) CreateEmptyTable(tableName string) bool {
-	return a.dataService.CreateEmptyTable(tableName)
+func (a *App) CreateEmptyTable(tableName string) (bool, error) {
+	return succeeded(a.dataService.CreateEmptyTable(tableName))
 }
 
 // GetTableData 獲取資料表資料
-func (a *App) GetTableData(tableName string) map[string]interface{} {
+func (a *App) GetTableData(tableName string) (map[string]interface{}, error) {
 	return a.dataService.GetTableData(tableName)
 }
 
 // UpdateCellValue 更新儲存格值
-func (a *App) UpdateCellValue(tableName string, rowIndex int, colIndex int, value string) bool {
-	return a.dataService.UpdateCellValue(tableName, rowIndex, colIndex, value)
+func (a *App) UpdateCellValue(tableName string, rowIndex int, colIndex int, value string) (bool, error) {
+	return succeeded(a.dataService.UpdateCellValue(tableName, rowIndex, colIndex, value))
 }
 
 // UpdateColumnName 更新欄名
-func (a *App) UpdateColumnName(tableName string, colIndex int, newName string) bool {
+func (a *App) UpdateColumnName(tableName string, colIndex int, newName string) (bool, error) {
 	return a.dataService.UpdateColumnName(tableName, colIndex, newName)
 }
 
 // SaveTable 保存資料表
-func (a *App) SaveTable(tableName string, filePath string) bool {
-	return a.dataService.SaveTable(tableName, filePath)
+func (a *App) SaveTable(tableName string, filePath string) (bool, error) {
+	return succeeded(a.dataService.SaveTable(tableName, filePath))
 }
 
 // AddColumn 新增欄位
-func (a *App) AddColumn(tableName string, columnName string) bool {
-	return a.dataService.AddColumn(tableName, columnName)
+func (a *App) AddColumn(tableName string, columnName string) (bool, error) {
+	return succeeded(a.dataService.AddColumn(tableName, columnName))
 }
 
 // AddRow 新增行
-func (a *App) AddRow(tableName string) bool {
-	return a.dataService.AddRow(tableName)
+func (a *App) AddRow(tableName string) (bool, error) {
+	return succeeded(a.dataService.AddRow(tableName))
 }
 
 // AddCalculatedColumn 新增計算欄位
-func (a *App) AddCalculatedColumn(tableName string, columnName string, formula string) bool {
-	return a.dataService.AddCalculatedColumn(tableName, columnName, formula)
+func (a *App) AddCalculatedColumn(tableName string, columnName string, formula string) (bool, error) {
+	return succeeded(a.dataService.AddCalculatedColumn(tableName, columnName, formula))
 }
 
 // GetTableNames 獲取所有表格名稱
@@ -131,55 +201,185 @@ func (a *App) GetTableNames() []string {
 }
 
 // RemoveTable 移除指定名稱的表格
-func (a *App) RemoveTable(tableName string) bool {
-	return a.dataService.RemoveTable(tableName)
+func (a *App) RemoveTable(tableName string) (bool, error) {
+	return succeeded(a.dataService.RemoveTable(tableName))
 }
 
 // ===== 基於 ID 的操作方法 =====
 
-// LoadTableByID 加載資料表到指定ID位置
-func (a *App) LoadTableByID(tableID int, tableName string, filePath string) int {
-	return a.dataService.LoadTableByID(tableID, tableName, filePath)
+// LoadTableByID 加載資料表到指定標籤頁位置，回傳新資料表的ID
+func (a *App) LoadTableByID(index int, tableName string, filePath string) (string, error) {
+	return a.dataService.LoadTableByID(index, tableName, filePath)
 }
 
-// CreateEmptyTableByID 在指定ID位置創建空白資料表
-func (a *App) CreateEmptyTableByID(tableID int, tableName string) int {
-	return a.dataService.CreateEmptyTableByID(tableID, tableName)
+// CreateEmptyTableByID 在指定標籤頁位置創建空白資料表，回傳新資料表的ID
+func (a *App) CreateEmptyTableByID(index int, tableName string) (string, error) {
+	return a.dataService.CreateEmptyTableByID(index, tableName)
 }
 
 // GetTableDataByID 根據ID獲取資料表資料
-func (a *App) GetTableDataByID(tableID int) map[string]interface{} {
+func (a *App) GetTableDataByID(tableID string) (map[string]interface{}, error) {
 	return a.dataService.GetTableDataByID(tableID)
 }
 
+// GetTableWindow 取得資料表中一個範圍的儲存格（依列排列），供表格只讀取可見的部分
+func (a *App) GetTableWindow(tableID string, rowOffset int, rowLimit int, colOffset int, colLimit int) (services.TableWindow, error) {
+	return a.dataService.GetTableWindow(tableID, rowOffset, rowLimit, colOffset, colLimit)
+}
+
 // UpdateCellValueByID 根據ID更新儲存格值
-func (a *App) UpdateCellValueByID(tableID int, rowIndex int, colIndex int, value string) bool {
-	return a.dataService.UpdateCellValueByID(tableID, rowIndex, colIndex, value)
+func (a *App) UpdateCellValueByID(tableID string, rowIndex int, colIndex int, value string) (bool, error) {
+	return succeeded(a.dataService.UpdateCellValueByID(tableID, rowIndex, colIndex, value))
 }
 
 // UpdateColumnNameByID 根據ID更新欄名
-func (a *App) UpdateColumnNameByID(tableID int, colIndex int, newName string) bool {
+func (a *App) UpdateColumnNameByID(tableID string, colIndex int, newName string) (bool, error) {
 	return a.dataService.UpdateColumnNameByID(tableID, colIndex, newName)
 }
 
 // SaveTableByID 根據ID保存資料表
-func (a *App) SaveTableByID(tableID int, filePath string) bool {
-	return a.dataService.SaveTableByID(tableID, filePath)
+func (a *App) SaveTableByID(tableID string, filePath string) (bool, error) {
+	return succeeded(a.dataService.SaveTableByID(tableID, filePath))
 }
 
 // AddColumnByID 根據ID新增欄位
-func (a *App) AddColumnByID(tableID int, columnName string) bool {
-	return a.dataService.AddColumnByID(tableID, columnName)
+func (a *App) AddColumnByID(tableID string, columnName string) (bool, error) {
+	return succeeded(a.dataService.AddColumnByID(tableID, columnName))
 }
 
 // AddRowByID 根據ID新增行
-func (a *App) AddRowByID(tableID int) bool {
-	return a.dataService.AddRowByID(tableID)
+func (a *App) AddRowByID(tableID string) (bool, error) {
+	return succeeded(a.dataService.AddRowByID(tableID))
 }
 
 // AddCalculatedColumnByID 根據ID新增計算欄位
-func (a *App) AddCalculatedColumnByID(tableID int, columnName string, formula string) bool {
-	return a.dataService.AddCalculatedColumnByID(tableID, columnName, formula)
+func (a *App) AddCalculatedColumnByID(tableID string, columnName string, formula string) (bool, error) {
+	return succeeded(a.dataService.AddCalculatedColumnByID(tableID, columnName, formula))
+}
+
+// ValidateCCL 檢查 CCL 公式的語法、參照的欄位與結果型別
+func (a *App) ValidateCCL(tableID string, formula string) (services.CCLValidation, error) {
+	return a.dataService.ValidateCCL(tableID, formula)
+}
+
+// PreviewCCL 以資料表的前幾列預覽 CCL 公式的計算結果
+func (a *App) PreviewCCL(tableID string, formula string, rows int) (services.CCLPreview, error) {
+	return a.dataService.PreviewCCL(tableID, formula, rows)
+}
+
+// SetColumnFormula 設定欄位的計算公式，來源變更時自動重新計算；公式為空字串時改回一般欄位
+func (a *App) SetColumnFormula(tableID string, colIndex int, formula string) (bool, error) {
+	return succeeded(a.dataService.SetColumnFormula(tableID, colIndex, formula))
+}
+
+// SetColumnType 宣告欄位的型別並轉換現有的值；force 為 true 時無法轉換的值改為缺失值
+func (a *App) SetColumnType(tableID string, colIndex int, dataType string, force bool) (bool, error) {
+	return succeeded(a.dataService.SetColumnType(tableID, colIndex, dataType, force))
+}
+
+// GetMissingPolicy 取得專案的缺失值設定
+func (a *App) GetMissingPolicy() services.MissingPolicy {
+	return a.dataService.GetMissingPolicy()
+}
+
+// SetMissingPolicy 設定專案的缺失值設定，並將符合 NA 記號的值轉為缺失值
+func (a *App) SetMissingPolicy(policy services.MissingPolicy) (bool, error) {
+	return succeeded(a.dataService.SetMissingPolicy(policy))
+}
+
+// GetColumnMissingPolicy 取得欄位實際使用的缺失值設定
+func (a *App) GetColumnMissingPolicy(tableID string, colIndex int) (services.ColumnMissingPolicy, error) {
+	return a.dataService.GetColumnMissingPolicy(tableID, colIndex)
+}
+
+// SetColumnMissingPolicy 設定欄位自訂的缺失值設定；policy 為 null 時改回沿用專案的設定
+func (a *App) SetColumnMissingPolicy(tableID string, colIndex int, policy *services.MissingPolicy) (bool, error) {
+	return succeeded(a.dataService.SetColumnMissingPolicy(tableID, colIndex, policy))
+}
+
+// GetVariableView 取得資料表所有變數（欄位）的資訊，供變數檢視使用
+func (a *App) GetVariableView(tableID string) ([]services.VariableInfo, error) {
+	return a.dataService.GetVariableView(tableID)
+}
+
+// SetVariableMetadata 設定變數的標籤、說明、測量尺度、數值標籤與顯示格式
+func (a *App) SetVariableMetadata(tableID string, colIndex int, meta services.VariableMetadata) (bool, error) {
+	return succeeded(a.dataService.SetVariableMetadata(tableID, colIndex, meta))
+}
+
+// GetColumnFormulas 取得資料表中所有計算欄位的公式
+func (a *App) GetColumnFormulas(tableID string) ([]services.ColumnFormulaInfo, error) {
+	return a.dataService.GetColumnFormulas(tableID)
+}
+
+// GetTableStatistics 計算資料表每一欄的描述統計（個數、缺失、平均數、四分位數等）
+func (a *App) GetTableStatistics(tableID string) (services.TableStatistics, error) {
+	return a.statsService.GetTableStatistics(tableID)
+}
+
+// GetColumnStatistics 計算資料表單一欄位的描述統計
+func (a *App) GetColumnStatistics(tableID string, colIndex int) (services.ColumnStatistics, error) {
+	return a.statsService.GetColumnStatistics(tableID, colIndex)
+}
+
+// GetMissingCounts 取得資料表各欄的缺失值數量
+func (a *App) GetMissingCounts(tableID string) ([]services.MissingCount, error) {
+	return a.statsService.GetMissingCounts(tableID)
+}
+
+// GetMissingPattern 取得資料表的缺失模式矩陣
+func (a *App) GetMissingPattern(tableID string) (services.MissingPattern, error) {
+	return a.statsService.GetMissingPattern(tableID)
+}
+
+// OneSampleTTest 單一樣本 t 檢定；confidenceLevel 為 0 時使用 0.95
+func (a *App) OneSampleTTest(tableID string, colIndex int, mu float64, confidenceLevel float64) (services.TestResult, error) {
+	return a.statsService.OneSampleTTest(tableID, colIndex, mu, confidenceLevel)
+}
+
+// PairedTTest 成對樣本 t 檢定
+func (a *App) PairedTTest(tableID string, col1 int, col2 int, confidenceLevel float64) (services.TestResult, error) {
+	return a.statsService.PairedTTest(tableID, col1, col2, confidenceLevel)
+}
+
+// IndependentTTest 獨立樣本 t 檢定，依組別欄的兩個組別比較數值欄
+func (a *App) IndependentTTest(tableID string, valueCol int, groupCol int, equalVariance bool, confidenceLevel float64) (services.TestResult, error) {
+	return a.statsService.IndependentTTest(tableID, valueCol, groupCol, equalVariance, confidenceLevel)
+}
+
+// OneWayANOVA 單因子變異數分析
+func (a *App) OneWayANOVA(tableID string, valueCol int, groupCol int) (services.TestResult, error) {
+	return a.statsService.OneWayANOVA(tableID, valueCol, groupCol)
+}
+
+// ChiSquareIndependence 兩個類別欄位的卡方獨立性檢定
+func (a *App) ChiSquareIndependence(tableID string, col1 int, col2 int) (services.TestResult, error) {
+	return a.statsService.ChiSquareIndependence(tableID, col1, col2)
+}
+
+// CreateTestResultTable 將檢定結果寫成新的標籤頁，回傳新資料表的ID
+func (a *App) CreateTestResultTable(result services.TestResult) (string, error) {
+	return a.statsService.CreateTestResultTable(result)
+}
+
+// CorrelationMatrix 計算所選欄位的相關矩陣（pearson、spearman 或 kendall），缺失值以成對刪除處理
+func (a *App) CorrelationMatrix(tableID string, cols []int, method string) (services.CorrelationResult, error) {
+	return a.statsService.CorrelationMatrix(tableID, cols, method)
+}
+
+// LinearRegression 最小平方法線性迴歸；confidenceLevel 為 0 時使用 0.95
+func (a *App) LinearRegression(tableID string, dependentCol int, independentCols []int, confidenceLevel float64) (services.RegressionResult, error) {
+	return a.statsService.LinearRegression(tableID, dependentCol, independentCols, confidenceLevel)
+}
+
+// CreateCorrelationTables 將相關矩陣寫成新的標籤頁，回傳新資料表的ID
+func (a *App) CreateCorrelationTables(result services.CorrelationResult) ([]string, error) {
+	return a.statsService.CreateCorrelationTables(result)
+}
+
+// CreateRegressionTables 將迴歸結果寫成新的標籤頁，回傳新資料表的ID
+func (a *App) CreateRegressionTables(result services.RegressionResult) ([]string, error) {
+	return a.statsService.CreateRegressionTables(result)
 }
 
 // GetTableCount 獲取表格總數
@@ -188,47 +388,210 @@ func (a *App) GetTableCount() int {
 }
 
 // GetTableInfo 獲取指定ID表格的基本信息
-func (a *App) GetTableInfo(tableID int) map[string]interface{} {
+func (a *App) GetTableInfo(tableID string) (map[string]interface{}, error) {
 	return a.dataService.GetTableInfo(tableID)
 }
 
 // RemoveTableByID 根據ID移除表格
-func (a *App) RemoveTableByID(tableID int) bool {
-	return a.dataService.RemoveTableByID(tableID)
+func (a *App) RemoveTableByID(tableID string) (bool, error) {
+	return succeeded(a.dataService.RemoveTableByID(tableID))
+}
+
+// GetTabOrder 依標籤頁順序獲取所有表格ID
+func (a *App) GetTabOrder() []string {
+	return a.dataService.GetTabOrder()
+}
+
+// MoveTab 將表格的標籤頁移動到指定位置
+func (a *App) MoveTab(tableID string, index int) (bool, error) {
+	return succeeded(a.dataService.MoveTab(tableID, index))
+}
+
+// ===== 列與欄的結構編輯 =====
+
+// InsertRowsByID 在指定位置之前插入空白列
+func (a *App) InsertRowsByID(tableID string, index int, count int) (bool, error) {
+	return succeeded(a.dataService.InsertRowsByID(tableID, index, count))
+}
+
+// DeleteRowsByID 刪除從 start 開始的 count 列
+func (a *App) DeleteRowsByID(tableID string, start int, count int) (bool, error) {
+	return succeeded(a.dataService.DeleteRowsByID(tableID, start, count))
+}
+
+// DuplicateRowsByID 複製從 start 開始的 count 列並插入在其後
+func (a *App) DuplicateRowsByID(tableID string, start int, count int) (bool, error) {
+	return succeeded(a.dataService.DuplicateRowsByID(tableID, start, count))
+}
+
+// MoveRowsByID 將從 start 開始的 count 列移動到 target
+func (a *App) MoveRowsByID(tableID string, start int, count int, target int) (bool, error) {
+	return succeeded(a.dataService.MoveRowsByID(tableID, start, count, target))
+}
+
+// InsertColumnsByID 在指定位置之前插入空白欄
+func (a *App) InsertColumnsByID(tableID string, index int, count int) (bool, error) {
+	return succeeded(a.dataService.InsertColumnsByID(tableID, index, count))
+}
+
+// DeleteColumnsByID 刪除從 start 開始的 count 個欄
+func (a *App) DeleteColumnsByID(tableID string, start int, count int) (bool, error) {
+	return succeeded(a.dataService.DeleteColumnsByID(tableID, start, count))
+}
+
+// DuplicateColumnsByID 複製從 start 開始的 count 個欄並插入在其後
+func (a *App) DuplicateColumnsByID(tableID string, start int, count int) (bool, error) {
+	return succeeded(a.dataService.DuplicateColumnsByID(tableID, start, count))
+}
+
+// MoveColumnsByID 將從 start 開始的 count 個欄移動到 target
+func (a *App) MoveColumnsByID(tableID string, start int, count int, target int) (bool, error) {
+	return succeeded(a.dataService.MoveColumnsByID(tableID, start, count, target))
+}
+
+// ===== 復原／重做 =====
+
+// Undo 復原指定資料表的上一個步驟
+func (a *App) Undo(tableID string) (bool, error) {
+	return a.dataService.Undo(tableID)
+}
+
+// Redo 重做指定資料表上一個被復原的步驟
+func (a *App) Redo(tableID string) (bool, error) {
+	return a.dataService.Redo(tableID)
+}
+
+// GetHistoryState 取得指定資料表的復原／重做狀態
+func (a *App) GetHistoryState(tableID string) (services.HistoryState, error) {
+	return a.dataService.GetHistoryState(tableID)
+}
+
+// BeginEditGroup 開始一組編輯，直到 EndEditGroup 前的變更視為一個復原步驟
+func (a *App) BeginEditGroup(tableID string, label string) (bool, error) {
+	return succeeded(a.dataService.BeginEditGroup(tableID, label))
+}
+
+// EndEditGroup 結束一組編輯
+func (a *App) EndEditGroup(tableID string) (bool, error) {
+	return succeeded(a.dataService.EndEditGroup(tableID))
+}
+
+// UpdateCellValuesByID 一次更新多個儲存格，整批變更視為一個復原步驟
+func (a *App) UpdateCellValuesByID(tableID string, updates []services.CellUpdate) (bool, error) {
+	return succeeded(a.dataService.UpdateCellValuesByID(tableID, updates))
+}
+
+// RestoreRemovedTable 還原最近一次移除的資料表
+func (a *App) RestoreRemovedTable() (string, error) {
+	return a.dataService.RestoreRemovedTable()
+}
+
+// GetUndoHistoryLimit 取得每個資料表最多保留的復原步驟數
+func (a *App) GetUndoHistoryLimit() int {
+	return config.GetUndoHistoryLimit()
+}
+
+// SetUndoHistoryLimit 設定每個資料表最多保留的復原步驟數
+func (a *App) SetUndoHistoryLimit(limit int) {
+	config.SetUndoHistoryLimit(limit)
+	if err := config.Save(); err != nil {
+		log.Printf("儲存復原步驟上限失敗: %v", err)
+	}
+	a.dataService.SetUndoHistoryLimit(config.GetUndoHistoryLimit())
 }
 
 // ===== 專案檔案操作方法 =====
 
 // SaveProject 儲存整個專案（所有標籤頁）
-func (a *App) SaveProject(filePath string) bool {
-	return a.dataService.SaveProject(filePath)
+func (a *App) SaveProject(filePath string) (bool, error) {
+	return succeeded(a.dataService.SaveProject(filePath))
 }
 
 // SaveProjectAs 另存新檔（所有標籤頁）
-func (a *App) SaveProjectAs(filePath string) bool {
-	return a.dataService.SaveProject(filePath)
+func (a *App) SaveProjectAs(filePath string) (bool, error) {
+	return a.SaveProject(filePath)
 }
 
 // LoadProject 載入專案檔案
-func (a *App) LoadProject(filePath string) bool {
-	return a.dataService.LoadProject(filePath)
+func (a *App) LoadProject(filePath string) (bool, error) {
+	return succeeded(a.dataService.LoadProject(filePath))
 }
 
 // ===== 資料表匯出方法 =====
 
 // ExportTableAsCSV 將指定資料表匯出為 CSV
-func (a *App) ExportTableAsCSV(tableID int, filePath string) bool {
-	return a.dataService.ExportTableAsCSV(tableID, filePath)
+func (a *App) ExportTableAsCSV(tableID string, filePath string) (bool, error) {
+	return succeeded(a.dataService.ExportTableAsCSV(tableID, filePath))
+}
+
+// ExportTableAsCSVWithOptions 以指定的分隔符號、引號、編碼與缺失值文字匯出 CSV
+func (a *App) ExportTableAsCSVWithOptions(tableID string, filePath string, options services.CSVExportOptions) (bool, error) {
+	return succeeded(a.dataService.ExportTableAsCSVWithOptions(tableID, filePath, options))
+}
+
+// GetDefaultCSVExportOptions 取得預設的 CSV 匯出設定
+func (a *App) GetDefaultCSVExportOptions() services.CSVExportOptions {
+	return services.DefaultCSVExportOptions()
 }
 
 // ExportTableAsJSON 將指定資料表匯出為 JSON
-func (a *App) ExportTableAsJSON(tableID int, filePath string) bool {
-	return a.dataService.ExportTableAsJSON(tableID, filePath)
+func (a *App) ExportTableAsJSON(tableID string, filePath string) (bool, error) {
+	return succeeded(a.dataService.ExportTableAsJSON(tableID, filePath))
+}
+
+// ExportTableAsJSONWithOptions 以 records 或 columns 排列方式匯出 JSON
+func (a *App) ExportTableAsJSONWithOptions(tableID string, filePath string, options services.JSONExportOptions) (bool, error) {
+	return succeeded(a.dataService.ExportTableAsJSONWithOptions(tableID, filePath, options))
 }
 
 // ExportTableAsExcel 將指定資料表匯出為 Excel
-func (a *App) ExportTableAsExcel(tableID int, filePath string) bool {
-	return a.dataService.ExportTableAsExcel(tableID, filePath)
+func (a *App) ExportTableAsExcel(tableID string, filePath string) (bool, error) {
+	return succeeded(a.dataService.ExportTableAsExcel(tableID, filePath))
+}
+
+// ExportTablesAsExcel 將多個資料表匯出為同一個 Excel 檔案，每個資料表一個工作表
+func (a *App) ExportTablesAsExcel(tableIDs []string, filePath string) (bool, error) {
+	return succeeded(a.dataService.ExportTablesAsExcel(tableIDs, filePath))
+}
+
+// ExportTableAsSPSS 將指定資料表匯出為 SPSS .sav
+func (a *App) ExportTableAsSPSS(tableID string, filePath string) (bool, error) {
+	return succeeded(a.dataService.ExportTableAsSPSS(tableID, filePath))
+}
+
+// ExportTableAsStata 將指定資料表匯出為 Stata .dta
+func (a *App) ExportTableAsStata(tableID string, filePath string) (bool, error) {
+	return succeeded(a.dataService.ExportTableAsStata(tableID, filePath))
+}
+
+// ExportTableAsParquet 將指定資料表匯出為 Parquet（Snappy 壓縮）
+func (a *App) ExportTableAsParquet(tableID string, filePath string) (bool, error) {
+	return succeeded(a.dataService.ExportTableAsParquet(tableID, filePath))
+}
+
+// ExportTableAsParquetWithOptions 以指定的壓縮方式與 row group 大小匯出 Parquet
+func (a *App) ExportTableAsParquetWithOptions(tableID string, filePath string, options services.ColumnarExportOptions) (bool, error) {
+	return succeeded(a.dataService.ExportTableAsParquetWithOptions(tableID, filePath, options))
+}
+
+// GetDefaultParquetExportOptions 取得 Parquet 匯出的預設設定
+func (a *App) GetDefaultParquetExportOptions() services.ColumnarExportOptions {
+	return services.DefaultParquetExportOptions()
+}
+
+// ExportTableAsArrow 將指定資料表匯出為 Arrow IPC 檔案（Feather v2）
+func (a *App) ExportTableAsArrow(tableID string, filePath string) (bool, error) {
+	return succeeded(a.dataService.ExportTableAsArrow(tableID, filePath))
+}
+
+// ExportTableAsArrowWithOptions 以指定的壓縮方式與 record batch 大小匯出 Arrow IPC 檔案
+func (a *App) ExportTableAsArrowWithOptions(tableID string, filePath string, options services.ColumnarExportOptions) (bool, error) {
+	return succeeded(a.dataService.ExportTableAsArrowWithOptions(tableID, filePath, options))
+}
+
+// GetDefaultArrowExportOptions 取得 Arrow 匯出的預設設定
+func (a *App) GetDefaultArrowExportOptions() services.ColumnarExportOptions {
+	return services.DefaultArrowExportOptions()
 }
 
 // ===== 專案狀態管理 =====
@@ -248,29 +611,146 @@ func (a *App) GetCurrentProjectPath() string {
 	return a.dataService.GetCurrentProjectPath()
 }
 
+// MarkAsModified 標記專案有變更
+func (a *App) MarkAsModified() {
+	a.dataService.MarkAsModified()
+}
+
+// GetDirtyState 取得專案與各資料表的未儲存狀態
+func (a *App) GetDirtyState() services.DirtyState {
+	return a.dataService.GetDirtyState()
+}
+
+// IsTableDirty 檢查資料表自上次儲存後是否有變更
+func (a *App) IsTableDirty(tableID string) (bool, error) {
+	return a.dataService.IsTableDirty(tableID)
+}
+
+// GetTableRevision 取得資料表目前的修訂號
+func (a *App) GetTableRevision(tableID string) (uint64, error) {
+	return a.dataService.GetTableRevision(tableID)
+}
+
 // ===== 檔案開啟功能 =====
 
-// OpenCSVFile 開啟CSV檔案
-func (a *App) OpenCSVFile(filePath string) int {
+// OpenCSVFile 開啟CSV檔案，自動偵測格式
+func (a *App) OpenCSVFile(filePath string) (string, error) {
 	return a.dataService.OpenCSVFile(filePath)
 }
 
+// OpenCSVFileWithOptions 以指定設定開啟CSV檔案
+func (a *App) OpenCSVFileWithOptions(filePath string, options *services.CSVImportOptions) (string, error) {
+	return a.dataService.OpenCSVFileWithOptions(filePath, options)
+}
+
+// DetectCSVOptions 偵測CSV檔案的格式設定
+func (a *App) DetectCSVOptions(filePath string) (services.CSVImportOptions, error) {
+	return a.dataService.DetectCSVOptions(filePath)
+}
+
+// PreviewCSVFile 預覽CSV檔案的前幾列；options 為 null 時自動偵測格式
+func (a *App) PreviewCSVFile(filePath string, options *services.CSVImportOptions, maxRows int) (*services.CSVPreview, error) {
+	return a.dataService.PreviewCSVFile(filePath, options, maxRows)
+}
+
 // OpenJSONFile 開啟JSON檔案
-func (a *App) OpenJSONFile(filePath string) int {
+func (a *App) OpenJSONFile(filePath string) (string, error) {
 	return a.dataService.OpenJSONFile(filePath)
 }
 
+// OpenSPSSFile 開啟SPSS .sav檔案，包含變數標籤、數值標籤與缺失值定義
+func (a *App) OpenSPSSFile(filePath string) (string, error) {
+	return a.dataService.OpenSPSSFile(filePath)
+}
+
+// OpenStataFile 開啟Stata .dta檔案，包含變數標籤與數值標籤
+func (a *App) OpenStataFile(filePath string) (string, error) {
+	return a.dataService.OpenStataFile(filePath)
+}
+
+// OpenParquetFile 開啟Parquet檔案
+func (a *App) OpenParquetFile(filePath string) (string, error) {
+	return a.dataService.OpenParquetFile(filePath)
+}
+
+// OpenParquetFileWithOptions 只匯入Parquet檔案中選取的欄
+func (a *App) OpenParquetFileWithOptions(filePath string, options *services.ColumnarImportOptions) (string, error) {
+	return a.dataService.OpenParquetFileWithOptions(filePath, options)
+}
+
+// DescribeParquetFile 取得Parquet檔案的列數與各欄型別
+func (a *App) DescribeParquetFile(filePath string) (services.ColumnarFileInfo, error) {
+	return a.dataService.DescribeParquetFile(filePath)
+}
+
+// OpenArrowFile 開啟Arrow IPC或Feather檔案
+func (a *App) OpenArrowFile(filePath string) (string, error) {
+	return a.dataService.OpenArrowFile(filePath)
+}
+
+// OpenArrowFileWithOptions 只匯入Arrow檔案中選取的欄
+func (a *App) OpenArrowFileWithOptions(filePath string, options *services.ColumnarImportOptions) (string, error) {
+	return a.dataService.OpenArrowFileWithOptions(filePath, options)
+}
+
+// DescribeArrowFile 取得Arrow檔案的列數與各欄型別
+func (a *App) DescribeArrowFile(filePath string) (services.ColumnarFileInfo, error) {
+	return a.dataService.DescribeArrowFile(filePath)
+}
+
 // OpenSQLiteFile 開啟SQLite檔案
-func (a *App) OpenSQLiteFile(filePath string, tableName string) int {
+func (a *App) OpenSQLiteFile(filePath string, tableName string) (string, error) {
 	return a.dataService.OpenSQLiteFile(filePath, tableName)
 }
 
+// OpenSQLiteQuery 以唯讀SELECT查詢SQLite檔案並開啟結果
+func (a *App) OpenSQLiteQuery(filePath string, query string) (string, error) {
+	return a.dataService.OpenSQLiteQuery(filePath, query)
+}
+
 // GetSQLiteTables 取得SQLite檔案中的表格列表
-func (a *App) GetSQLiteTables(filePath string) []string {
+func (a *App) GetSQLiteTables(filePath string) ([]string, error) {
 	return a.dataService.GetSQLiteTables(filePath)
 }
 
+// DescribeSQLiteTables 取得SQLite檔案中表格的列數與欄位結構
+func (a *App) DescribeSQLiteTables(filePath string) ([]services.SQLiteTableInfo, error) {
+	return a.dataService.DescribeSQLiteTables(filePath)
+}
+
+// OpenExcelFile 開啟Excel檔案的第一個可見工作表，第一列作為標題
+func (a *App) OpenExcelFile(filePath string) (string, error) {
+	return a.dataService.OpenExcelFile(filePath)
+}
+
+// OpenExcelFileWithOptions 以指定的工作表、儲存格範圍與標題列開啟Excel檔案
+func (a *App) OpenExcelFileWithOptions(filePath string, options services.ExcelImportOptions) (string, error) {
+	return a.dataService.OpenExcelFileWithOptions(filePath, options)
+}
+
+// GetExcelSheets 取得Excel檔案中的工作表列表與已使用範圍
+func (a *App) GetExcelSheets(filePath string) ([]services.ExcelSheetInfo, error) {
+	return a.dataService.GetExcelSheets(filePath)
+}
+
+// ===== 檔案對話框 =====
+
 // OpenFileDialog 開啟檔案選擇對話框
-func (a *App) OpenFileDialog(filters string) string {
+func (a *App) OpenFileDialog(filters string) (string, error) {
 	return a.dataService.OpenFileDialog(a.ctx, filters)
 }
+
+// OpenMultipleFilesDialog 開啟可多選的檔案選擇對話框
+func (a *App) OpenMultipleFilesDialog(filters string) ([]string, error) {
+	return a.dataService.OpenMultipleFilesDialog(a.ctx, filters)
+}
+
+// SaveFileDialog 開啟儲存檔案對話框
+func (a *App) SaveFileDialog(filters string, defaultFilename string) (string, error) {
+	return a.dataService.SaveFileDialog(a.ctx, filters, defaultFilename)
+}
+
+// OpenDirectoryDialog 開啟資料夾選擇對話框
+func (a *App) OpenDirectoryDialog(title string) (string, error) {
+	return a.dataService.OpenDirectoryDialog(a.ctx, title)
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
 type Key string
@@ -16,17 +17,27 @@ const (
 
 type settings struct {
 	Language string `json:"language"`
+	// LastDirectories 各類檔案（依副檔名區分）最後一次使用的資料夾
+	LastDirectories map[string]string `json:"lastDirectories,omitempty"`
+	// UndoHistoryLimit 每個資料表最多保留的復原步驟數
+	UndoHistoryLimit int `json:"undoHistoryLimit,omitempty"`
 	// 可擴充其他設定欄位
 }
 
+// DefaultUndoHistoryLimit 未設定時每個資料表保留的復原步驟數
+const DefaultUndoHistoryLimit = 100
+
 var (
+	mu      sync.RWMutex // 保護 current；設定可能同時由多個前端呼叫讀寫
+	saveMu  sync.Mutex   // 讓寫入檔案依序進行，較舊的內容不會覆蓋較新的內容
 	current settings
 	cfgPath string
 )
 
 func defaultSettings() settings {
 	return settings{
-		Language: "zh-TW",
+		Language:         "zh-TW",
+		UndoHistoryLimit: DefaultUndoHistoryLimit,
 	}
 }
 
@@ -42,12 +53,15 @@ func init() {
 func Load() error {
 	data, err := os.ReadFile(cfgPath)
 	if err != nil {
+		mu.Lock()
 		current = defaultSettings()
-		err := Save()
-		return err
+		mu.Unlock()
+		return Save()
 	}
+	mu.Lock()
 	err = json.Unmarshal(data, &current)
 	if err != nil {
+		mu.Unlock()
 		return err
 	}
 
@@ -57,6 +71,7 @@ func Load() error {
 		configLost = true
 		current.Language = defaultSettings().Language
 	}
+	mu.Unlock()
 
 	if configLost {
 		if err := Save(); err != nil {
@@ -68,12 +83,18 @@ func Load() error {
 
 // Save 儲存設定檔
 func Save() error {
-	_ = os.MkdirAll(filepath.Dir(cfgPath), 0755)
+	saveMu.Lock()
+	defer saveMu.Unlock()
+	mu.RLock()
 	data, _ := json.MarshalIndent(current, "", "  ")
+	mu.RUnlock()
+	_ = os.MkdirAll(filepath.Dir(cfgPath), 0755)
 	return os.WriteFile(cfgPath, data, 0644)
 }
 
 func Get(k Key) string {
+	mu.RLock()
+	defer mu.RUnlock()
 	switch k {
 	case Language:
 		return current.Language
@@ -83,12 +104,48 @@ func Get(k Key) string {
 }
 
 func Set(k Key, v string) {
+	mu.Lock()
+	defer mu.Unlock()
 	switch k {
 	case Language:
 		current.Language = v
 	}
 }
 
+// GetLastDirectory 取得指定檔案種類最後一次使用的資料夾
+func GetLastDirectory(kind string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current.LastDirectories[kind]
+}
+
+// SetLastDirectory 記錄指定檔案種類最後一次使用的資料夾
+func SetLastDirectory(kind string, dir string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if current.LastDirectories == nil {
+		current.LastDirectories = make(map[string]string)
+	}
+	current.LastDirectories[kind] = dir
+}
+
+// GetUndoHistoryLimit 取得每個資料表最多保留的復原步驟數
+func GetUndoHistoryLimit() int {
+	mu.RLock()
+	defer mu.RUnlock()
+	if current.UndoHistoryLimit <= 0 {
+		return DefaultUndoHistoryLimit
+	}
+	return current.UndoHistoryLimit
+}
+
+// SetUndoHistoryLimit 設定每個資料表最多保留的復原步驟數
+func SetUndoHistoryLimit(limit int) {
+	mu.Lock()
+	defer mu.Unlock()
+	current.UndoHistoryLimit = limit
+}
+
 // Path 傳回設定檔路徑
 func Path() string {
 	return cfgPath
@@ -0,0 +1,30 @@
+// 錯誤服務 - 解析後端回傳的結構化錯誤
+
+// 後端 ServiceError 經 ErrorFormatter 轉換後的內容
+export interface ServiceError {
+    code: string;
+    messageKey: string;
+    message: string;
+    localizedMessage: string;
+    details?: Record<string, any>;
+}
+
+// 判斷是否為後端回傳的結構化錯誤
+export function isServiceError(err: unknown): err is ServiceError {
+    return typeof err === 'object' && err !== null && 'code' in err && 'messageKey' in err;
+}
+
+// 將錯誤轉為顯示用文字：優先使用目前語言的訊息，並附上英文說明中的細節
+export function formatError(err: unknown): string {
+    if (isServiceError(err)) {
+        const localized = err.localizedMessage || err.message;
+        if (err.message && err.message !== localized) {
+            return `${localized}（${err.message}）`;
+        }
+        return localized;
+    }
+    if (err instanceof Error) {
+        return err.message;
+    }
+    return String(err);
+}
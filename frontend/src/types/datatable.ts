@@ -1,21 +1,30 @@
 // Table 資料結構的類型定義
 export interface Column {
-  id: number;
-  name: string;
+  id: number; // 欄位 ID，重新命名與移動後保持不變
+  index: number; // 欄位目前的位置
+  name: string; // 欄名，可能留白
+  type: ColumnType; // 宣告的型別，空字串表示欄位沒有任何值、尚未決定
 }
 
-export interface Cell {
-  [key: string]: string | number;
-}
+export type ColumnType =
+  | ''
+  | 'numeric'
+  | 'integer'
+  | 'string'
+  | 'boolean'
+  | 'datetime'
+  | 'categorical';
+
+export type Cell = string | number | boolean | null;
 
 export interface Row {
   id: number;
-  cells: Cell;
+  cells: Cell[]; // 依欄位順序排列，cells[j] 對應 columns[j]
 }
 
 export interface TableData {
   columns: Column[];
-  rows: Row[];
+  rows: Row[]; // 長度為總列數；尚未向後端讀取的列為空位
 }
 
 // 統計數據接口
@@ -38,7 +47,7 @@ export interface EditingState {
 
 // 基於ID的儲存格編輯狀態
 export interface EditingStateByID {
-  tableID: number;
+  tableID: string;
   rowIndex: number;
   colIndex: number;
   colName: string;
@@ -48,7 +57,7 @@ export interface EditingStateByID {
 
 // 表格基本信息
 export interface TableInfo {
-  id: number;
+  id: string;
   name: string;
   rowCount: number;
   colCount: number;
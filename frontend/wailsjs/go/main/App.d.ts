@@ -1,69 +1,212 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {services} from '../models';
 
 export function AddCalculatedColumn(arg1:string,arg2:string,arg3:string):Promise<boolean>;
 
-export function AddCalculatedColumnByID(arg1:number,arg2:string,arg3:string):Promise<boolean>;
+export function AddCalculatedColumnByID(arg1:string,arg2:string,arg3:string):Promise<boolean>;
 
 export function AddColumn(arg1:string,arg2:string):Promise<boolean>;
 
-export function AddColumnByID(arg1:number,arg2:string):Promise<boolean>;
+export function AddColumnByID(arg1:string,arg2:string):Promise<boolean>;
 
 export function AddRow(arg1:string):Promise<boolean>;
 
-export function AddRowByID(arg1:number):Promise<boolean>;
+export function AddRowByID(arg1:string):Promise<boolean>;
+
+export function BeginEditGroup(arg1:string,arg2:string):Promise<boolean>;
+
+export function ChiSquareIndependence(arg1:string,arg2:number,arg3:number):Promise<services.TestResult>;
+
+export function CorrelationMatrix(arg1:string,arg2:Array<number>,arg3:string):Promise<services.CorrelationResult>;
+
+export function CreateCorrelationTables(arg1:services.CorrelationResult):Promise<Array<string>>;
 
 export function CreateEmptyTable(arg1:string):Promise<boolean>;
 
-export function CreateEmptyTableByID(arg1:number,arg2:string):Promise<number>;
+export function CreateEmptyTableByID(arg1:number,arg2:string):Promise<string>;
+
+export function CreateRegressionTables(arg1:services.RegressionResult):Promise<Array<string>>;
+
+export function CreateTestResultTable(arg1:services.TestResult):Promise<string>;
+
+export function DeleteColumnsByID(arg1:string,arg2:number,arg3:number):Promise<boolean>;
+
+export function DeleteRowsByID(arg1:string,arg2:number,arg3:number):Promise<boolean>;
+
+export function DescribeArrowFile(arg1:string):Promise<services.ColumnarFileInfo>;
+
+export function DescribeParquetFile(arg1:string):Promise<services.ColumnarFileInfo>;
+
+export function DescribeSQLiteTables(arg1:string):Promise<Array<services.SQLiteTableInfo>>;
+
+export function DetectCSVOptions(arg1:string):Promise<services.CSVImportOptions>;
+
+export function DuplicateColumnsByID(arg1:string,arg2:number,arg3:number):Promise<boolean>;
+
+export function DuplicateRowsByID(arg1:string,arg2:number,arg3:number):Promise<boolean>;
+
+export function EndEditGroup(arg1:string):Promise<boolean>;
+
+export function ExportTableAsArrow(arg1:string,arg2:string):Promise<boolean>;
+
+export function ExportTableAsArrowWithOptions(arg1:string,arg2:string,arg3:services.ColumnarExportOptions):Promise<boolean>;
+
+export function ExportTableAsCSV(arg1:string,arg2:string):Promise<boolean>;
+
+export function ExportTableAsCSVWithOptions(arg1:string,arg2:string,arg3:services.CSVExportOptions):Promise<boolean>;
+
+export function ExportTableAsExcel(arg1:string,arg2:string):Promise<boolean>;
 
-export function ExportTableAsCSV(arg1:number,arg2:string):Promise<boolean>;
+export function ExportTableAsJSON(arg1:string,arg2:string):Promise<boolean>;
 
-export function ExportTableAsExcel(arg1:number,arg2:string):Promise<boolean>;
+export function ExportTableAsJSONWithOptions(arg1:string,arg2:string,arg3:services.JSONExportOptions):Promise<boolean>;
 
-export function ExportTableAsJSON(arg1:number,arg2:string):Promise<boolean>;
+export function ExportTableAsParquet(arg1:string,arg2:string):Promise<boolean>;
+
+export function ExportTableAsParquetWithOptions(arg1:string,arg2:string,arg3:services.ColumnarExportOptions):Promise<boolean>;
+
+export function ExportTableAsSPSS(arg1:string,arg2:string):Promise<boolean>;
+
+export function ExportTableAsStata(arg1:string,arg2:string):Promise<boolean>;
+
+export function ExportTablesAsExcel(arg1:Array<string>,arg2:string):Promise<boolean>;
+
+export function GetColumnFormulas(arg1:string):Promise<Array<services.ColumnFormulaInfo>>;
+
+export function GetColumnMissingPolicy(arg1:string,arg2:number):Promise<services.ColumnMissingPolicy>;
+
+export function GetColumnStatistics(arg1:string,arg2:number):Promise<services.ColumnStatistics>;
 
 export function GetCurrentLanguage():Promise<string>;
 
 export function GetCurrentProjectPath():Promise<string>;
 
+export function GetDefaultArrowExportOptions():Promise<services.ColumnarExportOptions>;
+
+export function GetDefaultCSVExportOptions():Promise<services.CSVExportOptions>;
+
+export function GetDefaultParquetExportOptions():Promise<services.ColumnarExportOptions>;
+
+export function GetDirtyState():Promise<services.DirtyState>;
+
+export function GetExcelSheets(arg1:string):Promise<Array<services.ExcelSheetInfo>>;
+
+export function GetHistoryState(arg1:string):Promise<services.HistoryState>;
+
+export function GetMissingCounts(arg1:string):Promise<Array<services.MissingCount>>;
+
+export function GetMissingPattern(arg1:string):Promise<services.MissingPattern>;
+
+export function GetMissingPolicy():Promise<services.MissingPolicy>;
+
 export function GetParamValue(arg1:string):Promise<string>;
 
 export function GetSQLiteTables(arg1:string):Promise<Array<string>>;
 
+export function GetTabOrder():Promise<Array<string>>;
+
 export function GetTableCount():Promise<number>;
 
 export function GetTableData(arg1:string):Promise<Record<string, any>>;
 
-export function GetTableDataByID(arg1:number):Promise<Record<string, any>>;
+export function GetTableDataByID(arg1:string):Promise<Record<string, any>>;
 
-export function GetTableInfo(arg1:number):Promise<Record<string, any>>;
+export function GetTableInfo(arg1:string):Promise<Record<string, any>>;
 
 export function GetTableNames():Promise<Array<string>>;
 
+export function GetTableRevision(arg1:string):Promise<number>;
+
+export function GetTableStatistics(arg1:string):Promise<services.TableStatistics>;
+
+export function GetTableWindow(arg1:string,arg2:number,arg3:number,arg4:number,arg5:number):Promise<services.TableWindow>;
+
 export function GetText(arg1:string):Promise<string>;
 
+export function GetUndoHistoryLimit():Promise<number>;
+
+export function GetVariableView(arg1:string):Promise<Array<services.VariableInfo>>;
+
 export function HasUnsavedChanges():Promise<boolean>;
 
+export function IndependentTTest(arg1:string,arg2:number,arg3:number,arg4:boolean,arg5:number):Promise<services.TestResult>;
+
+export function InsertColumnsByID(arg1:string,arg2:number,arg3:number):Promise<boolean>;
+
+export function InsertRowsByID(arg1:string,arg2:number,arg3:number):Promise<boolean>;
+
+export function IsTableDirty(arg1:string):Promise<boolean>;
+
+export function LinearRegression(arg1:string,arg2:number,arg3:Array<number>,arg4:number):Promise<services.RegressionResult>;
+
 export function LoadProject(arg1:string):Promise<boolean>;
 
 export function LoadTable(arg1:string,arg2:string):Promise<boolean>;
 
-export function LoadTableByID(arg1:number,arg2:string,arg3:string):Promise<number>;
+export function LoadTableByID(arg1:number,arg2:string,arg3:string):Promise<string>;
+
+export function MarkAsModified():Promise<void>;
 
 export function MarkAsSaved():Promise<void>;
 
-export function OpenCSVFile(arg1:string):Promise<number>;
+export function MoveColumnsByID(arg1:string,arg2:number,arg3:number,arg4:number):Promise<boolean>;
+
+export function MoveRowsByID(arg1:string,arg2:number,arg3:number,arg4:number):Promise<boolean>;
+
+export function MoveTab(arg1:string,arg2:number):Promise<boolean>;
+
+export function OneSampleTTest(arg1:string,arg2:number,arg3:number,arg4:number):Promise<services.TestResult>;
+
+export function OneWayANOVA(arg1:string,arg2:number,arg3:number):Promise<services.TestResult>;
+
+export function OpenArrowFile(arg1:string):Promise<string>;
+
+export function OpenArrowFileWithOptions(arg1:string,arg2:services.ColumnarImportOptions):Promise<string>;
+
+export function OpenCSVFile(arg1:string):Promise<string>;
+
+export function OpenCSVFileWithOptions(arg1:string,arg2:services.CSVImportOptions):Promise<string>;
+
+export function OpenDirectoryDialog(arg1:string):Promise<string>;
+
+export function OpenExcelFile(arg1:string):Promise<string>;
+
+export function OpenExcelFileWithOptions(arg1:string,arg2:services.ExcelImportOptions):Promise<string>;
 
 export function OpenFileDialog(arg1:string):Promise<string>;
 
-export function OpenJSONFile(arg1:string):Promise<number>;
+export function OpenJSONFile(arg1:string):Promise<string>;
+
+export function OpenMultipleFilesDialog(arg1:string):Promise<Array<string>>;
 
-export function OpenSQLiteFile(arg1:string,arg2:string):Promise<number>;
+export function OpenParquetFile(arg1:string):Promise<string>;
+
+export function OpenParquetFileWithOptions(arg1:string,arg2:services.ColumnarImportOptions):Promise<string>;
+
+export function OpenSPSSFile(arg1:string):Promise<string>;
+
+export function OpenSQLiteFile(arg1:string,arg2:string):Promise<string>;
+
+export function OpenSQLiteQuery(arg1:string,arg2:string):Promise<string>;
+
+export function OpenStataFile(arg1:string):Promise<string>;
+
+export function PairedTTest(arg1:string,arg2:number,arg3:number,arg4:number):Promise<services.TestResult>;
+
+export function PreviewCCL(arg1:string,arg2:string,arg3:number):Promise<services.CCLPreview>;
+
+export function PreviewCSVFile(arg1:string,arg2:services.CSVImportOptions,arg3:number):Promise<services.CSVPreview>;
+
+export function Redo(arg1:string):Promise<boolean>;
 
 export function RemoveTable(arg1:string):Promise<boolean>;
 
-export function RemoveTableByID(arg1:number):Promise<boolean>;
+export function RemoveTableByID(arg1:string):Promise<boolean>;
+
+export function RestoreRemovedTable():Promise<string>;
+
+export function SaveFileDialog(arg1:string,arg2:string):Promise<string>;
 
 export function SaveProject(arg1:string):Promise<boolean>;
 
@@ -71,14 +214,32 @@ export function SaveProjectAs(arg1:string):Promise<boolean>;
 
 export function SaveTable(arg1:string,arg2:string):Promise<boolean>;
 
-export function SaveTableByID(arg1:number,arg2:string):Promise<boolean>;
+export function SaveTableByID(arg1:string,arg2:string):Promise<boolean>;
+
+export function SetColumnFormula(arg1:string,arg2:number,arg3:string):Promise<boolean>;
+
+export function SetColumnMissingPolicy(arg1:string,arg2:number,arg3:services.MissingPolicy):Promise<boolean>;
+
+export function SetColumnType(arg1:string,arg2:number,arg3:string,arg4:boolean):Promise<boolean>;
 
 export function SetLanguage(arg1:string):Promise<void>;
 
+export function SetMissingPolicy(arg1:services.MissingPolicy):Promise<boolean>;
+
+export function SetUndoHistoryLimit(arg1:number):Promise<void>;
+
+export function SetVariableMetadata(arg1:string,arg2:number,arg3:services.VariableMetadata):Promise<boolean>;
+
+export function Undo(arg1:string):Promise<boolean>;
+
 export function UpdateCellValue(arg1:string,arg2:number,arg3:number,arg4:string):Promise<boolean>;
 
-export function UpdateCellValueByID(arg1:number,arg2:number,arg3:number,arg4:string):Promise<boolean>;
+export function UpdateCellValueByID(arg1:string,arg2:number,arg3:number,arg4:string):Promise<boolean>;
+
+export function UpdateCellValuesByID(arg1:string,arg2:Array<services.CellUpdate>):Promise<boolean>;
 
 export function UpdateColumnName(arg1:string,arg2:number,arg3:string):Promise<boolean>;
 
-export function UpdateColumnNameByID(arg1:number,arg2:number,arg3:string):Promise<boolean>;
+export function UpdateColumnNameByID(arg1:string,arg2:number,arg3:string):Promise<boolean>;
+
+export function ValidateCCL(arg1:string,arg2:string):Promise<services.CCLValidation>;
@@ -26,6 +26,22 @@ export function AddRowByID(arg1) {
   return window['go']['main']['App']['AddRowByID'](arg1);
 }
 
+export function BeginEditGroup(arg1, arg2) {
+  return window['go']['main']['App']['BeginEditGroup'](arg1, arg2);
+}
+
+export function ChiSquareIndependence(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ChiSquareIndependence'](arg1, arg2, arg3);
+}
+
+export function CorrelationMatrix(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CorrelationMatrix'](arg1, arg2, arg3);
+}
+
+export function CreateCorrelationTables(arg1) {
+  return window['go']['main']['App']['CreateCorrelationTables'](arg1);
+}
+
 export function CreateEmptyTable(arg1) {
   return window['go']['main']['App']['CreateEmptyTable'](arg1);
 }
@@ -34,10 +50,66 @@ export function CreateEmptyTableByID(arg1, arg2) {
   return window['go']['main']['App']['CreateEmptyTableByID'](arg1, arg2);
 }
 
+export function CreateRegressionTables(arg1) {
+  return window['go']['main']['App']['CreateRegressionTables'](arg1);
+}
+
+export function CreateTestResultTable(arg1) {
+  return window['go']['main']['App']['CreateTestResultTable'](arg1);
+}
+
+export function DeleteColumnsByID(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DeleteColumnsByID'](arg1, arg2, arg3);
+}
+
+export function DeleteRowsByID(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DeleteRowsByID'](arg1, arg2, arg3);
+}
+
+export function DescribeArrowFile(arg1) {
+  return window['go']['main']['App']['DescribeArrowFile'](arg1);
+}
+
+export function DescribeParquetFile(arg1) {
+  return window['go']['main']['App']['DescribeParquetFile'](arg1);
+}
+
+export function DescribeSQLiteTables(arg1) {
+  return window['go']['main']['App']['DescribeSQLiteTables'](arg1);
+}
+
+export function DetectCSVOptions(arg1) {
+  return window['go']['main']['App']['DetectCSVOptions'](arg1);
+}
+
+export function DuplicateColumnsByID(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DuplicateColumnsByID'](arg1, arg2, arg3);
+}
+
+export function DuplicateRowsByID(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DuplicateRowsByID'](arg1, arg2, arg3);
+}
+
+export function EndEditGroup(arg1) {
+  return window['go']['main']['App']['EndEditGroup'](arg1);
+}
+
+export function ExportTableAsArrow(arg1, arg2) {
+  return window['go']['main']['App']['ExportTableAsArrow'](arg1, arg2);
+}
+
+export function ExportTableAsArrowWithOptions(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportTableAsArrowWithOptions'](arg1, arg2, arg3);
+}
+
 export function ExportTableAsCSV(arg1, arg2) {
   return window['go']['main']['App']['ExportTableAsCSV'](arg1, arg2);
 }
 
+export function ExportTableAsCSVWithOptions(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportTableAsCSVWithOptions'](arg1, arg2, arg3);
+}
+
 export function ExportTableAsExcel(arg1, arg2) {
   return window['go']['main']['App']['ExportTableAsExcel'](arg1, arg2);
 }
@@ -46,6 +118,42 @@ export function ExportTableAsJSON(arg1, arg2) {
   return window['go']['main']['App']['ExportTableAsJSON'](arg1, arg2);
 }
 
+export function ExportTableAsJSONWithOptions(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportTableAsJSONWithOptions'](arg1, arg2, arg3);
+}
+
+export function ExportTableAsParquet(arg1, arg2) {
+  return window['go']['main']['App']['ExportTableAsParquet'](arg1, arg2);
+}
+
+export function ExportTableAsParquetWithOptions(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportTableAsParquetWithOptions'](arg1, arg2, arg3);
+}
+
+export function ExportTableAsSPSS(arg1, arg2) {
+  return window['go']['main']['App']['ExportTableAsSPSS'](arg1, arg2);
+}
+
+export function ExportTableAsStata(arg1, arg2) {
+  return window['go']['main']['App']['ExportTableAsStata'](arg1, arg2);
+}
+
+export function ExportTablesAsExcel(arg1, arg2) {
+  return window['go']['main']['App']['ExportTablesAsExcel'](arg1, arg2);
+}
+
+export function GetColumnFormulas(arg1) {
+  return window['go']['main']['App']['GetColumnFormulas'](arg1);
+}
+
+export function GetColumnMissingPolicy(arg1, arg2) {
+  return window['go']['main']['App']['GetColumnMissingPolicy'](arg1, arg2);
+}
+
+export function GetColumnStatistics(arg1, arg2) {
+  return window['go']['main']['App']['GetColumnStatistics'](arg1, arg2);
+}
+
 export function GetCurrentLanguage() {
   return window['go']['main']['App']['GetCurrentLanguage']();
 }
@@ -54,6 +162,42 @@ export function GetCurrentProjectPath() {
   return window['go']['main']['App']['GetCurrentProjectPath']();
 }
 
+export function GetDefaultArrowExportOptions() {
+  return window['go']['main']['App']['GetDefaultArrowExportOptions']();
+}
+
+export function GetDefaultCSVExportOptions() {
+  return window['go']['main']['App']['GetDefaultCSVExportOptions']();
+}
+
+export function GetDefaultParquetExportOptions() {
+  return window['go']['main']['App']['GetDefaultParquetExportOptions']();
+}
+
+export function GetDirtyState() {
+  return window['go']['main']['App']['GetDirtyState']();
+}
+
+export function GetExcelSheets(arg1) {
+  return window['go']['main']['App']['GetExcelSheets'](arg1);
+}
+
+export function GetHistoryState(arg1) {
+  return window['go']['main']['App']['GetHistoryState'](arg1);
+}
+
+export function GetMissingCounts(arg1) {
+  return window['go']['main']['App']['GetMissingCounts'](arg1);
+}
+
+export function GetMissingPattern(arg1) {
+  return window['go']['main']['App']['GetMissingPattern'](arg1);
+}
+
+export function GetMissingPolicy() {
+  return window['go']['main']['App']['GetMissingPolicy']();
+}
+
 export function GetParamValue(arg1) {
   return window['go']['main']['App']['GetParamValue'](arg1);
 }
@@ -62,6 +206,10 @@ export function GetSQLiteTables(arg1) {
   return window['go']['main']['App']['GetSQLiteTables'](arg1);
 }
 
+export function GetTabOrder() {
+  return window['go']['main']['App']['GetTabOrder']();
+}
+
 export function GetTableCount() {
   return window['go']['main']['App']['GetTableCount']();
 }
@@ -82,14 +230,54 @@ export function GetTableNames() {
   return window['go']['main']['App']['GetTableNames']();
 }
 
+export function GetTableRevision(arg1) {
+  return window['go']['main']['App']['GetTableRevision'](arg1);
+}
+
+export function GetTableStatistics(arg1) {
+  return window['go']['main']['App']['GetTableStatistics'](arg1);
+}
+
+export function GetTableWindow(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['GetTableWindow'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function GetText(arg1) {
   return window['go']['main']['App']['GetText'](arg1);
 }
 
+export function GetUndoHistoryLimit() {
+  return window['go']['main']['App']['GetUndoHistoryLimit']();
+}
+
+export function GetVariableView(arg1) {
+  return window['go']['main']['App']['GetVariableView'](arg1);
+}
+
 export function HasUnsavedChanges() {
   return window['go']['main']['App']['HasUnsavedChanges']();
 }
 
+export function IndependentTTest(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['IndependentTTest'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function InsertColumnsByID(arg1, arg2, arg3) {
+  return window['go']['main']['App']['InsertColumnsByID'](arg1, arg2, arg3);
+}
+
+export function InsertRowsByID(arg1, arg2, arg3) {
+  return window['go']['main']['App']['InsertRowsByID'](arg1, arg2, arg3);
+}
+
+export function IsTableDirty(arg1) {
+  return window['go']['main']['App']['IsTableDirty'](arg1);
+}
+
+export function LinearRegression(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['LinearRegression'](arg1, arg2, arg3, arg4);
+}
+
 export function LoadProject(arg1) {
   return window['go']['main']['App']['LoadProject'](arg1);
 }
@@ -102,14 +290,62 @@ export function LoadTableByID(arg1, arg2, arg3) {
   return window['go']['main']['App']['LoadTableByID'](arg1, arg2, arg3);
 }
 
+export function MarkAsModified() {
+  return window['go']['main']['App']['MarkAsModified']();
+}
+
 export function MarkAsSaved() {
   return window['go']['main']['App']['MarkAsSaved']();
 }
 
+export function MoveColumnsByID(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['MoveColumnsByID'](arg1, arg2, arg3, arg4);
+}
+
+export function MoveRowsByID(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['MoveRowsByID'](arg1, arg2, arg3, arg4);
+}
+
+export function MoveTab(arg1, arg2) {
+  return window['go']['main']['App']['MoveTab'](arg1, arg2);
+}
+
+export function OneSampleTTest(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['OneSampleTTest'](arg1, arg2, arg3, arg4);
+}
+
+export function OneWayANOVA(arg1, arg2, arg3) {
+  return window['go']['main']['App']['OneWayANOVA'](arg1, arg2, arg3);
+}
+
+export function OpenArrowFile(arg1) {
+  return window['go']['main']['App']['OpenArrowFile'](arg1);
+}
+
+export function OpenArrowFileWithOptions(arg1, arg2) {
+  return window['go']['main']['App']['OpenArrowFileWithOptions'](arg1, arg2);
+}
+
 export function OpenCSVFile(arg1) {
   return window['go']['main']['App']['OpenCSVFile'](arg1);
 }
 
+export function OpenCSVFileWithOptions(arg1, arg2) {
+  return window['go']['main']['App']['OpenCSVFileWithOptions'](arg1, arg2);
+}
+
+export function OpenDirectoryDialog(arg1) {
+  return window['go']['main']['App']['OpenDirectoryDialog'](arg1);
+}
+
+export function OpenExcelFile(arg1) {
+  return window['go']['main']['App']['OpenExcelFile'](arg1);
+}
+
+export function OpenExcelFileWithOptions(arg1, arg2) {
+  return window['go']['main']['App']['OpenExcelFileWithOptions'](arg1, arg2);
+}
+
 export function OpenFileDialog(arg1) {
   return window['go']['main']['App']['OpenFileDialog'](arg1);
 }
@@ -118,10 +354,50 @@ export function OpenJSONFile(arg1) {
   return window['go']['main']['App']['OpenJSONFile'](arg1);
 }
 
+export function OpenMultipleFilesDialog(arg1) {
+  return window['go']['main']['App']['OpenMultipleFilesDialog'](arg1);
+}
+
+export function OpenParquetFile(arg1) {
+  return window['go']['main']['App']['OpenParquetFile'](arg1);
+}
+
+export function OpenParquetFileWithOptions(arg1, arg2) {
+  return window['go']['main']['App']['OpenParquetFileWithOptions'](arg1, arg2);
+}
+
+export function OpenSPSSFile(arg1) {
+  return window['go']['main']['App']['OpenSPSSFile'](arg1);
+}
+
 export function OpenSQLiteFile(arg1, arg2) {
   return window['go']['main']['App']['OpenSQLiteFile'](arg1, arg2);
 }
 
+export function OpenSQLiteQuery(arg1, arg2) {
+  return window['go']['main']['App']['OpenSQLiteQuery'](arg1, arg2);
+}
+
+export function OpenStataFile(arg1) {
+  return window['go']['main']['App']['OpenStataFile'](arg1);
+}
+
+export function PairedTTest(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['PairedTTest'](arg1, arg2, arg3, arg4);
+}
+
+export function PreviewCCL(arg1, arg2, arg3) {
+  return window['go']['main']['App']['PreviewCCL'](arg1, arg2, arg3);
+}
+
+export function PreviewCSVFile(arg1, arg2, arg3) {
+  return window['go']['main']['App']['PreviewCSVFile'](arg1, arg2, arg3);
+}
+
+export function Redo(arg1) {
+  return window['go']['main']['App']['Redo'](arg1);
+}
+
 export function RemoveTable(arg1) {
   return window['go']['main']['App']['RemoveTable'](arg1);
 }
@@ -130,6 +406,14 @@ export function RemoveTableByID(arg1) {
   return window['go']['main']['App']['RemoveTableByID'](arg1);
 }
 
+export function RestoreRemovedTable() {
+  return window['go']['main']['App']['RestoreRemovedTable']();
+}
+
+export function SaveFileDialog(arg1, arg2) {
+  return window['go']['main']['App']['SaveFileDialog'](arg1, arg2);
+}
+
 export function SaveProject(arg1) {
   return window['go']['main']['App']['SaveProject'](arg1);
 }
@@ -146,10 +430,38 @@ export function SaveTableByID(arg1, arg2) {
   return window['go']['main']['App']['SaveTableByID'](arg1, arg2);
 }
 
+export function SetColumnFormula(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetColumnFormula'](arg1, arg2, arg3);
+}
+
+export function SetColumnMissingPolicy(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetColumnMissingPolicy'](arg1, arg2, arg3);
+}
+
+export function SetColumnType(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SetColumnType'](arg1, arg2, arg3, arg4);
+}
+
 export function SetLanguage(arg1) {
   return window['go']['main']['App']['SetLanguage'](arg1);
 }
 
+export function SetMissingPolicy(arg1) {
+  return window['go']['main']['App']['SetMissingPolicy'](arg1);
+}
+
+export function SetUndoHistoryLimit(arg1) {
+  return window['go']['main']['App']['SetUndoHistoryLimit'](arg1);
+}
+
+export function SetVariableMetadata(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetVariableMetadata'](arg1, arg2, arg3);
+}
+
+export function Undo(arg1) {
+  return window['go']['main']['App']['Undo'](arg1);
+}
+
 export function UpdateCellValue(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['UpdateCellValue'](arg1, arg2, arg3, arg4);
 }
@@ -158,6 +470,10 @@ export function UpdateCellValueByID(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['UpdateCellValueByID'](arg1, arg2, arg3, arg4);
 }
 
+export function UpdateCellValuesByID(arg1, arg2) {
+  return window['go']['main']['App']['UpdateCellValuesByID'](arg1, arg2);
+}
+
 export function UpdateColumnName(arg1, arg2, arg3) {
   return window['go']['main']['App']['UpdateColumnName'](arg1, arg2, arg3);
 }
@@ -165,3 +481,7 @@ export function UpdateColumnName(arg1, arg2, arg3) {
 export function UpdateColumnNameByID(arg1, arg2, arg3) {
   return window['go']['main']['App']['UpdateColumnNameByID'](arg1, arg2, arg3);
 }
+
+export function ValidateCCL(arg1, arg2) {
+  return window['go']['main']['App']['ValidateCCL'](arg1, arg2);
+}
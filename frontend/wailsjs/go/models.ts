@@ -0,0 +1,1038 @@
+export namespace services {
+	
+	export class CCLColumnRef {
+	    letter: string;
+	    index: number;
+	    name: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CCLColumnRef(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.letter = source["letter"];
+	        this.index = source["index"];
+	        this.name = source["name"];
+	    }
+	}
+	export class CCLDiagnostic {
+	    offset: number;
+	    length: number;
+	    token: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CCLDiagnostic(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.offset = source["offset"];
+	        this.length = source["length"];
+	        this.token = source["token"];
+	        this.message = source["message"];
+	    }
+	}
+	export class CCLPreview {
+	    values: any[];
+	    resultType: string;
+	    totalRows: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CCLPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.values = source["values"];
+	        this.resultType = source["resultType"];
+	        this.totalRows = source["totalRows"];
+	    }
+	}
+	export class CCLValidation {
+	    valid: boolean;
+	    errors: CCLDiagnostic[];
+	    referencedColumns: CCLColumnRef[];
+	    resultType: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CCLValidation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.valid = source["valid"];
+	        this.errors = this.convertValues(source["errors"], CCLDiagnostic);
+	        this.referencedColumns = this.convertValues(source["referencedColumns"], CCLColumnRef);
+	        this.resultType = source["resultType"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CSVExportOptions {
+	    delimiter: string;
+	    quote: string;
+	    quoteAll: boolean;
+	    encoding: string;
+	    naToken: string;
+	    omitHeader: boolean;
+	    decimalSeparator: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CSVExportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.delimiter = source["delimiter"];
+	        this.quote = source["quote"];
+	        this.quoteAll = source["quoteAll"];
+	        this.encoding = source["encoding"];
+	        this.naToken = source["naToken"];
+	        this.omitHeader = source["omitHeader"];
+	        this.decimalSeparator = source["decimalSeparator"];
+	    }
+	}
+	export class CSVImportOptions {
+	    encoding: string;
+	    delimiter: string;
+	    quote: string;
+	    hasHeader: boolean;
+	    decimalSeparator: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CSVImportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.encoding = source["encoding"];
+	        this.delimiter = source["delimiter"];
+	        this.quote = source["quote"];
+	        this.hasHeader = source["hasHeader"];
+	        this.decimalSeparator = source["decimalSeparator"];
+	    }
+	}
+	export class CSVPreview {
+	    options: CSVImportOptions;
+	    header: string[];
+	    rows: any[][];
+	    truncated: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CSVPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.options = this.convertValues(source["options"], CSVImportOptions);
+	        this.header = source["header"];
+	        this.rows = source["rows"];
+	        this.truncated = source["truncated"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CellUpdate {
+	    row: number;
+	    col: number;
+	    value: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CellUpdate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.row = source["row"];
+	        this.col = source["col"];
+	        this.value = source["value"];
+	    }
+	}
+	export class ColumnFormulaInfo {
+	    col: number;
+	    letter: string;
+	    name: string;
+	    formula: string;
+	    dependsOn: number[];
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ColumnFormulaInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.col = source["col"];
+	        this.letter = source["letter"];
+	        this.name = source["name"];
+	        this.formula = source["formula"];
+	        this.dependsOn = source["dependsOn"];
+	        this.error = source["error"];
+	    }
+	}
+	export class MissingPolicy {
+	    tokens: string[];
+	    exportToken: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MissingPolicy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tokens = source["tokens"];
+	        this.exportToken = source["exportToken"];
+	    }
+	}
+	export class ColumnMissingPolicy {
+	    policy: MissingPolicy;
+	    custom: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ColumnMissingPolicy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.policy = this.convertValues(source["policy"], MissingPolicy);
+	        this.custom = source["custom"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ColumnStatistics {
+	    col: number;
+	    name: string;
+	    type: string;
+	    count: number;
+	    missing: number;
+	    unique: number;
+	    mode: any[];
+	    mean?: number;
+	    median?: number;
+	    std?: number;
+	    variance?: number;
+	    min?: number;
+	    max?: number;
+	    q1?: number;
+	    q3?: number;
+	    skewness?: number;
+	    kurtosis?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ColumnStatistics(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.col = source["col"];
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.count = source["count"];
+	        this.missing = source["missing"];
+	        this.unique = source["unique"];
+	        this.mode = source["mode"];
+	        this.mean = source["mean"];
+	        this.median = source["median"];
+	        this.std = source["std"];
+	        this.variance = source["variance"];
+	        this.min = source["min"];
+	        this.max = source["max"];
+	        this.q1 = source["q1"];
+	        this.q3 = source["q3"];
+	        this.skewness = source["skewness"];
+	        this.kurtosis = source["kurtosis"];
+	    }
+	}
+	export class ColumnarColumnInfo {
+	    name: string;
+	    fileType: string;
+	    type: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ColumnarColumnInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.fileType = source["fileType"];
+	        this.type = source["type"];
+	    }
+	}
+	export class ColumnarExportOptions {
+	    compression: string;
+	    groupRows: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ColumnarExportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.compression = source["compression"];
+	        this.groupRows = source["groupRows"];
+	    }
+	}
+	export class ColumnarFileInfo {
+	    rowCount: number;
+	    groups: number;
+	    columns: ColumnarColumnInfo[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ColumnarFileInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rowCount = source["rowCount"];
+	        this.groups = source["groups"];
+	        this.columns = this.convertValues(source["columns"], ColumnarColumnInfo);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ColumnarImportOptions {
+	    columns: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ColumnarImportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.columns = source["columns"];
+	    }
+	}
+	export class ConfidenceInterval {
+	    lower?: number;
+	    upper?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfidenceInterval(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.lower = source["lower"];
+	        this.upper = source["upper"];
+	    }
+	}
+	export class CorrelationResult {
+	    method: string;
+	    title: string;
+	    columns: string[];
+	    coefficients: number[][];
+	    pValues: number[][];
+	    n: number[][];
+	
+	    static createFrom(source: any = {}) {
+	        return new CorrelationResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.method = source["method"];
+	        this.title = source["title"];
+	        this.columns = source["columns"];
+	        this.coefficients = source["coefficients"];
+	        this.pValues = source["pValues"];
+	        this.n = source["n"];
+	    }
+	}
+	export class TableDirtyState {
+	    tableID: string;
+	    name: string;
+	    dirty: boolean;
+	    revision: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TableDirtyState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tableID = source["tableID"];
+	        this.name = source["name"];
+	        this.dirty = source["dirty"];
+	        this.revision = source["revision"];
+	    }
+	}
+	export class DirtyState {
+	    hasUnsavedChanges: boolean;
+	    projectPath: string;
+	    tables: TableDirtyState[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DirtyState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hasUnsavedChanges = source["hasUnsavedChanges"];
+	        this.projectPath = source["projectPath"];
+	        this.tables = this.convertValues(source["tables"], TableDirtyState);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class EffectSize {
+	    type: string;
+	    value?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new EffectSize(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.value = source["value"];
+	    }
+	}
+	export class ExcelImportOptions {
+	    sheet: string;
+	    range: string;
+	    headerRow: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExcelImportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sheet = source["sheet"];
+	        this.range = source["range"];
+	        this.headerRow = source["headerRow"];
+	    }
+	}
+	export class ExcelSheetInfo {
+	    name: string;
+	    visible: boolean;
+	    usedRange: string;
+	    rows: number;
+	    columns: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExcelSheetInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.visible = source["visible"];
+	        this.usedRange = source["usedRange"];
+	        this.rows = source["rows"];
+	        this.columns = source["columns"];
+	    }
+	}
+	export class GroupSummary {
+	    name: string;
+	    n: number;
+	    mean?: number;
+	    std?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new GroupSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.n = source["n"];
+	        this.mean = source["mean"];
+	        this.std = source["std"];
+	    }
+	}
+	export class HistoryState {
+	    canUndo: boolean;
+	    canRedo: boolean;
+	    undoLabel: string;
+	    redoLabel: string;
+	    undoCount: number;
+	    redoCount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.canUndo = source["canUndo"];
+	        this.canRedo = source["canRedo"];
+	        this.undoLabel = source["undoLabel"];
+	        this.redoLabel = source["redoLabel"];
+	        this.undoCount = source["undoCount"];
+	        this.redoCount = source["redoCount"];
+	    }
+	}
+	export class JSONExportOptions {
+	    orientation: string;
+	    indent: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new JSONExportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.orientation = source["orientation"];
+	        this.indent = source["indent"];
+	    }
+	}
+	export class MissingCount {
+	    col: number;
+	    name: string;
+	    count: number;
+	    missing: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new MissingCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.col = source["col"];
+	        this.name = source["name"];
+	        this.count = source["count"];
+	        this.missing = source["missing"];
+	    }
+	}
+	export class MissingPatternRow {
+	    missing: boolean[];
+	    rows: number;
+	    missingCount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new MissingPatternRow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.missing = source["missing"];
+	        this.rows = source["rows"];
+	        this.missingCount = source["missingCount"];
+	    }
+	}
+	export class MissingPattern {
+	    rowCount: number;
+	    columns: MissingCount[];
+	    patterns: MissingPatternRow[];
+	
+	    static createFrom(source: any = {}) {
+	        return new MissingPattern(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rowCount = source["rowCount"];
+	        this.columns = this.convertValues(source["columns"], MissingCount);
+	        this.patterns = this.convertValues(source["patterns"], MissingPatternRow);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class RegressionCoefficient {
+	    term: string;
+	    estimate?: number;
+	    stdError?: number;
+	    t?: number;
+	    pValue?: number;
+	    ciLower?: number;
+	    ciUpper?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RegressionCoefficient(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.term = source["term"];
+	        this.estimate = source["estimate"];
+	        this.stdError = source["stdError"];
+	        this.t = source["t"];
+	        this.pValue = source["pValue"];
+	        this.ciLower = source["ciLower"];
+	        this.ciUpper = source["ciUpper"];
+	    }
+	}
+	export class RegressionResult {
+	    title: string;
+	    dependent: string;
+	    independents: string[];
+	    coefficients: RegressionCoefficient[];
+	    rSquared?: number;
+	    adjustedRSquared?: number;
+	    f?: number;
+	    fPValue?: number;
+	    dfModel: number;
+	    dfResidual: number;
+	    residualStdError?: number;
+	    confidenceLevel: number;
+	    n: number;
+	    excluded: number;
+	    fitted: number[];
+	    residuals: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RegressionResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.dependent = source["dependent"];
+	        this.independents = source["independents"];
+	        this.coefficients = this.convertValues(source["coefficients"], RegressionCoefficient);
+	        this.rSquared = source["rSquared"];
+	        this.adjustedRSquared = source["adjustedRSquared"];
+	        this.f = source["f"];
+	        this.fPValue = source["fPValue"];
+	        this.dfModel = source["dfModel"];
+	        this.dfResidual = source["dfResidual"];
+	        this.residualStdError = source["residualStdError"];
+	        this.confidenceLevel = source["confidenceLevel"];
+	        this.n = source["n"];
+	        this.excluded = source["excluded"];
+	        this.fitted = source["fitted"];
+	        this.residuals = source["residuals"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SQLiteColumn {
+	    name: string;
+	    declaredType: string;
+	    affinity: string;
+	    notNull: boolean;
+	    primaryKey: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SQLiteColumn(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.declaredType = source["declaredType"];
+	        this.affinity = source["affinity"];
+	        this.notNull = source["notNull"];
+	        this.primaryKey = source["primaryKey"];
+	    }
+	}
+	export class SQLiteTableInfo {
+	    name: string;
+	    type: string;
+	    rowCount: number;
+	    columns: SQLiteColumn[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SQLiteTableInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.rowCount = source["rowCount"];
+	        this.columns = this.convertValues(source["columns"], SQLiteColumn);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TableColumn {
+	    id: number;
+	    index: number;
+	    name: string;
+	    type: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TableColumn(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.index = source["index"];
+	        this.name = source["name"];
+	        this.type = source["type"];
+	    }
+	}
+	
+	export class TableStatistics {
+	    rowCount: number;
+	    columnCount: number;
+	    cellCount: number;
+	    missingCells: number;
+	    numericColumns: number;
+	    columns: ColumnStatistics[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TableStatistics(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rowCount = source["rowCount"];
+	        this.columnCount = source["columnCount"];
+	        this.cellCount = source["cellCount"];
+	        this.missingCells = source["missingCells"];
+	        this.numericColumns = source["numericColumns"];
+	        this.columns = this.convertValues(source["columns"], ColumnStatistics);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TableWindow {
+	    rowCount: number;
+	    colCount: number;
+	    rowOffset: number;
+	    colOffset: number;
+	    columns: TableColumn[];
+	    rows: any[][];
+	
+	    static createFrom(source: any = {}) {
+	        return new TableWindow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rowCount = source["rowCount"];
+	        this.colCount = source["colCount"];
+	        this.rowOffset = source["rowOffset"];
+	        this.colOffset = source["colOffset"];
+	        this.columns = this.convertValues(source["columns"], TableColumn);
+	        this.rows = source["rows"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TestResult {
+	    test: string;
+	    title: string;
+	    columns: string[];
+	    statisticName: string;
+	    statistic?: number;
+	    df?: number;
+	    df2?: number;
+	    pValue?: number;
+	    confidenceLevel?: number;
+	    ci?: ConfidenceInterval;
+	    effectSizes: EffectSize[];
+	    groups: GroupSummary[];
+	    n: number;
+	    excluded: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TestResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.test = source["test"];
+	        this.title = source["title"];
+	        this.columns = source["columns"];
+	        this.statisticName = source["statisticName"];
+	        this.statistic = source["statistic"];
+	        this.df = source["df"];
+	        this.df2 = source["df2"];
+	        this.pValue = source["pValue"];
+	        this.confidenceLevel = source["confidenceLevel"];
+	        this.ci = this.convertValues(source["ci"], ConfidenceInterval);
+	        this.effectSizes = this.convertValues(source["effectSizes"], EffectSize);
+	        this.groups = this.convertValues(source["groups"], GroupSummary);
+	        this.n = source["n"];
+	        this.excluded = source["excluded"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ValueLabel {
+	    value: any;
+	    label: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ValueLabel(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.value = source["value"];
+	        this.label = source["label"];
+	    }
+	}
+	export class VariableInfo {
+	    col: number;
+	    id: number;
+	    name: string;
+	    type: string;
+	    formula: string;
+	    missing: ColumnMissingPolicy;
+	    label: string;
+	    description: string;
+	    measure: string;
+	    valueLabels: ValueLabel[];
+	    format: string;
+	    width: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new VariableInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.col = source["col"];
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.formula = source["formula"];
+	        this.missing = this.convertValues(source["missing"], ColumnMissingPolicy);
+	        this.label = source["label"];
+	        this.description = source["description"];
+	        this.measure = source["measure"];
+	        this.valueLabels = this.convertValues(source["valueLabels"], ValueLabel);
+	        this.format = source["format"];
+	        this.width = source["width"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class VariableMetadata {
+	    label: string;
+	    description: string;
+	    measure: string;
+	    valueLabels: ValueLabel[];
+	    format: string;
+	    width: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new VariableMetadata(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.label = source["label"];
+	        this.description = source["description"];
+	        this.measure = source["measure"];
+	        this.valueLabels = this.convertValues(source["valueLabels"], ValueLabel);
+	        this.format = source["format"];
+	        this.width = source["width"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
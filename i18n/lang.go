@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+
+	"insyra-insights/keypath"
 )
 
 func init() {
@@ -34,7 +36,11 @@ func LoadLanguage(code string) error {
 		return fmt.Errorf("json parse %s failed: %w", filePath, err)
 	}
 	result := make(map[string]string)
-	flatten(raw, "", result)
+	keypath.Flatten(keypath.Map(raw), "", func(key string, value any) {
+		if text, ok := value.(string); ok {
+			result[key] = text
+		}
+	})
 	loadedLangs[code] = result
 
 	if code == currentLang {
@@ -65,18 +71,3 @@ func T(key string) string {
 	log.Printf("⚠️ Missing key: %s", key)
 	return "??" + key + "??"
 }
-
-func flatten(input map[string]any, prefix string, output map[string]string) {
-	for k, v := range input {
-		fullKey := k
-		if prefix != "" {
-			fullKey = prefix + "." + k
-		}
-		switch val := v.(type) {
-		case string:
-			output[fullKey] = val
-		case map[string]any:
-			flatten(val, fullKey, output)
-		}
-	}
-}
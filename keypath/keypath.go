@@ -0,0 +1,47 @@
+package keypath
+
+import (
+	"maps"
+	"slices"
+)
+
+// Object 巢狀物件，Keys 決定展開的順序
+type Object interface {
+	Keys() []string
+	Get(key string) any
+}
+
+// Map 以一般的 map 表示的物件，依鍵名排序展開
+type Map map[string]any
+
+func (m Map) Keys() []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+func (m Map) Get(key string) any {
+	return m[key]
+}
+
+// Join 以點號連接上層的鍵與子鍵
+func Join(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// Flatten 將巢狀物件展開為以點號連接的鍵，例如 {"a":{"b":1}} → "a.b"；
+// 值為 Object 或 map[string]any 時繼續展開，其餘的值依序交給 leaf
+func Flatten(obj Object, prefix string, leaf func(key string, value any)) {
+	for _, k := range obj.Keys() {
+		fullKey := Join(prefix, k)
+		switch val := obj.Get(k).(type) {
+		case Object:
+			Flatten(val, fullKey, leaf)
+		case map[string]any:
+			Flatten(Map(val), fullKey, leaf)
+		default:
+			leaf(fullKey, val)
+		}
+	}
+}
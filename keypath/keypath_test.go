@@ -0,0 +1,25 @@
+package keypath
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFlattenMap(t *testing.T) {
+	input := Map{
+		"b": "x",
+		"a": map[string]any{"d": 1, "c": map[string]any{"e": true}},
+	}
+	var keys []string
+	values := make(map[string]any)
+	Flatten(input, "", func(key string, value any) {
+		keys = append(keys, key)
+		values[key] = value
+	})
+	if want := []string{"a.c.e", "a.d", "b"}; !slices.Equal(keys, want) {
+		t.Fatalf("keys = %v, want %v", keys, want)
+	}
+	if values["a.c.e"] != true || values["a.d"] != 1 || values["b"] != "x" {
+		t.Fatalf("values = %v", values)
+	}
+}
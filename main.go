@@ -2,8 +2,8 @@ package main
 
 import (
 	"embed"
+	"insyra-insights/services"
 
-	"github.com/HazelnutParadise/insyra"
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
@@ -32,14 +32,14 @@ func main() {
 	// 	}
 	// }
 
-	insyra.Config.SetDontPanic(true)
+	services.ConfigureInsyra()
 
 	// Create an instance of the app structure
 	app := NewApp()
 
 	// Create application with options
 	err := wails.Run(&options.App{
-		Title:  "Insyra Insights",
+		Title:  appTitle,
 		Width:  1280,
 		Height: 800,
 		AssetServer: &assetserver.Options{
@@ -47,6 +47,8 @@ func main() {
 		},
 		BackgroundColour: &options.RGBA{R: 245, G: 245, B: 245, A: 1},
 		OnStartup:        app.startup,
+		OnBeforeClose:    app.beforeClose,
+		ErrorFormatter:   formatError,
 		Bind: []any{
 			app,
 		},
@@ -0,0 +1,994 @@
+package services
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ===== Arrow IPC（Feather v2）匯入與匯出 =====
+//
+// 讀取 Arrow IPC 的檔案格式（.arrow、.feather）與串流格式（.arrows），只支援頂層的基本型別欄位與字典編碼欄位。
+// 讀取時逐個 record batch 只讀入選取欄位的緩衝區；寫出時使用檔案格式，每個 record batch 包含 GroupRows 列。
+
+// Arrow 的型別代碼（Type union）
+const (
+	arrowNull          = 1
+	arrowInt           = 2
+	arrowFloat         = 3
+	arrowBinary        = 4
+	arrowUtf8          = 5
+	arrowBool          = 6
+	arrowDecimal       = 7
+	arrowDate          = 8
+	arrowTime          = 9
+	arrowTimestamp     = 10
+	arrowInterval      = 11
+	arrowList          = 12
+	arrowStruct        = 13
+	arrowUnion         = 14
+	arrowFixedBinary   = 15
+	arrowFixedList     = 16
+	arrowMap           = 17
+	arrowDuration      = 18
+	arrowLargeBinary   = 19
+	arrowLargeUtf8     = 20
+	arrowLargeList     = 21
+	arrowRunEnd        = 22
+	arrowBinaryView    = 23
+	arrowUtf8View      = 24
+	arrowListView      = 25
+	arrowLargeListView = 26
+)
+
+var arrowTypeNames = map[uint8]string{
+	arrowNull: "null", arrowInt: "int", arrowFloat: "float", arrowBinary: "binary", arrowUtf8: "utf8", arrowBool: "bool",
+	arrowDecimal: "decimal", arrowDate: "date", arrowTime: "time", arrowTimestamp: "timestamp", arrowInterval: "interval",
+	arrowList: "list", arrowStruct: "struct", arrowUnion: "union", arrowFixedBinary: "fixed_size_binary",
+	arrowFixedList: "fixed_size_list", arrowMap: "map", arrowDuration: "duration", arrowLargeBinary: "large_binary",
+	arrowLargeUtf8: "large_utf8", arrowLargeList: "large_list", arrowRunEnd: "run_end_encoded",
+	arrowBinaryView: "binary_view", arrowUtf8View: "utf8_view", arrowListView: "list_view", arrowLargeListView: "large_list_view",
+}
+
+// Arrow 訊息的種類（MessageHeader union）
+const (
+	arrowSchemaMessage     = 1
+	arrowDictionaryMessage = 2
+	arrowBatchMessage      = 3
+)
+
+const (
+	arrowMagic        = "ARROW1"
+	arrowContinuation = 0xFFFFFFFF
+	arrowVersion      = 4 // MetadataVersion V5
+)
+
+var arrowUnits = []time.Duration{time.Second, time.Millisecond, time.Microsecond, time.Nanosecond}
+var arrowUnitNames = []string{"s", "ms", "us", "ns"}
+
+// OpenArrowFile 開啟 Arrow IPC 或 Feather 檔案的所有欄並創建新的資料表
+func (s *DataTableService) OpenArrowFile(filePath string) (string, error) {
+	return s.OpenArrowFileWithOptions(filePath, nil)
+}
+
+// OpenArrowFileWithOptions 只讀取選取的欄；逐個 record batch 讀取，不需要一次載入整個檔案
+func (s *DataTableService) OpenArrowFileWithOptions(filePath string, options *ColumnarImportOptions) (string, error) {
+	r, err := openArrow(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer r.file.Close()
+	return s.openColumnar(filePath, "arrow", options,
+		func() (ColumnarFileInfo, error) { return r.info(), nil },
+		r.read)
+}
+
+// DescribeArrowFile 取得 Arrow 檔案的列數、record batch 數與各欄型別，供選擇要匯入的欄
+func (s *DataTableService) DescribeArrowFile(filePath string) (ColumnarFileInfo, error) {
+	r, err := openArrow(filePath)
+	if err != nil {
+		return ColumnarFileInfo{}, err
+	}
+	defer r.file.Close()
+	return r.info(), nil
+}
+
+// ExportTableAsArrow 以預設設定（不壓縮）將資料表匯出為 Arrow IPC 檔案（Feather v2）
+func (s *DataTableService) ExportTableAsArrow(tableID string, filePath string) error {
+	return s.ExportTableAsArrowWithOptions(tableID, filePath, DefaultArrowExportOptions())
+}
+
+// ExportTableAsArrowWithOptions 以指定的壓縮方式與 record batch 大小將資料表匯出為 Arrow IPC 檔案
+func (s *DataTableService) ExportTableAsArrowWithOptions(tableID string, filePath string, options ColumnarExportOptions) error {
+	options, err := normalizeExportOptions(options, CompressionNone, CompressionZstd)
+	if err != nil {
+		return err
+	}
+	_, columns, err := s.columnarColumns(tableID)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("create arrow file: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	if err := writeArrow(w, columns, options); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ----- 讀取 -----
+
+// arrowReader 已開啟的 Arrow 檔案、欄位與各訊息的標頭
+type arrowReader struct {
+	file     *os.File
+	size     int64
+	fields   []*arrowField
+	messages []arrowMessage
+}
+
+// arrowMessage 字典或 record batch 訊息的標頭與內容的起始位置
+type arrowMessage struct {
+	kind   uint8
+	header fbTable
+	body   int64
+	length int64
+}
+
+// arrowField 一個欄位；字典編碼的欄位在 record batch 中儲存 index 型別的索引
+type arrowField struct {
+	info     ColumnarColumnInfo
+	value    arrowType
+	index    *arrowType
+	dictID   int64
+	children []*arrowField
+}
+
+// arrowType 值的型別與解碼所需的參數
+type arrowType struct {
+	id       uint8
+	name     string
+	dataType string // 空字串表示無法匯入
+	width    int    // 整數、時間與 decimal 的位元數，或固定長度二進位的位元組數
+	signed   bool
+	scale    int
+	unit     time.Duration
+	days     bool // Date 以天為單位
+	dense    bool // 密集的 union
+}
+
+// openArrow 開啟檔案，讀取結構與所有字典及 record batch 訊息的標頭
+func openArrow(filePath string) (*arrowReader, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("open arrow file: %w", err)
+	}
+	r := &arrowReader{file: f}
+	if err := r.readLayout(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *arrowReader) readAt(n int64, offset int64) ([]byte, error) {
+	if n < 0 || offset < 0 || n > columnarMaxBuffer || offset > r.size-n {
+		return nil, errFileInvalid("arrow", "data is out of range")
+	}
+	buf := make([]byte, n)
+	if _, err := r.file.ReadAt(buf, offset); err != nil {
+		return nil, fmt.Errorf("read arrow file: %w", err)
+	}
+	return buf, nil
+}
+
+func (r *arrowReader) readLayout() error {
+	stat, err := r.file.Stat()
+	if err != nil {
+		return fmt.Errorf("open arrow file: %w", err)
+	}
+	r.size = stat.Size()
+	head := make([]byte, min(r.size, 8))
+	if _, err := r.file.ReadAt(head, 0); err != nil {
+		return fmt.Errorf("read arrow file: %w", err)
+	}
+	switch {
+	case strings.HasPrefix(string(head), arrowMagic):
+		return r.readFileLayout()
+	case strings.HasPrefix(string(head), "FEA1"):
+		return errFileUnsupported("arrow", "feather version 1")
+	}
+	return r.readStreamLayout()
+}
+
+// readFileLayout 由檔案結尾取得結構與各訊息的位置
+func (r *arrowReader) readFileLayout() error {
+	tail, err := r.readAt(10, r.size-10)
+	if err != nil || string(tail[4:]) != arrowMagic {
+		return errFileInvalid("arrow", "file footer is missing")
+	}
+	n := int64(int32(binary.LittleEndian.Uint32(tail)))
+	buf, err := r.readAt(n, r.size-10-n)
+	if err != nil {
+		return errFileInvalid("arrow", "file footer is truncated")
+	}
+	footer, ok := fbRoot(buf)
+	if !ok {
+		return errFileInvalid("arrow", "file footer is corrupt")
+	}
+	schema, ok := footer.table(1)
+	if !ok {
+		return errFileInvalid("arrow", "schema is missing")
+	}
+	if err := r.readSchema(schema); err != nil {
+		return err
+	}
+	// Block：offset、metaDataLength（含填充）、bodyLength
+	blocks := append(footer.int64s(2, 3), footer.int64s(3, 3)...)
+	for _, block := range blocks {
+		message, err := r.readMessage(block[0], int64(int32(block[1])))
+		if err != nil {
+			return err
+		}
+		r.messages = append(r.messages, message)
+	}
+	return nil
+}
+
+// readStreamLayout 依序讀取串流格式的訊息標頭，略過內容
+func (r *arrowReader) readStreamLayout() error {
+	offset := int64(0)
+	for offset+4 <= r.size {
+		prefix, err := r.readAt(min(8, r.size-offset), offset)
+		if err != nil {
+			return err
+		}
+		size, skip := int64(binary.LittleEndian.Uint32(prefix)), int64(4)
+		if size == arrowContinuation {
+			if len(prefix) < 8 {
+				break
+			}
+			size, skip = int64(binary.LittleEndian.Uint32(prefix[4:])), 8
+		}
+		if size == 0 {
+			break
+		}
+		message, err := r.readMessage(offset, skip+size)
+		if err != nil {
+			return err
+		}
+		if message.kind == arrowSchemaMessage {
+			if r.fields != nil {
+				return errFileInvalid("arrow", "stream contains more than one schema")
+			}
+			if err := r.readSchema(message.header); err != nil {
+				return err
+			}
+		} else {
+			r.messages = append(r.messages, message)
+		}
+		offset = message.body + message.length
+	}
+	if r.fields == nil {
+		return errFileInvalid("arrow", "not an arrow file")
+	}
+	return nil
+}
+
+// readMessage 讀取位於 offset、長度為 size（含前綴）的訊息標頭
+func (r *arrowReader) readMessage(offset int64, size int64) (arrowMessage, error) {
+	buf, err := r.readAt(size, offset)
+	if err != nil || len(buf) < 8 {
+		return arrowMessage{}, errFileInvalid("arrow", "message is truncated")
+	}
+	meta := buf[4:]
+	if binary.LittleEndian.Uint32(buf) == arrowContinuation {
+		meta = buf[8:]
+	}
+	root, ok := fbRoot(meta)
+	if !ok {
+		return arrowMessage{}, errFileInvalid("arrow", "message is corrupt")
+	}
+	header, _ := root.table(2)
+	message := arrowMessage{
+		kind:   root.u8(1, 0),
+		header: header,
+		body:   offset + size,
+		length: root.i64(3, 0),
+	}
+	if message.length < 0 || message.body > r.size-message.length {
+		return arrowMessage{}, errFileInvalid("arrow", "message body is truncated")
+	}
+	return message, nil
+}
+
+func (r *arrowReader) readSchema(schema fbTable) error {
+	if schema.i16(0, 0) == 1 {
+		return errFileUnsupported("arrow", "big-endian data")
+	}
+	r.fields = []*arrowField{}
+	for _, f := range schema.tables(1) {
+		r.fields = append(r.fields, newArrowField(f, 0))
+	}
+	return nil
+}
+
+// newArrowField 讀取欄位的型別、字典編碼與子欄位
+func newArrowField(f fbTable, depth int) *arrowField {
+	typ, _ := f.table(3)
+	field := &arrowField{value: newArrowType(f.u8(2, 0), typ)}
+	if depth < thriftMaxDepth {
+		for _, child := range f.tables(5) {
+			field.children = append(field.children, newArrowField(child, depth+1))
+		}
+	}
+	fileType, dataType := field.value.name, field.value.dataType
+	if len(field.children) > 0 {
+		dataType = ""
+	}
+	if dict, ok := f.table(4); ok {
+		index := arrowType{id: arrowInt, width: 32, signed: true}
+		if t, ok := dict.table(1); ok {
+			index = newArrowType(arrowInt, t)
+		}
+		field.index = &index
+		field.dictID = dict.i64(0, 0)
+		fileType = "dictionary<" + fileType + ">"
+		if dataType == DataTypeString && field.value.id != arrowBinary && field.value.id != arrowLargeBinary && field.value.id != arrowBinaryView {
+			dataType = DataTypeCategorical
+		}
+	}
+	field.info = ColumnarColumnInfo{Name: f.text(0), FileType: fileType, Type: dataType}
+	return field
+}
+
+// newArrowType 讀取型別表格
+func newArrowType(id uint8, t fbTable) arrowType {
+	a := arrowType{id: id, name: arrowTypeNames[id]}
+	if a.name == "" {
+		a.name = fmt.Sprintf("type %d", id)
+	}
+	unit := func(slot int, def int16) int {
+		u := int(t.i16(slot, def))
+		if u < 0 || u >= len(arrowUnits) {
+			u = int(def)
+		}
+		a.unit = arrowUnits[u]
+		return u
+	}
+	switch id {
+	case arrowNull, arrowUtf8, arrowLargeUtf8, arrowUtf8View, arrowBinary, arrowLargeBinary, arrowBinaryView:
+		a.dataType = DataTypeString
+	case arrowBool:
+		a.dataType = DataTypeBoolean
+	case arrowInt:
+		a.width, a.signed = int(t.i32(0, 0)), t.bool(1)
+		a.name = fmt.Sprintf("int%d", a.width)
+		if !a.signed {
+			a.name = "u" + a.name
+		}
+		if a.width == 8 || a.width == 16 || a.width == 32 || a.width == 64 {
+			a.dataType = DataTypeInteger
+		}
+	case arrowFloat:
+		if precision := t.i16(0, 0); precision >= 0 && precision <= 2 {
+			a.width = 16 << precision
+		}
+		a.name = fmt.Sprintf("float%d", a.width)
+		if a.width > 0 && a.width <= 64 {
+			a.dataType = DataTypeNumeric
+		}
+	case arrowDecimal:
+		a.width, a.scale = int(t.i32(2, 128)), int(t.i32(1, 0))
+		a.name = fmt.Sprintf("decimal%d(%d, %d)", a.width, t.i32(0, 0), a.scale)
+		if a.width%8 == 0 && a.width > 0 && a.width <= 256 {
+			a.dataType = DataTypeNumeric
+		}
+	case arrowDate:
+		a.days = t.i16(0, 1) == 0
+		a.width, a.name = 64, "date64"
+		if a.days {
+			a.width, a.name = 32, "date32"
+		}
+		a.dataType = DataTypeDateTime
+	case arrowTime:
+		u := unit(0, 1)
+		a.width = int(t.i32(1, 32))
+		a.name = fmt.Sprintf("time%d[%s]", a.width, arrowUnitNames[u])
+		if a.width == 32 || a.width == 64 {
+			a.dataType = DataTypeString
+		}
+	case arrowTimestamp:
+		u := unit(0, 0)
+		a.width = 64
+		a.name = "timestamp[" + arrowUnitNames[u]
+		if zone := t.text(1); zone != "" {
+			a.name += ", " + zone
+		}
+		a.name += "]"
+		a.dataType = DataTypeDateTime
+	case arrowFixedBinary:
+		a.width = int(t.i32(0, 0))
+		a.name = fmt.Sprintf("fixed_size_binary[%d]", a.width)
+		if a.width > 0 {
+			a.dataType = DataTypeString
+		}
+	case arrowUnion:
+		a.dense = t.i16(0, 0) == 1
+	}
+	return a
+}
+
+// info 檔案的列數、record batch 數與各欄資訊
+func (r *arrowReader) info() ColumnarFileInfo {
+	info := ColumnarFileInfo{Columns: make([]ColumnarColumnInfo, len(r.fields))}
+	for _, m := range r.messages {
+		if m.kind == arrowBatchMessage {
+			info.RowCount += m.header.i64(0, 0)
+			info.Groups++
+		}
+	}
+	for j, f := range r.fields {
+		info.Columns[j] = f.info
+	}
+	return info
+}
+
+// read 依序處理字典與 record batch 訊息，只讀取選取欄位的緩衝區；每讀完一個 record batch 就交給 appendGroup
+func (r *arrowReader) read(selected []int, appendGroup func(group [][]any)) error {
+	dictFields := map[int64]*arrowField{}
+	for _, j := range selected {
+		if f := r.fields[j]; f.index != nil {
+			dictFields[f.dictID] = f
+		}
+	}
+	dicts := map[int64][]any{}
+	for _, m := range r.messages {
+		switch m.kind {
+		case arrowDictionaryMessage:
+			id := m.header.i64(0, 0)
+			f := dictFields[id]
+			data, ok := m.header.table(1)
+			if f == nil || !ok {
+				continue
+			}
+			batch := arrowBatch{r: r, header: data, body: m.body, length: m.length}
+			if err := batch.init(); err != nil {
+				return err
+			}
+			dict, err := batch.readArray(&f.value, 0, 0, 0)
+			if err != nil {
+				return err
+			}
+			if m.header.bool(2) {
+				dicts[id] = append(dicts[id], dict...)
+			} else {
+				dicts[id] = dict
+			}
+		case arrowBatchMessage:
+			batch := arrowBatch{r: r, header: m.header, body: m.body, length: m.length}
+			if err := batch.init(); err != nil {
+				return err
+			}
+			positions := make([]arrowPosition, len(r.fields))
+			var p arrowPosition
+			for j, f := range r.fields {
+				positions[j] = p
+				if err := batch.advance(f, &p, 0); err != nil {
+					return err
+				}
+			}
+			values := make([][]any, len(selected))
+			for k, j := range selected {
+				column, err := batch.readColumn(r.fields[j], positions[j], dicts)
+				if err != nil {
+					return err
+				}
+				values[k] = column
+			}
+			appendGroup(values)
+		}
+	}
+	return nil
+}
+
+// arrowPosition 欄位在 record batch 中的第一個節點、緩衝區與 variadic 緩衝區計數的位置
+type arrowPosition struct {
+	node, buffer, variadic int
+}
+
+// arrowBatch 讀取中的 record batch
+type arrowBatch struct {
+	r        *arrowReader
+	header   fbTable
+	body     int64
+	length   int64
+	nodes    [][]int64 // length、null_count
+	buffers  [][]int64 // offset、length
+	variadic []int64
+	codec    string
+}
+
+func (b *arrowBatch) init() error {
+	b.nodes = b.header.int64s(1, 2)
+	b.buffers = b.header.int64s(2, 2)
+	start, n := b.header.vector(4, 8)
+	for i := range n {
+		b.variadic = append(b.variadic, int64(binary.LittleEndian.Uint64(b.header.buf[start+8*i:])))
+	}
+	if c, ok := b.header.table(3); ok {
+		switch c.u8(0, 0) {
+		case 0:
+			b.codec = CompressionLZ4
+		case 1:
+			b.codec = CompressionZstd
+		default:
+			return errFileUnsupported("arrow", "body compression")
+		}
+	}
+	return nil
+}
+
+// bufferCount 型別使用的緩衝區數；view 型別另外使用 variadic 緩衝區
+func (b *arrowBatch) bufferCount(t *arrowType, p *arrowPosition) (int, error) {
+	switch t.id {
+	case arrowNull, arrowRunEnd:
+		return 0, nil
+	case arrowStruct, arrowFixedList:
+		return 1, nil
+	case arrowBinary, arrowUtf8, arrowLargeBinary, arrowLargeUtf8, arrowListView, arrowLargeListView:
+		return 3, nil
+	case arrowBinaryView, arrowUtf8View:
+		if p.variadic >= len(b.variadic) || b.variadic[p.variadic] < 0 || b.variadic[p.variadic] > int64(len(b.buffers)) {
+			return 0, errFileInvalid("arrow", "variadic buffer counts are missing")
+		}
+		n := 2 + int(b.variadic[p.variadic])
+		p.variadic++
+		return n, nil
+	case arrowUnion:
+		if t.dense {
+			return 2, nil
+		}
+		return 1, nil
+	}
+	return 2, nil
+}
+
+// advance 略過一個欄位（含子欄位）的節點與緩衝區
+func (b *arrowBatch) advance(f *arrowField, p *arrowPosition, depth int) error {
+	p.node++
+	if f.index != nil {
+		p.buffer += 2
+		return nil
+	}
+	n, err := b.bufferCount(&f.value, p)
+	if err != nil {
+		return err
+	}
+	p.buffer += n
+	if depth >= thriftMaxDepth {
+		return errFileInvalid("arrow", "schema is too deeply nested")
+	}
+	for _, child := range f.children {
+		if err := b.advance(child, p, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readColumn 讀取一個頂層欄位；字典編碼的欄位以索引查詢字典
+func (b *arrowBatch) readColumn(f *arrowField, p arrowPosition, dicts map[int64][]any) ([]any, error) {
+	if f.index == nil {
+		return b.readArray(&f.value, p.node, p.buffer, p.variadic)
+	}
+	indices, err := b.readArray(f.index, p.node, p.buffer, p.variadic)
+	if err != nil {
+		return nil, err
+	}
+	dict, ok := dicts[f.dictID]
+	if !ok {
+		return nil, errFileInvalid("arrow", "dictionary of "+f.info.Name+" is missing")
+	}
+	for i, index := range indices {
+		if index == nil {
+			continue
+		}
+		k, ok := index.(int)
+		if !ok || k < 0 || k >= len(dict) {
+			return nil, errFileInvalid("arrow", "dictionary index is out of range")
+		}
+		indices[i] = dict[k]
+	}
+	return indices, nil
+}
+
+// buffer 讀取並解壓縮第 i 個緩衝區
+func (b *arrowBatch) buffer(i int) ([]byte, error) {
+	if i >= len(b.buffers) {
+		return nil, errFileInvalid("arrow", "record batch is missing buffers")
+	}
+	offset, length := b.buffers[i][0], b.buffers[i][1]
+	if length == 0 {
+		return nil, nil
+	}
+	if offset < 0 || length < 0 || offset > b.length-length {
+		return nil, errFileInvalid("arrow", "buffer is out of range")
+	}
+	data, err := b.r.readAt(length, b.body+offset)
+	if err != nil || b.codec == "" {
+		return data, err
+	}
+	// 壓縮的緩衝區以解壓縮後的長度開頭，-1 表示未壓縮
+	if len(data) < 8 {
+		return nil, errFileInvalid("arrow", "compressed buffer is truncated")
+	}
+	size := int64(binary.LittleEndian.Uint64(data))
+	if size == -1 {
+		return data[8:], nil
+	}
+	if size < 0 || size > columnarMaxBuffer {
+		return nil, errFileInvalid("arrow", "compressed buffer is corrupt")
+	}
+	out, err := decompress(b.codec, data[8:], int(size))
+	if err != nil {
+		return nil, errFileInvalid("arrow", "buffer cannot be decompressed")
+	}
+	return out, nil
+}
+
+// readArray 讀取一個無子欄位的陣列
+func (b *arrowBatch) readArray(t *arrowType, node int, buffer int, variadic int) ([]any, error) {
+	if t.dataType == "" && t.id != arrowInt {
+		return nil, errFileUnsupported("arrow", t.name+" columns")
+	}
+	if node >= len(b.nodes) {
+		return nil, errFileInvalid("arrow", "record batch is missing field nodes")
+	}
+	n, nulls := b.nodes[node][0], b.nodes[node][1]
+	if n < 0 || n > columnarMaxBuffer {
+		return nil, errFileInvalid("arrow", "array length is out of range")
+	}
+	count, err := b.bufferCount(t, &arrowPosition{variadic: variadic})
+	if err != nil {
+		return nil, err
+	}
+	buffers := make([][]byte, count)
+	for k := range buffers {
+		if buffers[k], err = b.buffer(buffer + k); err != nil {
+			return nil, err
+		}
+	}
+	return t.decode(int(n), nulls, buffers)
+}
+
+// decode 將緩衝區解碼為 n 個值；null_count 為 0 時可以省略有效位元圖
+func (t *arrowType) decode(n int, nulls int64, buffers [][]byte) ([]any, error) {
+	corrupt := errFileInvalid("arrow", t.name+" buffer is truncated")
+	if t.id == arrowNull {
+		return make([]any, n), nil
+	}
+	if nulls < 0 || nulls > int64(n) {
+		return nil, errFileInvalid("arrow", "null count of "+t.name+" is out of range")
+	}
+	// 其他型別的每個值在第二個緩衝區中至少佔一個位元，先檢查長度再配置結果
+	if len(buffers) < 2 || len(buffers[1])*8 < n {
+		return nil, corrupt
+	}
+	out := make([]any, n)
+	validity := buffers[0]
+	if nulls != 0 && len(validity)*8 < n {
+		return nil, corrupt
+	}
+	valid := func(i int) bool {
+		return nulls == 0 || validity[i>>3]>>(i&7)&1 == 1
+	}
+
+	switch t.id {
+	case arrowBool:
+		data := buffers[1]
+		if len(data)*8 < n {
+			return nil, corrupt
+		}
+		for i := range n {
+			if valid(i) {
+				out[i] = data[i>>3]>>(i&7)&1 == 1
+			}
+		}
+	case arrowBinary, arrowUtf8, arrowLargeBinary, arrowLargeUtf8:
+		offsets, data := buffers[1], buffers[2]
+		size := 4
+		if t.id == arrowLargeBinary || t.id == arrowLargeUtf8 {
+			size = 8
+		}
+		if n > 0 && len(offsets) < size*(n+1) {
+			return nil, corrupt
+		}
+		offset := func(i int) int64 {
+			if size == 4 {
+				return int64(int32(binary.LittleEndian.Uint32(offsets[4*i:])))
+			}
+			return int64(binary.LittleEndian.Uint64(offsets[8*i:]))
+		}
+		for i := range n {
+			start, end := offset(i), offset(i+1)
+			if start < 0 || end < start || end > int64(len(data)) {
+				return nil, corrupt
+			}
+			if valid(i) {
+				out[i] = t.fromBytes(data[start:end])
+			}
+		}
+	case arrowBinaryView, arrowUtf8View:
+		views := buffers[1]
+		if len(views) < 16*n {
+			return nil, corrupt
+		}
+		for i := range n {
+			if !valid(i) {
+				continue
+			}
+			view := views[16*i : 16*i+16]
+			length := int(binary.LittleEndian.Uint32(view))
+			if length <= 12 {
+				out[i] = t.fromBytes(view[4 : 4+length])
+				continue
+			}
+			index, start := int(binary.LittleEndian.Uint32(view[8:])), int(binary.LittleEndian.Uint32(view[12:]))
+			if 2+index >= len(buffers) || start > len(buffers[2+index])-length {
+				return nil, corrupt
+			}
+			out[i] = t.fromBytes(buffers[2+index][start : start+length])
+		}
+	default:
+		size := t.width / 8
+		if t.id == arrowFixedBinary {
+			size = t.width
+		}
+		data := buffers[1]
+		if size <= 0 || len(data) < size*n {
+			return nil, corrupt
+		}
+		for i := range n {
+			if valid(i) {
+				out[i] = t.fromFixed(data[i*size : (i+1)*size])
+			}
+		}
+	}
+	return out, nil
+}
+
+// fromFixed 將固定寬度的值轉為欄位的值
+func (t *arrowType) fromFixed(b []byte) any {
+	var v int64
+	switch len(b) {
+	case 1:
+		v = int64(int8(b[0]))
+	case 2:
+		v = int64(int16(binary.LittleEndian.Uint16(b)))
+	case 4:
+		v = int64(int32(binary.LittleEndian.Uint32(b)))
+	case 8:
+		v = int64(binary.LittleEndian.Uint64(b))
+	}
+	switch t.id {
+	case arrowInt:
+		if t.signed {
+			return int(v)
+		}
+		u := v & int64(uint64(math.MaxUint64)>>(64-8*len(b)))
+		if len(b) == 8 && v < 0 {
+			return float64(uint64(v))
+		}
+		return int(u)
+	case arrowFloat:
+		switch len(b) {
+		case 2:
+			return float16Value(binary.LittleEndian.Uint16(b))
+		case 4:
+			return float64(math.Float32frombits(uint32(v)))
+		}
+		return math.Float64frombits(uint64(v))
+	case arrowDecimal:
+		// Arrow 以小端序儲存，轉為大端序後解碼
+		big := slices.Clone(b)
+		slices.Reverse(big)
+		return decimalValue(big, t.scale)
+	case arrowDate:
+		if t.days {
+			return time.Unix(v*86400, 0).UTC()
+		}
+		return timeFromUnit(v, time.Millisecond)
+	case arrowTime:
+		return timeOfDayText(v, t.unit)
+	case arrowTimestamp:
+		return timeFromUnit(v, t.unit)
+	}
+	return binaryText(b)
+}
+
+// fromBytes 將變動長度的值轉為欄位的值
+func (t *arrowType) fromBytes(b []byte) any {
+	switch t.id {
+	case arrowUtf8, arrowLargeUtf8, arrowUtf8View:
+		return string(b)
+	}
+	return binaryText(b)
+}
+
+// ----- 寫出 -----
+
+// writeArrow 將各欄寫成 Arrow IPC 檔案格式
+func writeArrow(w io.Writer, columns []statColumn, options ColumnarExportOptions) error {
+	names := columnarNames(columns)
+	kinds := make([]columnarKind, len(columns))
+	fields := make([]fbObject, len(columns))
+	for j, col := range columns {
+		kinds[j] = columnarKindOf(col)
+		id, typ := arrowTypeOf(kinds[j])
+		fields[j] = fbObject{{0, names[j]}, {1, true}, {2, id}, {3, typ}, {5, []fbObject{}}}
+	}
+	schema := fbObject{{1, fields}}
+	rows := 0
+	if len(columns) > 0 {
+		rows = len(columns[0].values)
+	}
+
+	offset := int64(0)
+	write := func(b []byte) error {
+		n, err := w.Write(b)
+		offset += int64(n)
+		return err
+	}
+	// 訊息：繼續標記、標頭長度、標頭（補齊到 8 的倍數），接著是內容
+	message := func(kind uint8, header fbObject, body []byte) ([]int64, error) {
+		meta := buildFlatbuffer(fbObject{{0, int16(arrowVersion)}, {1, kind}, {2, header}, {3, int64(len(body))}})
+		block := []int64{offset, int64(8 + len(meta)), int64(len(body))}
+		prefix := binary.LittleEndian.AppendUint32(nil, arrowContinuation)
+		prefix = binary.LittleEndian.AppendUint32(prefix, uint32(len(meta)))
+		for _, b := range [][]byte{prefix, meta, body} {
+			if err := write(b); err != nil {
+				return nil, err
+			}
+		}
+		return block, nil
+	}
+
+	if err := write([]byte(arrowMagic + "\x00\x00")); err != nil {
+		return err
+	}
+	if _, err := message(arrowSchemaMessage, schema, nil); err != nil {
+		return err
+	}
+	var blocks []byte
+	for start := 0; start < rows; start += options.GroupRows {
+		end := min(start+options.GroupRows, rows)
+		batch, body, err := encodeArrowBatch(kinds, columns, start, end, options.Compression)
+		if err != nil {
+			return err
+		}
+		block, err := message(arrowBatchMessage, batch, body)
+		if err != nil {
+			return err
+		}
+		blocks = binary.LittleEndian.AppendUint64(blocks, uint64(block[0]))
+		blocks = binary.LittleEndian.AppendUint64(blocks, uint64(block[1]))
+		blocks = binary.LittleEndian.AppendUint64(blocks, uint64(block[2]))
+	}
+	// 串流結束標記與檔案結尾
+	eos := binary.LittleEndian.AppendUint32(nil, arrowContinuation)
+	if err := write(append(eos, 0, 0, 0, 0)); err != nil {
+		return err
+	}
+	footer := buildFlatbuffer(fbObject{
+		{0, int16(arrowVersion)},
+		{1, schema},
+		{2, fbStructs{size: 24}},
+		{3, fbStructs{size: 24, data: blocks}},
+	})
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(len(footer)))
+	return write(append(footer, arrowMagic...))
+}
+
+// arrowTypeOf 儲存型別對應的 Arrow 型別代碼與型別表格
+func arrowTypeOf(kind columnarKind) (uint8, fbObject) {
+	switch kind {
+	case kindInt64:
+		return arrowInt, fbObject{{0, int32(64)}, {1, true}}
+	case kindDouble:
+		return arrowFloat, fbObject{{0, int16(2)}}
+	case kindBool:
+		return arrowBool, fbObject{}
+	case kindTimestamp:
+		return arrowTimestamp, fbObject{{0, int16(2)}, {1, "UTC"}}
+	}
+	return arrowUtf8, fbObject{}
+}
+
+// encodeArrowBatch 編碼 [start, end) 列為 record batch 的標頭與內容
+func encodeArrowBatch(kinds []columnarKind, columns []statColumn, start, end int, compression string) (fbObject, []byte, error) {
+	n := end - start
+	var body, nodes, buffers []byte
+	appendBuffer := func(data []byte) {
+		if compression != CompressionNone && len(data) > 0 {
+			packed := compress(compression, data)
+			data = append(binary.LittleEndian.AppendUint64(nil, uint64(len(data))), packed...)
+		}
+		buffers = binary.LittleEndian.AppendUint64(buffers, uint64(len(body)))
+		buffers = binary.LittleEndian.AppendUint64(buffers, uint64(len(data)))
+		body = append(body, data...)
+		for len(body)%8 != 0 {
+			body = append(body, 0)
+		}
+	}
+	for j, col := range columns {
+		validity := make([]byte, (n+7)/8)
+		var data, offsets []byte
+		if kinds[j] == kindBool {
+			data = make([]byte, (n+7)/8)
+		}
+		if kinds[j] == kindString {
+			offsets = binary.LittleEndian.AppendUint32(nil, 0)
+		}
+		nulls := 0
+		for i, v := range col.values[start:end] {
+			v = columnarValue(kinds[j], v)
+			if v == nil {
+				nulls++
+			} else {
+				validity[i>>3] |= 1 << (i & 7)
+			}
+			switch kinds[j] {
+			case kindInt64, kindTimestamp:
+				x, _ := v.(int64)
+				data = binary.LittleEndian.AppendUint64(data, uint64(x))
+			case kindDouble:
+				x, _ := v.(float64)
+				data = binary.LittleEndian.AppendUint64(data, math.Float64bits(x))
+			case kindBool:
+				if x, _ := v.(bool); x {
+					data[i>>3] |= 1 << (i & 7)
+				}
+			default:
+				x, _ := v.(string)
+				data = append(data, x...)
+				if len(data) > math.MaxInt32 {
+					return nil, nil, invalidArgument("text in column %q exceeds 2 GiB in one record batch", col.name).WithDetail("column", col.name)
+				}
+				offsets = binary.LittleEndian.AppendUint32(offsets, uint32(len(data)))
+			}
+		}
+		nodes = binary.LittleEndian.AppendUint64(nodes, uint64(n))
+		nodes = binary.LittleEndian.AppendUint64(nodes, uint64(nulls))
+		if nulls == 0 {
+			validity = nil
+		}
+		appendBuffer(validity)
+		if offsets != nil {
+			appendBuffer(offsets)
+		}
+		appendBuffer(data)
+	}
+	batch := fbObject{
+		{0, int64(n)},
+		{1, fbStructs{size: 16, data: nodes}},
+		{2, fbStructs{size: 16, data: buffers}},
+	}
+	if compression == CompressionZstd {
+		batch = append(batch, fbSlot{3, fbObject{{0, uint8(1)}}})
+	}
+	return batch, body, nil
+}
@@ -0,0 +1,408 @@
+package services
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+// ===== 即時更新的計算欄位 =====
+//
+// 以 CCL 公式建立的欄位會記住公式與參照的欄位。來源儲存格變更時，
+// 依相依關係只重新計算受影響的計算欄位；單一儲存格的編輯只重新計算該列。
+// 公式以欄位字母參照其他欄位，因此插入、刪除或移動欄位時會一併改寫公式中的參照。
+
+// columnFormula 計算欄位的公式
+type columnFormula struct {
+	formula string
+	deps    []int  // 參照的欄位索引
+	err     string // 最近一次重新計算的錯誤
+}
+
+// tableFormulas 資料表中所有計算欄位，以欄位索引為鍵
+type tableFormulas map[int]*columnFormula
+
+// ColumnFormulaInfo 計算欄位的公式與狀態
+type ColumnFormulaInfo struct {
+	Col       int    `json:"col"`
+	Letter    string `json:"letter"`
+	Name      string `json:"name"`
+	Formula   string `json:"formula"`
+	DependsOn []int  `json:"dependsOn"`
+	Error     string `json:"error"`
+}
+
+// formulasOf 取得資料表的計算欄位，不存在時建立
+func (s *DataTableService) formulasOf(dt *insyra.DataTable) tableFormulas {
+	f, ok := s.formulas[dt]
+	if !ok {
+		f = make(tableFormulas)
+		s.formulas[dt] = f
+	}
+	return f
+}
+
+// formulaTexts 取得資料表所有計算欄位的公式文字
+func (s *DataTableService) formulaTexts(dt *insyra.DataTable) map[int]string {
+	texts := make(map[int]string, len(s.formulas[dt]))
+	for col, f := range s.formulas[dt] {
+		texts[col] = f.formula
+	}
+	return texts
+}
+
+// setFormulaTexts 以公式文字取代資料表的計算欄位，無法解析的公式會被捨棄
+func (s *DataTableService) setFormulaTexts(dt *insyra.DataTable, texts map[int]string) {
+	formulas := make(tableFormulas, len(texts))
+	for col, text := range texts {
+		if v := validateCCL(dt, text); v.Valid {
+			formulas[col] = &columnFormula{formula: text, deps: referencedIndices(v)}
+		}
+	}
+	s.formulas[dt] = formulas
+}
+
+// referencedIndices 取出公式參照的欄位索引
+func referencedIndices(v CCLValidation) []int {
+	deps := make([]int, len(v.ReferencedColumns))
+	for i, ref := range v.ReferencedColumns {
+		deps[i] = ref.Index
+	}
+	return deps
+}
+
+// GetColumnFormulas 取得資料表中所有計算欄位的公式，依欄位順序排列
+func (s *DataTableService) GetColumnFormulas(tableID string) ([]ColumnFormulaInfo, error) {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return nil, errTableNotFound(tableID)
+	}
+	formulas := s.formulas[dt]
+	infos := make([]ColumnFormulaInfo, 0, len(formulas))
+	for _, col := range slices.Sorted(maps.Keys(formulas)) {
+		f := formulas[col]
+		infos = append(infos, ColumnFormulaInfo{
+			Col:       col,
+			Letter:    indexToLetters(col),
+			Name:      dt.GetColByNumber(col).GetName(),
+			Formula:   f.formula,
+			DependsOn: slices.Clone(f.deps),
+			Error:     f.err,
+		})
+	}
+	return infos, nil
+}
+
+// SetColumnFormula 設定或取代欄位的公式並立即重新計算；formula 為空字串時移除公式，保留目前的值
+func (s *DataTableService) SetColumnFormula(tableID string, colIndex int, formula string) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
+	}
+	if _, colCount := dt.Size(); colIndex < 0 || colIndex >= colCount {
+		return errColumnOutOfRange(colIndex, colCount)
+	}
+
+	formulas := s.formulasOf(dt)
+	var next *columnFormula
+	if formula != "" {
+		v := validateCCL(dt, formula)
+		if !v.Valid {
+			return errFormulaInvalid(formula, v.Errors[0]).WithDetail("col", colIndex)
+		}
+		next = &columnFormula{formula: formula, deps: referencedIndices(v)}
+		if cycle := formulaCycle(formulas, colIndex, next.deps); cycle != nil {
+			letters := make([]string, len(cycle))
+			for i, col := range cycle {
+				letters[i] = indexToLetters(col)
+			}
+			return newError(ErrCodeFormula, "errors.formula_cycle",
+				"formula %q creates a circular reference: %s", formula, strings.Join(letters, " -> ")).
+				WithDetail("formula", formula).
+				WithDetail("col", colIndex).
+				WithDetail("cycle", cycle)
+		}
+	}
+
+	prev := formulas[colIndex]
+	oldValues := dt.GetColByNumber(colIndex).Data()
+	oldType := s.columnTypeOf(dt, colIndex)
+	id := s.columnIDsOf(dt)[colIndex]
+	apply := func(dt *insyra.DataTable, f *columnFormula) {
+		if f == nil {
+			delete(s.formulasOf(dt), colIndex)
+		} else {
+			s.formulasOf(dt)[colIndex] = &columnFormula{formula: f.formula, deps: slices.Clone(f.deps)}
+		}
+	}
+	apply(dt, next)
+	if next != nil {
+		s.recomputeColumns(dt, []int{colIndex}, true)
+	}
+	s.record(dt, "set formula",
+		func(dt *insyra.DataTable) {
+			apply(dt, prev)
+			dt.UpdateColByNumber(colIndex, insyra.NewDataList(slices.Clone(oldValues)...).SetName(dt.GetColByNumber(colIndex).GetName()))
+			// 公式決定的型別改回設定公式前的型別
+			if oldType == DataTypeUnset {
+				delete(s.columnTypesOf(dt), id)
+			} else {
+				s.columnTypesOf(dt)[id] = oldType
+			}
+		},
+		func(dt *insyra.DataTable) { apply(dt, next) },
+	)
+	return nil
+}
+
+// formulaCycle 檢查把 deps 設為 col 的相依欄位後是否形成循環，回傳循環經過的欄位
+func formulaCycle(formulas tableFormulas, col int, deps []int) []int {
+	depsOf := func(c int) []int {
+		if c == col {
+			return deps
+		}
+		if f := formulas[c]; f != nil {
+			return f.deps
+		}
+		return nil
+	}
+	// 從 col 沿著相依關係搜尋，能回到 col 即為循環
+	var path []int
+	visited := make(map[int]bool)
+	var visit func(c int) bool
+	visit = func(c int) bool {
+		path = append(path, c)
+		for _, d := range depsOf(c) {
+			if d == col {
+				path = append(path, col)
+				return true
+			}
+			if !visited[d] {
+				visited[d] = true
+				if visit(d) {
+					return true
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+	if visit(col) {
+		return path
+	}
+	return nil
+}
+
+// dependentsInOrder 找出直接或間接依賴 changed 的計算欄位，依計算順序排列；
+// includeChanged 為 true 時 changed 中的計算欄位本身也會重新計算
+func dependentsInOrder(formulas tableFormulas, changed []int, includeChanged bool) []int {
+	affected := make(map[int]bool)
+	queue := slices.Clone(changed)
+	if includeChanged {
+		for _, c := range changed {
+			if formulas[c] != nil {
+				affected[c] = true
+			}
+		}
+	}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		for col, f := range formulas {
+			if !affected[col] && slices.Contains(f.deps, c) {
+				affected[col] = true
+				queue = append(queue, col)
+			}
+		}
+	}
+
+	// 拓撲排序：相依的欄位先計算
+	var order []int
+	done := make(map[int]bool)
+	var visit func(c int)
+	visit = func(c int) {
+		if done[c] {
+			return
+		}
+		done[c] = true
+		for _, d := range formulas[c].deps {
+			if affected[d] {
+				visit(d)
+			}
+		}
+		order = append(order, c)
+	}
+	for _, c := range slices.Sorted(maps.Keys(affected)) {
+		visit(c)
+	}
+	return order
+}
+
+// recomputeColumns 重新計算受 changed 影響的所有計算欄位
+func (s *DataTableService) recomputeColumns(dt *insyra.DataTable, changed []int, includeChanged bool) {
+	formulas := s.formulas[dt]
+	if len(formulas) == 0 {
+		return
+	}
+	for _, col := range dependentsInOrder(formulas, changed, includeChanged) {
+		f := formulas[col]
+		values, err := evaluateFormula(dt, f.formula)
+		f.err = ""
+		if err != nil {
+			// 公式無法計算時清空整欄，避免留下過期的值
+			rowCount, _ := dt.Size()
+			values = make([]any, rowCount)
+			f.err = err.Error()
+		}
+		s.storeFormulaValues(dt, col, values)
+	}
+}
+
+// storeFormulaValues 寫入計算欄位整欄的結果；計算欄位的型別由結果推斷，在計算時一併更新
+func (s *DataTableService) storeFormulaValues(dt *insyra.DataTable, col int, values []any) {
+	id := s.columnIDsOf(dt)[col]
+	if dataType := inferColumnType(values); dataType == DataTypeUnset {
+		delete(s.columnTypesOf(dt), id)
+	} else {
+		values, _ = convertValues(values, dataType)
+		s.columnTypesOf(dt)[id] = dataType
+	}
+	name := dt.GetColByNumber(col).GetName()
+	dt.UpdateColByNumber(col, insyra.NewDataList(values...).SetName(name))
+}
+
+// recomputeAll 重新計算資料表所有的計算欄位（復原、重做或結構變更之後）
+func (s *DataTableService) recomputeAll(dt *insyra.DataTable) {
+	s.recomputeColumns(dt, slices.Collect(maps.Keys(s.formulas[dt])), true)
+}
+
+// recomputeRow 與 recomputeColumns 相同，但只重新計算第 row 列（負數表示最後一列）
+func (s *DataTableService) recomputeRow(dt *insyra.DataTable, changed []int, includeChanged bool, row int) {
+	formulas := s.formulas[dt]
+	if len(formulas) == 0 {
+		return
+	}
+	if row < 0 {
+		rowCount, _ := dt.Size()
+		row = rowCount - 1
+	}
+	for _, target := range dependentsInOrder(formulas, changed, includeChanged) {
+		f := formulas[target]
+		// 以該列的副本計算，公式只會讀取同一列的值
+		_, colCount := dt.Size()
+		cells := make([]*insyra.DataList, colCount)
+		for j := range colCount {
+			cells[j] = insyra.NewDataList(dt.GetElementByNumberIndex(row, j))
+		}
+		values, err := evaluateFormula(insyra.NewDataTable(cells...), f.formula)
+		var value any
+		f.err = ""
+		if err != nil {
+			f.err = err.Error()
+		} else if len(values) > 0 {
+			value = values[0]
+		}
+		// 結果符合欄位目前的型別時只更新這一格，否則重新推斷整欄的型別
+		if dataType := s.columnTypeOf(dt, target); dataType != DataTypeUnset || isMissingValue(value) {
+			if converted, ok := convertValue(value, dataType); ok {
+				dt.UpdateElement(row, indexToLetters(target), converted)
+				continue
+			}
+		}
+		dt.UpdateElement(row, indexToLetters(target), value)
+		s.storeFormulaValues(dt, target, dt.GetColByNumber(target).Data())
+	}
+}
+
+// evaluateFormula 計算公式在每一列的結果，不改變資料表
+func evaluateFormula(dt *insyra.DataTable, formula string) ([]any, error) {
+	if err := addCalculatedColumn(dt, "", formula); err != nil {
+		return nil, err
+	}
+	_, colCount := dt.Size()
+	values := dt.GetColByNumber(colCount - 1).Data()
+	dt.DropColsByNumber(colCount - 1)
+	return values, nil
+}
+
+// errCalculatedColumn 計算欄位的值由公式決定，不能直接編輯
+func errCalculatedColumn(col int, formula string) *ServiceError {
+	return newError(ErrCodeConflict, "errors.calculated_column_readonly",
+		"column %d is calculated by formula %q", col, formula).
+		WithDetail("col", col).
+		WithDetail("formula", formula)
+}
+
+// ----- 結構變更時改寫公式 -----
+
+// remapFormulas 依結構編輯後各欄的來源（origin[新索引] = 舊索引，新增的欄為 -1）搬移公式並改寫其中的參照；
+// 複製的欄沿用來源欄的公式，參照到已刪除欄位的公式會被移除，該欄保留目前的值
+func remapFormulas(texts map[int]string, origin []int, oldCount int) map[int]string {
+	// 舊索引 → 新索引；複製時以原本的欄（第一個出現的位置）為準
+	mapping := make([]int, oldCount)
+	for i := range mapping {
+		mapping[i] = -1
+	}
+	for newIndex, old := range origin {
+		if old >= 0 && old < oldCount && mapping[old] < 0 {
+			mapping[old] = newIndex
+		}
+	}
+	out := make(map[int]string, len(texts))
+	for newIndex, old := range origin {
+		text, ok := texts[old]
+		if old < 0 || !ok {
+			continue
+		}
+		if rewritten, ok := rewriteCCLRefs(text, mapping); ok {
+			out[newIndex] = rewritten
+		}
+	}
+	return out
+}
+
+// rewriteCCLRefs 依 mapping 改寫公式中的欄位字母；參照到已刪除的欄位時回傳 false
+func rewriteCCLRefs(formula string, mapping []int) (string, bool) {
+	tokens, diag := tokenizeCCL(formula)
+	if diag != nil {
+		return formula, false
+	}
+	input := []rune(formula)
+	var b strings.Builder
+	last := 0
+	for i, tok := range tokens {
+		if tok.kind != cclIdent || tokens[i+1].kind == cclLParen {
+			continue
+		}
+		index, ok := columnLetterIndex(tok.text)
+		if !ok || index >= len(mapping) {
+			continue
+		}
+		if mapping[index] < 0 {
+			return formula, false
+		}
+		b.WriteString(string(input[last:tok.offset]))
+		b.WriteString(indexToLetters(mapping[index]))
+		last = tok.offset + tok.length
+	}
+	b.WriteString(string(input[last:]))
+	return b.String(), true
+}
+
+// columnLetterIndex 將欄位字母（A、B、…、AA）轉為索引
+func columnLetterIndex(letters string) (int, bool) {
+	if letters == "" || strings.IndexFunc(letters, func(r rune) bool { return r < 'A' || r > 'Z' }) >= 0 {
+		return 0, false
+	}
+	index := 0
+	for _, r := range letters {
+		index = index*26 + int(r-'A') + 1
+	}
+	return index - 1, true
+}
@@ -0,0 +1,528 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+// ===== CCL 公式檢查與預覽 =====
+//
+// insyra 的 CCL 剖析器屬於內部套件，無法直接呼叫，且遇到錯誤時只會記錄警告。
+// 這裡依相同的語法重新剖析公式，以便回報錯誤位置、參照的欄位與結果型別；
+// 實際計算仍交給 insyra。
+
+// CCL 公式結果型別
+const (
+	CCLTypeNumber  = "number"
+	CCLTypeString  = "string"
+	CCLTypeBoolean = "boolean"
+	CCLTypeMixed   = "mixed" // 型別依資料而定，或各列不一致
+)
+
+// defaultCCLPreviewRows PreviewCCL 未指定列數時預覽的列數
+const defaultCCLPreviewRows = 10
+
+// CCLDiagnostic 公式中的一個錯誤；Offset 與 Length 以字元計算
+type CCLDiagnostic struct {
+	Offset  int    `json:"offset"`
+	Length  int    `json:"length"`
+	Token   string `json:"token"`
+	Message string `json:"message"`
+}
+
+// CCLColumnRef 公式參照的欄位
+type CCLColumnRef struct {
+	Letter string `json:"letter"`
+	Index  int    `json:"index"`
+	Name   string `json:"name"`
+}
+
+// CCLValidation 公式檢查結果
+type CCLValidation struct {
+	Valid             bool            `json:"valid"`
+	Errors            []CCLDiagnostic `json:"errors"`
+	ReferencedColumns []CCLColumnRef  `json:"referencedColumns"`
+	ResultType        string          `json:"resultType"`
+}
+
+// CCLPreview 公式在前幾列的計算結果
+type CCLPreview struct {
+	Values     []any  `json:"values"`
+	ResultType string `json:"resultType"`
+	TotalRows  int    `json:"totalRows"`
+}
+
+// cclFunction 內建函式的參數規則與結果型別
+type cclFunction struct {
+	minArgs int
+	maxArgs int // -1 表示不限
+	odd     bool
+	result  func(args []string) string
+}
+
+// cclFunctions insyra 註冊的 CCL 函式（名稱不分大小寫）
+var cclFunctions = map[string]cclFunction{
+	"IF":     {minArgs: 3, maxArgs: 3, result: func(args []string) string { return unifyCCLTypes(args[1], args[2]) }},
+	"AND":    {minArgs: 2, maxArgs: -1, result: func([]string) string { return CCLTypeBoolean }},
+	"OR":     {minArgs: 2, maxArgs: -1, result: func([]string) string { return CCLTypeBoolean }},
+	"CONCAT": {minArgs: 2, maxArgs: -1, result: func([]string) string { return CCLTypeString }},
+	"CASE": {minArgs: 3, maxArgs: -1, odd: true, result: func(args []string) string {
+		results := []string{args[len(args)-1]}
+		for i := 1; i < len(args)-1; i += 2 {
+			results = append(results, args[i])
+		}
+		return unifyCCLTypes(results...)
+	}},
+}
+
+// ValidateCCL 檢查公式的語法、參照的欄位與函式，並推斷結果型別
+func (s *DataTableService) ValidateCCL(tableID string, formula string) (CCLValidation, error) {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return CCLValidation{}, errTableNotFound(tableID)
+	}
+	return validateCCL(dt, formula), nil
+}
+
+// PreviewCCL 以資料表的前 rows 列計算公式，不修改資料表；rows 不大於 0 時預覽 10 列
+func (s *DataTableService) PreviewCCL(tableID string, formula string, rows int) (CCLPreview, error) {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return CCLPreview{}, errTableNotFound(tableID)
+	}
+	if rows <= 0 {
+		rows = defaultCCLPreviewRows
+	}
+	validation := validateCCL(dt, formula)
+	if !validation.Valid {
+		return CCLPreview{}, errFormulaInvalid(formula, validation.Errors[0])
+	}
+
+	// 在前幾列的副本上計算，與新增計算欄位時使用相同的 insyra 實作
+	names, columns := snapshotColumns(dt)
+	totalRows := columnLength(columns)
+	n := min(rows, totalRows)
+	sample := make([]*insyra.DataList, len(columns))
+	for j, col := range columns {
+		values := make([]any, n)
+		copy(values, col[:min(n, len(col))])
+		sample[j] = insyra.NewDataList(values...).SetName(names[j])
+	}
+	preview := insyra.NewDataTable(sample...)
+	if err := addCalculatedColumn(preview, "preview", formula); err != nil {
+		return CCLPreview{}, err
+	}
+
+	values := preview.GetColByNumber(-1).Data()
+	for i, v := range values {
+		// JSON 無法表示 NaN 與無限大
+		if f, ok := v.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+			values[i] = nil
+		}
+	}
+	return CCLPreview{
+		Values:     values,
+		ResultType: validation.ResultType,
+		TotalRows:  totalRows,
+	}, nil
+}
+
+// errFormulaInvalid 公式檢查不通過，細節包含錯誤位置與字詞
+func errFormulaInvalid(formula string, d CCLDiagnostic) *ServiceError {
+	return newError(ErrCodeFormula, "errors.formula_invalid", "formula %q: %s at offset %d", formula, d.Message, d.Offset).
+		WithDetail("formula", formula).
+		WithDetail("offset", d.Offset).
+		WithDetail("length", d.Length).
+		WithDetail("token", d.Token)
+}
+
+// validateCCL 檢查公式並推斷結果型別
+func validateCCL(dt *insyra.DataTable, formula string) CCLValidation {
+	names, columns := snapshotColumns(dt)
+	c := &cclChecker{
+		names:       names,
+		columnTypes: make([]string, len(columns)),
+	}
+	for j, col := range columns {
+		c.columnTypes[j] = cclColumnType(col)
+	}
+
+	result := CCLValidation{Errors: []CCLDiagnostic{}, ReferencedColumns: []CCLColumnRef{}}
+	tokens, diag := tokenizeCCL(formula)
+	if diag != nil {
+		result.Errors = append(result.Errors, *diag)
+		return result
+	}
+	c.tokens = tokens
+	typ, diag := c.parseExpression(0)
+	if diag == nil && c.current().kind != cclEOF {
+		diag = c.unexpected(c.current())
+	}
+	if diag != nil {
+		c.errors = append(c.errors, *diag)
+	}
+
+	result.Errors = append(result.Errors, c.errors...)
+	result.ReferencedColumns = append(result.ReferencedColumns, c.refs...)
+	result.Valid = len(result.Errors) == 0
+	if result.Valid {
+		result.ResultType = typ
+	}
+	return result
+}
+
+// cclColumnType 依欄位中所有非空值推斷參照該欄時的型別
+func cclColumnType(values []any) string {
+	typ := ""
+	for _, v := range values {
+		var t string
+		switch v.(type) {
+		case nil:
+			continue
+		case float64, float32, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			t = CCLTypeNumber
+		case bool:
+			t = CCLTypeBoolean
+		case string:
+			t = CCLTypeString
+		default:
+			return CCLTypeMixed
+		}
+		if typ != "" && typ != t {
+			return CCLTypeMixed
+		}
+		typ = t
+	}
+	if typ == "" {
+		return CCLTypeMixed
+	}
+	return typ
+}
+
+// unifyCCLTypes 多個分支可能的結果型別；不一致時為 mixed
+func unifyCCLTypes(types ...string) string {
+	for _, t := range types[1:] {
+		if t != types[0] {
+			return CCLTypeMixed
+		}
+	}
+	return types[0]
+}
+
+// ----- 詞法分析 -----
+
+type cclTokenKind int
+
+const (
+	cclEOF cclTokenKind = iota
+	cclIdent
+	cclNumber
+	cclString
+	cclBoolean
+	cclLParen
+	cclRParen
+	cclComma
+	cclOperator
+)
+
+type cclToken struct {
+	kind   cclTokenKind
+	text   string
+	offset int
+	length int
+}
+
+// tokenizeCCL 依 insyra 的規則切分字詞，並記錄每個字詞的字元位置
+func tokenizeCCL(formula string) ([]cclToken, *CCLDiagnostic) {
+	input := []rune(formula)
+	var tokens []cclToken
+	emit := func(kind cclTokenKind, start, end int) {
+		tokens = append(tokens, cclToken{kind: kind, text: string(input[start:end]), offset: start, length: end - start})
+	}
+	i := 0
+	for i < len(input) {
+		ch := input[i]
+		start := i
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case unicode.IsLetter(ch):
+			for i < len(input) && (unicode.IsLetter(input[i]) || unicode.IsDigit(input[i])) {
+				i++
+			}
+			if word := string(input[start:i]); word == "true" || word == "false" {
+				emit(cclBoolean, start, i)
+			} else {
+				emit(cclIdent, start, i)
+			}
+		case unicode.IsDigit(ch) || ch == '.':
+			for i < len(input) && (unicode.IsDigit(input[i]) || input[i] == '.') {
+				i++
+			}
+			emit(cclNumber, start, i)
+		case ch == '"' || ch == '\'':
+			i++
+			for i < len(input) && input[i] != ch {
+				i++
+			}
+			if i >= len(input) {
+				return nil, &CCLDiagnostic{Offset: start, Length: len(input) - start, Token: string(input[start:]),
+					Message: fmt.Sprintf("unclosed string starting with %c", ch)}
+			}
+			i++
+			tokens = append(tokens, cclToken{kind: cclString, text: string(input[start+1 : i-1]), offset: start, length: i - start})
+		case ch == '(':
+			i++
+			emit(cclLParen, start, i)
+		case ch == ')':
+			i++
+			emit(cclRParen, start, i)
+		case ch == ',':
+			i++
+			emit(cclComma, start, i)
+		case strings.ContainsRune("+-*/%^=<>!", ch):
+			for i < len(input) && strings.ContainsRune("+-*/%^=<>!", input[i]) {
+				i++
+			}
+			emit(cclOperator, start, i)
+		default:
+			return nil, &CCLDiagnostic{Offset: start, Length: 1, Token: string(ch),
+				Message: fmt.Sprintf("unexpected character %q", ch)}
+		}
+	}
+	tokens = append(tokens, cclToken{kind: cclEOF, offset: len(input)})
+	return tokens, nil
+}
+
+// ----- 語法與語意檢查 -----
+
+// cclChecker 以與 insyra 相同的優先順序剖析公式，同時推斷型別並收集語意錯誤
+type cclChecker struct {
+	tokens      []cclToken
+	pos         int
+	names       []string
+	columnTypes []string
+	refs        []CCLColumnRef
+	errors      []CCLDiagnostic // 不影響剖析的錯誤，例如未知的欄位或函式
+}
+
+func (c *cclChecker) current() cclToken {
+	return c.tokens[c.pos]
+}
+
+func (c *cclChecker) advance() {
+	if c.pos < len(c.tokens)-1 {
+		c.pos++
+	}
+}
+
+// report 記錄語意錯誤並繼續剖析
+func (c *cclChecker) report(tok cclToken, format string, args ...any) {
+	c.errors = append(c.errors, CCLDiagnostic{
+		Offset:  tok.offset,
+		Length:  tok.length,
+		Token:   tok.text,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// unexpected 無法繼續剖析的字詞
+func (c *cclChecker) unexpected(tok cclToken) *CCLDiagnostic {
+	if tok.kind == cclEOF {
+		return &CCLDiagnostic{Offset: tok.offset, Message: "unexpected end of formula"}
+	}
+	return &CCLDiagnostic{Offset: tok.offset, Length: tok.length, Token: tok.text,
+		Message: fmt.Sprintf("unexpected %q", tok.text)}
+}
+
+// cclPrecedence 運算子的優先順序，與 insyra 相同
+func cclPrecedence(op string) int {
+	switch op {
+	case "=", "==", "!=", ">", "<", ">=", "<=":
+		return 1
+	case "+", "-":
+		return 2
+	case "*", "/", "%":
+		return 3
+	case "^":
+		return 4
+	}
+	return 0
+}
+
+func isCCLComparison(op string) bool {
+	switch op {
+	case "<", ">", "<=", ">=", "==", "!=":
+		return true
+	}
+	return false
+}
+
+// checkOperator 檢查 insyra 是否能計算此運算子
+func (c *cclChecker) checkOperator(tok cclToken) {
+	switch tok.text {
+	case "+", "-", "*", "/", "^", "<", ">", "<=", ">=", "==", "!=":
+	case "=":
+		c.report(tok, "unsupported operator %q; use == to compare", tok.text)
+	default:
+		c.report(tok, "unsupported operator %q", tok.text)
+	}
+}
+
+// parseExpression 剖析運算式並回傳其結果型別
+func (c *cclChecker) parseExpression(precedence int) (string, *CCLDiagnostic) {
+	left, diag := c.parsePrimary()
+	if diag != nil {
+		return "", diag
+	}
+
+	// 連續比較，例如 1 < A <= B；insyra 在此只接受單一值
+	if tok := c.current(); tok.kind == cclOperator && isCCLComparison(tok.text) && cclPrecedence(tok.text) >= precedence {
+		for tok := c.current(); tok.kind == cclOperator && isCCLComparison(tok.text); tok = c.current() {
+			c.advance()
+			if _, diag := c.parsePrimary(); diag != nil {
+				return "", diag
+			}
+		}
+		if tok := c.current(); tok.kind == cclOperator {
+			// insyra 會忽略比較之後的運算，例如 A > B + 1 只計算 A > B
+			return "", &CCLDiagnostic{Offset: tok.offset, Length: tok.length, Token: tok.text,
+				Message: fmt.Sprintf("unexpected %q after comparison; wrap it in parentheses, e.g. A > (B + 1)", tok.text)}
+		}
+		return CCLTypeBoolean, nil
+	}
+
+	for {
+		tok := c.current()
+		if tok.kind != cclOperator || cclPrecedence(tok.text) < precedence {
+			return left, nil
+		}
+		c.checkOperator(tok)
+		c.advance()
+		if _, diag := c.parseExpression(cclPrecedence(tok.text) + 1); diag != nil {
+			return "", diag
+		}
+		if isCCLComparison(tok.text) || tok.text == "=" {
+			left = CCLTypeBoolean
+		} else {
+			left = CCLTypeNumber
+		}
+	}
+}
+
+// parsePrimary 剖析常值、欄位參照、函式呼叫或括號內的運算式
+func (c *cclChecker) parsePrimary() (string, *CCLDiagnostic) {
+	tok := c.current()
+	switch tok.kind {
+	case cclNumber:
+		c.advance()
+		if _, err := strconv.ParseFloat(tok.text, 64); err != nil {
+			c.report(tok, "invalid number %q", tok.text)
+		}
+		return CCLTypeNumber, nil
+	case cclString:
+		c.advance()
+		return CCLTypeString, nil
+	case cclBoolean:
+		c.advance()
+		return CCLTypeBoolean, nil
+	case cclIdent:
+		c.advance()
+		if c.current().kind == cclLParen {
+			return c.parseCall(tok)
+		}
+		return c.columnRef(tok), nil
+	case cclLParen:
+		c.advance()
+		typ, diag := c.parseExpression(0)
+		if diag != nil {
+			return "", diag
+		}
+		if c.current().kind != cclRParen {
+			return "", c.expected(")")
+		}
+		c.advance()
+		return typ, nil
+	}
+	return "", c.unexpected(tok)
+}
+
+// expected 缺少必要的字詞
+func (c *cclChecker) expected(what string) *CCLDiagnostic {
+	tok := c.current()
+	if tok.kind == cclEOF {
+		return &CCLDiagnostic{Offset: tok.offset, Message: fmt.Sprintf("expected %q before end of formula", what)}
+	}
+	return &CCLDiagnostic{Offset: tok.offset, Length: tok.length, Token: tok.text,
+		Message: fmt.Sprintf("expected %q, got %q", what, tok.text)}
+}
+
+// parseCall 剖析函式呼叫的參數並檢查參數個數
+func (c *cclChecker) parseCall(name cclToken) (string, *CCLDiagnostic) {
+	c.advance() // (
+	var args []string
+	for c.current().kind != cclRParen {
+		typ, diag := c.parseExpression(0)
+		if diag != nil {
+			return "", diag
+		}
+		args = append(args, typ)
+		if c.current().kind == cclComma {
+			c.advance()
+			continue
+		}
+		if c.current().kind != cclRParen {
+			return "", c.expected(")")
+		}
+	}
+	c.advance() // )
+
+	fn, ok := cclFunctions[strings.ToUpper(name.text)]
+	if !ok {
+		c.report(name, "unknown function %s", name.text)
+		return CCLTypeMixed, nil
+	}
+	fnName := strings.ToUpper(name.text)
+	switch {
+	case fn.maxArgs == fn.minArgs && len(args) != fn.minArgs:
+		c.report(name, "%s requires %d arguments, got %d", fnName, fn.minArgs, len(args))
+	case len(args) < fn.minArgs:
+		c.report(name, "%s requires at least %d arguments, got %d", fnName, fn.minArgs, len(args))
+	case fn.odd && len(args)%2 != 1:
+		c.report(name, "%s requires condition/result pairs followed by a default value", fnName)
+	default:
+		return fn.result(args), nil
+	}
+	return CCLTypeMixed, nil
+}
+
+// columnRef 檢查欄位參照（A、B、…、AA）是否存在並記錄
+func (c *cclChecker) columnRef(tok cclToken) string {
+	if strings.IndexFunc(tok.text, func(r rune) bool { return r < 'A' || r > 'Z' }) >= 0 {
+		c.report(tok, "column reference %s must be uppercase column letters such as A or AB", tok.text)
+		return CCLTypeMixed
+	}
+	index := 0
+	for _, r := range tok.text {
+		index = index*26 + int(r-'A') + 1
+	}
+	index--
+	if index >= len(c.names) {
+		c.report(tok, "column %s does not exist (table has %d columns)", tok.text, len(c.names))
+		return CCLTypeMixed
+	}
+	if !slices.ContainsFunc(c.refs, func(r CCLColumnRef) bool { return r.Index == index }) {
+		c.refs = append(c.refs, CCLColumnRef{Letter: tok.text, Index: index, Name: c.names[index]})
+	}
+	return c.columnTypes[index]
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+// ===== 欄位 ID 與欄名 =====
+//
+// 欄位在資料表中的位置會因插入、刪除、移動而改變，欄名也可以重新命名或留白，
+// 因此另外為每一欄配發一個不會重複的 ID，讓前端在這些操作之後仍能辨識同一欄。
+
+// columnIDsOf 取得資料表各欄的 ID；欄位附加到末尾或從末尾移除時在此補發或截斷
+func (s *DataTableService) columnIDsOf(dt *insyra.DataTable) []int {
+	_, colCount := dt.Size()
+	ids := s.columnIDs[dt]
+	if len(ids) > colCount {
+		ids = ids[:colCount]
+	}
+	for len(ids) < colCount {
+		ids = append(ids, s.newColumnID())
+	}
+	s.columnIDs[dt] = ids
+	return ids
+}
+
+// newColumnID 配發一個新的欄位 ID
+func (s *DataTableService) newColumnID() int {
+	s.nextColumnID++
+	return s.nextColumnID
+}
+
+// setColumnIDs 以 ids 取代資料表各欄的 ID，並確保之後配發的 ID 不會與其重複；
+// 無效（不大於 0）或重複的 ID 會重新配發，例如載入舊版專案檔案時
+func (s *DataTableService) setColumnIDs(dt *insyra.DataTable, ids []int) {
+	ids = slices.Clone(ids)
+	for _, id := range ids {
+		s.nextColumnID = max(s.nextColumnID, id)
+	}
+	seen := make(map[int]bool, len(ids))
+	for j, id := range ids {
+		if id <= 0 || seen[id] {
+			ids[j] = s.newColumnID()
+		}
+		seen[ids[j]] = true
+	}
+	s.columnIDs[dt] = ids
+}
+
+// remapColumnIDs 依結構編輯後各欄的原始索引決定新的欄位 ID：
+// 搬移的欄保留原本的 ID，新增的欄與複製出的欄配發新的 ID
+func (s *DataTableService) remapColumnIDs(ids []int, origin []int) []int {
+	result := make([]int, len(origin))
+	used := make(map[int]bool, len(origin))
+	for j, from := range origin {
+		if from >= 0 && from < len(ids) && !used[from] {
+			used[from] = true
+			result[j] = ids[from]
+			continue
+		}
+		result[j] = s.newColumnID()
+	}
+	return result
+}
+
+// copyColumnSettings 讓 ID 為 to 的欄沿用 ID 為 from 的欄的型別、缺失值設定與變數資訊，例如複製欄位時
+func (s *DataTableService) copyColumnSettings(dt *insyra.DataTable, from int, to int) {
+	if dataType, ok := s.columnTypesOf(dt)[from]; ok {
+		s.columnTypesOf(dt)[to] = dataType
+	}
+	if policy, ok := s.missingPoliciesOf(dt)[from]; ok {
+		s.missingPoliciesOf(dt)[to] = policy
+	}
+	if meta, ok := s.variablesOf(dt)[from]; ok {
+		s.variablesOf(dt)[to] = meta
+	}
+}
+
+// uniqueColumnName 取得第 colIndex 欄可以使用的欄名：與其他欄重複時加上 "_1"、"_2"……；
+// 空白欄名（尚未命名的欄）允許重複。colIndex 為 -1 表示新增的欄
+func uniqueColumnName(dt *insyra.DataTable, colIndex int, name string) string {
+	if name == "" {
+		return ""
+	}
+	_, colCount := dt.Size()
+	taken := make(map[string]bool, colCount)
+	for j := range colCount {
+		if j != colIndex {
+			taken[dt.GetColNameByNumber(j)] = true
+		}
+	}
+	unique := name
+	for n := 1; taken[unique]; n++ {
+		unique = fmt.Sprintf("%s_%d", name, n)
+	}
+	return unique
+}
+
+// uniqueColumnNames 依序檢查 names，與前面的欄名重複時依 uniqueColumnName 的規則加上流水號；
+// 流水號會避開 names 中原有的欄名，空白欄名保持不變
+func uniqueColumnNames(names []string) []string {
+	taken := make(map[string]bool, len(names))
+	for _, name := range names {
+		taken[name] = true
+	}
+	seen := make(map[string]bool, len(names))
+	unique := make([]string, len(names))
+	for j, name := range names {
+		if name != "" && seen[name] {
+			base := name
+			for n := 1; taken[name]; n++ {
+				name = fmt.Sprintf("%s_%d", base, n)
+			}
+			taken[name] = true
+		}
+		seen[name] = true
+		unique[j] = name
+	}
+	return unique
+}
+
+// newTableFromColumns 以 columns 建立資料表；重複的欄名先以 uniqueColumnNames 加上流水號
+func newTableFromColumns(columns []*insyra.DataList) *insyra.DataTable {
+	names := make([]string, len(columns))
+	for j, col := range columns {
+		names[j] = col.GetName()
+	}
+	for j, name := range uniqueColumnNames(names) {
+		columns[j].SetName(name)
+	}
+	return insyra.NewDataTable(columns...)
+}
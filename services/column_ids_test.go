@@ -0,0 +1,73 @@
+package services
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+func TestUniqueColumnNames(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  []string
+	}{
+		{[]string{"a", "b"}, []string{"a", "b"}},
+		{[]string{"a", "a", "a"}, []string{"a", "a_1", "a_2"}},
+		// 流水號避開原有的欄名
+		{[]string{"x", "x", "x_1"}, []string{"x", "x_2", "x_1"}},
+		{[]string{"", ""}, []string{"", ""}},
+	}
+	for _, tt := range tests {
+		if got := uniqueColumnNames(tt.names); !slices.Equal(got, tt.want) {
+			t.Errorf("uniqueColumnNames(%q) = %q, want %q", tt.names, got, tt.want)
+		}
+	}
+}
+
+func columnNames(dt *insyra.DataTable) []string {
+	_, colCount := dt.Size()
+	names := make([]string, colCount)
+	for j := range names {
+		names[j] = dt.GetColNameByNumber(j)
+	}
+	return names
+}
+
+func TestDuplicateColumnsUsesUniqueNames(t *testing.T) {
+	s, id, dt := newStructureTestTable(t)
+	if err := s.DuplicateColumnsByID(id, 0, 2); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := columnNames(dt), []string{"A", "B", "A_1", "B_1", "C"}; !slices.Equal(got, want) {
+		t.Fatalf("names after duplicate = %q, want %q", got, want)
+	}
+	if err := s.DuplicateColumnsByID(id, 2, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := columnNames(dt), []string{"A", "B", "A_1", "A_1_1", "B_1", "C"}; !slices.Equal(got, want) {
+		t.Fatalf("names after duplicating a copy = %q, want %q", got, want)
+	}
+}
+
+func TestImportUsesUniqueHeaderNames(t *testing.T) {
+	ConfigureInsyra()
+	s := NewDataTableService()
+	csvPath := writeTestFile(t, "dup.csv", "x,x,x_1,\n1,2,3,4\n")
+	id, err := s.OpenCSVFile(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := columnNames(s.getTableByID(id)), []string{"x", "x_2", "x_1", "Column4"}; !slices.Equal(got, want) {
+		t.Errorf("csv names = %q, want %q", got, want)
+	}
+
+	jsonPath := writeTestFile(t, "dup.json", `{"a": [1, 2], "a_1": [3, 4]}`)
+	id, err = s.OpenJSONFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := columnNames(s.getTableByID(id)), []string{"a", "a_1"}; !slices.Equal(got, want) {
+		t.Errorf("json names = %q, want %q", got, want)
+	}
+}
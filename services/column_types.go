@@ -0,0 +1,399 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+// ===== 欄位型別 =====
+//
+// 每一欄都有宣告的型別，儲存格的值依型別轉換後才寫入資料表，
+// 因此數值欄中存放的是數值而不是文字。型別以欄位 ID 為鍵保存，搬移欄位後仍然有效。
+
+// 欄位的宣告型別
+const (
+	DataTypeUnset       = ""            // 尚未決定：欄位沒有任何值，第一個輸入的值決定型別
+	DataTypeNumeric     = "numeric"     // 浮點數
+	DataTypeInteger     = "integer"     // 整數
+	DataTypeString      = "string"      // 文字
+	DataTypeBoolean     = "boolean"     // 布林值
+	DataTypeDateTime    = "datetime"    // 日期與時間
+	DataTypeCategorical = "categorical" // 類別：數值代碼或文字標籤
+)
+
+// dataTypes 可以宣告的型別
+var dataTypes = map[string]bool{
+	DataTypeNumeric:     true,
+	DataTypeInteger:     true,
+	DataTypeString:      true,
+	DataTypeBoolean:     true,
+	DataTypeDateTime:    true,
+	DataTypeCategorical: true,
+}
+
+// dateTimeLayouts 輸入日期與時間時接受的格式
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"2006/01/02",
+}
+
+// columnTypesOf 取得資料表各欄 ID 對應的型別，不存在時建立
+func (s *DataTableService) columnTypesOf(dt *insyra.DataTable) map[int]string {
+	types, ok := s.columnTypes[dt]
+	if !ok {
+		types = make(map[int]string)
+		s.columnTypes[dt] = types
+	}
+	return types
+}
+
+// columnTypeOf 取得第 col 欄宣告的型別；只查詢不推斷，讀取資料時不會改變資料表。
+// 型別在匯入（importTable）與編輯時決定
+func (s *DataTableService) columnTypeOf(dt *insyra.DataTable, col int) string {
+	return s.columnTypes[dt][s.columnIDsOf(dt)[col]]
+}
+
+// inferColumnTypes 依現有的值推斷所有尚未宣告型別的欄位並轉換其值（匯入或載入資料表時使用）
+func (s *DataTableService) inferColumnTypes(dt *insyra.DataTable) {
+	types := s.columnTypesOf(dt)
+	for j, id := range s.columnIDsOf(dt) {
+		if _, ok := types[id]; ok {
+			continue
+		}
+		column := dt.GetColByNumber(j)
+		values := column.Data()
+		dataType := inferColumnType(values)
+		if dataType == DataTypeUnset {
+			continue
+		}
+		// 推斷出的型別一定能轉換所有的值
+		converted, _ := convertValues(values, dataType)
+		dt.UpdateColByNumber(j, insyra.NewDataList(converted...).SetName(column.GetName()))
+		types[id] = dataType
+	}
+}
+
+// columnTypeTexts 取得各欄的型別，以欄位索引為鍵，省略尚未決定的欄
+func (s *DataTableService) columnTypeTexts(dt *insyra.DataTable) map[int]string {
+	texts := make(map[int]string)
+	types := s.columnTypesOf(dt)
+	for j, id := range s.columnIDsOf(dt) {
+		if dataType := types[id]; dataType != DataTypeUnset {
+			texts[j] = dataType
+		}
+	}
+	return texts
+}
+
+// setColumnTypeTexts 以欄位索引設定各欄的型別並轉換現有的值（例如以文字儲存的日期），
+// 未知的型別略過並改為推斷
+func (s *DataTableService) setColumnTypeTexts(dt *insyra.DataTable, texts map[int]string) {
+	types := s.columnTypesOf(dt)
+	ids := s.columnIDsOf(dt)
+	for j, dataType := range texts {
+		if j < 0 || j >= len(ids) || !dataTypes[dataType] {
+			continue
+		}
+		column := dt.GetColByNumber(j)
+		converted, _ := convertValues(column.Data(), dataType)
+		dt.UpdateColByNumber(j, insyra.NewDataList(converted...).SetName(column.GetName()))
+		types[ids[j]] = dataType
+	}
+	s.inferColumnTypes(dt)
+}
+
+// SetColumnType 宣告欄位的型別並轉換現有的值；無法轉換的值在 force 為 true 時改為缺失值，
+// 否則回傳錯誤且資料表不變。計算欄位的型別由公式決定，不可變更
+func (s *DataTableService) SetColumnType(tableID string, colIndex int, dataType string, force bool) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
+	}
+	if _, colCount := dt.Size(); colIndex < 0 || colIndex >= colCount {
+		return errColumnOutOfRange(colIndex, colCount)
+	}
+	if !dataTypes[dataType] {
+		return invalidArgument("unknown data type %q", dataType).WithDetail("type", dataType)
+	}
+	if f := s.formulas[dt][colIndex]; f != nil {
+		return errCalculatedColumn(colIndex, f.formula)
+	}
+
+	oldType := s.columnTypeOf(dt, colIndex)
+	column := dt.GetColByNumber(colIndex)
+	oldValues := column.Data()
+	newValues, failed := convertValues(oldValues, dataType)
+	if len(failed) > 0 && !force {
+		return errTypeConversion(colIndex, dataType, failed)
+	}
+	if dataType == oldType && len(failed) == 0 {
+		return nil
+	}
+
+	id := s.columnIDsOf(dt)[colIndex]
+	apply := func(dt *insyra.DataTable, dataType string, values []any) {
+		dt.UpdateColByNumber(colIndex, insyra.NewDataList(values...).SetName(dt.GetColNameByNumber(colIndex)))
+		if dataType == DataTypeUnset {
+			delete(s.columnTypesOf(dt), id)
+		} else {
+			s.columnTypesOf(dt)[id] = dataType
+		}
+	}
+	apply(dt, dataType, newValues)
+	s.recomputeColumns(dt, []int{colIndex}, false)
+	s.record(dt, "set column type",
+		func(dt *insyra.DataTable) { apply(dt, oldType, oldValues) },
+		func(dt *insyra.DataTable) { apply(dt, dataType, newValues) },
+	)
+	return nil
+}
+
+// coerceInput 將輸入的文字依第 col 欄的型別轉換為儲存格的值；
+// 欄位尚未決定型別時以此值推斷並回傳 true，呼叫者需在復原時清除推斷出的型別
+func (s *DataTableService) coerceInput(dt *insyra.DataTable, row int, col int, text string) (any, bool, error) {
+	dataType := s.columnTypeOf(dt, col)
+	inferred := false
+	if dataType == DataTypeUnset {
+		// 空白欄位的第一個值決定欄位型別
+		dataType = inferColumnType([]any{text})
+		s.columnTypesOf(dt)[s.columnIDsOf(dt)[col]] = dataType
+		inferred = true
+	}
+	value, ok := convertValue(text, dataType)
+	if !ok {
+		if inferred {
+			delete(s.columnTypesOf(dt), s.columnIDsOf(dt)[col])
+		}
+		return nil, false, errInvalidValue(row, col, text, dataType)
+	}
+	return value, inferred, nil
+}
+
+// inferInputTypes 依整批輸入（例如貼上）的文字推斷尚未決定型別的欄位，
+// 避免只以第一個值決定型別而拒絕同一批中的其他值
+func (s *DataTableService) inferInputTypes(dt *insyra.DataTable, updates []CellUpdate) {
+	_, colCount := dt.Size()
+	inputs := make(map[int][]any)
+	for _, u := range updates {
+		if u.Col < 0 || u.Col >= colCount || s.formulas[dt][u.Col] != nil || s.missingPolicyOf(dt, u.Col).Policy.isMissing(u.Value) {
+			continue
+		}
+		if s.columnTypeOf(dt, u.Col) == DataTypeUnset {
+			inputs[u.Col] = append(inputs[u.Col], u.Value)
+		}
+	}
+	for col, values := range inputs {
+		id := s.columnIDsOf(dt)[col]
+		dataType := inferColumnType(values)
+		s.columnTypesOf(dt)[id] = dataType
+		s.record(dt, "infer column type",
+			func(dt *insyra.DataTable) { delete(s.columnTypesOf(dt), id) },
+			func(dt *insyra.DataTable) { s.columnTypesOf(dt)[id] = dataType },
+		)
+	}
+}
+
+// inferColumnType 推斷能容納所有非缺失值的型別：布林、日期時間、整數、數值，其餘為文字；
+// 沒有任何值時回傳 DataTypeUnset。類別型別不會被推斷
+func inferColumnType(values []any) string {
+	candidates := []string{DataTypeBoolean, DataTypeDateTime, DataTypeInteger, DataTypeNumeric}
+	seen := false
+	for _, v := range values {
+		if isMissingValue(v) {
+			continue
+		}
+		seen = true
+		candidates = slices.DeleteFunc(candidates, func(dataType string) bool {
+			_, ok := inferValue(v, dataType)
+			return !ok
+		})
+		if len(candidates) == 0 {
+			return DataTypeString
+		}
+	}
+	if !seen {
+		return DataTypeUnset
+	}
+	return candidates[0]
+}
+
+// inferValue 判斷值是否屬於型別；比 convertValue 嚴格：只有 true／false 視為布林值，
+// 浮點數與帶小數點的文字不視為整數，補零的文字（例如 "007"）不視為數值
+func inferValue(v any, dataType string) (any, bool) {
+	switch val := v.(type) {
+	case string:
+		text := strings.TrimSpace(val)
+		switch dataType {
+		case DataTypeBoolean:
+			if !strings.EqualFold(text, "true") && !strings.EqualFold(text, "false") {
+				return nil, false
+			}
+		case DataTypeInteger:
+			if _, err := strconv.Atoi(text); err != nil || isZeroPadded(text) {
+				return nil, false
+			}
+		case DataTypeNumeric:
+			// 補零的數字通常是代碼，轉為數值會遺失前導的 0
+			if isZeroPadded(text) {
+				return nil, false
+			}
+		}
+	case float32, float64:
+		if dataType == DataTypeInteger || dataType == DataTypeBoolean {
+			return nil, false
+		}
+	default:
+		if _, isBool := v.(bool); dataType == DataTypeBoolean && !isBool {
+			return nil, false
+		}
+	}
+	return convertValue(v, dataType)
+}
+
+// convertValues 將所有值轉換為型別，回傳轉換後的值與無法轉換的列（這些列改為 nil）
+func convertValues(values []any, dataType string) ([]any, []int) {
+	converted := make([]any, len(values))
+	var failed []int
+	for i, v := range values {
+		c, ok := convertValue(v, dataType)
+		if !ok {
+			failed = append(failed, i)
+			continue
+		}
+		converted[i] = c
+	}
+	return converted, failed
+}
+
+// convertValue 將單一值轉換為型別；缺失值一律轉為 nil
+func convertValue(v any, dataType string) (any, bool) {
+	if isMissingValue(v) {
+		return nil, true
+	}
+	if text, ok := v.(string); ok && dataType != DataTypeString {
+		v = strings.TrimSpace(text)
+	}
+	switch dataType {
+	case DataTypeNumeric:
+		if b, ok := v.(bool); ok {
+			return boolNumber(b), true
+		}
+		return toNumber(v)
+	case DataTypeInteger:
+		switch val := v.(type) {
+		case bool:
+			return int(boolNumber(val)), true
+		case int:
+			return val, true
+		}
+		f, ok := toNumber(v)
+		if !ok || f != math.Trunc(f) || math.Abs(f) > math.MaxInt64 {
+			return nil, false
+		}
+		return int(f), true
+	case DataTypeString:
+		return formatValue(v), true
+	case DataTypeBoolean:
+		return toBool(v)
+	case DataTypeDateTime:
+		return toTime(v)
+	case DataTypeCategorical:
+		// 類別值保留原本的型別；看起來像數值的文字視為數值代碼，但 "02134" 這類補零的代碼保留為文字
+		if text, ok := v.(string); ok && !isZeroPadded(text) {
+			if n, ok := parseNumber(text, "."); ok {
+				return n, true
+			}
+		}
+		return v, true
+	}
+	return nil, false
+}
+
+// boolNumber 將布林值轉為 1 或 0
+func boolNumber(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// toBool 將布林值、數值 1／0 或 true／false、yes／no 等文字轉為布林值
+func toBool(v any) (any, bool) {
+	switch val := v.(type) {
+	case bool:
+		return val, true
+	case string:
+		switch strings.ToLower(val) {
+		case "true", "t", "yes", "y", "1":
+			return true, true
+		case "false", "f", "no", "n", "0":
+			return false, true
+		}
+		return nil, false
+	}
+	if f, ok := insyra.ToFloat64Safe(v); ok && (f == 0 || f == 1) {
+		return f == 1, true
+	}
+	return nil, false
+}
+
+// toTime 將時間或 dateTimeLayouts 中任一格式的文字轉為時間
+func toTime(v any) (any, bool) {
+	switch val := v.(type) {
+	case time.Time:
+		return val, true
+	case string:
+		for _, layout := range dateTimeLayouts {
+			if t, err := time.Parse(layout, val); err == nil {
+				return t, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// formatValue 將值轉為文字；浮點數不使用科學記號，時間使用 RFC 3339
+func formatValue(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32)
+	case time.Time:
+		return val.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// errInvalidValue 輸入的值不符合欄位的型別
+func errInvalidValue(row int, col int, value string, dataType string) *ServiceError {
+	return newError(ErrCodeInvalidArgument, "errors.invalid_value",
+		"value %q at row %d, column %d is not a valid %s", value, row, col, dataType).
+		WithDetail("row", row).WithDetail("col", col).WithDetail("value", value).WithDetail("type", dataType)
+}
+
+// errTypeConversion 欄位中有值無法轉換為新的型別
+func errTypeConversion(col int, dataType string, rows []int) *ServiceError {
+	return newError(ErrCodeInvalidArgument, "errors.type_conversion_failed",
+		"%d value(s) in column %d cannot be converted to %s", len(rows), col, dataType).
+		WithDetail("col", col).WithDetail("type", dataType).WithDetail("count", len(rows)).
+		WithDetail("rows", rows[:min(len(rows), 10)])
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+// 讀取資料表時不推斷型別，也不轉換欄位的值
+func TestReadsDoNotInferColumnTypes(t *testing.T) {
+	ConfigureInsyra()
+	s := NewDataTableService()
+	statistics := NewStatisticsService(s)
+	dt := insyra.NewDataTable(insyra.NewDataList("1", "2").SetName("x"))
+	s.lock()
+	id := s.insertTable(-1, newTableID(), dt)
+	s.unlock()
+
+	if _, err := s.GetTableDataByID(id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetTableWindow(id, 0, 10, 0, 10); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetVariableView(id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := statistics.GetTableStatistics(id); err != nil {
+		t.Fatal(err)
+	}
+	if got := dt.GetColByNumber(0).Data(); !reflect.DeepEqual(got, []any{"1", "2"}) {
+		t.Errorf("values after reads = %#v, want the original text", got)
+	}
+	if len(s.columnTypes[dt]) != 0 {
+		t.Errorf("reads declared types %v", s.columnTypes[dt])
+	}
+}
+
+// LoadTable 與 LoadTableByID 與其他匯入相同：套用缺失值設定並推斷型別
+func TestLoadTableAppliesImportSettings(t *testing.T) {
+	ConfigureInsyra()
+	path := writeTestFile(t, "load.json", `[{"x": 1, "y": "a"}, {"x": ".", "y": "b"}, {"x": 3, "y": "."}]`)
+	s := NewDataTableService()
+	byID, err := s.LoadTableByID(0, "byID", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LoadTable("byName", path); err != nil {
+		t.Fatal(err)
+	}
+	for _, dt := range []*insyra.DataTable{s.getTableByID(byID), s.findTableByName("byName")} {
+		if got := []string{s.columnTypeOf(dt, 0), s.columnTypeOf(dt, 1)}; !reflect.DeepEqual(got, []string{DataTypeInteger, DataTypeString}) {
+			t.Errorf("%s types = %q", dt.GetName(), got)
+		}
+		if got := dt.GetColByNumber(0).Data(); got[1] != nil {
+			t.Errorf("%s x = %#v, want the NA token as nil", dt.GetName(), got)
+		}
+		if got := dt.GetColByNumber(1).Data(); got[2] != nil {
+			t.Errorf("%s y = %#v, want the NA token as nil", dt.GetName(), got)
+		}
+	}
+}
+
+// 計算欄位的型別在計算時決定，復原設定公式時還原原本的型別
+func TestCalculatedColumnTypeFollowsResults(t *testing.T) {
+	s, id, dt := newStructureTestTable(t)
+	if got := s.columnTypeOf(dt, 2); got != DataTypeNumeric {
+		t.Fatalf("calculated column type = %q, want %q", got, DataTypeNumeric)
+	}
+	if err := s.SetColumnType(id, 0, DataTypeNumeric, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateCellValueByID(id, 0, 0, "1.5"); err != nil {
+		t.Fatal(err)
+	}
+	if got := dt.GetElementByNumberIndex(0, 2); got != 15.0 {
+		t.Errorf("calculated value = %#v, want 15", got)
+	}
+
+	// 將文字欄 B 改為計算欄位，型別隨結果改為數值；復原後回到文字
+	if err := s.SetColumnFormula(id, 1, "A+1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.columnTypeOf(dt, 1); got != DataTypeNumeric {
+		t.Errorf("type after setting a formula = %q, want %q", got, DataTypeNumeric)
+	}
+	if _, err := s.Undo(id); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.columnTypeOf(dt, 1); got != DataTypeString {
+		t.Errorf("type after undo = %q, want %q", got, DataTypeString)
+	}
+}
+
+// 類別欄把數值文字視為數值代碼，但補零的代碼（郵遞區號、編號）保留為文字
+func TestConvertCategoricalKeepsZeroPaddedCodes(t *testing.T) {
+	tests := []struct {
+		in   any
+		want any
+	}{
+		{"02134", "02134"},
+		{"-01", "-01"},
+		{"2134", 2134},
+		{"0", 0},
+		{"0.5", 0.5},
+		{"north", "north"},
+		{true, true},
+	}
+	for _, tt := range tests {
+		if got, ok := convertValue(tt.in, DataTypeCategorical); !ok || got != tt.want {
+			t.Errorf("convertValue(%#v, categorical) = %#v, %v; want %#v", tt.in, got, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,464 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ===== 欄式檔案（Parquet、Arrow IPC / Feather）共用 =====
+//
+// 兩種格式都以欄為單位儲存，並分成多個 row group 或 record batch。
+// 讀取時只讀取選取的欄，逐組解碼為數值、布林、時間或文字，不經過文字轉換；
+// 寫出時依欄位型別選擇對應的型別，並分組寫出。
+
+// 欄式檔案的壓縮方式
+const (
+	CompressionNone   = "none"
+	CompressionSnappy = "snappy"
+	CompressionGzip   = "gzip"
+	CompressionZstd   = "zstd"
+	CompressionLZ4    = "lz4"
+)
+
+const (
+	// columnarGroupRows 寫出時每個 row group 或 record batch 的預設列數
+	columnarGroupRows = 65536
+	// columnarMaxBuffer 單一頁面或緩衝區的最大位元組數，避免損毀的檔案造成過大的配置
+	columnarMaxBuffer = 1 << 31
+)
+
+// ColumnarImportOptions Parquet 與 Arrow 檔案的匯入設定
+type ColumnarImportOptions struct {
+	Columns []string `json:"columns"` // 要讀取的欄位名稱，空白表示所有支援的欄位
+}
+
+// ColumnarExportOptions Parquet 與 Arrow 檔案的匯出設定
+type ColumnarExportOptions struct {
+	Compression string `json:"compression"` // "none"、"snappy"、"gzip" 或 "zstd"；Arrow 只支援 "none" 與 "zstd"
+	GroupRows   int    `json:"groupRows"`   // 每個 row group 或 record batch 的列數，0 表示預設值
+}
+
+// ColumnarFileInfo 欄式檔案的結構
+type ColumnarFileInfo struct {
+	RowCount int64                `json:"rowCount"`
+	Groups   int                  `json:"groups"` // Parquet 的 row group 數或 Arrow 的 record batch 數
+	Columns  []ColumnarColumnInfo `json:"columns"`
+}
+
+// ColumnarColumnInfo 欄式檔案中的一欄
+type ColumnarColumnInfo struct {
+	Name     string `json:"name"`
+	FileType string `json:"fileType"` // 檔案中的型別，例如 "INT64 (TIMESTAMP)"、"utf8"
+	Type     string `json:"type"`     // 匯入後的欄位型別，無法匯入的欄（例如巢狀結構）為空字串
+}
+
+// DefaultParquetExportOptions 預設的 Parquet 匯出設定：Snappy 壓縮
+func DefaultParquetExportOptions() ColumnarExportOptions {
+	return ColumnarExportOptions{Compression: CompressionSnappy, GroupRows: columnarGroupRows}
+}
+
+// DefaultArrowExportOptions 預設的 Arrow 匯出設定：不壓縮，任何版本的 Arrow 都能讀取
+func DefaultArrowExportOptions() ColumnarExportOptions {
+	return ColumnarExportOptions{Compression: CompressionNone, GroupRows: columnarGroupRows}
+}
+
+// selectColumns 依匯入設定決定要讀取的欄，回傳欄位索引；不存在或無法匯入的欄位回傳錯誤
+func selectColumns(format string, columns []ColumnarColumnInfo, options *ColumnarImportOptions) ([]int, error) {
+	if options == nil || len(options.Columns) == 0 {
+		var selected []int
+		for j, col := range columns {
+			if col.Type != "" {
+				selected = append(selected, j)
+			}
+		}
+		return selected, nil
+	}
+	selected := make([]int, 0, len(options.Columns))
+	for _, name := range options.Columns {
+		j := slices.IndexFunc(columns, func(col ColumnarColumnInfo) bool { return col.Name == name })
+		switch {
+		case j < 0:
+			return nil, invalidArgument("column %q not found", name).WithDetail("column", name)
+		case columns[j].Type == "":
+			return nil, errFileUnsupported(format, "column "+name+" of type "+columns[j].FileType)
+		}
+		selected = append(selected, j)
+	}
+	return selected, nil
+}
+
+// openColumnar 讀取檔案結構並檢查選取的欄位，再由 read 逐個 row group 或 record batch 將值附加到各欄，
+// 讀完後建立資料表並回傳ID；供 Parquet 與 Arrow 的匯入使用。解碼的暫存只保留一組，不會累積整個檔案
+func (s *DataTableService) openColumnar(filePath string, format string, options *ColumnarImportOptions,
+	describe func() (ColumnarFileInfo, error), read func(selected []int, appendGroup func(group [][]any)) error) (string, error) {
+	info, err := describe()
+	if err != nil {
+		return "", err
+	}
+	selected, err := selectColumns(format, info.Columns, options)
+	if err != nil {
+		return "", err
+	}
+	columns := make([]statColumn, len(selected))
+	for k, j := range selected {
+		columns[k] = statColumn{name: info.Columns[j].Name, dataType: info.Columns[j].Type}
+	}
+	err = read(selected, func(group [][]any) {
+		for k, values := range group {
+			columns[k].values = append(columns[k].values, values...)
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	return s.appendStatTable(tableNameFromPath(filePath), columns), nil
+}
+
+// columnarColumns 取得要寫出的各欄，型別為空的欄依值推斷
+func (s *DataTableService) columnarColumns(tableID string) (string, []statColumn, error) {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return "", nil, errTableNotFound(tableID)
+	}
+	return dt.GetName(), s.statColumns(dt), nil
+}
+
+// normalizeExportOptions 檢查匯出設定並補上預設值
+func normalizeExportOptions(options ColumnarExportOptions, compressions ...string) (ColumnarExportOptions, error) {
+	if options.Compression == "" {
+		options.Compression = CompressionNone
+	}
+	if !slices.Contains(compressions, options.Compression) {
+		return options, invalidArgument("unsupported compression %q", options.Compression).WithDetail("compression", options.Compression)
+	}
+	if options.GroupRows < 0 {
+		return options, invalidArgument("rows per group must not be negative, got %d", options.GroupRows).WithDetail("groupRows", options.GroupRows)
+	}
+	if options.GroupRows == 0 {
+		options.GroupRows = columnarGroupRows
+	}
+	return options, nil
+}
+
+// ----- 寫出時的值 -----
+
+// columnarKind 寫出時各欄的儲存型別
+type columnarKind int
+
+const (
+	kindString columnarKind = iota
+	kindInt64
+	kindDouble
+	kindBool
+	kindTimestamp // 以微秒儲存的 UTC 時間
+)
+
+// columnarKindOf 依欄位型別決定寫出時的儲存型別；數值代碼的類別欄仍以文字寫出
+func columnarKindOf(col statColumn) columnarKind {
+	switch col.dataType {
+	case DataTypeInteger:
+		return kindInt64
+	case DataTypeNumeric:
+		return kindDouble
+	case DataTypeBoolean:
+		return kindBool
+	case DataTypeDateTime:
+		return kindTimestamp
+	}
+	return kindString
+}
+
+// columnarNames 寫出時的欄名：去除前後空白、補上空白欄名並讓重複的欄名不衝突
+func columnarNames(columns []statColumn) []string {
+	names := make([]string, len(columns))
+	for j, col := range columns {
+		names[j] = col.name
+	}
+	return statVariableNames(names, func(rune, bool) bool { return true }, math.MaxInt32, false, nil)
+}
+
+// columnarValue 將值轉為儲存型別對應的 Go 值：int64、float64、bool、int64（微秒）或 string；缺失值回傳 nil
+func columnarValue(kind columnarKind, v any) any {
+	if v == nil {
+		return nil
+	}
+	switch kind {
+	case kindInt64:
+		if f, ok := statNumber(v); ok && f == math.Trunc(f) && math.Abs(f) < 1<<63 {
+			return int64(f)
+		}
+	case kindDouble:
+		if f, ok := statNumber(v); ok {
+			return f
+		}
+	case kindBool:
+		if b, ok := toBool(v); ok {
+			return b
+		}
+	case kindTimestamp:
+		if t, ok := v.(time.Time); ok {
+			return t.UnixMicro()
+		}
+	default:
+		return formatValue(v)
+	}
+	return nil
+}
+
+// ----- 讀取時的值 -----
+
+// timeFromUnit 將自 1970-01-01 起算的單位數轉為 UTC 時間
+func timeFromUnit(v int64, unit time.Duration) time.Time {
+	switch unit {
+	case time.Second:
+		return time.Unix(v, 0).UTC()
+	case time.Millisecond:
+		return time.UnixMilli(v).UTC()
+	case time.Microsecond:
+		return time.UnixMicro(v).UTC()
+	}
+	return time.Unix(0, v).UTC()
+}
+
+// timeOfDayText 將一天中的時間（單位數）轉為 "15:04:05.000000" 形式的文字
+func timeOfDayText(v int64, unit time.Duration) string {
+	t := time.Unix(0, 0).UTC().Add(time.Duration(v) * unit)
+	if t.Nanosecond() == 0 {
+		return t.Format("15:04:05")
+	}
+	return t.Format("15:04:05.999999999")
+}
+
+// decimalValue 將二補數的大端序整數依小數位數轉為浮點數
+func decimalValue(b []byte, scale int) float64 {
+	n := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+	}
+	f, _ := new(big.Float).SetInt(n).Float64()
+	return f / math.Pow10(scale)
+}
+
+// binaryText 將二進位值轉為文字：有效的 UTF-8 直接使用，其餘以十六進位表示
+func binaryText(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	return "0x" + hex.EncodeToString(b)
+}
+
+// float16Value 將半精度浮點數的位元轉為 float64
+func float16Value(bits uint16) float64 {
+	sign := 1.0
+	if bits&0x8000 != 0 {
+		sign = -1
+	}
+	exp := int(bits>>10) & 0x1f
+	frac := float64(bits & 0x3ff)
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(frac, -24)
+	case 0x1f:
+		if frac == 0 {
+			return sign * math.Inf(1)
+		}
+		return math.NaN()
+	}
+	return sign * math.Ldexp(1+frac/1024, exp-15)
+}
+
+// ----- 壓縮 -----
+
+var (
+	zstdDecoder = sync.OnceValue(func() *zstd.Decoder {
+		d, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(columnarMaxBuffer))
+		return d
+	})
+	zstdEncoder = sync.OnceValue(func() *zstd.Encoder {
+		e, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		return e
+	})
+)
+
+// errCorruptBlock 壓縮資料無法解壓縮
+var errCorruptBlock = errors.New("corrupt compressed data")
+
+// decompress 解壓縮一個頁面或緩衝區；size 為解壓縮後的位元組數，未知時為 -1
+func decompress(codec string, src []byte, size int) ([]byte, error) {
+	if size > columnarMaxBuffer {
+		return nil, errCorruptBlock
+	}
+	var out []byte
+	var err error
+	switch codec {
+	case CompressionNone:
+		return src, nil
+	case CompressionSnappy:
+		// 解壓縮前先比對標頭記錄的長度，損毀的長度不會造成過大的配置
+		if n, e := snappy.DecodedLen(src); e != nil || n > columnarMaxBuffer || size >= 0 && n != size {
+			return nil, errCorruptBlock
+		}
+		out, err = snappy.Decode(nil, src)
+	case CompressionGzip:
+		var r *gzip.Reader
+		if r, err = gzip.NewReader(bytes.NewReader(src)); err == nil {
+			out, err = io.ReadAll(io.LimitReader(r, columnarMaxBuffer))
+		}
+	case CompressionZstd:
+		var h zstd.Header
+		if e := h.Decode(src); e != nil || size >= 0 && h.HasFCS && h.FrameContentSize > uint64(size) {
+			return nil, errCorruptBlock
+		}
+		out, err = zstdDecoder().DecodeAll(src, nil)
+	case CompressionLZ4:
+		out, err = lz4DecodeFrame(src)
+	default:
+		return nil, errors.New("unknown compression " + codec)
+	}
+	if err == nil && size >= 0 && len(out) != size {
+		err = errCorruptBlock
+	}
+	return out, err
+}
+
+// compress 壓縮一個頁面或緩衝區
+func compress(codec string, src []byte) []byte {
+	switch codec {
+	case CompressionSnappy:
+		return snappy.Encode(nil, src)
+	case CompressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write(src)
+		w.Close()
+		return buf.Bytes()
+	case CompressionZstd:
+		return zstdEncoder().EncodeAll(src, nil)
+	}
+	return src
+}
+
+// lz4DecodeBlock 解壓縮一個 LZ4 區塊並附加到 dst；dst 中已有的內容可以被參照（連續的區塊）
+func lz4DecodeBlock(dst []byte, src []byte) ([]byte, error) {
+	i := 0
+	length := func(n int) (int, bool) {
+		if n != 15 {
+			return n, true
+		}
+		for i < len(src) {
+			b := src[i]
+			i++
+			n += int(b)
+			if b != 255 {
+				return n, true
+			}
+		}
+		return 0, false
+	}
+	for i < len(src) {
+		token := src[i]
+		i++
+		lit, ok := length(int(token >> 4))
+		if !ok || lit > len(src)-i {
+			return nil, errCorruptBlock
+		}
+		dst = append(dst, src[i:i+lit]...)
+		i += lit
+		if i == len(src) {
+			break
+		}
+		if i+2 > len(src) {
+			return nil, errCorruptBlock
+		}
+		offset := int(binary.LittleEndian.Uint16(src[i:]))
+		i += 2
+		match, ok := length(int(token & 15))
+		if !ok || offset == 0 || offset > len(dst) || len(dst)+match > columnarMaxBuffer {
+			return nil, errCorruptBlock
+		}
+		start := len(dst) - offset
+		for k := range match + 4 {
+			dst = append(dst, dst[start+k])
+		}
+	}
+	return dst, nil
+}
+
+// lz4DecodeFrame 解壓縮 LZ4 frame 格式的資料，可以包含多個 frame
+func lz4DecodeFrame(src []byte) ([]byte, error) {
+	var out []byte
+	for len(src) > 0 {
+		if len(src) < 7 {
+			return nil, errCorruptBlock
+		}
+		magic := binary.LittleEndian.Uint32(src)
+		if magic&0xfffffff0 == 0x184d2a50 {
+			// 可略過的 frame
+			n := int(binary.LittleEndian.Uint32(src[4:]))
+			if n > len(src)-8 {
+				return nil, errCorruptBlock
+			}
+			src = src[8+n:]
+			continue
+		}
+		flags := src[4]
+		if magic != 0x184d2204 || flags>>6 != 1 {
+			return nil, errCorruptBlock
+		}
+		pos := 7
+		if flags&0x08 != 0 {
+			pos += 8
+		}
+		if flags&0x01 != 0 {
+			pos += 4
+		}
+		for {
+			if pos+4 > len(src) {
+				return nil, errCorruptBlock
+			}
+			size := binary.LittleEndian.Uint32(src[pos:])
+			pos += 4
+			if size == 0 {
+				break
+			}
+			raw := size&0x80000000 != 0
+			n := int(size & 0x7fffffff)
+			if n > len(src)-pos {
+				return nil, errCorruptBlock
+			}
+			var err error
+			if raw {
+				out = append(out, src[pos:pos+n]...)
+			} else if out, err = lz4DecodeBlock(out, src[pos:pos+n]); err != nil {
+				return nil, err
+			}
+			pos += n
+			if flags&0x10 != 0 {
+				pos += 4
+			}
+		}
+		if flags&0x04 != 0 {
+			pos += 4
+		}
+		if pos > len(src) {
+			return nil, errCorruptBlock
+		}
+		src = src[pos:]
+	}
+	return out, nil
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testdata 中的 .parquet 與 .arrow 由 testdata/columnargen 以 arrow-go 產生，每個檔案都是同樣的 10 列、分成 3 組
+
+var columnarFixtureNames = []string{"id", "small", "score", "name", "note", "flag", "day", "ts"}
+
+func columnarFixtureColumns() [][]any {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	return [][]any{
+		{1001, 1008, 995, 1200, -40, 1201, 1202, 1300, 7, 1000000},
+		{3, nil, -7, 12, 12, nil, 0, 5, 6, 2147483647},
+		{1.5, -0.25, nil, 3.125, 100.0, nil, 2.5, -8.0, 0.0, 1e10},
+		{"alpha", "beta", "alpha", nil, "gamma", "beta", "alpha", "資料", nil, "gamma"},
+		// 空白文字視為缺失值
+		{"n1", nil, "longer note", "n4", nil, "n6", "n7", "note eight", "n9", "最後"},
+		{true, false, nil, true, true, false, nil, false, true, true},
+		{day(2024, 1, 1), day(1970, 1, 1), day(1969, 12, 31), nil, day(2000, 2, 29), day(2024, 12, 31), day(1999, 6, 15), day(2010, 10, 10), nil, day(2038, 1, 19)},
+		{
+			time.Date(2024, 1, 1, 12, 30, 45, 123456000, time.UTC), nil, day(1970, 1, 1), time.Date(1960, 6, 1, 8, 0, 0, 0, time.UTC),
+			time.Date(2024, 2, 29, 23, 59, 59, 999999000, time.UTC), time.Date(2001, 9, 9, 1, 46, 40, 0, time.UTC), nil,
+			time.Date(2024, 7, 4, 0, 0, 0, 500000000, time.UTC), time.Date(1999, 12, 31, 23, 59, 59, 0, time.UTC), time.Date(2020, 5, 5, 5, 5, 5, 5000, time.UTC),
+		},
+	}
+}
+
+// checkColumnarFixture 檢查匯入後的欄名、值與型別；nameType 為 name 欄的型別
+func checkColumnarFixture(t *testing.T, s *DataTableService, id string, nameType string) {
+	t.Helper()
+	dt := s.getTableByID(id)
+	names, columns := snapshotColumns(dt)
+	if !reflect.DeepEqual(names, columnarFixtureNames) {
+		t.Fatalf("names = %q, want %q", names, columnarFixtureNames)
+	}
+	for j, want := range columnarFixtureColumns() {
+		if !reflect.DeepEqual(columns[j], want) {
+			t.Errorf("%s = %#v, want %#v", names[j], columns[j], want)
+		}
+	}
+	types := make([]string, len(names))
+	for j := range names {
+		types[j] = s.columnTypeOf(dt, j)
+	}
+	want := []string{DataTypeInteger, DataTypeInteger, DataTypeNumeric, nameType, DataTypeString, DataTypeBoolean, DataTypeDateTime, DataTypeDateTime}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("types = %q, want %q", types, want)
+	}
+}
+
+func TestOpenParquetFixtures(t *testing.T) {
+	ConfigureInsyra()
+	files := []string{
+		// 資料頁面 v1 與 RLE_DICTIONARY，各種壓縮方式
+		"v1_dict_none.parquet",
+		"v1_dict_snappy.parquet",
+		"v1_dict_gzip.parquet",
+		"v1_dict_zstd.parquet",
+		"v1_dict_lz4raw.parquet",
+		// 舊版的 PLAIN_DICTIONARY
+		"v1_plain_dictionary.parquet",
+		// 資料頁面 v2
+		"v2_dict_zstd.parquet",
+		// 資料頁面 v2 與 DELTA_BINARY_PACKED、DELTA_BYTE_ARRAY、DELTA_LENGTH_BYTE_ARRAY、BYTE_STREAM_SPLIT
+		"v2_delta_snappy.parquet",
+	}
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			path := filepath.Join("testdata", file)
+			s := NewDataTableService()
+			info, err := s.DescribeParquetFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.RowCount != 10 || info.Groups != 3 {
+				t.Errorf("rows = %d, groups = %d, want 10 rows in 3 groups", info.RowCount, info.Groups)
+			}
+			id, err := s.OpenParquetFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkColumnarFixture(t, s, id, DataTypeString)
+		})
+	}
+}
+
+func TestOpenArrowFixtures(t *testing.T) {
+	ConfigureInsyra()
+	// name 欄以字典編碼，各 record batch 共用同一個字典
+	for _, file := range []string{"batches.arrow", "batches_lz4.arrow", "batches_zstd.arrow", "batches.arrows"} {
+		t.Run(file, func(t *testing.T) {
+			path := filepath.Join("testdata", file)
+			s := NewDataTableService()
+			info, err := s.DescribeArrowFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.RowCount != 10 || info.Groups != 3 {
+				t.Errorf("rows = %d, groups = %d, want 10 rows in 3 batches", info.RowCount, info.Groups)
+			}
+			id, err := s.OpenArrowFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkColumnarFixture(t, s, id, DataTypeCategorical)
+		})
+	}
+}
+
+// 只讀取選取的欄時，各組的值依選取的順序附加到對應的欄
+func TestOpenColumnarSelectedColumns(t *testing.T) {
+	ConfigureInsyra()
+	s := NewDataTableService()
+	options := &ColumnarImportOptions{Columns: []string{"ts", "id"}}
+	want := columnarFixtureColumns()
+	for _, open := range []func() (string, error){
+		func() (string, error) {
+			return s.OpenParquetFileWithOptions(filepath.Join("testdata", "v2_delta_snappy.parquet"), options)
+		},
+		func() (string, error) {
+			return s.OpenArrowFileWithOptions(filepath.Join("testdata", "batches_zstd.arrow"), options)
+		},
+	} {
+		id, err := open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		names, columns := snapshotColumns(s.getTableByID(id))
+		if !reflect.DeepEqual(names, options.Columns) {
+			t.Fatalf("names = %q, want %q", names, options.Columns)
+		}
+		if !reflect.DeepEqual(columns, [][]any{want[7], want[0]}) {
+			t.Errorf("columns = %v, want ts and id", columns)
+		}
+	}
+}
+
+// 讀取時每個 row group 或 record batch 各交給 appendGroup 一次，只包含該組的列
+func TestColumnarReadersAppendEachGroup(t *testing.T) {
+	pr, err := openParquet(filepath.Join("testdata", "v1_dict_snappy.parquet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.file.Close()
+	ar, err := openArrow(filepath.Join("testdata", "batches.arrows"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ar.file.Close()
+	for name, read := range map[string]func([]int, func([][]any)) error{"parquet": pr.read, "arrow": ar.read} {
+		var sizes []int
+		err := read([]int{0, 3}, func(group [][]any) {
+			if len(group) != 2 || len(group[0]) != len(group[1]) {
+				t.Errorf("%s group = %v", name, group)
+			}
+			sizes = append(sizes, len(group[0]))
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []int{4, 4, 2}; !reflect.DeepEqual(sizes, want) {
+			t.Errorf("%s group sizes = %v, want %v", name, sizes, want)
+		}
+	}
+}
+
+func TestColumnarReadersRejectMalformedFiles(t *testing.T) {
+	allColumns := func(n int) []int {
+		selected := make([]int, n)
+		for j := range selected {
+			selected[j] = j
+		}
+		return selected
+	}
+	parseParquet := func(path string) error {
+		r, err := openParquet(path)
+		if err != nil {
+			return err
+		}
+		defer r.file.Close()
+		return r.read(allColumns(len(r.columns)), func([][]any) {})
+	}
+	parseArrow := func(path string) error {
+		r, err := openArrow(path)
+		if err != nil {
+			return err
+		}
+		defer r.file.Close()
+		return r.read(allColumns(len(r.fields)), func([][]any) {})
+	}
+	tests := []struct {
+		file  string
+		parse func(path string) error
+	}{
+		{"v1_dict_none.parquet", parseParquet},
+		{"v2_dict_zstd.parquet", parseParquet},
+		{"v2_delta_snappy.parquet", parseParquet},
+		{"batches.arrow", parseArrow},
+		{"batches_lz4.arrow", parseArrow},
+		{"batches_zstd.arrow", parseArrow},
+		{"batches.arrows", parseArrow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			raw, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// 讀取器從檔案讀取，每個損毀版本都寫入同一個暫存檔
+			path := filepath.Join(t.TempDir(), tt.file)
+			checkMalformed(t, raw, func(data []byte) error {
+				if err := os.WriteFile(path, data, 0o644); err != nil {
+					return err
+				}
+				return tt.parse(path)
+			})
+		})
+	}
+}
+
+// 損毀的 null_count 與頁面值數要在配置或索引前被拒絕
+func TestColumnarReadersRejectCorruptCounts(t *testing.T) {
+	int32s := &arrowType{id: arrowInt, name: "int32", dataType: DataTypeInteger, width: 32, signed: true}
+	data := make([]byte, 16)
+	for _, nulls := range []int64{-1, 5} {
+		if _, err := int32s.decode(4, nulls, [][]byte{nil, data}); err == nil {
+			t.Errorf("null count %d: want an error", nulls)
+		}
+	}
+	if _, err := int32s.decode(4, 1, [][]byte{nil, data}); err == nil {
+		t.Error("missing validity bitmap: want an error")
+	}
+	if _, err := int32s.decode(64, 0, [][]byte{nil, data}); err == nil {
+		t.Error("short data buffer: want an error")
+	}
+	for _, n := range []int{-1, columnarMaxBuffer + 1} {
+		if _, err := decodeHybrid([]byte{0x02, 0x01}, 1, n); err == nil {
+			t.Errorf("level count %d: want an error", n)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/HazelnutParadise/insyra"
+	"github.com/HazelnutParadise/insyra/stats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// ===== 相關與迴歸 =====
+//
+// 相關矩陣以成對刪除處理缺失值（每一對欄位各自使用兩欄皆非缺失的列）；
+// 迴歸以完整列刪除處理缺失值。結果可另外以 Create*Tables 寫成新的標籤頁。
+
+// 相關係數的計算方法
+const (
+	CorrelationPearson  = "pearson"
+	CorrelationSpearman = "spearman"
+	CorrelationKendall  = "kendall"
+)
+
+// correlationMethods 方法名稱對應 insyra 的計算方法
+var correlationMethods = map[string]stats.CorrelationMethod{
+	CorrelationPearson:  stats.PearsonCorrelation,
+	CorrelationSpearman: stats.SpearmanCorrelation,
+	CorrelationKendall:  stats.KendallCorrelation,
+}
+
+// CorrelationResult 相關矩陣；無法計算的係數（例如某欄變異數為 0）為 null
+type CorrelationResult struct {
+	Method       string       `json:"method"`
+	Title        string       `json:"title"`
+	Columns      []string     `json:"columns"`
+	Coefficients [][]*float64 `json:"coefficients"`
+	PValues      [][]*float64 `json:"pValues"`
+	N            [][]int      `json:"n"` // 每一對欄位使用的列數
+}
+
+// RegressionCoefficient 迴歸係數
+type RegressionCoefficient struct {
+	Term     string   `json:"term"` // "(Intercept)" 或自變數的欄名
+	Estimate *float64 `json:"estimate"`
+	StdError *float64 `json:"stdError"`
+	T        *float64 `json:"t"`
+	PValue   *float64 `json:"pValue"`
+	CILower  *float64 `json:"ciLower"`
+	CIUpper  *float64 `json:"ciUpper"`
+}
+
+// RegressionResult 最小平方法線性迴歸的結果
+type RegressionResult struct {
+	Title            string                  `json:"title"`
+	Dependent        string                  `json:"dependent"`
+	Independents     []string                `json:"independents"`
+	Coefficients     []RegressionCoefficient `json:"coefficients"`
+	RSquared         *float64                `json:"rSquared"`
+	AdjustedRSquared *float64                `json:"adjustedRSquared"`
+	F                *float64                `json:"f"`
+	FPValue          *float64                `json:"fPValue"`
+	DFModel          int                     `json:"dfModel"`
+	DFResidual       int                     `json:"dfResidual"`
+	ResidualStdError *float64                `json:"residualStdError"`
+	ConfidenceLevel  float64                 `json:"confidenceLevel"`
+	N                int                     `json:"n"`
+	Excluded         int                     `json:"excluded"`
+	// 以下與資料表的列對應，被排除的列為 null
+	Fitted    []*float64 `json:"fitted"`
+	Residuals []*float64 `json:"residuals"`
+}
+
+// CorrelationMatrix 計算所選欄位兩兩之間的相關係數；method 為 "pearson"、"spearman" 或 "kendall"
+func (s *StatisticsService) CorrelationMatrix(tableID string, cols []int, method string) (CorrelationResult, error) {
+	s.data.lock()
+	defer s.data.unlock()
+	dt, _, err := s.prepareTest(tableID, 0)
+	if err != nil {
+		return CorrelationResult{}, err
+	}
+	if method == "" {
+		method = CorrelationPearson
+	}
+	m, ok := correlationMethods[method]
+	if !ok {
+		return CorrelationResult{}, invalidArgument("unknown correlation method %q", method).WithDetail("method", method)
+	}
+	if len(cols) < 2 {
+		return CorrelationResult{}, invalidArgument("correlation needs at least 2 columns, got %d", len(cols))
+	}
+
+	k := len(cols)
+	result := CorrelationResult{
+		Method:       method,
+		Columns:      make([]string, k),
+		Coefficients: make([][]*float64, k),
+		PValues:      make([][]*float64, k),
+		N:            make([][]int, k),
+	}
+	for i := range k {
+		result.Coefficients[i] = make([]*float64, k)
+		result.PValues[i] = make([]*float64, k)
+		result.N[i] = make([]int, k)
+	}
+	for i := range k {
+		for j := i; j < k; j++ {
+			sample, err := numericSample(dt, []int{cols[i], cols[j]})
+			if err != nil {
+				return CorrelationResult{}, err
+			}
+			result.Columns[i], result.Columns[j] = sample.names[0], sample.names[1]
+			result.N[i][j], result.N[j][i] = sample.n, sample.n
+			if i == j {
+				one, zero := 1.0, 0.0
+				result.Coefficients[i][i], result.PValues[i][i] = &one, &zero
+				continue
+			}
+			var r *stats.CorrelationResult
+			captureInsyra(func() {
+				r = stats.Correlation(insyra.NewDataList(sample.columns[0]), insyra.NewDataList(sample.columns[1]), m)
+			})
+			if r == nil {
+				continue
+			}
+			result.Coefficients[i][j], result.Coefficients[j][i] = finite(r.Statistic), finite(r.Statistic)
+			result.PValues[i][j], result.PValues[j][i] = finite(r.PValue), finite(r.PValue)
+		}
+	}
+	result.Title = fmt.Sprintf("%s correlation (%s)", strings.ToUpper(method[:1])+method[1:], strings.Join(result.Columns, ", "))
+	return result, nil
+}
+
+// LinearRegression 以最小平方法估計 dependentCol 對 independentCols 的線性迴歸（含截距）；
+// confidenceLevel 為 0 時使用 0.95
+func (s *StatisticsService) LinearRegression(tableID string, dependentCol int, independentCols []int, confidenceLevel float64) (RegressionResult, error) {
+	s.data.lock()
+	defer s.data.unlock()
+	dt, cl, err := s.prepareTest(tableID, confidenceLevel)
+	if err != nil {
+		return RegressionResult{}, err
+	}
+	if len(independentCols) == 0 {
+		return RegressionResult{}, invalidArgument("regression needs at least 1 independent column")
+	}
+	sample, err := numericSample(dt, append([]int{dependentCol}, independentCols...))
+	if err != nil {
+		return RegressionResult{}, err
+	}
+
+	n, k := sample.n, len(independentCols)
+	dfResidual := n - k - 1
+	if dfResidual <= 0 {
+		return RegressionResult{}, invalidArgument("regression with %d independent columns needs more than %d complete rows, got %d", k, k+1, n).
+			WithDetail("n", n)
+	}
+
+	// 設計矩陣：第一欄為截距
+	x := mat.NewDense(n, k+1, nil)
+	y := mat.NewVecDense(n, sample.columns[0])
+	for i := range n {
+		x.Set(i, 0, 1)
+		for j := range k {
+			x.Set(i, j+1, sample.columns[j+1][i])
+		}
+	}
+	var xtx, xtxInv mat.Dense
+	xtx.Mul(x.T(), x)
+	if err := xtxInv.Inverse(&xtx); err != nil {
+		return RegressionResult{}, newError(ErrCodeInvalidArgument, "errors.regression_singular",
+			"independent columns are collinear or constant").
+			WithDetail("columns", sample.names[1:])
+	}
+	var xty, beta, fitted mat.VecDense
+	xty.MulVec(x.T(), y)
+	beta.MulVec(&xtxInv, &xty)
+	fitted.MulVec(x, &beta)
+
+	mean := mat.Sum(y) / float64(n)
+	var sse, sst float64
+	for i := range n {
+		e := y.AtVec(i) - fitted.AtVec(i)
+		sse += e * e
+		d := y.AtVec(i) - mean
+		sst += d * d
+	}
+	mse := sse / float64(dfResidual)
+	rSquared := 1 - sse/sst
+	f := ((sst - sse) / float64(k)) / mse
+
+	tDist := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: float64(dfResidual)}
+	tCritical := tDist.Quantile(1 - (1-cl)/2)
+	fDist := distuv.F{D1: float64(k), D2: float64(dfResidual)}
+
+	result := RegressionResult{
+		Title:            fmt.Sprintf("Linear regression (%s ~ %s)", sample.names[0], strings.Join(sample.names[1:], " + ")),
+		Dependent:        sample.names[0],
+		Independents:     sample.names[1:],
+		Coefficients:     make([]RegressionCoefficient, k+1),
+		RSquared:         finite(rSquared),
+		AdjustedRSquared: finite(1 - (1-rSquared)*float64(n-1)/float64(dfResidual)),
+		F:                finite(f),
+		FPValue:          finite(1 - fDist.CDF(f)),
+		DFModel:          k,
+		DFResidual:       dfResidual,
+		ResidualStdError: finite(math.Sqrt(mse)),
+		ConfidenceLevel:  cl,
+		N:                n,
+		Excluded:         sample.excluded,
+	}
+	for j := range k + 1 {
+		term := "(Intercept)"
+		if j > 0 {
+			term = sample.names[j]
+		}
+		b := beta.AtVec(j)
+		se := math.Sqrt(mse * xtxInv.At(j, j))
+		t := b / se
+		result.Coefficients[j] = RegressionCoefficient{
+			Term:     term,
+			Estimate: finite(b),
+			StdError: finite(se),
+			T:        finite(t),
+			PValue:   finite(2 * tDist.CDF(-math.Abs(t))),
+			CILower:  finite(b - tCritical*se),
+			CIUpper:  finite(b + tCritical*se),
+		}
+	}
+
+	rowCount, _ := dt.Size()
+	result.Fitted = make([]*float64, rowCount)
+	result.Residuals = make([]*float64, rowCount)
+	for i, row := range sample.rows {
+		result.Fitted[row] = finite(fitted.AtVec(i))
+		result.Residuals[row] = finite(y.AtVec(i) - fitted.AtVec(i))
+	}
+	return result, nil
+}
+
+// CreateCorrelationTables 將相關矩陣寫成係數、p 值與列數三個新的資料表，回傳新資料表的ID
+func (s *StatisticsService) CreateCorrelationTables(result CorrelationResult) ([]string, error) {
+	s.data.lock()
+	defer s.data.unlock()
+	if len(result.Columns) == 0 {
+		return nil, invalidArgument("correlation result is empty")
+	}
+	matrix := func(suffix string, cell func(i, j int) any) string {
+		columns := []*insyra.DataList{insyra.NewDataList(toAnySlice(result.Columns)...).SetName("Variable")}
+		for j, name := range result.Columns {
+			values := make([]any, len(result.Columns))
+			for i := range values {
+				values[i] = cell(i, j)
+			}
+			columns = append(columns, insyra.NewDataList(values...).SetName(name))
+		}
+		dt := insyra.NewDataTable(columns...)
+		dt.SetName(result.Title + " " + suffix)
+		return s.data.appendTable(dt)
+	}
+	return []string{
+		matrix("r", func(i, j int) any { return floatOrNil(result.Coefficients[i][j]) }),
+		matrix("p", func(i, j int) any { return floatOrNil(result.PValues[i][j]) }),
+		matrix("n", func(i, j int) any { return result.N[i][j] }),
+	}, nil
+}
+
+// CreateRegressionTables 將迴歸結果寫成係數、模型摘要與殘差三個新的資料表，回傳新資料表的ID
+func (s *StatisticsService) CreateRegressionTables(result RegressionResult) ([]string, error) {
+	s.data.lock()
+	defer s.data.unlock()
+	if len(result.Coefficients) == 0 {
+		return nil, invalidArgument("regression result is empty")
+	}
+
+	n := len(result.Coefficients)
+	terms := make([]any, n)
+	columns := make([][]any, 6)
+	for i := range columns {
+		columns[i] = make([]any, n)
+	}
+	for i, c := range result.Coefficients {
+		terms[i] = c.Term
+		for j, v := range []*float64{c.Estimate, c.StdError, c.T, c.PValue, c.CILower, c.CIUpper} {
+			columns[j][i] = floatOrNil(v)
+		}
+	}
+	percent := result.ConfidenceLevel * 100
+	coefficients := insyra.NewDataTable(
+		insyra.NewDataList(terms...).SetName("Term"),
+		insyra.NewDataList(columns[0]...).SetName("Estimate"),
+		insyra.NewDataList(columns[1]...).SetName("Std. Error"),
+		insyra.NewDataList(columns[2]...).SetName("t"),
+		insyra.NewDataList(columns[3]...).SetName("p"),
+		insyra.NewDataList(columns[4]...).SetName(fmt.Sprintf("CI%g Lower", percent)),
+		insyra.NewDataList(columns[5]...).SetName(fmt.Sprintf("CI%g Upper", percent)),
+	)
+	coefficients.SetName(result.Title + " coefficients")
+
+	summary := insyra.NewDataTable(
+		insyra.NewDataList("dependent", "n", "excluded", "r_squared", "adjusted_r_squared", "f", "df_model", "df_residual", "p", "residual_std_error").SetName("Item"),
+		insyra.NewDataList(result.Dependent, result.N, result.Excluded,
+			floatOrNil(result.RSquared), floatOrNil(result.AdjustedRSquared), floatOrNil(result.F),
+			result.DFModel, result.DFResidual, floatOrNil(result.FPValue), floatOrNil(result.ResidualStdError),
+		).SetName("Value"),
+	)
+	summary.SetName(result.Title + " summary")
+
+	rows := make([]any, len(result.Residuals))
+	fitted := make([]any, len(result.Residuals))
+	residuals := make([]any, len(result.Residuals))
+	for i := range result.Residuals {
+		rows[i] = i + 1
+		if i < len(result.Fitted) {
+			fitted[i] = floatOrNil(result.Fitted[i])
+		}
+		residuals[i] = floatOrNil(result.Residuals[i])
+	}
+	residualTable := insyra.NewDataTable(
+		insyra.NewDataList(rows...).SetName("Row"),
+		insyra.NewDataList(fitted...).SetName("Fitted"),
+		insyra.NewDataList(residuals...).SetName("Residual"),
+	)
+	residualTable.SetName(result.Title + " residuals")
+
+	return []string{
+		s.data.appendTable(coefficients),
+		s.data.appendTable(summary),
+		s.data.appendTable(residualTable),
+	}, nil
+}
+
+// floatOrNil 將可為 null 的數值轉為儲存格的值
+func floatOrNil(f *float64) any {
+	if f == nil {
+		return nil
+	}
+	return *f
+}
+
+// toAnySlice 將字串切片轉為儲存格的值
+func toAnySlice(values []string) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
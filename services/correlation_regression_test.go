@@ -0,0 +1,131 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+// 參考值由 testdata/stats_reference.py 計算，迴歸係數以分數精確求解
+
+// newRegressionTestTable 第 9 列的 y 為缺失值
+func newRegressionTestTable(t *testing.T) (*StatisticsService, string) {
+	return newStatisticsTestTable(t,
+		insyra.NewDataList(1, 2, 3, 4, 5, 6, 7, 8, 9).SetName("x1"),
+		insyra.NewDataList(2, 1, 4, 3, 6, 5, 8, 9, 10).SetName("x2"),
+		insyra.NewDataList(3.1, 3.9, 6.2, 6.8, 9.9, 10.1, 13.8, 15.2, nil).SetName("y"),
+	)
+}
+
+func TestLinearRegression(t *testing.T) {
+	s, id := newRegressionTestTable(t)
+	r, err := s.LinearRegression(id, 2, []int{0, 1}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.N != 8 || r.Excluded != 1 || r.DFModel != 2 || r.DFResidual != 5 {
+		t.Errorf("n = %d, excluded = %d, df = %d/%d; want 8, 1, 2/5", r.N, r.Excluded, r.DFModel, r.DFResidual)
+	}
+	if r.Dependent != "y" || !reflect.DeepEqual(r.Independents, []string{"x1", "x2"}) {
+		t.Errorf("variables = %q ~ %q", r.Dependent, r.Independents)
+	}
+	checkClose(t, "r2", r.RSquared, 0.9961282176163934)
+	checkClose(t, "adjusted r2", r.AdjustedRSquared, 0.9945795046629508)
+	checkClose(t, "F", r.F, 643.1974468878121)
+	checkClose(t, "F p", r.FPValue, 9.327759686109264e-07)
+	checkClose(t, "residual std error", r.ResidualStdError, 0.3243696753312643)
+
+	want := []struct {
+		term                       string
+		estimate, se, t, p, lo, hi float64
+	}{
+		{"(Intercept)", 0.6901960784313725, 0.252892532182201, 2.7292070369800734, 0.0413192637292469, 0.04011512883573498, 1.34027702802701},
+		{"x1", 1.0637254901960784, 0.13814209067643074, 7.700227244190442, 0.0005893129881913985, 0.7086199411664211, 1.4188310392257357},
+		{"x2", 0.6627450980392157, 0.12017224598194245, 5.514959736533528, 0.0026828975674346007, 0.3538325053704155, 0.9716576907080159},
+	}
+	if len(r.Coefficients) != len(want) {
+		t.Fatalf("%d coefficients, want %d", len(r.Coefficients), len(want))
+	}
+	for i, w := range want {
+		c := r.Coefficients[i]
+		if c.Term != w.term {
+			t.Errorf("term %d = %q, want %q", i, c.Term, w.term)
+		}
+		checkClose(t, w.term+" estimate", c.Estimate, w.estimate)
+		checkClose(t, w.term+" std error", c.StdError, w.se)
+		checkClose(t, w.term+" t", c.T, w.t)
+		checkClose(t, w.term+" p", c.PValue, w.p)
+		checkClose(t, w.term+" ci lower", c.CILower, w.lo)
+		checkClose(t, w.term+" ci upper", c.CIUpper, w.hi)
+	}
+
+	// 被排除的列沒有配適值與殘差
+	if len(r.Fitted) != 9 || r.Fitted[8] != nil || r.Residuals[8] != nil {
+		t.Errorf("fitted = %v, residuals = %v; want 9 rows with the last one null", r.Fitted, r.Residuals)
+	}
+	checkClose(t, "fitted[0]", r.Fitted[0], 3.0794117647058825)
+	checkClose(t, "residual[0]", r.Residuals[0], 0.020588235294117647)
+}
+
+func TestLinearRegressionErrors(t *testing.T) {
+	s, id := newRegressionTestTable(t)
+	if _, err := s.LinearRegression(id, 2, nil, 0); err == nil {
+		t.Error("no independent columns: want an error")
+	}
+	// b 為 a 的兩倍，兩欄完全共線
+	s2, id2 := newStatisticsTestTable(t,
+		insyra.NewDataList(1, 2, 3, 4, 5).SetName("a"),
+		insyra.NewDataList(2, 4, 6, 8, 10).SetName("b"),
+		insyra.NewDataList(1.1, 2.3, 2.9, 4.2, 5.1).SetName("y"),
+	)
+	if _, err := s2.LinearRegression(id2, 2, []int{0, 1}, 0); err == nil {
+		t.Error("collinear columns: want an error")
+	}
+	// 3 列不足以估計截距與兩個斜率後仍有殘差自由度
+	s3, id3 := newStatisticsTestTable(t,
+		insyra.NewDataList(1, 2, 3).SetName("a"),
+		insyra.NewDataList(3, 1, 2).SetName("b"),
+		insyra.NewDataList(1.0, 2.5, 2.0).SetName("y"),
+	)
+	if _, err := s3.LinearRegression(id3, 2, []int{0, 1}, 0); err == nil {
+		t.Error("no residual degrees of freedom: want an error")
+	}
+}
+
+// 相關矩陣以成對刪除處理缺失值
+func TestPearsonCorrelationMatrix(t *testing.T) {
+	s, id := newRegressionTestTable(t)
+	r, err := s.CorrelationMatrix(id, []int{0, 1, 2}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Method != CorrelationPearson || !reflect.DeepEqual(r.Columns, []string{"x1", "x2", "y"}) {
+		t.Errorf("method = %q, columns = %q", r.Method, r.Columns)
+	}
+	if want := [][]int{{9, 9, 8}, {9, 9, 8}, {8, 8, 8}}; !reflect.DeepEqual(r.N, want) {
+		t.Errorf("n = %v, want %v", r.N, want)
+	}
+	pairs := []struct {
+		i, j int
+		r, p float64
+	}{
+		{0, 1, 0.9526279441628824, 7.272626679468057e-05},
+		{0, 2, 0.9861928488089114, 6.512445084204926e-06},
+		{1, 2, 0.9747891629292122, 3.930553249797966e-05},
+	}
+	for _, w := range pairs {
+		for _, ij := range [][2]int{{w.i, w.j}, {w.j, w.i}} {
+			checkClose(t, r.Columns[ij[0]]+"~"+r.Columns[ij[1]]+" r", r.Coefficients[ij[0]][ij[1]], w.r)
+			checkClose(t, r.Columns[ij[0]]+"~"+r.Columns[ij[1]]+" p", r.PValues[ij[0]][ij[1]], w.p)
+		}
+	}
+	checkClose(t, "diagonal", r.Coefficients[1][1], 1)
+
+	if _, err := s.CorrelationMatrix(id, []int{0, 1}, "distance"); err == nil {
+		t.Error("unknown method: want an error")
+	}
+	if _, err := s.CorrelationMatrix(id, []int{0}, ""); err == nil {
+		t.Error("one column: want an error")
+	}
+}
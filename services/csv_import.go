@@ -0,0 +1,559 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/HazelnutParadise/insyra"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// ===== CSV 匯入 =====
+
+// 支援的 CSV 文字編碼
+const (
+	EncodingUTF8    = "utf-8"
+	EncodingUTF8BOM = "utf-8-bom"
+	EncodingUTF16LE = "utf-16le"
+	EncodingUTF16BE = "utf-16be"
+	EncodingBig5    = "big5"
+)
+
+const (
+	// csvSniffBytes 偵測格式時取樣的位元組數
+	csvSniffBytes = 64 * 1024
+	// csvSniffRows 偵測格式時最多分析的列數
+	csvSniffRows = 50
+	// defaultPreviewRows 預覽時預設回傳的列數
+	defaultPreviewRows = 20
+)
+
+// CSVImportOptions CSV 匯入設定，偵測結果也以此結構回傳
+type CSVImportOptions struct {
+	Encoding         string `json:"encoding"`
+	Delimiter        string `json:"delimiter"`
+	Quote            string `json:"quote"` // 空字串表示不處理引號
+	HasHeader        bool   `json:"hasHeader"`
+	DecimalSeparator string `json:"decimalSeparator"`
+}
+
+// CSVPreview CSV 預覽結果
+type CSVPreview struct {
+	Options   CSVImportOptions `json:"options"`
+	Header    []string         `json:"header"`
+	Rows      [][]any          `json:"rows"`
+	Truncated bool             `json:"truncated"`
+}
+
+var (
+	csvDelimiterCandidates = []rune{',', ';', '\t', '|'}
+	decimalCommaPattern    = regexp.MustCompile(`^[-+]?\d+,\d+$`)
+	decimalPointPattern    = regexp.MustCompile(`^[-+]?\d+\.\d+$`)
+)
+
+// DetectCSVOptions 偵測 CSV 檔案的編碼、分隔符號、引號、標題列與小數點符號
+func (s *DataTableService) DetectCSVOptions(filePath string) (CSVImportOptions, error) {
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		return CSVImportOptions{}, fmt.Errorf("read csv file: %w", err)
+	}
+	return detectCSVOptions(raw)
+}
+
+// PreviewCSVFile 解析 CSV 檔案的前 maxRows 列；options 為 nil 時自動偵測格式
+func (s *DataTableService) PreviewCSVFile(filePath string, options *CSVImportOptions, maxRows int) (*CSVPreview, error) {
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("read csv file: %w", err)
+	}
+	opts, err := resolveCSVOptions(raw, options)
+	if err != nil {
+		return nil, err
+	}
+	if maxRows <= 0 {
+		maxRows = defaultPreviewRows
+	}
+
+	text, err := decodeText(raw, opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	// 多讀一列以判斷是否還有更多資料
+	limit := maxRows + 1
+	if opts.HasHeader {
+		limit++
+	}
+	records, err := parseDelimited(text, opts, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// 與匯入相同，符合專案 NA 記號的值顯示為缺失值
+	policy := s.GetMissingPolicy()
+	header, body := splitCSVHeader(records, opts.HasHeader)
+	preview := &CSVPreview{
+		Options: opts,
+		Header:  header,
+		Rows:    make([][]any, 0, min(len(body), maxRows)),
+	}
+	if len(body) > maxRows {
+		body = body[:maxRows]
+		preview.Truncated = true
+	}
+	for _, record := range body {
+		row := make([]any, len(header))
+		for j := range header {
+			if j < len(record) {
+				if v := parseCSVCell(record[j], opts.DecimalSeparator); !policy.isMissing(v) {
+					row[j] = v
+				}
+			}
+		}
+		preview.Rows = append(preview.Rows, row)
+	}
+	return preview, nil
+}
+
+// OpenCSVFile 開啟CSV檔案並創建新的資料表，格式自動偵測
+func (s *DataTableService) OpenCSVFile(filePath string) (string, error) {
+	return s.OpenCSVFileWithOptions(filePath, nil)
+}
+
+// OpenCSVFileWithOptions 以指定設定開啟CSV檔案並創建新的資料表；options 為 nil 時自動偵測
+func (s *DataTableService) OpenCSVFileWithOptions(filePath string, options *CSVImportOptions) (string, error) {
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("read csv file: %w", err)
+	}
+	opts, err := resolveCSVOptions(raw, options)
+	if err != nil {
+		return "", err
+	}
+	text, err := decodeText(raw, opts.Encoding)
+	if err != nil {
+		return "", err
+	}
+	records, err := parseDelimited(text, opts, -1)
+	if err != nil {
+		return "", err
+	}
+
+	header, body := splitCSVHeader(records, opts.HasHeader)
+	columns := make([]*insyra.DataList, len(header))
+	for j, name := range header {
+		values := make([]any, len(body))
+		for i, record := range body {
+			if j < len(record) {
+				values[i] = parseCSVCell(record[j], opts.DecimalSeparator)
+			}
+		}
+		columns[j] = insyra.NewDataList(values...).SetName(name)
+	}
+
+	dt := newTableFromColumns(columns)
+	dt.SetName(tableNameFromPath(filePath))
+	return s.appendImportedTable(dt), nil
+}
+
+// resolveCSVOptions 補齊使用者設定；未提供時使用偵測結果
+func resolveCSVOptions(raw []byte, options *CSVImportOptions) (CSVImportOptions, error) {
+	if options == nil {
+		return detectCSVOptions(raw)
+	}
+	opts := *options
+	if opts.Encoding == "" {
+		opts.Encoding = detectEncoding(raw)
+	}
+	if utf8.RuneCountInString(opts.Delimiter) != 1 {
+		return opts, invalidArgument("csv delimiter must be a single character, got %q", opts.Delimiter).
+			WithDetail("delimiter", opts.Delimiter)
+	}
+	if utf8.RuneCountInString(opts.Quote) > 1 {
+		return opts, invalidArgument("csv quote must be at most one character, got %q", opts.Quote).
+			WithDetail("quote", opts.Quote)
+	}
+	if opts.DecimalSeparator == "" {
+		opts.DecimalSeparator = "."
+	}
+	if opts.DecimalSeparator != "." && opts.DecimalSeparator != "," {
+		return opts, invalidArgument("unsupported decimal separator %q", opts.DecimalSeparator).
+			WithDetail("decimalSeparator", opts.DecimalSeparator)
+	}
+	return opts, nil
+}
+
+// detectCSVOptions 以檔案開頭的取樣內容推測 CSV 格式
+func detectCSVOptions(raw []byte) (CSVImportOptions, error) {
+	opts := CSVImportOptions{
+		Encoding:         detectEncoding(raw),
+		Delimiter:        ",",
+		Quote:            `"`,
+		DecimalSeparator: ".",
+	}
+
+	sample := raw
+	if len(sample) > csvSniffBytes {
+		sample = sample[:csvSniffBytes]
+		// 避免截斷在最後一列中間
+		if i := bytes.LastIndexByte(sample, '\n'); i > 0 {
+			sample = sample[:i+1]
+		}
+	}
+	text, err := decodeText(sample, opts.Encoding)
+	if err != nil {
+		return opts, err
+	}
+
+	opts.Quote = detectQuote(text)
+	opts.Delimiter = string(detectDelimiter(text, opts.Quote))
+
+	records, err := parseDelimited(text, opts, csvSniffRows)
+	if err != nil {
+		return opts, err
+	}
+	opts.DecimalSeparator = detectDecimalSeparator(records, opts.Delimiter)
+	opts.HasHeader = detectHeader(records, opts.DecimalSeparator)
+	return opts, nil
+}
+
+// detectEncoding 依 BOM 與位元組內容判斷文字編碼
+func detectEncoding(raw []byte) string {
+	switch {
+	case bytes.HasPrefix(raw, []byte{0xEF, 0xBB, 0xBF}):
+		return EncodingUTF8BOM
+	case bytes.HasPrefix(raw, []byte{0xFF, 0xFE}):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(raw, []byte{0xFE, 0xFF}):
+		return EncodingUTF16BE
+	}
+
+	sample := raw
+	if len(sample) > csvSniffBytes {
+		sample = sample[:csvSniffBytes]
+	}
+	// 取樣可能截斷在多位元組字元中間，容許結尾最多 3 個位元組不完整
+	for cut := 0; cut < utf8.UTFMax && cut <= len(sample); cut++ {
+		if utf8.Valid(sample[:len(sample)-cut]) {
+			return EncodingUTF8
+		}
+		if len(sample) == len(raw) {
+			break
+		}
+	}
+	return EncodingBig5
+}
+
+// errUnsupportedEncoding 不支援的文字編碼
+func errUnsupportedEncoding(encoding string) *ServiceError {
+	return invalidArgument("unsupported encoding %q", encoding).WithDetail("encoding", encoding)
+}
+
+// decodeText 將位元組依指定編碼轉為 UTF-8 字串
+func decodeText(raw []byte, encoding string) (string, error) {
+	switch strings.ToLower(encoding) {
+	case EncodingUTF8, EncodingUTF8BOM, "utf8", "":
+		return string(bytes.TrimPrefix(raw, []byte{0xEF, 0xBB, 0xBF})), nil
+	case EncodingUTF16LE:
+		out, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder().Bytes(raw)
+		if err != nil {
+			return "", wrapError(ErrCodeParse, "errors.decode_failed", err, "decode utf-16le")
+		}
+		return string(out), nil
+	case EncodingUTF16BE:
+		out, err := unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewDecoder().Bytes(raw)
+		if err != nil {
+			return "", wrapError(ErrCodeParse, "errors.decode_failed", err, "decode utf-16be")
+		}
+		return string(out), nil
+	case EncodingBig5:
+		out, err := traditionalchinese.Big5.NewDecoder().Bytes(raw)
+		if err != nil {
+			return "", wrapError(ErrCodeParse, "errors.decode_failed", err, "decode big5")
+		}
+		return string(out), nil
+	default:
+		return "", errUnsupportedEncoding(encoding)
+	}
+}
+
+// detectQuote 判斷欄位使用雙引號、單引號或不使用引號
+func detectQuote(text string) string {
+	count := func(q byte) int {
+		n := 0
+		for i := 0; i < len(text); i++ {
+			if text[i] != q {
+				continue
+			}
+			// 只計算出現在欄位開頭的引號
+			if i == 0 || strings.IndexByte(",;\t|\n", text[i-1]) >= 0 {
+				n++
+			}
+		}
+		return n
+	}
+	double, single := count('"'), count('\'')
+	if single > double {
+		return "'"
+	}
+	return `"`
+}
+
+// detectDelimiter 選出讓每列欄位數最一致的分隔符號
+func detectDelimiter(text string, quote string) rune {
+	best, bestScore := ',', -1.0
+	for _, delim := range csvDelimiterCandidates {
+		records, err := parseDelimited(text, CSVImportOptions{Delimiter: string(delim), Quote: quote}, csvSniffRows)
+		if err != nil || len(records) == 0 {
+			continue
+		}
+		counts := make(map[int]int)
+		for _, record := range records {
+			counts[len(record)]++
+		}
+		mode, modeCount := 0, 0
+		for fields, n := range counts {
+			if n > modeCount || (n == modeCount && fields > mode) {
+				mode, modeCount = fields, n
+			}
+		}
+		if mode < 2 {
+			continue
+		}
+		// 一致性為主，欄位數為輔
+		score := float64(modeCount)/float64(len(records)) + float64(mode)/1000
+		if score > bestScore {
+			best, bestScore = delim, score
+		}
+	}
+	return best
+}
+
+// detectDecimalSeparator 判斷數值使用小數點或逗號
+func detectDecimalSeparator(records [][]string, delimiter string) string {
+	if delimiter == "," {
+		return "."
+	}
+	commas, points := 0, 0
+	for _, record := range records {
+		for _, field := range record {
+			field = strings.TrimSpace(field)
+			switch {
+			case decimalCommaPattern.MatchString(field):
+				commas++
+			case decimalPointPattern.MatchString(field):
+				points++
+			}
+		}
+	}
+	if commas > points {
+		return ","
+	}
+	return "."
+}
+
+// detectHeader 判斷第一列是否為標題列
+func detectHeader(records [][]string, decimalSeparator string) bool {
+	if len(records) == 0 {
+		return false
+	}
+	first := records[0]
+	seen := make(map[string]bool, len(first))
+	for _, field := range first {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if _, ok := parseNumber(field, decimalSeparator); ok {
+			// 標題列不應含有數值
+			return false
+		}
+		seen[field] = true
+	}
+	if len(records) == 1 {
+		return len(seen) > 0
+	}
+
+	// 若某欄在資料列中大多為數值，而第一列為文字，即視為標題列
+	for j := range first {
+		numeric, total := 0, 0
+		for _, record := range records[1:] {
+			if j >= len(record) || strings.TrimSpace(record[j]) == "" {
+				continue
+			}
+			total++
+			if _, ok := parseNumber(strings.TrimSpace(record[j]), decimalSeparator); ok {
+				numeric++
+			}
+		}
+		if total > 0 && numeric*2 > total {
+			return true
+		}
+	}
+	// 全為文字欄位時，第一列名稱不重複且不為空即視為標題列
+	return len(seen) == len(first)
+}
+
+// parseDelimited 解析分隔文字；limit < 0 表示解析全部
+func parseDelimited(text string, opts CSVImportOptions, limit int) ([][]string, error) {
+	delim, _ := utf8.DecodeRuneInString(opts.Delimiter)
+	quote := rune(-1)
+	if opts.Quote != "" {
+		quote, _ = utf8.DecodeRuneInString(opts.Quote)
+	}
+
+	var (
+		records  [][]string
+		record   []string
+		field    strings.Builder
+		inQuotes bool
+		quoted   bool
+		line     = 1
+	)
+	endField := func() {
+		record = append(record, field.String())
+		field.Reset()
+		quoted = false
+	}
+	endRecord := func() {
+		endField()
+		// 略過空白列
+		if len(record) > 1 || record[0] != "" {
+			records = append(records, record)
+		}
+		record = nil
+	}
+
+	for i := 0; i < len(text); {
+		if limit >= 0 && len(records) >= limit {
+			return records, nil
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+
+		if inQuotes {
+			if r == quote {
+				if next, n := utf8.DecodeRuneInString(text[i:]); next == quote && n > 0 {
+					field.WriteRune(quote)
+					i += n
+				} else {
+					inQuotes = false
+				}
+				continue
+			}
+			if r == '\n' {
+				line++
+			}
+			field.WriteRune(r)
+			continue
+		}
+
+		switch {
+		case r == quote && field.Len() == 0 && !quoted:
+			inQuotes, quoted = true, true
+		case r == delim:
+			endField()
+		case r == '\r':
+			// \r\n 由 \n 處理；單獨的 \r 視為換行
+			if !strings.HasPrefix(text[i:], "\n") {
+				endRecord()
+				line++
+			}
+		case r == '\n':
+			endRecord()
+			line++
+		default:
+			field.WriteRune(r)
+		}
+	}
+	if inQuotes {
+		return nil, newError(ErrCodeParse, "errors.csv_unterminated_quote", "unterminated quoted field near line %d", line).
+			WithDetail("line", line)
+	}
+	if field.Len() > 0 || len(record) > 0 {
+		endRecord()
+	}
+	if limit >= 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return records, nil
+}
+
+// splitCSVHeader 分出標題列與資料列，並補齊欄位名稱；重複的欄名以 uniqueColumnNames 加上流水號
+func splitCSVHeader(records [][]string, hasHeader bool) ([]string, [][]string) {
+	width := 0
+	for _, record := range records {
+		width = max(width, len(record))
+	}
+	header := make([]string, width)
+	body := records
+	if hasHeader && len(records) > 0 {
+		copy(header, records[0])
+		body = records[1:]
+	}
+	for j := range header {
+		header[j] = strings.TrimSpace(header[j])
+		if header[j] == "" {
+			header[j] = fmt.Sprintf("Column%d", j+1)
+		}
+	}
+	return uniqueColumnNames(header), body
+}
+
+// parseCSVCell 將欄位文字轉為儲存格的值：空字串為 nil，數值轉為 int 或 float64；
+// 補零的數字（例如 "007"、郵遞區號）保留為文字
+func parseCSVCell(field string, decimalSeparator string) any {
+	trimmed := strings.TrimSpace(field)
+	if trimmed == "" {
+		return nil
+	}
+	if isZeroPadded(trimmed) {
+		return field
+	}
+	if v, ok := parseNumber(trimmed, decimalSeparator); ok {
+		return v
+	}
+	return field
+}
+
+// isZeroPadded 判斷文字是否以多餘的 0 開頭，例如 "007"、"-01"；"0"、"0.5" 不算
+func isZeroPadded(s string) bool {
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	return len(s) > 1 && s[0] == '0' && s[1] >= '0' && s[1] <= '9'
+}
+
+// parseNumber 以指定小數點符號解析數值
+func parseNumber(s string, decimalSeparator string) (any, bool) {
+	if decimalSeparator == "," {
+		if strings.Contains(s, ".") {
+			return nil, false
+		}
+		s = strings.Replace(s, ",", ".", 1)
+	}
+	if i, err := strconv.Atoi(s); err == nil {
+		return i, true
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, false
+	}
+	// 不把 "NaN"、"Inf" 之類的文字當成數值
+	if strings.ContainsAny(s, "nNiI") {
+		return nil, false
+	}
+	return f, true
+}
+
+// tableNameFromPath 以檔名（不含副檔名）作為資料表名稱
+func tableNameFromPath(filePath string) string {
+	base := filepath.Base(filePath)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseCSVCellKeepsZeroPaddedNumbers(t *testing.T) {
+	tests := []struct {
+		field string
+		want  any
+	}{
+		{"007", "007"},
+		{"01234", "01234"},
+		{"-01", "-01"},
+		{"0", 0},
+		{"0.5", 0.5},
+		{"-0.25", -0.25},
+		{"42", 42},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := parseCSVCell(tt.field, "."); got != tt.want {
+			t.Errorf("parseCSVCell(%q) = %#v, want %#v", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestOpenCSVFileKeepsZipCodesAsText(t *testing.T) {
+	ConfigureInsyra()
+	path := writeTestFile(t, "zip.csv", "zip,amount\n02134,1\n10001,2\n00501,.\n")
+	s := NewDataTableService()
+	id, err := s.OpenCSVFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dt := s.getTableByID(id)
+	if got := s.columnTypeOf(dt, 0); got != DataTypeString {
+		t.Fatalf("zip column type = %q, want %q", got, DataTypeString)
+	}
+	zips := dt.GetColByNumber(0).Data()
+	if zips[0] != "02134" || zips[1] != "10001" || zips[2] != "00501" {
+		t.Fatalf("zip values = %#v", zips)
+	}
+	if got := dt.GetColByNumber(1).Data()[2]; got != nil {
+		t.Fatalf("NA token imported as %#v, want nil", got)
+	}
+}
+
+func TestPreviewCSVFileAppliesMissingPolicy(t *testing.T) {
+	path := writeTestFile(t, "na.csv", "a,b\n1,NA\n.,x\n")
+	s := NewDataTableService()
+	if err := s.SetMissingPolicy(MissingPolicy{Tokens: []string{".", "NA"}}); err != nil {
+		t.Fatal(err)
+	}
+	preview, err := s.PreviewCSVFile(path, nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := preview.Rows[0][1]; got != nil {
+		t.Errorf("preview NA = %#v, want nil", got)
+	}
+	if got := preview.Rows[1][0]; got != nil {
+		t.Errorf("preview . = %#v, want nil", got)
+	}
+	if got := preview.Rows[1][1]; got != "x" {
+		t.Errorf("preview x = %#v", got)
+	}
+}
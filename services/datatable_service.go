@@ -1,29 +1,62 @@
 package services
 
 import (
-	"context"
-	"fmt"
-
+	"maps"
+	"os"
 	"slices"
+	"sync"
+
+	"insyra-insights/config"
 
 	"github.com/HazelnutParadise/insyra"
+	"github.com/google/uuid"
 )
 
 // DataTableService 提供資料表的核心操作
+//
+// Wails 可能同時呼叫多個綁定的方法，因此所有公開方法都先以 lock 取得 mu，
+// 同一時間只有一個操作能讀寫資料表與服務狀態；未公開的輔助函式假設呼叫者已持有 mu
 type DataTableService struct {
-	dataTables []*insyra.DataTable
+	mu              sync.Mutex
+	project         ProjectState                                   // 目前專案的檔案路徑與未儲存狀態
+	tables          map[string]*insyra.DataTable                   // 以ID索引的資料表
+	tabOrder        []string                                       // 標籤頁順序，依序為各資料表的ID
+	histories       map[*insyra.DataTable]*tableHistory            // 各資料表的復原／重做歷程
+	removedTables   []removedTable                                 // 已移除、可還原的資料表
+	undoLimit       int                                            // 每個資料表最多保留的復原步驟數，由 SetUndoHistoryLimit 設定
+	revisions       map[*insyra.DataTable]*tableRevision           // 各資料表的修訂號
+	formulas        map[*insyra.DataTable]tableFormulas            // 各資料表計算欄位的公式
+	columnIDs       map[*insyra.DataTable][]int                    // 各資料表各欄的 ID，依欄位順序排列
+	columnTypes     map[*insyra.DataTable]map[int]string           // 各資料表各欄的型別，以欄位 ID 為鍵
+	missingPolicies map[*insyra.DataTable]map[int]MissingPolicy    // 各資料表各欄自訂的缺失值設定，以欄位 ID 為鍵
+	variables       map[*insyra.DataTable]map[int]VariableMetadata // 各資料表各欄的變數資訊，以欄位 ID 為鍵
+	nextColumnID    int                                            // 最後一個配發的欄位 ID
+	dirtyListener   func(DirtyState)                               // 未儲存狀態改變時的回呼
+	dirtyChanged    bool                                           // 持有 mu 期間未儲存狀態有改變，釋放時通知
+	dirtyQueue      []DirtyState                                   // 尚未送出的未儲存狀態，依改變的順序排列
+	dirtyWake       chan struct{}                                  // 喚醒送出通知的 goroutine
 }
 
 // NewDataTableService 創建一個新的 DataTableService 實例
 func NewDataTableService() *DataTableService {
 	return &DataTableService{
-		dataTables: make([]*insyra.DataTable, 0),
+		project:         ProjectState{missingPolicy: DefaultMissingPolicy()},
+		tables:          make(map[string]*insyra.DataTable),
+		tabOrder:        make([]string, 0),
+		histories:       make(map[*insyra.DataTable]*tableHistory),
+		revisions:       make(map[*insyra.DataTable]*tableRevision),
+		formulas:        make(map[*insyra.DataTable]tableFormulas),
+		columnIDs:       make(map[*insyra.DataTable][]int),
+		columnTypes:     make(map[*insyra.DataTable]map[int]string),
+		missingPolicies: make(map[*insyra.DataTable]map[int]MissingPolicy),
+		variables:       make(map[*insyra.DataTable]map[int]VariableMetadata),
+		undoLimit:       config.DefaultUndoHistoryLimit,
 	}
 }
 
 // findTableByName 根據表格名稱查找表格
 func (s *DataTableService) findTableByName(tableName string) *insyra.DataTable {
-	for _, dt := range s.dataTables {
+	for _, dt := range s.orderedTables() {
 		if dt.GetName() == tableName {
 			return dt
 		}
@@ -31,17 +64,14 @@ func (s *DataTableService) findTableByName(tableName string) *insyra.DataTable {
 	return nil
 }
 
-// getTableByID 根據slice索引獲取表格
-func (s *DataTableService) getTableByID(tableID int) *insyra.DataTable {
-	if tableID < 0 || tableID >= len(s.dataTables) {
-		return nil
-	}
-	return s.dataTables[tableID]
+// getTableByID 根據ID獲取表格
+func (s *DataTableService) getTableByID(tableID string) *insyra.DataTable {
+	return s.tables[tableID]
 }
 
-// findTableIndex 根據表格名稱查找索引
+// findTableIndex 根據表格名稱查找標籤頁位置
 func (s *DataTableService) findTableIndex(tableName string) int {
-	for i, dt := range s.dataTables {
+	for i, dt := range s.orderedTables() {
 		if dt.GetName() == tableName {
 			return i
 		}
@@ -49,202 +79,234 @@ func (s *DataTableService) findTableIndex(tableName string) int {
 	return -1
 }
 
-// LoadTable 加載資料表
-func (s *DataTableService) LoadTable(tableName string, filePath string) bool {
-	dt := insyra.NewDataTable()
+// orderedTables 依標籤頁順序取得所有資料表
+func (s *DataTableService) orderedTables() []*insyra.DataTable {
+	tables := make([]*insyra.DataTable, len(s.tabOrder))
+	for i, id := range s.tabOrder {
+		tables[i] = s.tables[id]
+	}
+	return tables
+}
+
+// tableIDOf 取得資料表的ID，資料表不在任何標籤頁中時回傳空字串
+func (s *DataTableService) tableIDOf(dt *insyra.DataTable) string {
+	for _, id := range s.tabOrder {
+		if s.tables[id] == dt {
+			return id
+		}
+	}
+	return ""
+}
+
+// newTableID 產生新的資料表ID；ID 與標籤頁位置無關，移除或移動其他標籤頁時不會改變
+func newTableID() string {
+	return uuid.NewString()
+}
+
+// insertTable 以 tableID 將資料表插入到第 index 個標籤頁；index 超出範圍時加到最後，回傳 tableID
+func (s *DataTableService) insertTable(index int, tableID string, dt *insyra.DataTable) string {
+	if index < 0 || index > len(s.tabOrder) {
+		index = len(s.tabOrder)
+	}
+	s.tables[tableID] = dt
+	s.tabOrder = slices.Insert(s.tabOrder, index, tableID)
+	return tableID
+}
+
+// appendImportedTable 取得 mu 後將匯入的資料表加到最後一個標籤頁之後，並回傳其ID；
+// 讀取與解析檔案不需持有 mu，避免大型檔案阻擋其他操作
+func (s *DataTableService) appendImportedTable(dt *insyra.DataTable) string {
+	s.lock()
+	defer s.unlock()
+	return s.appendTable(dt)
+}
+
+// appendTable 以 importTable 將資料表加到最後一個標籤頁之後，回傳其ID
+func (s *DataTableService) appendTable(dt *insyra.DataTable) string {
+	return s.importTable(-1, dt, nil)
+}
+
+// importTable 完成匯入或載入的資料表並插入到第 index 個標籤頁，回傳其ID。
+// 先依缺失值設定將 NA 記號轉為缺失值，再套用 types（以欄位索引為鍵）宣告的型別並推斷其餘的欄位；
+// 所有匯入與載入檔案的路徑都經過這裡，之後讀取時不再推斷型別
+func (s *DataTableService) importTable(index int, dt *insyra.DataTable, types map[int]string) string {
+	_, colCount := dt.Size()
+	s.applyMissingPolicy(dt, identity(colCount))
+	s.setColumnTypeTexts(dt, types)
+	s.markProjectModified()
+	return s.insertTable(index, newTableID(), dt)
+}
+
+// detachTable 將資料表從標籤頁中移除，回傳其原本的位置
+func (s *DataTableService) detachTable(tableID string) int {
+	index := slices.Index(s.tabOrder, tableID)
+	s.tabOrder = slices.Delete(s.tabOrder, index, index+1)
+	delete(s.tables, tableID)
+	return index
+}
 
-	// 使用 insyra 的 LoadFromJSON 方法載入資料
-	err := dt.LoadFromJSON(filePath)
+// loadJSONTable 從 JSON 檔案載入資料表
+func loadJSONTable(filePath string) (*insyra.DataTable, error) {
+	raw, err := os.ReadFile(filePath)
 	if err != nil {
-		return false
+		return nil, err
+	}
+	columns, err := parseJSONTable(raw)
+	if err != nil {
+		return nil, err
+	}
+	return newTableFromColumns(columns), nil
+}
+
+// LoadTable 加載資料表
+func (s *DataTableService) LoadTable(tableName string, filePath string) error {
+	s.lock()
+	defer s.unlock()
+	dt, err := loadJSONTable(filePath)
+	if err != nil {
+		return err
 	}
 
 	// 設定表格名稱
 	dt.SetName(tableName)
-	s.dataTables = append(s.dataTables, dt)
-	return true
+	s.appendTable(dt)
+	return nil
 }
 
 // CreateEmptyTable 創建一個空白資料表
-func (s *DataTableService) CreateEmptyTable(tableName string) bool {
+func (s *DataTableService) CreateEmptyTable(tableName string) error {
+	s.lock()
+	defer s.unlock()
 	dt := insyra.NewDataTable()
 	dt.SetName(tableName)
-	s.dataTables = append(s.dataTables, dt)
-	return true
+	s.markProjectModified()
+	s.insertTable(-1, newTableID(), dt)
+	return nil
 }
 
 // GetTableData 獲取資料表的完整資料
-func (s *DataTableService) GetTableData(tableName string) map[string]any {
+func (s *DataTableService) GetTableData(tableName string) (map[string]any, error) {
+	s.lock()
+	defer s.unlock()
 	dt := s.findTableByName(tableName)
 	if dt == nil {
-		return nil
-	}
-
-	result := make(map[string]any)
-
-	// 獲取表格大小
-	rowCount, colCount := dt.Size()
-
-	// 獲取所有欄名
-	var columns []map[string]any
-	for i := 0; i < colCount; i++ {
-		col := dt.GetColByNumber(i)
-		columns = append(columns, map[string]any{
-			"id":   i,
-			"name": col.GetName(),
-		})
-	}
-	result["columns"] = columns
-	// 獲取所有行資料
-	var rows []map[string]any
-	for i := range rowCount {
-		row := make(map[string]any)
-		row["id"] = i
-
-		cells := make(map[string]any)
-		for j := range colCount {
-			// 使用 GetElementByNumberIndex 獲取每個單元格的值
-			cellValue := dt.GetElementByNumberIndex(i, j)
-			cells[columns[j]["name"].(string)] = cellValue
-		}
-		row["cells"] = cells
-		rows = append(rows, row)
+		return nil, errTableNameNotFound(tableName)
 	}
-	result["rows"] = rows
-
-	return result
+	return s.tableData(dt), nil
 }
 
 // UpdateCellValue 更新儲存格的值
-func (s *DataTableService) UpdateCellValue(tableName string, rowIndex int, colIndex int, value string) bool {
+func (s *DataTableService) UpdateCellValue(tableName string, rowIndex int, colIndex int, value string) error {
+	s.lock()
+	defer s.unlock()
 	dt := s.findTableByName(tableName)
 	if dt == nil {
-		return false
+		return errTableNameNotFound(tableName)
 	}
-
-	// 處理特殊值：空字符串轉換為 nil
-	var cellValue any
-	if value == "" {
-		cellValue = nil
-	} else {
-		cellValue = value
-	}
-
-	// 使用 UpdateElement 設置單元格值
-	// 需要將列索引轉換為列字母標識符 (A, B, C, ...)
-	colLetter := indexToLetters(colIndex)
-	dt.UpdateElement(rowIndex, colLetter, cellValue)
-	return true
+	return s.updateCell(dt, rowIndex, colIndex, value)
 }
 
-// UpdateColumnName 更新欄名
-func (s *DataTableService) UpdateColumnName(tableName string, colIndex int, newName string) bool {
+// UpdateColumnName 更新欄名；與其他欄重複的名稱會自動加上流水號，名稱沒有變更時回傳 false
+func (s *DataTableService) UpdateColumnName(tableName string, colIndex int, newName string) (bool, error) {
+	s.lock()
+	defer s.unlock()
 	dt := s.findTableByName(tableName)
 	if dt == nil {
-		return false
-	}
-
-	oldName := dt.GetColByNumber(colIndex).GetName()
-	if oldName == newName {
-		return false // 沒有變更
+		return false, errTableNameNotFound(tableName)
 	}
-
-	dt.SetColNameByNumber(colIndex, newName)
-	return true
+	return s.renameColumn(dt, colIndex, newName)
 }
 
 // SaveTable 保存資料表
-func (s *DataTableService) SaveTable(tableName string, filePath string) bool {
+func (s *DataTableService) SaveTable(tableName string, filePath string) error {
+	s.lock()
+	defer s.unlock()
 	dt := s.findTableByName(tableName)
 	if dt == nil {
-		return false
+		return errTableNameNotFound(tableName)
 	}
 	// 使用 insyra 的 ToJSON 方法保存為 JSON
 	err := dt.ToJSON(filePath, true) // useColNames = true
-	return err == nil
+	return wrapError(ErrCodeIO, "errors.io", err, "save table %q", tableName)
 }
 
 // AddColumn 新增欄
-func (s *DataTableService) AddColumn(tableName string, columnName string) bool {
-	fmt.Printf("AddColumn 被調用: tableName=%s, columnName=%s\n", tableName, columnName)
+func (s *DataTableService) AddColumn(tableName string, columnName string) error {
+	s.lock()
+	defer s.unlock()
 	dt := s.findTableByName(tableName)
 	if dt == nil {
-		fmt.Printf("錯誤: 找不到 tableName=%s 的資料表\n", tableName)
-		return false
+		return errTableNameNotFound(tableName)
 	}
-
-	fmt.Printf("資料表存在，正在新增欄位: %s\n", columnName)
-	newCol := insyra.NewDataList(nil).SetName(columnName)
-	dt.AppendCols(newCol)
-	fmt.Printf("成功新增欄位: %s\n", columnName)
-	return true
+	s.addColumn(dt, columnName)
+	return nil
 }
 
 // AddRow 新增列
-func (s *DataTableService) AddRow(tableName string) bool {
-	fmt.Printf("AddRow 被調用: tableName=%s\n", tableName)
+func (s *DataTableService) AddRow(tableName string) error {
+	s.lock()
+	defer s.unlock()
 	dt := s.findTableByName(tableName)
 	if dt == nil {
-		fmt.Printf("錯誤: 找不到 tableName=%s 的資料表\n", tableName)
-		return false
+		return errTableNameNotFound(tableName)
 	}
-
-	fmt.Printf("資料表存在，正在新增行\n")
-
-	// 獲取當前資料表的欄位數量
-	_, colCount := dt.Size()
-	fmt.Printf("當前資料表有 %d 個欄位\n", colCount)
-
-	// 如果沒有欄位，先創建一個預設欄位
-	if colCount == 0 {
-		fmt.Printf("資料表沒有欄位，先創建預設欄位\n")
-		defaultCol := insyra.NewDataList(nil).SetName("Column1")
-		dt.AppendCols(defaultCol)
-		colCount = 1
-	}
-
-	// 創建新行資料，為每個欄位設置空值
-	newRowData := make([]interface{}, colCount)
-	for i := range newRowData {
-		newRowData[i] = nil
-	}
-
-	newRow := insyra.NewDataList(newRowData...)
-	dt.AppendRowsFromDataList(newRow)
-	fmt.Printf("成功新增行\n")
-	return true
+	s.addRow(dt)
+	return nil
 }
 
 // AddCalculatedColumn 新增計算欄位
-func (s *DataTableService) AddCalculatedColumn(tableName string, columnName string, formula string) bool {
+func (s *DataTableService) AddCalculatedColumn(tableName string, columnName string, formula string) error {
+	s.lock()
+	defer s.unlock()
 	dt := s.findTableByName(tableName)
 	if dt == nil {
-		return false
+		return errTableNameNotFound(tableName)
 	}
-	dt.AddColUsingCCL(columnName, formula)
-	// todo: 處理錯誤
-	return true
+	return s.addCalculated(dt, columnName, formula)
+}
+
+// addCalculatedColumn 以 CCL 公式在資料表末尾新增欄位；公式檢查不通過或 insyra 沒有新增欄位時回傳錯誤
+func addCalculatedColumn(dt *insyra.DataTable, columnName string, formula string) error {
+	if v := validateCCL(dt, formula); !v.Valid {
+		return errFormulaInvalid(formula, v.Errors[0]).WithDetail("columnName", columnName)
+	}
+	_, colCount := dt.Size()
+	messages := captureInsyra(func() { dt.AddColUsingCCL(columnName, formula) })
+	if _, newCount := dt.Size(); newCount > colCount {
+		return nil
+	}
+	e := newError(ErrCodeFormula, "errors.formula_failed", "formula %q failed", formula).
+		WithDetail("formula", formula).
+		WithDetail("columnName", columnName)
+	if len(messages) > 0 {
+		e.Message += ": " + messages[len(messages)-1].Message
+		e.WithDetail("insyra", messages)
+	}
+	return e
 }
 
 // GetTableNames 獲取所有表格名稱
 func (s *DataTableService) GetTableNames() []string {
-	names := make([]string, len(s.dataTables))
-	for i, dt := range s.dataTables {
+	s.lock()
+	defer s.unlock()
+	names := make([]string, len(s.tabOrder))
+	for i, dt := range s.orderedTables() {
 		names[i] = dt.GetName()
 	}
 	return names
 }
 
-// RemoveTable 移除指定名稱的表格
-func (s *DataTableService) RemoveTable(tableName string) bool {
-	for i, dt := range s.dataTables {
-		if dt.GetName() == tableName {
-			// 從切片中移除
-			s.dataTables = append(s.dataTables[:i], s.dataTables[i+1:]...)
-			return true
+// RemoveTable 移除指定名稱的表格；與 RemoveTableByID 相同，移除的表格可以還原
+func (s *DataTableService) RemoveTable(tableName string) error {
+	s.lock()
+	defer s.unlock()
+	for _, tableID := range s.tabOrder {
+		if dt := s.tables[tableID]; dt.GetName() == tableName {
+			s.removeTable(tableID, dt)
+			return nil
 		}
 	}
-	return false
+	return errTableNameNotFound(tableName)
 }
 
 // indexToLetters 將數字索引轉換為字母索引 (A, B, C, ..., AA, AB, ...)
@@ -264,183 +326,298 @@ func indexToLetters(index int) string {
 	return result
 }
 
-// ===== 基於 ID 的操作方法 =====
+// snapshotColumns 取得資料表所有欄位的名稱與資料副本
+func snapshotColumns(dt *insyra.DataTable) ([]string, [][]any) {
+	_, colCount := dt.Size()
+	names := make([]string, colCount)
+	columns := make([][]any, colCount)
+	for j := range colCount {
+		col := dt.GetColByNumber(j)
+		names[j] = col.GetName()
+		columns[j] = col.Data()
+	}
+	return names, columns
+}
 
-// LoadTableByID 加載資料表到指定位置 (如果ID超出範圍則添加到末尾)
-func (s *DataTableService) LoadTableByID(tableID int, tableName string, filePath string) int {
-	dt := insyra.NewDataTable()
+// columnLength 取得各欄位中最長的長度
+func columnLength(columns [][]any) int {
+	n := 0
+	for _, col := range columns {
+		n = max(n, len(col))
+	}
+	return n
+}
 
-	// 使用 insyra 的 LoadFromJSON 方法載入資料
-	err := dt.LoadFromJSON(filePath)
+// ===== 基於 ID 的操作方法 =====
+
+// LoadTableByID 加載資料表到第 index 個標籤頁 (如果位置超出範圍則添加到末尾)，回傳新資料表的ID
+func (s *DataTableService) LoadTableByID(index int, tableName string, filePath string) (string, error) {
+	s.lock()
+	defer s.unlock()
+	dt, err := loadJSONTable(filePath)
 	if err != nil {
-		return -1
+		return "", err
 	}
 
 	// 設定表格名稱
 	dt.SetName(tableName)
-
-	// 如果 tableID 有效，插入到指定位置
-	if tableID >= 0 && tableID < len(s.dataTables) {
-		// 插入到指定位置
-		s.dataTables = append(s.dataTables[:tableID+1], s.dataTables[tableID:]...)
-		s.dataTables[tableID] = dt
-		return tableID
-	} else {
-		// 添加到末尾
-		s.dataTables = append(s.dataTables, dt)
-		return len(s.dataTables) - 1
-	}
+	return s.importTable(index, dt, nil), nil
 }
 
-// CreateEmptyTableByID 在指定位置創建空白資料表
-func (s *DataTableService) CreateEmptyTableByID(tableID int, tableName string) int {
+// CreateEmptyTableByID 在第 index 個標籤頁創建空白資料表 (如果位置超出範圍則添加到末尾)，回傳新資料表的ID
+func (s *DataTableService) CreateEmptyTableByID(index int, tableName string) (string, error) {
+	s.lock()
+	defer s.unlock()
 	dt := insyra.NewDataTable()
 	dt.SetName(tableName)
 
 	// 創建一個預設的欄位以確保表格有基本結構
 	defaultCol := insyra.NewDataList(nil).SetName("Column1")
 	dt.AppendCols(defaultCol)
-
-	// 如果 tableID 有效，插入到指定位置
-	if tableID >= 0 && tableID < len(s.dataTables) {
-		// 插入到指定位置
-		s.dataTables = append(s.dataTables[:tableID+1], s.dataTables[tableID:]...)
-		s.dataTables[tableID] = dt
-		return tableID
-	} else {
-		// 添加到末尾
-		s.dataTables = append(s.dataTables, dt)
-		return len(s.dataTables) - 1
-	}
+	// 新增標籤頁是專案結構的變更
+	s.markProjectModified()
+	return s.insertTable(index, newTableID(), dt), nil
 }
 
 // GetTableDataByID 根據ID獲取資料表的完整資料
-func (s *DataTableService) GetTableDataByID(tableID int) map[string]any {
+func (s *DataTableService) GetTableDataByID(tableID string) (map[string]any, error) {
+	s.lock()
+	defer s.unlock()
 	dt := s.getTableByID(tableID)
 	if dt == nil {
-		return nil
+		return nil, errTableNotFound(tableID)
 	}
+	return s.tableData(dt), nil
+}
 
-	result := make(map[string]any)
+// TableColumn 前端表格使用的欄位資訊
+type TableColumn struct {
+	ID    int    `json:"id"`    // 在重新命名與移動後保持不變
+	Index int    `json:"index"` // 目前的位置
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+}
+
+// tableColumn 取得第 j 欄的欄位資訊；ids 為 columnIDsOf 的結果
+func (s *DataTableService) tableColumn(dt *insyra.DataTable, ids []int, j int) TableColumn {
+	return TableColumn{ID: ids[j], Index: j, Name: dt.GetColNameByNumber(j), Type: s.columnTypeOf(dt, j)}
+}
 
-	// 獲取表格大小
+// tableData 取得資料表的欄位與所有列；
+// 欄名可以留白，因此每列的 cells 依欄位順序排列，cells[j] 對應 columns[j]
+func (s *DataTableService) tableData(dt *insyra.DataTable) map[string]any {
 	rowCount, colCount := dt.Size()
+	ids := s.columnIDsOf(dt)
 
-	// 獲取所有欄名
-	var columns []map[string]any
-	for i := range colCount {
-		col := dt.GetColByNumber(i)
-		columns = append(columns, map[string]any{
-			"id":   i,
-			"name": col.GetName(),
-		})
+	// 獲取所有欄位
+	columns := make([]TableColumn, colCount)
+	for j := range colCount {
+		columns[j] = s.tableColumn(dt, ids, j)
 	}
-	result["columns"] = columns
 
-	// 獲取所有行資料
-	var rows []map[string]any
+	// 獲取所有行資料；GetRow 會以 nil 補齊較短的欄位
+	rows := make([]map[string]any, rowCount)
 	for i := range rowCount {
-		row := make(map[string]any)
-		row["id"] = i
-
-		cells := make(map[string]any)
-		for j := range colCount {
-			// 使用 GetElementByNumberIndex 獲取每個單元格的值
-			cellValue := dt.GetElementByNumberIndex(i, j)
-			cells[columns[j]["name"].(string)] = cellValue
+		cells := dt.GetRow(i).Data()
+		for j, v := range cells {
+			cells[j] = jsonCell(v)
+		}
+		rows[i] = map[string]any{
+			"id":    i,
+			"cells": cells,
 		}
-		row["cells"] = cells
-		rows = append(rows, row)
 	}
-	result["rows"] = rows
 
-	return result
+	return map[string]any{
+		"columns": columns,
+		"rows":    rows,
+	}
 }
 
 // UpdateCellValueByID 根據ID更新儲存格的值
-func (s *DataTableService) UpdateCellValueByID(tableID int, rowIndex int, colIndex int, value string) bool {
+func (s *DataTableService) UpdateCellValueByID(tableID string, rowIndex int, colIndex int, value string) error {
+	s.lock()
+	defer s.unlock()
 	dt := s.getTableByID(tableID)
 	if dt == nil {
-		return false
+		return errTableNotFound(tableID)
 	}
-	// 處理特殊值：點和空字串都轉換為 nil
+	return s.updateCell(dt, rowIndex, colIndex, value)
+}
+
+// UpdateCellValuesByID 根據ID一次更新多個儲存格（例如貼上），整批變更視為一個復原步驟；
+// 超出範圍的儲存格會略過，並回傳第一個錯誤
+func (s *DataTableService) UpdateCellValuesByID(tableID string, updates []CellUpdate) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
+	}
+	s.historyOf(dt).openGroup("paste")
+	defer s.endGroup(dt)
+	s.inferInputTypes(dt, updates)
+
+	var firstErr error
+	for _, u := range updates {
+		if err := s.updateCell(dt, u.Row, u.Col, u.Value); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// updateCell 更新單一儲存格並記錄復原步驟，再重新計算依賴此欄的計算欄位；
+// 群組進行中時延後到群組結束才重新計算
+func (s *DataTableService) updateCell(dt *insyra.DataTable, rowIndex int, colIndex int, value string) error {
+	rowCount, colCount := dt.Size()
+	if rowIndex < 0 || rowIndex >= rowCount || colIndex < 0 || colIndex >= colCount {
+		return errCellOutOfRange(rowIndex, colIndex, rowCount, colCount)
+	}
+	if f := s.formulas[dt][colIndex]; f != nil {
+		return errCalculatedColumn(colIndex, f.formula)
+	}
+	// 符合缺失值設定的輸入轉換為 nil，其餘依欄位型別轉換
 	var cellValue any
-	if value == "." || value == "" {
-		cellValue = nil
-	} else {
-		cellValue = value
+	inferred := false
+	if !s.missingPolicyOf(dt, colIndex).Policy.isMissing(value) {
+		var err error
+		if cellValue, inferred, err = s.coerceInput(dt, rowIndex, colIndex, value); err != nil {
+			return err
+		}
 	}
 
 	// 使用 UpdateElement 設置單元格值
 	colLetter := indexToLetters(colIndex)
+	oldValue := dt.GetElementByNumberIndex(rowIndex, colIndex)
 	dt.UpdateElement(rowIndex, colLetter, cellValue)
-	return true
+	id := s.columnIDsOf(dt)[colIndex]
+	dataType := s.columnTypesOf(dt)[id]
+	s.record(dt, "edit cell",
+		func(dt *insyra.DataTable) {
+			dt.UpdateElement(rowIndex, colLetter, oldValue)
+			// 復原由這個值推斷出的型別
+			if inferred {
+				delete(s.columnTypesOf(dt), id)
+			}
+		},
+		func(dt *insyra.DataTable) {
+			dt.UpdateElement(rowIndex, colLetter, cellValue)
+			if inferred {
+				s.columnTypesOf(dt)[id] = dataType
+			}
+		},
+	)
+	if h := s.historyOf(dt); h.group != nil {
+		h.group.changed[colIndex] = true
+	} else {
+		s.recomputeRow(dt, []int{colIndex}, false, rowIndex)
+	}
+	return nil
 }
 
-// UpdateColumnNameByID 根據ID更新欄名
-func (s *DataTableService) UpdateColumnNameByID(tableID int, colIndex int, newName string) bool {
+// UpdateColumnNameByID 根據ID更新欄名；與其他欄重複的名稱會自動加上流水號，名稱沒有變更時回傳 false
+func (s *DataTableService) UpdateColumnNameByID(tableID string, colIndex int, newName string) (bool, error) {
+	s.lock()
+	defer s.unlock()
 	dt := s.getTableByID(tableID)
 	if dt == nil {
-		return false
+		return false, errTableNotFound(tableID)
+	}
+	return s.renameColumn(dt, colIndex, newName)
+}
+
+// renameColumn 更新第 colIndex 欄的欄名並記錄復原步驟；重複的名稱自動加上流水號
+func (s *DataTableService) renameColumn(dt *insyra.DataTable, colIndex int, newName string) (bool, error) {
+	if _, colCount := dt.Size(); colIndex < 0 || colIndex >= colCount {
+		return false, errColumnOutOfRange(colIndex, colCount)
 	}
 
-	oldName := dt.GetColByNumber(colIndex).GetName()
+	oldName := dt.GetColNameByNumber(colIndex)
+	newName = uniqueColumnName(dt, colIndex, newName)
 	if oldName == newName {
-		return false // 沒有變更
+		return false, nil // 沒有變更
 	}
 
 	dt.SetColNameByNumber(colIndex, newName)
-	return true
+	s.record(dt, "rename column",
+		func(dt *insyra.DataTable) { dt.SetColNameByNumber(colIndex, oldName) },
+		func(dt *insyra.DataTable) { dt.SetColNameByNumber(colIndex, newName) },
+	)
+	return true, nil
 }
 
 // SaveTableByID 根據ID保存資料表
-func (s *DataTableService) SaveTableByID(tableID int, filePath string) bool {
+func (s *DataTableService) SaveTableByID(tableID string, filePath string) error {
+	s.lock()
+	defer s.unlock()
 	dt := s.getTableByID(tableID)
 	if dt == nil {
-		return false
+		return errTableNotFound(tableID)
 	}
 	// 使用 insyra 的 ToJSON 方法保存為 JSON
 	err := dt.ToJSON(filePath, true) // useColNames = true
-	return err == nil
+	return wrapError(ErrCodeIO, "errors.io", err, "save table %q", tableID)
 }
 
 // AddColumnByID 根據ID新增欄
-func (s *DataTableService) AddColumnByID(tableID int, columnName string) bool {
-	fmt.Printf("AddColumnByID 被調用: tableID=%d, columnName=%s\n", tableID, columnName)
+func (s *DataTableService) AddColumnByID(tableID string, columnName string) error {
+	s.lock()
+	defer s.unlock()
 	dt := s.getTableByID(tableID)
 	if dt == nil {
-		fmt.Printf("錯誤: 找不到 tableID=%d 的資料表\n", tableID)
-		return false
+		return errTableNotFound(tableID)
 	}
+	s.addColumn(dt, columnName)
+	return nil
+}
 
-	fmt.Printf("資料表存在，正在新增欄位: %s\n", columnName)
-	newCol := insyra.NewDataList(nil).SetName(columnName)
-	dt.AppendCols(newCol)
-	fmt.Printf("成功新增欄位: %s\n", columnName)
-	return true
+// addColumn 在資料表末尾新增空白欄並記錄復原步驟；重複的名稱自動加上流水號
+func (s *DataTableService) addColumn(dt *insyra.DataTable, columnName string) {
+	columnName = uniqueColumnName(dt, -1, columnName)
+	dt.AppendCols(insyra.NewDataList(nil).SetName(columnName))
+	s.recordAppendedColumn(dt, "add column")
 }
 
 // AddRowByID 根據ID新增列
-func (s *DataTableService) AddRowByID(tableID int) bool {
-	fmt.Printf("AddRowByID 被調用: tableID=%d\n", tableID)
+func (s *DataTableService) AddRowByID(tableID string) error {
+	s.lock()
+	defer s.unlock()
 	dt := s.getTableByID(tableID)
 	if dt == nil {
-		fmt.Printf("錯誤: 找不到 tableID=%d 的資料表\n", tableID)
-		return false
+		return errTableNotFound(tableID)
 	}
+	s.addRow(dt)
+	return nil
+}
 
-	fmt.Printf("資料表存在，正在新增行\n")
+// addRow 在資料表末尾新增一列空值並記錄復原步驟
+func (s *DataTableService) addRow(dt *insyra.DataTable) {
+	addedDefaultCol := appendEmptyRow(dt)
+	s.recomputeRow(dt, slices.Collect(maps.Keys(s.formulas[dt])), true, -1)
+	s.record(dt, "add row",
+		func(dt *insyra.DataTable) {
+			dt.DropRowsByIndex(-1)
+			if addedDefaultCol {
+				dt.DropColsByNumber(0)
+			}
+		},
+		func(dt *insyra.DataTable) { appendEmptyRow(dt) },
+	)
+}
 
+// appendEmptyRow 在資料表末尾新增一列空值；資料表沒有欄位時先建立預設欄位並回傳 true
+func appendEmptyRow(dt *insyra.DataTable) bool {
 	// 獲取當前資料表的欄位數量
 	_, colCount := dt.Size()
-	fmt.Printf("當前資料表有 %d 個欄位\n", colCount)
 
 	// 如果沒有欄位，先創建一個預設欄位
+	addedDefaultCol := false
 	if colCount == 0 {
-		fmt.Printf("資料表沒有欄位，先創建預設欄位\n")
 		defaultCol := insyra.NewDataList(nil).SetName("Column1")
 		dt.AppendCols(defaultCol)
 		colCount = 1
+		addedDefaultCol = true
 	}
 
 	// 創建新行資料，為每個欄位設置空值
@@ -451,34 +628,102 @@ func (s *DataTableService) AddRowByID(tableID int) bool {
 
 	newRow := insyra.NewDataList(newRowData...)
 	dt.AppendRowsFromDataList(newRow)
-	fmt.Printf("成功新增行\n")
-	return true
+	return addedDefaultCol
+}
+
+// recordAppendedColumn 將剛加入資料表末尾的欄位（及其公式與 ID）記錄為復原步驟
+func (s *DataTableService) recordAppendedColumn(dt *insyra.DataTable, label string) {
+	added := dt.GetColByNumber(-1)
+	_, colCount := dt.Size()
+	formula := s.formulas[dt][colCount-1]
+	id := s.columnIDsOf(dt)[colCount-1]
+	s.record(dt, label,
+		func(dt *insyra.DataTable) {
+			dt.DropColsByNumber(colCount - 1)
+			delete(s.formulasOf(dt), colCount-1)
+		},
+		func(dt *insyra.DataTable) {
+			dt.AppendCols(insyra.NewDataList(slices.Clone(added.Data())).SetName(added.GetName()))
+			if formula != nil {
+				s.formulasOf(dt)[colCount-1] = formula
+			}
+			// 重做時沿用原本的 ID
+			s.columnIDs[dt] = append(s.columnIDsOf(dt)[:colCount-1], id)
+		},
+	)
 }
 
-// AddCalculatedColumnByID 根據ID新增計算欄位
-func (s *DataTableService) AddCalculatedColumnByID(tableID int, columnName string, formula string) bool {
+// registerAppendedFormula 將公式記錄為資料表最後一欄的計算公式，並依目前的結果決定欄位型別
+func (s *DataTableService) registerAppendedFormula(dt *insyra.DataTable, formula string) {
+	_, colCount := dt.Size()
+	v := validateCCL(dt, formula)
+	s.formulasOf(dt)[colCount-1] = &columnFormula{formula: formula, deps: referencedIndices(v)}
+	s.storeFormulaValues(dt, colCount-1, dt.GetColByNumber(colCount-1).Data())
+}
+
+// AddCalculatedColumnByID 根據ID新增計算欄位；公式有誤時回傳 ErrCodeFormula 錯誤，資料表不變
+func (s *DataTableService) AddCalculatedColumnByID(tableID string, columnName string, formula string) error {
+	s.lock()
+	defer s.unlock()
 	dt := s.getTableByID(tableID)
 	if dt == nil {
-		return false
+		return errTableNotFound(tableID)
 	}
 
-	// 使用 AddColUsingCCL 方法來執行 CCL 公式並新增欄位
-	dt.AddColUsingCCL(columnName, formula)
-	// todo: 處理錯誤
+	return s.addCalculated(dt, columnName, formula)
+}
 
-	return true
+// addCalculated 以公式新增計算欄位並記錄復原步驟
+func (s *DataTableService) addCalculated(dt *insyra.DataTable, columnName string, formula string) error {
+	// 使用 AddColUsingCCL 方法來執行 CCL 公式並新增欄位，並記住公式以便來源變更時重新計算
+	if err := addCalculatedColumn(dt, columnName, formula); err != nil {
+		return err
+	}
+	s.registerAppendedFormula(dt, formula)
+	s.recordAppendedColumn(dt, "add calculated column")
+	return nil
 }
 
 // GetTableCount 獲取表格總數
 func (s *DataTableService) GetTableCount() int {
-	return len(s.dataTables)
+	s.lock()
+	defer s.unlock()
+	return len(s.tabOrder)
+}
+
+// GetTabOrder 依標籤頁順序取得所有資料表的ID
+func (s *DataTableService) GetTabOrder() []string {
+	s.lock()
+	defer s.unlock()
+	return slices.Clone(s.tabOrder)
+}
+
+// MoveTab 將資料表的標籤頁移動到第 index 個位置，其他標籤頁依序遞補；資料表的ID不變
+func (s *DataTableService) MoveTab(tableID string, index int) error {
+	s.lock()
+	defer s.unlock()
+	from := slices.Index(s.tabOrder, tableID)
+	if from < 0 {
+		return errTableNotFound(tableID)
+	}
+	if index < 0 || index >= len(s.tabOrder) {
+		return errIndexOutOfBounds("tab", index, len(s.tabOrder)-1)
+	}
+	if from == index {
+		return nil
+	}
+	s.tabOrder = moveBlock(s.tabOrder, from, 1, index)
+	s.markProjectModified()
+	return nil
 }
 
 // GetTableInfo 獲取指定ID表格的基本信息
-func (s *DataTableService) GetTableInfo(tableID int) map[string]any {
+func (s *DataTableService) GetTableInfo(tableID string) (map[string]any, error) {
+	s.lock()
+	defer s.unlock()
 	dt := s.getTableByID(tableID)
 	if dt == nil {
-		return nil
+		return nil, errTableNotFound(tableID)
 	}
 
 	rowCount, colCount := dt.Size()
@@ -487,184 +732,124 @@ func (s *DataTableService) GetTableInfo(tableID int) map[string]any {
 		"name":     dt.GetName(),
 		"rowCount": rowCount,
 		"colCount": colCount,
-	}
+	}, nil
 }
 
 // RemoveTableByID 根據ID移除表格
-func (s *DataTableService) RemoveTableByID(tableID int) bool {
-	if tableID < 0 || tableID >= len(s.dataTables) {
-		return false
+func (s *DataTableService) RemoveTableByID(tableID string) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
 	}
-	// 從切片中移除
-	s.dataTables = slices.Delete(s.dataTables, tableID, tableID+1)
-	return true
+	s.removeTable(tableID, dt)
+	return nil
+}
+
+// removeTable 從標籤頁中移除資料表，保留資料表以便還原
+func (s *DataTableService) removeTable(tableID string, dt *insyra.DataTable) {
+	index := s.detachTable(tableID)
+	s.rememberRemovedTable(index, tableID, dt)
+	s.markProjectModified()
 }
 
 // ===== 專案檔案操作 =====
 
-// 專案狀態管理
+// ProjectState 專案的檔案路徑與未儲存狀態
 type ProjectState struct {
 	currentFilePath   string
 	hasUnsavedChanges bool
+	missingPolicy     MissingPolicy // 專案預設的缺失值設定
 }
 
-var projectState = &ProjectState{
-	currentFilePath:   "",
-	hasUnsavedChanges: false,
-}
-
-// SaveProject 儲存整個專案（所有標籤頁）
-func (s *DataTableService) SaveProject(filePath string) bool {
-	// TODO: 實現專案檔案儲存
-	// 應該保存所有 dataTables 到一個專案檔案中
-	// 格式可以是 JSON 或自定義格式
-
-	// 暫時實現：將所有表格保存到一個 JSON 檔案
-	projectData := map[string]any{
-		"version": "1.0",
-		"tables":  make([]map[string]any, 0),
+// SaveProject 儲存整個專案（所有標籤頁）為 .insa 檔案
+func (s *DataTableService) SaveProject(filePath string) error {
+	s.lock()
+	defer s.unlock()
+	if filePath == "" {
+		return invalidArgument("project file path is empty")
 	}
-	for i, dt := range s.dataTables {
-		if dt != nil {
-			rowCount, colCount := dt.Size()
-			tableData := map[string]any{
-				"id":       i,
-				"name":     dt.GetName(),
-				"rowCount": rowCount,
-				"colCount": colCount,
-				// TODO: 添加實際的資料匯出
-			}
-			projectData["tables"] = append(projectData["tables"].([]map[string]any), tableData)
-		}
+	if err := writeProjectFile(filePath, encodeProject(s.project.missingPolicy, s.projectTables())); err != nil {
+		return err
 	}
 
-	// 這裡應該實際寫入檔案
-	// 暫時返回 true
-	projectState.currentFilePath = filePath
-	projectState.hasUnsavedChanges = false
-	return true
-}
-
-// LoadProject 載入專案檔案
-func (s *DataTableService) LoadProject(filePath string) bool {
-	// TODO: 實現專案檔案載入
-	// 清空現有資料表，載入專案檔案中的所有表格
-
-	projectState.currentFilePath = filePath
-	projectState.hasUnsavedChanges = false
-	return true
-}
-
-// ===== 資料表匯出方法 =====
-
-// ExportTableAsCSV 將指定資料表匯出為 CSV
-func (s *DataTableService) ExportTableAsCSV(tableID int, filePath string) bool {
-	dt := s.getTableByID(tableID)
-	if dt == nil {
-		return false
-	}
-
-	// TODO: 實現 CSV 匯出
-	// 使用 insyra 的 CSV 輸出功能
-	return true
-}
-
-// ExportTableAsJSON 將指定資料表匯出為 JSON
-func (s *DataTableService) ExportTableAsJSON(tableID int, filePath string) bool {
-	dt := s.getTableByID(tableID)
-	if dt == nil {
-		return false
-	}
-
-	// TODO: 實現 JSON 匯出
-	return true
+	s.project.currentFilePath = filePath
+	s.markSaved()
+	return nil
 }
 
-// ExportTableAsExcel 將指定資料表匯出為 Excel
-func (s *DataTableService) ExportTableAsExcel(tableID int, filePath string) bool {
-	dt := s.getTableByID(tableID)
-	if dt == nil {
-		return false
+// LoadProject 載入專案檔案，清空現有資料表後依檔案內容重建所有標籤頁
+func (s *DataTableService) LoadProject(filePath string) error {
+	s.lock()
+	defer s.unlock()
+	project, err := readProjectFile(filePath)
+	if err != nil {
+		return err
 	}
-
-	// TODO: 實現 Excel 匯出
-	return true
+	tables, err := decodeProject(project)
+	if err != nil {
+		return err
+	}
+
+	s.tables = make(map[string]*insyra.DataTable, len(tables))
+	s.tabOrder = make([]string, 0, len(tables))
+	s.formulas = make(map[*insyra.DataTable]tableFormulas)
+	s.columnIDs = make(map[*insyra.DataTable][]int)
+	s.columnTypes = make(map[*insyra.DataTable]map[int]string)
+	s.missingPolicies = make(map[*insyra.DataTable]map[int]MissingPolicy)
+	s.variables = make(map[*insyra.DataTable]map[int]VariableMetadata)
+	s.nextColumnID = 0
+	s.project.missingPolicy = DefaultMissingPolicy()
+	if project.MissingPolicy != nil {
+		s.project.missingPolicy = project.MissingPolicy.normalized()
+	}
+	for _, table := range tables {
+		// 舊版檔案沒有資料表ID，或ID重複時重新產生
+		tableID := table.id
+		if _, taken := s.tables[tableID]; tableID == "" || taken {
+			tableID = newTableID()
+		}
+		s.insertTable(-1, tableID, table.dt)
+		s.setFormulaTexts(table.dt, table.formulas)
+		s.setColumnIDs(table.dt, table.columnIDs)
+		s.setMissingPolicyTexts(table.dt, table.missingPolicies)
+		s.setVariableTexts(table.dt, table.variables)
+		s.setColumnTypeTexts(table.dt, table.columnTypes)
+	}
+	s.resetHistory()
+	s.revisions = make(map[*insyra.DataTable]*tableRevision)
+	s.project.currentFilePath = filePath
+	s.markSaved()
+	return nil
 }
 
 // ===== 專案狀態管理 =====
 
 // HasUnsavedChanges 檢查是否有未儲存的變更
 func (s *DataTableService) HasUnsavedChanges() bool {
-	return projectState.hasUnsavedChanges
+	s.lock()
+	defer s.unlock()
+	return s.project.hasUnsavedChanges
 }
 
 // MarkAsSaved 標記專案為已儲存狀態
 func (s *DataTableService) MarkAsSaved() {
-	projectState.hasUnsavedChanges = false
+	s.lock()
+	defer s.unlock()
+	s.markSaved()
 }
 
 // GetCurrentProjectPath 獲取當前專案檔案路徑
 func (s *DataTableService) GetCurrentProjectPath() string {
-	return projectState.currentFilePath
+	s.lock()
+	defer s.unlock()
+	return s.project.currentFilePath
 }
 
 // MarkAsModified 標記專案有變更（在修改資料時調用）
 func (s *DataTableService) MarkAsModified() {
-	projectState.hasUnsavedChanges = true
-}
-
-// ===== 檔案開啟功能 =====
-
-// OpenCSVFile 開啟CSV檔案並創建新的資料表
-func (s *DataTableService) OpenCSVFile(filePath string) int {
-	// TODO: 實現CSV檔案載入功能
-	// 1. 讀取CSV檔案
-	// 2. 解析CSV格式
-	// 3. 創建新的資料表
-	// 4. 設定表格名稱
-	// 5. 返回新表格的ID
-	return -1
-}
-
-// OpenJSONFile 開啟JSON檔案並創建新的資料表
-func (s *DataTableService) OpenJSONFile(filePath string) int {
-	// TODO: 實現JSON檔案載入功能
-	// 1. 讀取JSON檔案
-	// 2. 解析JSON格式（支援陣列格式和物件格式）
-	// 3. 創建新的資料表
-	// 4. 設定表格名稱和欄位
-	// 5. 返回新表格的ID
-	return -1
-}
-
-// OpenSQLiteFile 開啟SQLite檔案中的指定表格
-func (s *DataTableService) OpenSQLiteFile(filePath string, tableName string) int {
-	// TODO: 實現SQLite檔案載入功能
-	// 1. 連接SQLite資料庫
-	// 2. 查詢指定表格的結構和資料
-	// 3. 創建新的資料表
-	// 4. 設定表格名稱和欄位
-	// 5. 載入所有資料行
-	// 6. 返回新表格的ID
-	return -1
-}
-
-// GetSQLiteTables 取得SQLite檔案中的表格列表
-func (s *DataTableService) GetSQLiteTables(filePath string) []string {
-	// TODO: 實現SQLite表格列表功能
-	// 1. 連接SQLite資料庫
-	// 2. 查詢sqlite_master表格
-	// 3. 取得所有用戶定義的表格名稱
-	// 4. 返回表格名稱列表
-	return []string{}
-}
-
-// OpenFileDialog 開啟檔案選擇對話框
-func (s *DataTableService) OpenFileDialog(ctx context.Context, filters string) string {
-	// TODO: 實現檔案選擇對話框功能
-	// 1. 使用Wails runtime.OpenFileDialog
-	// 2. 根據filters參數設定檔案過濾器
-	// 3. 顯示原生檔案選擇對話框
-	// 4. 返回使用者選擇的檔案路徑
-	return ""
+	s.lock()
+	defer s.unlock()
+	s.markProjectModified()
 }
@@ -0,0 +1,37 @@
+package services
+
+import "testing"
+
+func TestNameBasedMutatorsCanBeUndone(t *testing.T) {
+	ConfigureInsyra()
+	s := NewDataTableService()
+	id, err := s.CreateEmptyTableByID(-1, "t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dt := s.getTableByID(id)
+	initialRows, _ := dt.Size()
+
+	if err := s.AddColumn("t", "Column1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := dt.GetColNameByNumber(1); got != "Column1_1" {
+		t.Fatalf("added column name = %q, want Column1_1", got)
+	}
+	if err := s.AddRow("t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateColumnName("t", 0, "score"); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 3 {
+		if ok, err := s.Undo(id); err != nil || !ok {
+			t.Fatalf("Undo() = %v, %v", ok, err)
+		}
+	}
+	rows, cols := dt.Size()
+	if rows != initialRows || cols != 1 || dt.GetColNameByNumber(0) != "Column1" {
+		t.Fatalf("after undo: size %dx%d, first column %q", rows, cols, dt.GetColNameByNumber(0))
+	}
+}
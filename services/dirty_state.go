@@ -0,0 +1,172 @@
+package services
+
+import (
+	"github.com/HazelnutParadise/insyra"
+)
+
+// ===== 未儲存變更追蹤 =====
+
+// DirtyStateChangedEvent 專案或資料表的未儲存狀態改變時發出的 Wails 事件名稱
+const DirtyStateChangedEvent = "dirty-state-changed"
+
+// TableDirtyState 單一資料表的未儲存狀態
+type TableDirtyState struct {
+	TableID  string `json:"tableID"`
+	Name     string `json:"name"`
+	Dirty    bool   `json:"dirty"`
+	Revision uint64 `json:"revision"`
+}
+
+// DirtyState 專案的未儲存狀態，作為 DirtyStateChangedEvent 的內容
+type DirtyState struct {
+	HasUnsavedChanges bool              `json:"hasUnsavedChanges"`
+	ProjectPath       string            `json:"projectPath"`
+	Tables            []TableDirtyState `json:"tables"`
+}
+
+// tableRevision 資料表的修訂號；每次變更遞增，與最後儲存時的修訂號不同即為未儲存
+type tableRevision struct {
+	revision      uint64
+	savedRevision uint64
+}
+
+func (r *tableRevision) dirty() bool {
+	return r.revision != r.savedRevision
+}
+
+// SetDirtyStateListener 設定未儲存狀態改變時的回呼（由 App 轉為 Wails 事件）；
+// 回呼在另一個 goroutine 中依狀態改變的順序執行，不持有任何鎖，因此可以呼叫此服務的方法
+func (s *DataTableService) SetDirtyStateListener(listener func(DirtyState)) {
+	s.lock()
+	defer s.unlock()
+	s.dirtyListener = listener
+	if s.dirtyWake == nil {
+		s.dirtyWake = make(chan struct{}, 1)
+		go s.deliverDirtyStates()
+	}
+}
+
+// deliverDirtyStates 每次被喚醒時取出佇列中的狀態，釋放 mu 後依序交給監聽者
+func (s *DataTableService) deliverDirtyStates() {
+	for range s.dirtyWake {
+		s.mu.Lock()
+		queue, listener := s.dirtyQueue, s.dirtyListener
+		s.dirtyQueue = nil
+		s.mu.Unlock()
+		for _, state := range queue {
+			listener(state)
+		}
+	}
+}
+
+// lock 取得 mu；公開的方法一律以 lock／unlock 包住
+func (s *DataTableService) lock() {
+	s.mu.Lock()
+}
+
+// unlock 釋放 mu；期間未儲存狀態有改變時，先在持有 mu 時將狀態排入佇列，
+// 再由 deliverDirtyStates 通知監聽者，因此回呼不會阻擋其他操作，通知的順序也與改變的順序相同
+func (s *DataTableService) unlock() {
+	if s.dirtyChanged && s.dirtyListener != nil {
+		s.dirtyQueue = append(s.dirtyQueue, s.dirtyState())
+		select {
+		case s.dirtyWake <- struct{}{}:
+		default: // 已經有尚未處理的喚醒，佇列會一併送出
+		}
+	}
+	s.dirtyChanged = false
+	s.mu.Unlock()
+}
+
+// revisionOf 取得資料表的修訂資訊，不存在時建立
+func (s *DataTableService) revisionOf(dt *insyra.DataTable) *tableRevision {
+	r, ok := s.revisions[dt]
+	if !ok {
+		r = &tableRevision{}
+		s.revisions[dt] = r
+	}
+	return r
+}
+
+// touch 記錄資料表內容有變更：遞增修訂號並標記專案為未儲存
+func (s *DataTableService) touch(dt *insyra.DataTable) {
+	r := s.revisionOf(dt)
+	wasDirty := r.dirty() && s.project.hasUnsavedChanges
+	r.revision++
+	s.project.hasUnsavedChanges = true
+	if !wasDirty {
+		s.notifyDirtyState()
+	}
+}
+
+// markProjectModified 記錄專案結構有變更（新增、移除或還原標籤頁）
+func (s *DataTableService) markProjectModified() {
+	if s.project.hasUnsavedChanges {
+		return
+	}
+	s.project.hasUnsavedChanges = true
+	s.notifyDirtyState()
+}
+
+// markSaved 將專案與所有資料表標記為已儲存；專案路徑可能已改變，因此一律通知
+func (s *DataTableService) markSaved() {
+	for _, r := range s.revisions {
+		r.savedRevision = r.revision
+	}
+	s.project.hasUnsavedChanges = false
+	s.notifyDirtyState()
+}
+
+// notifyDirtyState 標記未儲存狀態有改變，由 unlock 通知監聽者
+func (s *DataTableService) notifyDirtyState() {
+	s.dirtyChanged = true
+}
+
+// GetDirtyState 取得專案與各資料表的未儲存狀態
+func (s *DataTableService) GetDirtyState() DirtyState {
+	s.lock()
+	defer s.unlock()
+	return s.dirtyState()
+}
+
+// dirtyState 取得專案與各資料表的未儲存狀態
+func (s *DataTableService) dirtyState() DirtyState {
+	state := DirtyState{
+		HasUnsavedChanges: s.project.hasUnsavedChanges,
+		ProjectPath:       s.project.currentFilePath,
+		Tables:            make([]TableDirtyState, len(s.tabOrder)),
+	}
+	for i, tableID := range s.tabOrder {
+		dt := s.tables[tableID]
+		r := s.revisionOf(dt)
+		state.Tables[i] = TableDirtyState{
+			TableID:  tableID,
+			Name:     dt.GetName(),
+			Dirty:    r.dirty(),
+			Revision: r.revision,
+		}
+	}
+	return state
+}
+
+// IsTableDirty 檢查資料表自上次儲存後是否有變更
+func (s *DataTableService) IsTableDirty(tableID string) (bool, error) {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return false, errTableNotFound(tableID)
+	}
+	return s.revisionOf(dt).dirty(), nil
+}
+
+// GetTableRevision 取得資料表目前的修訂號，每次變更都會遞增
+func (s *DataTableService) GetTableRevision(tableID string) (uint64, error) {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return 0, errTableNotFound(tableID)
+	}
+	return s.revisionOf(dt).revision, nil
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 回呼在不持有鎖的 goroutine 中依序執行：同時建立資料表與儲存時，回呼再讀取服務也不會死結
+func TestDirtyListenerRunsWithoutLock(t *testing.T) {
+	s := NewDataTableService()
+	var mu sync.Mutex
+	var states []DirtyState
+	s.SetDirtyStateListener(func(state DirtyState) {
+		s.GetDirtyState()
+		mu.Lock()
+		states = append(states, state)
+		mu.Unlock()
+	})
+
+	const workers, rounds = 8, 20
+	done := make(chan struct{})
+	go func() {
+		var wg sync.WaitGroup
+		for w := range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range rounds {
+					if _, err := s.CreateEmptyTableByID(-1, fmt.Sprintf("t%d_%d", w, i)); err != nil {
+						t.Error(err)
+						return
+					}
+					s.MarkAsSaved()
+				}
+			}()
+		}
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("CreateEmptyTableByID and MarkAsSaved are deadlocked with the listener")
+	}
+
+	// 最後一次儲存的狀態最後送達；資料表只增不減，因此通知中的資料表數不會減少
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		mu.Lock()
+		got := append([]DirtyState(nil), states...)
+		mu.Unlock()
+		if n := len(got); n > 0 && len(got[n-1].Tables) == workers*rounds && !got[n-1].HasUnsavedChanges {
+			for k := 1; k < n; k++ {
+				if len(got[k].Tables) < len(got[k-1].Tables) {
+					t.Fatalf("notification %d has %d tables after %d", k, len(got[k].Tables), len(got[k-1].Tables))
+				}
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d notifications, the last one does not have %d saved tables", len(got), workers*rounds)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+)
+
+// ===== 錯誤模型 =====
+
+// ErrorCode 錯誤類別，前端可依此決定處理方式
+type ErrorCode string
+
+const (
+	ErrCodeNotFound        ErrorCode = "not_found"        // 資料表、欄位或檔案不存在
+	ErrCodeInvalidArgument ErrorCode = "invalid_argument" // 參數不正確
+	ErrCodeOutOfRange      ErrorCode = "out_of_range"     // 列或欄索引超出範圍
+	ErrCodeIO              ErrorCode = "io"               // 檔案讀寫失敗
+	ErrCodeParse           ErrorCode = "parse"            // 檔案內容無法解析
+	ErrCodeFormula         ErrorCode = "formula"          // CCL 公式錯誤
+	ErrCodeConflict        ErrorCode = "conflict"         // 狀態不允許此操作（例如沒有可復原的步驟）
+	ErrCodeInsyra          ErrorCode = "insyra"           // insyra 回報的錯誤
+	ErrCodeInternal        ErrorCode = "internal"         // 其他未分類的錯誤
+)
+
+// ServiceError 服務方法回傳的錯誤，經 Wails 的 ErrorFormatter 原樣傳給前端
+type ServiceError struct {
+	Code       ErrorCode      `json:"code"`
+	MessageKey string         `json:"messageKey"` // i18n 鍵，例如 "errors.table_not_found"
+	Message    string         `json:"message"`    // 英文說明，供記錄與找不到翻譯時使用
+	Details    map[string]any `json:"details,omitempty"`
+	Err        error          `json:"-"`
+}
+
+func (e *ServiceError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *ServiceError) Unwrap() error {
+	return e.Err
+}
+
+// WithDetail 附加一項細節（例如列、欄或公式）並回傳自身
+func (e *ServiceError) WithDetail(key string, value any) *ServiceError {
+	if e.Details == nil {
+		e.Details = make(map[string]any)
+	}
+	e.Details[key] = value
+	return e
+}
+
+// newError 建立錯誤；message 為英文說明
+func newError(code ErrorCode, messageKey string, format string, args ...any) *ServiceError {
+	return &ServiceError{Code: code, MessageKey: messageKey, Message: fmt.Sprintf(format, args...)}
+}
+
+// wrapError 以錯誤類別包裝底層錯誤；err 為 nil 時回傳 nil
+func wrapError(code ErrorCode, messageKey string, err error, format string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+	var se *ServiceError
+	if errors.As(err, &se) {
+		return err
+	}
+	e := newError(code, messageKey, format, args...)
+	e.Err = err
+	return e
+}
+
+// errTableNotFound 找不到指定ID的資料表
+func errTableNotFound(tableID string) *ServiceError {
+	return newError(ErrCodeNotFound, "errors.table_not_found", "table %q not found", tableID).
+		WithDetail("tableID", tableID)
+}
+
+// errTableNameNotFound 找不到指定名稱的資料表
+func errTableNameNotFound(tableName string) *ServiceError {
+	return newError(ErrCodeNotFound, "errors.table_not_found", "table %q not found", tableName).
+		WithDetail("tableName", tableName)
+}
+
+// errCellOutOfRange 儲存格位置超出資料表範圍
+func errCellOutOfRange(row, col, rowCount, colCount int) *ServiceError {
+	return newError(ErrCodeOutOfRange, "errors.cell_out_of_range",
+		"cell (%d, %d) is out of range (size %d x %d)", row, col, rowCount, colCount).
+		WithDetail("row", row).WithDetail("col", col)
+}
+
+// errColumnOutOfRange 欄索引超出資料表範圍
+func errColumnOutOfRange(col, colCount int) *ServiceError {
+	return newError(ErrCodeOutOfRange, "errors.column_out_of_range",
+		"column %d is out of range (size %d)", col, colCount).
+		WithDetail("col", col)
+}
+
+// invalidArgument 參數不正確
+func invalidArgument(format string, args ...any) *ServiceError {
+	return newError(ErrCodeInvalidArgument, "errors.invalid_argument", format, args...)
+}
+
+// errFileInvalid 二進位檔案（SPSS、Stata、Parquet、Arrow）的內容無法解析
+func errFileInvalid(format string, reason string) *ServiceError {
+	return newError(ErrCodeParse, "errors.file_invalid", "invalid %s file: %s", format, reason).
+		WithDetail("format", format)
+}
+
+// errFileUnsupported 檔案使用了不支援的版本或功能
+func errFileUnsupported(format string, feature string) *ServiceError {
+	return newError(ErrCodeParse, "errors.file_unsupported", "unsupported %s file: %s", format, feature).
+		WithDetail("format", format).WithDetail("feature", feature)
+}
+
+// AsServiceError 將任意錯誤轉為 ServiceError；已知的錯誤型別會補上對應的類別與細節
+func AsServiceError(err error) *ServiceError {
+	if err == nil {
+		return nil
+	}
+	var se *ServiceError
+	if errors.As(err, &se) {
+		if err == error(se) {
+			return se
+		}
+		// 外層另有包裝時保留完整訊息
+		out := *se
+		out.Details = maps.Clone(se.Details)
+		out.Message = err.Error()
+		out.Err = nil
+		return &out
+	}
+
+	var jsonErr *JSONImportError
+	var pathErr *fs.PathError
+	switch {
+	case errors.As(err, &jsonErr):
+		e := &ServiceError{Code: ErrCodeParse, MessageKey: "errors.json_invalid", Message: err.Error()}
+		if jsonErr.Line > 0 {
+			e.WithDetail("line", jsonErr.Line).WithDetail("column", jsonErr.Column)
+		}
+		if jsonErr.Record >= 0 {
+			e.WithDetail("record", jsonErr.Record)
+		}
+		return e
+	case errors.Is(err, ErrProjectNotFound):
+		return &ServiceError{Code: ErrCodeNotFound, MessageKey: "errors.project_not_found", Message: err.Error()}
+	case errors.Is(err, ErrProjectTooNew):
+		return &ServiceError{Code: ErrCodeParse, MessageKey: "errors.project_too_new", Message: err.Error()}
+	case errors.Is(err, ErrProjectCorrupt):
+		return &ServiceError{Code: ErrCodeParse, MessageKey: "errors.project_corrupt", Message: err.Error()}
+	case errors.Is(err, fs.ErrNotExist):
+		e := &ServiceError{Code: ErrCodeNotFound, MessageKey: "errors.file_not_found", Message: err.Error()}
+		if errors.As(err, &pathErr) {
+			e.WithDetail("path", pathErr.Path)
+		}
+		return e
+	case errors.As(err, &pathErr):
+		return (&ServiceError{Code: ErrCodeIO, MessageKey: "errors.io", Message: err.Error()}).
+			WithDetail("path", pathErr.Path)
+	}
+	return &ServiceError{Code: ErrCodeInternal, MessageKey: "errors.internal", Message: err.Error()}
+}
@@ -0,0 +1,415 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HazelnutParadise/insyra"
+	"github.com/xuri/excelize/v2"
+)
+
+// ===== Excel 匯入 =====
+
+// ExcelSheetInfo 工作表資訊
+type ExcelSheetInfo struct {
+	Name      string `json:"name"`
+	Visible   bool   `json:"visible"`
+	UsedRange string `json:"usedRange"` // 例如 "A1:D20"，空白工作表為空字串
+	Rows      int    `json:"rows"`
+	Columns   int    `json:"columns"`
+}
+
+// ExcelImportOptions Excel 匯入設定
+type ExcelImportOptions struct {
+	Sheet     string `json:"sheet"`     // 空字串表示第一個可見的工作表
+	Range     string `json:"range"`     // 儲存格範圍，例如 "B2:F100"；空字串表示已使用範圍
+	HeaderRow int    `json:"headerRow"` // 標題列的列號（從 1 開始，需位於範圍內）；0 表示沒有標題列
+}
+
+// excelRange 以 1 為起點的儲存格範圍
+type excelRange struct {
+	startCol, startRow, endCol, endRow int
+}
+
+func (r excelRange) String() string {
+	start, _ := excelize.CoordinatesToCellName(r.startCol, r.startRow)
+	end, _ := excelize.CoordinatesToCellName(r.endCol, r.endRow)
+	return start + ":" + end
+}
+
+// GetExcelSheets 列出活頁簿中的工作表與其已使用範圍
+func (s *DataTableService) GetExcelSheets(filePath string) ([]ExcelSheetInfo, error) {
+	f, err := excelize.OpenFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("open excel file: %w", err)
+	}
+	defer f.Close()
+
+	sheets := make([]ExcelSheetInfo, 0, f.SheetCount)
+	for _, name := range f.GetSheetList() {
+		info := ExcelSheetInfo{Name: name}
+		if info.Visible, err = f.GetSheetVisible(name); err != nil {
+			return nil, err
+		}
+		used, ok, err := excelUsedRange(f, name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			info.UsedRange = used.String()
+			info.Rows = used.endRow - used.startRow + 1
+			info.Columns = used.endCol - used.startCol + 1
+		}
+		sheets = append(sheets, info)
+	}
+	return sheets, nil
+}
+
+// OpenExcelFile 開啟 Excel 檔案的第一個可見工作表，以已使用範圍的第一列作為標題
+func (s *DataTableService) OpenExcelFile(filePath string) (string, error) {
+	f, err := excelize.OpenFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("open excel file: %w", err)
+	}
+	defer f.Close()
+
+	sheet, err := firstVisibleSheet(f)
+	if err != nil {
+		return "", err
+	}
+	used, ok, err := excelUsedRange(f, sheet)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", errSheetEmpty(sheet)
+	}
+	return s.importExcelSheet(f, filePath, ExcelImportOptions{Sheet: sheet, HeaderRow: used.startRow})
+}
+
+// OpenExcelFileWithOptions 以指定的工作表、範圍與標題列開啟 Excel 檔案
+func (s *DataTableService) OpenExcelFileWithOptions(filePath string, options ExcelImportOptions) (string, error) {
+	f, err := excelize.OpenFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("open excel file: %w", err)
+	}
+	defer f.Close()
+
+	if options.Sheet == "" {
+		if options.Sheet, err = firstVisibleSheet(f); err != nil {
+			return "", err
+		}
+	}
+	return s.importExcelSheet(f, filePath, options)
+}
+
+// importExcelSheet 讀取工作表範圍內的儲存格並建立新的資料表
+func (s *DataTableService) importExcelSheet(f *excelize.File, filePath string, options ExcelImportOptions) (string, error) {
+	sheet := options.Sheet
+	if idx, err := f.GetSheetIndex(sheet); err != nil || idx < 0 {
+		return "", newError(ErrCodeNotFound, "errors.sheet_not_found", "sheet %q not found", sheet).
+			WithDetail("sheet", sheet)
+	}
+
+	var rng excelRange
+	if options.Range != "" {
+		var err error
+		if rng, err = parseExcelRange(options.Range); err != nil {
+			return "", err
+		}
+	} else {
+		used, ok, err := excelUsedRange(f, sheet)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", errSheetEmpty(sheet)
+		}
+		rng = used
+	}
+
+	firstDataRow := rng.startRow
+	if options.HeaderRow != 0 {
+		if options.HeaderRow < rng.startRow || options.HeaderRow > rng.endRow {
+			return "", invalidArgument("header row %d is outside range %s", options.HeaderRow, rng).
+				WithDetail("headerRow", options.HeaderRow).WithDetail("range", rng.String())
+		}
+		firstDataRow = options.HeaderRow + 1
+	}
+
+	reader, err := newExcelCellReader(f, sheet)
+	if err != nil {
+		return "", err
+	}
+
+	colCount := rng.endCol - rng.startCol + 1
+	columns := make([][]any, colCount)
+	for row := firstDataRow; row <= rng.endRow; row++ {
+		for j := range colCount {
+			v, err := reader.value(rng.startCol+j, row)
+			if err != nil {
+				return "", err
+			}
+			columns[j] = append(columns[j], v)
+		}
+	}
+	columns = trimTrailingEmptyRows(columns)
+
+	lists := make([]*insyra.DataList, colCount)
+	for j := range colCount {
+		col := rng.startCol + j
+		letter, _ := excelize.ColumnNumberToName(col)
+		name := letter
+		if options.HeaderRow != 0 {
+			cell, _ := excelize.CoordinatesToCellName(col, options.HeaderRow)
+			header, err := f.GetCellValue(sheet, cell)
+			if err != nil {
+				return "", err
+			}
+			if header = strings.TrimSpace(header); header != "" {
+				name = header
+			}
+		}
+		lists[j] = insyra.NewDataList(columns[j]...).SetName(name)
+	}
+
+	dt := newTableFromColumns(lists)
+	if f.SheetCount > 1 {
+		dt.SetName(sheet)
+	} else {
+		dt.SetName(tableNameFromPath(filePath))
+	}
+	return s.appendImportedTable(dt), nil
+}
+
+// firstVisibleSheet 取得第一個可見的工作表名稱
+func firstVisibleSheet(f *excelize.File) (string, error) {
+	sheets := f.GetSheetList()
+	for _, name := range sheets {
+		if visible, err := f.GetSheetVisible(name); err == nil && visible {
+			return name, nil
+		}
+	}
+	if len(sheets) > 0 {
+		return sheets[0], nil
+	}
+	return "", newError(ErrCodeParse, "errors.workbook_empty", "workbook has no sheets")
+}
+
+// errSheetEmpty 工作表沒有任何值
+func errSheetEmpty(sheet string) *ServiceError {
+	return newError(ErrCodeInvalidArgument, "errors.sheet_empty", "sheet %q is empty", sheet).
+		WithDetail("sheet", sheet)
+}
+
+// errInvalidCellRange 無法解析的儲存格範圍
+func errInvalidCellRange(ref string, err error) *ServiceError {
+	e := invalidArgument("invalid cell range %q", ref).WithDetail("range", ref)
+	e.MessageKey = "errors.invalid_cell_range"
+	e.Err = err
+	return e
+}
+
+// excelUsedRange 計算工作表中實際含有值的範圍；工作表記錄的 dimension 常不可靠，因此逐列掃描
+func excelUsedRange(f *excelize.File, sheet string) (excelRange, bool, error) {
+	rows, err := f.GetRows(sheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return excelRange{}, false, err
+	}
+	used := excelRange{startCol: math.MaxInt, startRow: math.MaxInt}
+	found := false
+	for i, row := range rows {
+		for j, cell := range row {
+			if strings.TrimSpace(cell) == "" {
+				continue
+			}
+			found = true
+			used.startRow = min(used.startRow, i+1)
+			used.endRow = max(used.endRow, i+1)
+			used.startCol = min(used.startCol, j+1)
+			used.endCol = max(used.endCol, j+1)
+		}
+	}
+	return used, found, nil
+}
+
+// parseExcelRange 解析 "A1:D20" 形式的範圍；單一儲存格視為 1×1 範圍
+func parseExcelRange(ref string) (excelRange, error) {
+	parts := strings.Split(strings.ReplaceAll(strings.ToUpper(strings.TrimSpace(ref)), "$", ""), ":")
+	if len(parts) > 2 {
+		return excelRange{}, errInvalidCellRange(ref, nil)
+	}
+	startCol, startRow, err := excelize.CellNameToCoordinates(parts[0])
+	if err != nil {
+		return excelRange{}, errInvalidCellRange(ref, err)
+	}
+	endCol, endRow := startCol, startRow
+	if len(parts) == 2 {
+		if endCol, endRow, err = excelize.CellNameToCoordinates(parts[1]); err != nil {
+			return excelRange{}, errInvalidCellRange(ref, err)
+		}
+	}
+	return excelRange{
+		startCol: min(startCol, endCol),
+		startRow: min(startRow, endRow),
+		endCol:   max(startCol, endCol),
+		endRow:   max(startRow, endRow),
+	}, nil
+}
+
+// trimTrailingEmptyRows 移除範圍尾端整列皆為空的列
+func trimTrailingEmptyRows(columns [][]any) [][]any {
+	n := columnLength(columns)
+	for ; n > 0; n-- {
+		empty := true
+		for _, col := range columns {
+			if col[n-1] != nil {
+				empty = false
+				break
+			}
+		}
+		if !empty {
+			break
+		}
+	}
+	for j := range columns {
+		columns[j] = columns[j][:n]
+	}
+	return columns
+}
+
+// excelCellReader 讀取儲存格的原始值並依儲存格類型與數值格式轉為對應的 Go 型別
+type excelCellReader struct {
+	f          *excelize.File
+	sheet      string
+	date1904   bool
+	dateStyles map[int]bool
+}
+
+func newExcelCellReader(f *excelize.File, sheet string) (*excelCellReader, error) {
+	props, err := f.GetWorkbookProps()
+	if err != nil {
+		return nil, err
+	}
+	return &excelCellReader{
+		f:          f,
+		sheet:      sheet,
+		date1904:   props.Date1904 != nil && *props.Date1904,
+		dateStyles: make(map[int]bool),
+	}, nil
+}
+
+// value 取得儲存格的值：空白與錯誤值為 nil，數值為 int 或 float64，日期格式的數值為 time.Time
+func (r *excelCellReader) value(col, row int) (any, error) {
+	cell, err := excelize.CoordinatesToCellName(col, row)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := r.f.GetCellValue(r.sheet, cell, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, err
+	}
+	if raw == "" {
+		return nil, nil
+	}
+	cellType, err := r.f.GetCellType(r.sheet, cell)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cellType {
+	case excelize.CellTypeBool:
+		return raw == "1" || strings.EqualFold(raw, "true"), nil
+	case excelize.CellTypeError:
+		return nil, nil
+	case excelize.CellTypeSharedString, excelize.CellTypeInlineString, excelize.CellTypeFormula:
+		return raw, nil
+	case excelize.CellTypeDate:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+			if t, err := time.Parse(layout, raw); err == nil {
+				return t, nil
+			}
+		}
+		return raw, nil
+	}
+
+	num, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return raw, nil
+	}
+	isDate, err := r.isDateStyle(cell)
+	if err != nil {
+		return nil, err
+	}
+	if isDate {
+		if t, err := excelize.ExcelDateToTime(num, r.date1904); err == nil {
+			return t, nil
+		}
+	}
+	if num == math.Trunc(num) && math.Abs(num) < 1<<53 {
+		return int(num), nil
+	}
+	return num, nil
+}
+
+// isDateStyle 判斷儲存格的數值格式是否為日期或時間
+func (r *excelCellReader) isDateStyle(cell string) (bool, error) {
+	styleID, err := r.f.GetCellStyle(r.sheet, cell)
+	if err != nil {
+		return false, err
+	}
+	if isDate, ok := r.dateStyles[styleID]; ok {
+		return isDate, nil
+	}
+	isDate := false
+	if style, err := r.f.GetStyle(styleID); err == nil {
+		if style.CustomNumFmt != nil {
+			isDate = isDateFormatCode(*style.CustomNumFmt)
+		} else {
+			isDate = isDateNumFmtID(style.NumFmt)
+		}
+	}
+	r.dateStyles[styleID] = isDate
+	return isDate, nil
+}
+
+// isDateNumFmtID 判斷內建數值格式編號是否為日期或時間格式（含東亞語系的日期格式）
+func isDateNumFmtID(id int) bool {
+	return (id >= 14 && id <= 22) || (id >= 27 && id <= 36) ||
+		(id >= 45 && id <= 47) || (id >= 50 && id <= 58)
+}
+
+// isDateFormatCode 判斷自訂格式字串是否含有日期或時間的代碼；忽略引號內的文字與方括號內的設定
+func isDateFormatCode(code string) bool {
+	inQuote := false
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case c == '\\' || c == '_' || c == '*':
+			i++ // 下一個字元是字面值或填充字元
+		case c == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '[':
+			// [h]、[mm]、[ss] 是經過時間格式，其他方括號是顏色或語系設定
+			end := strings.IndexByte(code[i:], ']')
+			if end < 0 {
+				return false
+			}
+			token := strings.ToLower(code[i+1 : i+end])
+			if token != "" && strings.Trim(token, "hms") == "" {
+				return true
+			}
+			i += end
+		default:
+			switch c | 0x20 {
+			case 'y', 'm', 'd', 'h', 's':
+				return true
+			}
+		}
+	}
+	return false
+}
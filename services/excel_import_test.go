@@ -0,0 +1,227 @@
+package services
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// writeExcelFixture 建立測試用的活頁簿：第一個工作表隱藏；Data 的 A1 為標題，B3:D3 為欄名，資料在第 4 至 6 列
+func writeExcelFixture(t *testing.T) string {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(f.SetSheetName("Sheet1", "Hidden"))
+	must(f.SetCellValue("Hidden", "A1", "secret"))
+	data, err := f.NewSheet("Data")
+	must(err)
+	// 使用中的工作表無法隱藏
+	f.SetActiveSheet(data)
+	must(f.SetSheetVisible("Hidden", false))
+
+	dateStyle, err := f.NewStyle(&excelize.Style{NumFmt: 14})
+	must(err)
+	code := `yyyy-mm-dd hh:mm`
+	dateTimeStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: &code})
+	must(err)
+	numberStyle, err := f.NewStyle(&excelize.Style{NumFmt: 2})
+	must(err)
+
+	must(f.SetCellValue("Data", "A1", "Report"))
+	must(f.SetSheetRow("Data", "B3", &[]any{"id", " when ", "note"}))
+	for i, id := range []int{1, 2, 3} {
+		cell, _ := excelize.CoordinatesToCellName(2, 4+i)
+		must(f.SetCellInt("Data", cell, id))
+	}
+	must(f.SetCellFloat("Data", "C4", 45000, -1, 64))
+	must(f.SetCellStyle("Data", "C4", "C4", dateStyle))
+	must(f.SetCellFloat("Data", "C5", 45000.5, -1, 64))
+	must(f.SetCellStyle("Data", "C5", "C5", dateTimeStyle))
+	must(f.SetCellFloat("Data", "C6", 45000, -1, 64))
+	must(f.SetCellStyle("Data", "C6", "C6", numberStyle))
+	must(f.SetCellValue("Data", "D4", "x"))
+	must(f.SetCellBool("Data", "D5", true))
+	// 有格式但沒有值的儲存格不算在已使用範圍內
+	must(f.SetCellStyle("Data", "B9", "D9", numberStyle))
+
+	path := filepath.Join(t.TempDir(), "book.xlsx")
+	must(f.SaveAs(path))
+	return path
+}
+
+func TestGetExcelSheets(t *testing.T) {
+	s := NewDataTableService()
+	sheets, err := s.GetExcelSheets(writeExcelFixture(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ExcelSheetInfo{
+		{Name: "Hidden", Visible: false, UsedRange: "A1:A1", Rows: 1, Columns: 1},
+		{Name: "Data", Visible: true, UsedRange: "A1:D6", Rows: 6, Columns: 4},
+	}
+	if !reflect.DeepEqual(sheets, want) {
+		t.Errorf("GetExcelSheets() = %+v, want %+v", sheets, want)
+	}
+}
+
+func TestOpenExcelFileWithRangeAndHeader(t *testing.T) {
+	ConfigureInsyra()
+	path := writeExcelFixture(t)
+	s := NewDataTableService()
+	id, err := s.OpenExcelFileWithOptions(path, ExcelImportOptions{Range: "$D$8:b3", HeaderRow: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dt := s.getTableByID(id)
+	if got, want := columnNames(dt), []string{"id", "when", "note"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("names = %q, want %q", got, want)
+	}
+	if dt.GetName() != "Data" {
+		t.Errorf("table name = %q, want the sheet name", dt.GetName())
+	}
+	// 範圍尾端的空白列被移除
+	if rows, _ := dt.Size(); rows != 3 {
+		t.Errorf("rows = %d, want 3", rows)
+	}
+	if got, want := dt.GetColByNumber(0).Data(), []any{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("id column = %#v, want %#v", got, want)
+	}
+
+	// 沒有標題列時以欄位字母命名，範圍從第 4 列開始
+	id, err = s.OpenExcelFileWithOptions(path, ExcelImportOptions{Sheet: "Data", Range: "B4:C6"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dt = s.getTableByID(id)
+	if got, want := columnNames(dt), []string{"B", "C"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("names without a header = %q, want %q", got, want)
+	}
+	if rows, _ := dt.Size(); rows != 3 {
+		t.Errorf("rows without a header = %d, want 3", rows)
+	}
+
+	for _, options := range []ExcelImportOptions{
+		{Range: "B4:D6", HeaderRow: 3},
+		{Range: "A1:B2:C3"},
+		{Range: "nope"},
+		{Sheet: "Missing"},
+	} {
+		if _, err := s.OpenExcelFileWithOptions(path, options); err == nil {
+			t.Errorf("options %+v: want an error", options)
+		}
+	}
+}
+
+// 預設開啟第一個可見的工作表，以已使用範圍的第一列作為標題
+func TestOpenExcelFileUsesFirstVisibleSheet(t *testing.T) {
+	ConfigureInsyra()
+	s := NewDataTableService()
+	id, err := s.OpenExcelFile(writeExcelFixture(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dt := s.getTableByID(id)
+	if got, want := columnNames(dt), []string{"Report", "B", "C", "D"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("names = %q, want %q", got, want)
+	}
+	if rows, _ := dt.Size(); rows != 5 {
+		t.Errorf("rows = %d, want 5", rows)
+	}
+}
+
+// 1904 日期系統的活頁簿以 1904-01-01 為起點
+func TestExcelDate1904(t *testing.T) {
+	ConfigureInsyra()
+	f := excelize.NewFile()
+	date1904 := true
+	if err := f.SetWorkbookProps(&excelize.WorkbookPropsOptions{Date1904: &date1904}); err != nil {
+		t.Fatal(err)
+	}
+	style, err := f.NewStyle(&excelize.Style{NumFmt: 14})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetCellValue("Sheet1", "A1", "day")
+	f.SetCellFloat("Sheet1", "A2", 1, -1, 64)
+	f.SetCellStyle("Sheet1", "A2", "A2", style)
+	path := filepath.Join(t.TempDir(), "1904.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	s := NewDataTableService()
+	id, err := s.OpenExcelFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.getTableByID(id).GetElementByNumberIndex(0, 0), time.Date(1904, 1, 2, 0, 0, 0, 0, time.UTC); got != want {
+		t.Errorf("serial 1 = %v, want %v", got, want)
+	}
+}
+
+// 日期格式的數值轉為時間，一般數值格式保留為數值
+func TestExcelCellReaderDetectsDates(t *testing.T) {
+	f, err := excelize.OpenFile(writeExcelFixture(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := newExcelCellReader(f, "Data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		col, row int
+		want     any
+	}{
+		{2, 4, 1},
+		{3, 4, time.Date(2023, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{3, 5, time.Date(2023, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{3, 6, 45000},
+		{4, 4, "x"},
+		{4, 5, true},
+		{4, 6, nil},
+	}
+	for _, tt := range tests {
+		got, err := r.value(tt.col, tt.row)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("value(%d, %d) = %#v, want %#v", tt.col, tt.row, got, tt.want)
+		}
+	}
+}
+
+func TestIsDateFormatCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"yyyy-mm-dd", true},
+		{"hh:mm:ss", true},
+		{"[h]:mm", true},
+		{"[$-404]e/m/d", true},
+		{"0.00", false},
+		{"#,##0", false},
+		{"[Red]0.00", false},
+		{`0.0 "days"`, false},
+		{`0\d`, false},
+		{"_(* #,##0_)", false},
+	}
+	for _, tt := range tests {
+		if got := isDateFormatCode(tt.code); got != tt.want {
+			t.Errorf("isDateFormatCode(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"insyra-insights/config"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ===== 原生檔案對話框 =====
+
+const (
+	// defaultDialogKind 無法由篩選器判斷檔案種類時使用的名稱
+	defaultDialogKind = "default"
+	// directoryDialogKind 選擇資料夾對話框使用的名稱
+	directoryDialogKind = "directory"
+)
+
+// parseFileFilters 解析篩選器字串，格式為「顯示名稱|樣式」，多組篩選器以 | 串接，
+// 同一組的多個樣式以分號分隔，例如：
+//
+//	"Insyra 專案檔案 (*.insa)|*.insa"
+//	"SQLite 檔案 (*.db;*.sqlite)|*.db;*.sqlite|所有檔案 (*.*)|*.*"
+//
+// 只有樣式而沒有顯示名稱時，以樣式本身作為顯示名稱
+func parseFileFilters(filters string) []runtime.FileFilter {
+	parts := strings.Split(filters, "|")
+	var result []runtime.FileFilter
+	for i := 0; i < len(parts); i += 2 {
+		name := strings.TrimSpace(parts[i])
+		pattern := name
+		if i+1 < len(parts) {
+			pattern = strings.TrimSpace(parts[i+1])
+		}
+		if pattern == "" {
+			continue
+		}
+		// 允許以逗號或空白分隔樣式，統一轉為 Wails 使用的分號
+		pattern = strings.Join(strings.FieldsFunc(pattern, func(r rune) bool {
+			return r == ';' || r == ',' || r == ' '
+		}), ";")
+		if name == "" {
+			name = pattern
+		}
+		result = append(result, runtime.FileFilter{DisplayName: name, Pattern: pattern})
+	}
+	return result
+}
+
+// dialogKind 以第一個篩選器的第一個副檔名作為檔案種類，用於記住最後使用的資料夾
+func dialogKind(filters []runtime.FileFilter) string {
+	if ext := defaultExtension(filters); ext != "" {
+		return strings.ToLower(strings.TrimPrefix(ext, "."))
+	}
+	return defaultDialogKind
+}
+
+// defaultExtension 取得第一個篩選器的第一個具體副檔名（含點），例如 ".insa"
+func defaultExtension(filters []runtime.FileFilter) string {
+	if len(filters) == 0 {
+		return ""
+	}
+	for _, pattern := range strings.Split(filters[0].Pattern, ";") {
+		ext := filepath.Ext(pattern)
+		if ext != "" && !strings.ContainsAny(ext, "*?") {
+			return ext
+		}
+	}
+	return ""
+}
+
+// lastDirectory 取得檔案種類最後使用的資料夾；資料夾已不存在時回傳空字串
+func lastDirectory(kind string) string {
+	dir := config.GetLastDirectory(kind)
+	if dir == "" {
+		return ""
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return ""
+	}
+	return dir
+}
+
+// rememberDirectory 記錄檔案種類最後使用的資料夾並寫入設定檔
+func rememberDirectory(kind string, dir string) {
+	if dir == "" || config.GetLastDirectory(kind) == dir {
+		return
+	}
+	config.SetLastDirectory(kind, dir)
+	if err := config.Save(); err != nil {
+		log.Printf("儲存最後使用的資料夾失敗: %v", err)
+	}
+}
+
+// OpenFileDialog 開啟檔案選擇對話框，使用者取消時回傳空字串
+func (s *DataTableService) OpenFileDialog(ctx context.Context, filters string) (string, error) {
+	fileFilters := parseFileFilters(filters)
+	kind := dialogKind(fileFilters)
+
+	selected, err := runtime.OpenFileDialog(ctx, runtime.OpenDialogOptions{
+		DefaultDirectory: lastDirectory(kind),
+		Filters:          fileFilters,
+	})
+	if err != nil || selected == "" {
+		return "", err
+	}
+	rememberDirectory(kind, filepath.Dir(selected))
+	return selected, nil
+}
+
+// OpenMultipleFilesDialog 開啟可多選的檔案選擇對話框
+func (s *DataTableService) OpenMultipleFilesDialog(ctx context.Context, filters string) ([]string, error) {
+	fileFilters := parseFileFilters(filters)
+	kind := dialogKind(fileFilters)
+
+	selected, err := runtime.OpenMultipleFilesDialog(ctx, runtime.OpenDialogOptions{
+		DefaultDirectory: lastDirectory(kind),
+		Filters:          fileFilters,
+	})
+	if err != nil || len(selected) == 0 {
+		return []string{}, err
+	}
+	rememberDirectory(kind, filepath.Dir(selected[0]))
+	return selected, nil
+}
+
+// SaveFileDialog 開啟儲存檔案對話框；若使用者未輸入副檔名，補上篩選器的預設副檔名
+func (s *DataTableService) SaveFileDialog(ctx context.Context, filters string, defaultFilename string) (string, error) {
+	fileFilters := parseFileFilters(filters)
+	kind := dialogKind(fileFilters)
+
+	selected, err := runtime.SaveFileDialog(ctx, runtime.SaveDialogOptions{
+		DefaultDirectory: lastDirectory(kind),
+		DefaultFilename:  defaultFilename,
+		Filters:          fileFilters,
+	})
+	if err != nil || selected == "" {
+		return "", err
+	}
+	if ext := defaultExtension(fileFilters); ext != "" && filepath.Ext(selected) == "" {
+		selected += ext
+	}
+	rememberDirectory(kind, filepath.Dir(selected))
+	return selected, nil
+}
+
+// OpenDirectoryDialog 開啟資料夾選擇對話框
+func (s *DataTableService) OpenDirectoryDialog(ctx context.Context, title string) (string, error) {
+	selected, err := runtime.OpenDirectoryDialog(ctx, runtime.OpenDialogOptions{
+		DefaultDirectory:     lastDirectory(directoryDialogKind),
+		Title:                title,
+		CanCreateDirectories: true,
+	})
+	if err != nil || selected == "" {
+		return "", err
+	}
+	rememberDirectory(directoryDialogKind, selected)
+	return selected, nil
+}
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"encoding/binary"
+	"slices"
+)
+
+// ===== FlatBuffers =====
+//
+// Arrow IPC 的訊息與檔案結尾以 FlatBuffers 編碼。
+// 讀取時每次存取都檢查範圍，損毀的資料只會讀到預設值；寫出時由前往後排列，子物件一律放在參照它的欄位之後。
+
+// fbTable 讀取中的 FlatBuffers 表格
+type fbTable struct {
+	buf []byte
+	pos int
+}
+
+// fbRoot 取得緩衝區的根表格
+func fbRoot(buf []byte) (fbTable, bool) {
+	t := fbTable{buf: buf}
+	if !t.in(0, 4) {
+		return t, false
+	}
+	t.pos = int(binary.LittleEndian.Uint32(buf))
+	return t, t.in(t.pos, 4)
+}
+
+func (t fbTable) in(pos, n int) bool {
+	return pos >= 0 && n >= 0 && pos <= len(t.buf)-n
+}
+
+// field 回傳欄位在緩衝區中的位置，欄位不存在時為 0
+func (t fbTable) field(slot int) int {
+	if !t.in(t.pos, 4) {
+		return 0
+	}
+	vt := t.pos - int(int32(binary.LittleEndian.Uint32(t.buf[t.pos:])))
+	if !t.in(vt, 4) {
+		return 0
+	}
+	entry := 4 + 2*slot
+	if entry+2 > int(binary.LittleEndian.Uint16(t.buf[vt:])) || !t.in(vt+entry, 2) {
+		return 0
+	}
+	off := int(binary.LittleEndian.Uint16(t.buf[vt+entry:]))
+	if off == 0 {
+		return 0
+	}
+	return t.pos + off
+}
+
+func (t fbTable) u8(slot int, def uint8) uint8 {
+	if p := t.field(slot); p != 0 && t.in(p, 1) {
+		return t.buf[p]
+	}
+	return def
+}
+
+func (t fbTable) bool(slot int) bool {
+	return t.u8(slot, 0) != 0
+}
+
+func (t fbTable) i16(slot int, def int16) int16 {
+	if p := t.field(slot); p != 0 && t.in(p, 2) {
+		return int16(binary.LittleEndian.Uint16(t.buf[p:]))
+	}
+	return def
+}
+
+func (t fbTable) i32(slot int, def int32) int32 {
+	if p := t.field(slot); p != 0 && t.in(p, 4) {
+		return int32(binary.LittleEndian.Uint32(t.buf[p:]))
+	}
+	return def
+}
+
+func (t fbTable) i64(slot int, def int64) int64 {
+	if p := t.field(slot); p != 0 && t.in(p, 8) {
+		return int64(binary.LittleEndian.Uint64(t.buf[p:]))
+	}
+	return def
+}
+
+// ref 回傳參照欄位指向的位置，欄位不存在時為 -1
+func (t fbTable) ref(slot int) int {
+	p := t.field(slot)
+	if p == 0 || !t.in(p, 4) {
+		return -1
+	}
+	return t.deref(p)
+}
+
+func (t fbTable) deref(p int) int {
+	target := p + int(binary.LittleEndian.Uint32(t.buf[p:]))
+	if !t.in(target, 4) {
+		return -1
+	}
+	return target
+}
+
+// table 讀取子表格
+func (t fbTable) table(slot int) (fbTable, bool) {
+	p := t.ref(slot)
+	return fbTable{buf: t.buf, pos: p}, p >= 0
+}
+
+func (t fbTable) text(slot int) string {
+	p := t.ref(slot)
+	if p < 0 {
+		return ""
+	}
+	n := int(binary.LittleEndian.Uint32(t.buf[p:]))
+	if !t.in(p+4, n) {
+		return ""
+	}
+	return string(t.buf[p+4 : p+4+n])
+}
+
+// vector 回傳向量第一個元素的位置與元素數；超出範圍時元素數為 0
+func (t fbTable) vector(slot int, size int) (int, int) {
+	p := t.ref(slot)
+	if p < 0 {
+		return 0, 0
+	}
+	n := int(binary.LittleEndian.Uint32(t.buf[p:]))
+	if n > (len(t.buf)-p-4)/size {
+		return 0, 0
+	}
+	return p + 4, n
+}
+
+// tables 讀取表格向量
+func (t fbTable) tables(slot int) []fbTable {
+	start, n := t.vector(slot, 4)
+	out := make([]fbTable, 0, n)
+	for i := range n {
+		out = append(out, fbTable{buf: t.buf, pos: t.deref(start + 4*i)})
+	}
+	return out
+}
+
+// int64s 讀取由 int64 組成的結構向量（例如 Buffer、FieldNode、Block），每個元素有 words 個值
+func (t fbTable) int64s(slot int, words int) [][]int64 {
+	start, n := t.vector(slot, 8*words)
+	out := make([][]int64, n)
+	for i := range out {
+		out[i] = make([]int64, words)
+		for k := range words {
+			out[i][k] = int64(binary.LittleEndian.Uint64(t.buf[start+8*(i*words+k):]))
+		}
+	}
+	return out
+}
+
+// ----- 編碼 -----
+
+// fbSlot 寫出的一個欄位；值為 uint8、bool、int16、int32、int64、string、fbObject、[]fbObject 或 fbStructs
+type fbSlot struct {
+	slot  int
+	value any
+}
+
+// fbObject 寫出的表格
+type fbObject []fbSlot
+
+// fbStructs 寫出的結構向量，每個元素 size 個位元組，以 8 個位元組對齊
+type fbStructs struct {
+	size int
+	data []byte
+}
+
+type fbBuilder struct {
+	buf []byte
+}
+
+// buildFlatbuffer 將根表格編碼為長度為 8 的倍數的緩衝區
+func buildFlatbuffer(root fbObject) []byte {
+	b := &fbBuilder{buf: make([]byte, 4)}
+	pos := b.table(root)
+	binary.LittleEndian.PutUint32(b.buf, uint32(pos))
+	b.pad(8, 0)
+	return b.buf
+}
+
+// pad 補零直到長度加上 extra 為 align 的倍數
+func (b *fbBuilder) pad(align, extra int) {
+	for (len(b.buf)+extra)%align != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+func fbSize(value any) int {
+	switch value.(type) {
+	case uint8, bool:
+		return 1
+	case int16:
+		return 2
+	case int64:
+		return 8
+	}
+	return 4
+}
+
+// table 寫出 vtable 與表格，接著寫出子物件並回填參照
+func (b *fbBuilder) table(t fbObject) int {
+	fields := slices.Clone(t)
+	slices.SortStableFunc(fields, func(x, y fbSlot) int { return fbSize(y.value) - fbSize(x.value) })
+	offsets := make([]int, len(fields))
+	size, slots := 4, 0
+	for i, f := range fields {
+		n := fbSize(f.value)
+		for size%n != 0 {
+			size++
+		}
+		offsets[i] = size
+		size += n
+		slots = max(slots, f.slot+1)
+	}
+	for size%4 != 0 {
+		size++
+	}
+
+	vtSize := 4 + 2*slots
+	b.pad(8, vtSize)
+	vt := len(b.buf)
+	b.buf = binary.LittleEndian.AppendUint16(b.buf, uint16(vtSize))
+	b.buf = binary.LittleEndian.AppendUint16(b.buf, uint16(size))
+	b.buf = append(b.buf, make([]byte, 2*slots)...)
+	for i, f := range fields {
+		binary.LittleEndian.PutUint16(b.buf[vt+4+2*f.slot:], uint16(offsets[i]))
+	}
+	pos := len(b.buf)
+	b.buf = append(b.buf, make([]byte, size)...)
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(int32(pos-vt)))
+	for i, f := range fields {
+		p := pos + offsets[i]
+		switch v := f.value.(type) {
+		case uint8:
+			b.buf[p] = v
+		case bool:
+			if v {
+				b.buf[p] = 1
+			}
+		case int16:
+			binary.LittleEndian.PutUint16(b.buf[p:], uint16(v))
+		case int32:
+			binary.LittleEndian.PutUint32(b.buf[p:], uint32(v))
+		case int64:
+			binary.LittleEndian.PutUint64(b.buf[p:], uint64(v))
+		}
+	}
+	for i, f := range fields {
+		if fbSize(f.value) == 4 {
+			if _, scalar := f.value.(int32); !scalar {
+				p := pos + offsets[i]
+				child := b.child(f.value) // 寫出子物件可能重新配置緩衝區，之後才回填
+				binary.LittleEndian.PutUint32(b.buf[p:], uint32(child-p))
+			}
+		}
+	}
+	return pos
+}
+
+// child 寫出字串、子表格或向量並回傳其位置
+func (b *fbBuilder) child(value any) int {
+	switch v := value.(type) {
+	case string:
+		b.pad(4, 0)
+		pos := len(b.buf)
+		b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(len(v)))
+		b.buf = append(append(b.buf, v...), 0)
+		return pos
+	case fbObject:
+		return b.table(v)
+	case []fbObject:
+		b.pad(4, 0)
+		pos := len(b.buf)
+		b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(len(v)))
+		b.buf = append(b.buf, make([]byte, 4*len(v))...)
+		for i, item := range v {
+			p := pos + 4 + 4*i
+			child := b.table(item)
+			binary.LittleEndian.PutUint32(b.buf[p:], uint32(child-p))
+		}
+		return pos
+	case fbStructs:
+		b.pad(8, 4)
+		pos := len(b.buf)
+		b.buf = binary.LittleEndian.AppendUint32(b.buf, uint32(len(v.data)/v.size))
+		b.buf = append(b.buf, v.data...)
+		return pos
+	}
+	panic("unsupported flatbuffers value")
+}
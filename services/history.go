@@ -0,0 +1,311 @@
+package services
+
+import (
+	"maps"
+	"slices"
+
+	"insyra-insights/config"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+// ===== 復原／重做 =====
+
+// tableEdit 一個可復原的編輯步驟
+type tableEdit struct {
+	label string
+	undo  func(dt *insyra.DataTable)
+	redo  func(dt *insyra.DataTable)
+}
+
+// tableHistory 單一資料表的編輯歷程
+type tableHistory struct {
+	undo  []*tableEdit
+	redo  []*tableEdit
+	group *editGroup // 進行中的群組，結束時合併為一個步驟
+}
+
+// editGroup 合併為單一步驟的多個編輯，允許巢狀開啟
+type editGroup struct {
+	label   string
+	edits   []*tableEdit
+	depth   int
+	changed map[int]bool // 群組內編輯過的欄，結束時一次重新計算相依的計算欄位
+}
+
+// removedTable 已移除、可還原的資料表
+type removedTable struct {
+	index int // 移除前的標籤頁位置
+	id    string
+	dt    *insyra.DataTable
+}
+
+// HistoryState 資料表的復原／重做狀態，供前端更新按鈕
+type HistoryState struct {
+	CanUndo   bool   `json:"canUndo"`
+	CanRedo   bool   `json:"canRedo"`
+	UndoLabel string `json:"undoLabel"`
+	RedoLabel string `json:"redoLabel"`
+	UndoCount int    `json:"undoCount"`
+	RedoCount int    `json:"redoCount"`
+}
+
+// CellUpdate 批次更新中的單一儲存格
+type CellUpdate struct {
+	Row   int    `json:"row"`
+	Col   int    `json:"col"`
+	Value string `json:"value"`
+}
+
+// historyOf 取得資料表的編輯歷程，不存在時建立
+func (s *DataTableService) historyOf(dt *insyra.DataTable) *tableHistory {
+	h, ok := s.histories[dt]
+	if !ok {
+		h = &tableHistory{}
+		s.histories[dt] = h
+	}
+	return h
+}
+
+// record 記錄已套用的編輯；群組進行中時暫存於群組內
+func (s *DataTableService) record(dt *insyra.DataTable, label string, undo, redo func(dt *insyra.DataTable)) {
+	s.touch(dt)
+	h := s.historyOf(dt)
+	edit := &tableEdit{label: label, undo: undo, redo: redo}
+	if h.group != nil {
+		h.group.edits = append(h.group.edits, edit)
+		return
+	}
+	h.push(edit, s.undoLimit)
+}
+
+// push 放入復原堆疊並清空重做堆疊，超過 limit 時捨棄最舊的步驟
+func (h *tableHistory) push(edit *tableEdit, limit int) {
+	h.undo = append(h.undo, edit)
+	h.redo = nil
+	h.trim(limit)
+}
+
+// trim 只保留最新的 limit 個復原步驟
+func (h *tableHistory) trim(limit int) {
+	if len(h.undo) > limit {
+		h.undo = slices.Delete(h.undo, 0, len(h.undo)-limit)
+	}
+}
+
+// openGroup 開始一組編輯；已有群組進行中時併入該群組
+func (h *tableHistory) openGroup(label string) {
+	if h.group != nil {
+		h.group.depth++
+		return
+	}
+	h.group = &editGroup{label: label, depth: 1, changed: make(map[int]bool)}
+}
+
+// endGroup 結束一層群組，最外層結束時合併為一個步驟
+func (h *tableHistory) endGroup(limit int) {
+	if h.group.depth--; h.group.depth == 0 {
+		h.closeGroup(limit)
+	}
+}
+
+// endGroup 結束資料表的一層群組；最外層結束時重新計算群組內變更所影響的計算欄位
+func (s *DataTableService) endGroup(dt *insyra.DataTable) {
+	h := s.historyOf(dt)
+	changed := h.group.changed
+	h.endGroup(s.undoLimit)
+	if h.group == nil && len(changed) > 0 {
+		s.recomputeColumns(dt, slices.Collect(maps.Keys(changed)), false)
+	}
+}
+
+// closeGroup 將群組內的編輯合併為一個步驟
+func (h *tableHistory) closeGroup(limit int) {
+	group := h.group
+	h.group = nil
+	switch len(group.edits) {
+	case 0:
+		return
+	case 1:
+		group.edits[0].label = group.label
+		h.push(group.edits[0], limit)
+		return
+	}
+	edits := group.edits
+	h.push(&tableEdit{
+		label: group.label,
+		undo: func(dt *insyra.DataTable) {
+			for i := len(edits) - 1; i >= 0; i-- {
+				edits[i].undo(dt)
+			}
+		},
+		redo: func(dt *insyra.DataTable) {
+			for _, edit := range edits {
+				edit.redo(dt)
+			}
+		},
+	}, limit)
+}
+
+// BeginEditGroup 開始一組編輯，直到 EndEditGroup 前的所有變更視為一個復原步驟
+func (s *DataTableService) BeginEditGroup(tableID string, label string) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
+	}
+	s.historyOf(dt).openGroup(label)
+	return nil
+}
+
+// EndEditGroup 結束一組編輯
+func (s *DataTableService) EndEditGroup(tableID string) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
+	}
+	h := s.historyOf(dt)
+	if h.group == nil {
+		return newError(ErrCodeConflict, "errors.no_edit_group", "table %q has no open edit group", tableID).
+			WithDetail("tableID", tableID)
+	}
+	s.endGroup(dt)
+	return nil
+}
+
+// Undo 復原資料表的上一個步驟，沒有可復原的步驟時回傳 false
+func (s *DataTableService) Undo(tableID string) (bool, error) {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return false, errTableNotFound(tableID)
+	}
+	h := s.historyOf(dt)
+	if h.group != nil || len(h.undo) == 0 {
+		return false, nil
+	}
+	edit := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+	edit.undo(dt)
+	s.recomputeAll(dt)
+	h.redo = append(h.redo, edit)
+	s.touch(dt)
+	return true, nil
+}
+
+// Redo 重做資料表上一個被復原的步驟，沒有可重做的步驟時回傳 false
+func (s *DataTableService) Redo(tableID string) (bool, error) {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return false, errTableNotFound(tableID)
+	}
+	h := s.historyOf(dt)
+	if h.group != nil || len(h.redo) == 0 {
+		return false, nil
+	}
+	edit := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+	edit.redo(dt)
+	s.recomputeAll(dt)
+	h.undo = append(h.undo, edit)
+	s.touch(dt)
+	return true, nil
+}
+
+// GetHistoryState 取得資料表的復原／重做狀態
+func (s *DataTableService) GetHistoryState(tableID string) (HistoryState, error) {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return HistoryState{}, errTableNotFound(tableID)
+	}
+	h := s.historyOf(dt)
+	state := HistoryState{UndoCount: len(h.undo), RedoCount: len(h.redo)}
+	if h.group == nil {
+		state.CanUndo = len(h.undo) > 0
+		state.CanRedo = len(h.redo) > 0
+	}
+	if n := len(h.undo); n > 0 {
+		state.UndoLabel = h.undo[n-1].label
+	}
+	if n := len(h.redo); n > 0 {
+		state.RedoLabel = h.redo[n-1].label
+	}
+	return state, nil
+}
+
+// ClearHistory 清除資料表的編輯歷程
+func (s *DataTableService) ClearHistory(tableID string) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
+	}
+	delete(s.histories, dt)
+	return nil
+}
+
+// RestoreRemovedTable 還原最近一次移除的資料表（含其編輯歷程）到原本的位置，回傳其原本的ID
+func (s *DataTableService) RestoreRemovedTable() (string, error) {
+	s.lock()
+	defer s.unlock()
+	if len(s.removedTables) == 0 {
+		return "", newError(ErrCodeConflict, "errors.nothing_to_restore", "no removed table to restore")
+	}
+	removed := s.removedTables[len(s.removedTables)-1]
+	s.removedTables = s.removedTables[:len(s.removedTables)-1]
+	s.insertTable(min(removed.index, len(s.tabOrder)), removed.id, removed.dt)
+	s.markProjectModified()
+	return removed.id, nil
+}
+
+// rememberRemovedTable 保留被移除的資料表以便還原
+func (s *DataTableService) rememberRemovedTable(index int, tableID string, dt *insyra.DataTable) {
+	s.removedTables = append(s.removedTables, removedTable{index: index, id: tableID, dt: dt})
+	s.trimRemovedTables()
+}
+
+// trimRemovedTables 只保留最新的 undoLimit 個已移除的資料表，並釋放其餘資料表的歷程
+func (s *DataTableService) trimRemovedTables() {
+	if limit := s.undoLimit; len(s.removedTables) > limit {
+		for _, old := range s.removedTables[:len(s.removedTables)-limit] {
+			delete(s.histories, old.dt)
+			delete(s.revisions, old.dt)
+			delete(s.formulas, old.dt)
+			delete(s.columnIDs, old.dt)
+			delete(s.columnTypes, old.dt)
+			delete(s.missingPolicies, old.dt)
+			delete(s.variables, old.dt)
+		}
+		s.removedTables = slices.Delete(s.removedTables, 0, len(s.removedTables)-limit)
+	}
+}
+
+// SetUndoHistoryLimit 設定每個資料表最多保留的復原步驟數，0 以下使用預設值；
+// 調降時立即捨棄超出上限的步驟與已移除的資料表
+func (s *DataTableService) SetUndoHistoryLimit(limit int) {
+	s.lock()
+	defer s.unlock()
+	if limit <= 0 {
+		limit = config.DefaultUndoHistoryLimit
+	}
+	s.undoLimit = limit
+	for _, h := range s.histories {
+		h.trim(limit)
+	}
+	s.trimRemovedTables()
+}
+
+// resetHistory 清除所有資料表的編輯歷程與已移除的資料表（載入專案時使用）
+func (s *DataTableService) resetHistory() {
+	s.histories = make(map[*insyra.DataTable]*tableHistory)
+	s.removedTables = nil
+}
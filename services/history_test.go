@@ -0,0 +1,125 @@
+package services
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"insyra-insights/config"
+)
+
+func TestUndoHistoryLimitTrimsHistory(t *testing.T) {
+	s, id, _ := newStructureTestTable(t)
+	for i := range 5 {
+		if err := s.UpdateCellValueByID(id, 0, 0, strconv.Itoa(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.SetUndoHistoryLimit(2)
+	state, err := s.GetHistoryState(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.UndoCount != 2 {
+		t.Fatalf("undo count after lowering the limit = %d, want 2", state.UndoCount)
+	}
+	if err := s.UpdateCellValueByID(id, 0, 0, "9"); err != nil {
+		t.Fatal(err)
+	}
+	if state, _ := s.GetHistoryState(id); state.UndoCount != 2 {
+		t.Fatalf("undo count after another edit = %d, want 2", state.UndoCount)
+	}
+
+	s.SetUndoHistoryLimit(0)
+	if s.undoLimit != config.DefaultUndoHistoryLimit {
+		t.Errorf("limit 0 = %d, want the default %d", s.undoLimit, config.DefaultUndoHistoryLimit)
+	}
+}
+
+// 以 go test -race 執行時檢查編輯、讀取、復原與調整上限同時進行不會產生資料競爭
+func TestConcurrentEditsAndUndoLimit(t *testing.T) {
+	s, id, _ := newStructureTestTable(t)
+	const rounds = 50
+	var wg sync.WaitGroup
+	run := func(fn func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range rounds {
+				fn(i)
+			}
+		}()
+	}
+	run(func(i int) {
+		if err := s.UpdateCellValueByID(id, i%4, 0, strconv.Itoa(i)); err != nil {
+			t.Error(err)
+		}
+	})
+	run(func(int) {
+		if _, err := s.GetTableDataByID(id); err != nil {
+			t.Error(err)
+		}
+	})
+	run(func(int) {
+		if _, err := s.Undo(id); err != nil {
+			t.Error(err)
+		}
+	})
+	run(func(i int) {
+		// 與 App.SetUndoHistoryLimit 相同：更新設定後再交給服務
+		config.SetUndoHistoryLimit(i%5 + 1)
+		s.SetUndoHistoryLimit(config.GetUndoHistoryLimit())
+	})
+	wg.Wait()
+
+	state, err := s.GetHistoryState(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit := config.GetUndoHistoryLimit(); state.UndoCount > limit {
+		t.Errorf("undo count %d exceeds the limit %d", state.UndoCount, limit)
+	}
+}
+
+// 以名稱移除的資料表與以 ID 移除的相同，可以連同公式與歷程一起還原
+func TestRemoveTableByNameCanBeRestored(t *testing.T) {
+	s, id, dt := newStructureTestTable(t)
+	if err := s.UpdateCellValueByID(id, 0, 0, "9"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RemoveTable(dt.GetName()); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := s.RestoreRemovedTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored != id || s.getTableByID(id) != dt {
+		t.Fatalf("RestoreRemovedTable() = %q, want %q", restored, id)
+	}
+	if state, _ := s.GetHistoryState(id); state.UndoCount == 0 {
+		t.Error("history was not kept")
+	}
+	if len(s.formulas[dt]) == 0 {
+		t.Error("formulas were not kept")
+	}
+
+	// 超出上限而無法還原的資料表，其歷程與修訂號一併釋放
+	s.SetUndoHistoryLimit(1)
+	if err := s.RemoveTable(dt.GetName()); err != nil {
+		t.Fatal(err)
+	}
+	other, err := s.CreateEmptyTableByID(-1, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RemoveTableByID(other); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.histories[dt]; ok {
+		t.Error("history of a table that can no longer be restored was kept")
+	}
+	if _, ok := s.revisions[dt]; ok {
+		t.Error("revision of a table that can no longer be restored was kept")
+	}
+}
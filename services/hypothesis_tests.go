@@ -0,0 +1,512 @@
+package services
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/HazelnutParadise/insyra"
+	"github.com/HazelnutParadise/insyra/stats"
+)
+
+// ===== 假設檢定 =====
+//
+// 每個檢定只納入所需欄位皆非缺失的列；數值欄中出現無法轉為數值的值時回傳錯誤。
+// 結果可另外以 CreateTestResultTable 寫成新的標籤頁。
+
+// 檢定種類
+const (
+	TestOneSampleT    = "one_sample_t"
+	TestPairedT       = "paired_t"
+	TestIndependentT  = "independent_t"
+	TestOneWayANOVA   = "one_way_anova"
+	TestChiSquareIndp = "chi_square_independence"
+)
+
+// defaultConfidenceLevel 未指定信賴水準時使用的預設值
+const defaultConfidenceLevel = 0.95
+
+// TestResult 假設檢定的結果；無法計算的數值為 null
+type TestResult struct {
+	Test            string              `json:"test"`
+	Title           string              `json:"title"`
+	Columns         []string            `json:"columns"`       // 參與檢定的欄名
+	StatisticName   string              `json:"statisticName"` // "t"、"F" 或 "chi2"
+	Statistic       *float64            `json:"statistic"`
+	DF              *float64            `json:"df"`
+	DF2             *float64            `json:"df2"` // F 檢定的組內自由度
+	PValue          *float64            `json:"pValue"`
+	ConfidenceLevel *float64            `json:"confidenceLevel"`
+	CI              *ConfidenceInterval `json:"ci"` // t 檢定的平均數（或平均差）信賴區間
+	EffectSizes     []EffectSize        `json:"effectSizes"`
+	Groups          []GroupSummary      `json:"groups"`
+	N               int                 `json:"n"`        // 納入檢定的列數
+	Excluded        int                 `json:"excluded"` // 因缺失值排除的列數
+}
+
+// ConfidenceInterval 信賴區間
+type ConfidenceInterval struct {
+	Lower *float64 `json:"lower"`
+	Upper *float64 `json:"upper"`
+}
+
+// EffectSize 效果量，例如 cohen_d、eta_squared、cramers_v
+type EffectSize struct {
+	Type  string   `json:"type"`
+	Value *float64 `json:"value"`
+}
+
+// GroupSummary 各組（或各欄）的摘要
+type GroupSummary struct {
+	Name string   `json:"name"`
+	N    int      `json:"n"`
+	Mean *float64 `json:"mean"`
+	Std  *float64 `json:"std"`
+}
+
+// OneSampleTTest 單一樣本 t 檢定：檢定欄位平均數是否等於 mu；confidenceLevel 為 0 時使用 0.95
+func (s *StatisticsService) OneSampleTTest(tableID string, colIndex int, mu float64, confidenceLevel float64) (TestResult, error) {
+	s.data.lock()
+	defer s.data.unlock()
+	dt, cl, err := s.prepareTest(tableID, confidenceLevel)
+	if err != nil {
+		return TestResult{}, err
+	}
+	sample, err := numericSample(dt, []int{colIndex})
+	if err != nil {
+		return TestResult{}, err
+	}
+	values := sample.columns[0]
+
+	var r *stats.TTestResult
+	messages := captureInsyra(func() {
+		r = stats.SingleSampleTTest(insyra.NewDataList(values), mu, cl)
+	})
+	if r == nil {
+		return TestResult{}, errTestFailed(TestOneSampleT, messages)
+	}
+	result := tTestResult(TestOneSampleT, r, cl, sample.rowSample)
+	result.Title = fmt.Sprintf("One-sample t-test (%s, mu = %g)", sample.names[0], mu)
+	result.Groups = []GroupSummary{summarize(sample.names[0], values)}
+	return result, nil
+}
+
+// PairedTTest 成對樣本 t 檢定：檢定兩欄差值的平均數是否為 0
+func (s *StatisticsService) PairedTTest(tableID string, col1 int, col2 int, confidenceLevel float64) (TestResult, error) {
+	s.data.lock()
+	defer s.data.unlock()
+	dt, cl, err := s.prepareTest(tableID, confidenceLevel)
+	if err != nil {
+		return TestResult{}, err
+	}
+	sample, err := numericSample(dt, []int{col1, col2})
+	if err != nil {
+		return TestResult{}, err
+	}
+
+	var r *stats.TTestResult
+	messages := captureInsyra(func() {
+		r = stats.PairedTTest(insyra.NewDataList(sample.columns[0]), insyra.NewDataList(sample.columns[1]), cl)
+	})
+	if r == nil {
+		return TestResult{}, errTestFailed(TestPairedT, messages)
+	}
+	result := tTestResult(TestPairedT, r, cl, sample.rowSample)
+	result.Title = fmt.Sprintf("Paired t-test (%s, %s)", sample.names[0], sample.names[1])
+	result.Groups = []GroupSummary{
+		summarize(sample.names[0], sample.columns[0]),
+		summarize(sample.names[1], sample.columns[1]),
+	}
+	return result, nil
+}
+
+// IndependentTTest 獨立樣本 t 檢定：依 groupCol 的兩個組別比較 valueCol 的平均數；
+// equalVariance 為 false 時使用 Welch 校正
+func (s *StatisticsService) IndependentTTest(tableID string, valueCol int, groupCol int, equalVariance bool, confidenceLevel float64) (TestResult, error) {
+	s.data.lock()
+	defer s.data.unlock()
+	dt, cl, err := s.prepareTest(tableID, confidenceLevel)
+	if err != nil {
+		return TestResult{}, err
+	}
+	sample, err := groupedSample(dt, valueCol, groupCol)
+	if err != nil {
+		return TestResult{}, err
+	}
+	if len(sample.levels) != 2 {
+		return TestResult{}, invalidArgument("grouping column %q must have exactly 2 groups, found %d", sample.groupName, len(sample.levels)).
+			WithDetail("col", groupCol).
+			WithDetail("groups", sample.levels)
+	}
+
+	var r *stats.TTestResult
+	messages := captureInsyra(func() {
+		r = stats.TwoSampleTTest(insyra.NewDataList(sample.groups[0]), insyra.NewDataList(sample.groups[1]), equalVariance, cl)
+	})
+	if r == nil {
+		return TestResult{}, errTestFailed(TestIndependentT, messages)
+	}
+	result := tTestResult(TestIndependentT, r, cl, sample.rowSample)
+	result.Title = fmt.Sprintf("Independent t-test (%s by %s)", sample.valueName, sample.groupName)
+	result.Groups = sample.summaries()
+	return result, nil
+}
+
+// OneWayANOVA 單因子變異數分析：依 groupCol 的組別比較 valueCol 的平均數
+func (s *StatisticsService) OneWayANOVA(tableID string, valueCol int, groupCol int) (TestResult, error) {
+	s.data.lock()
+	defer s.data.unlock()
+	dt, _, err := s.prepareTest(tableID, 0)
+	if err != nil {
+		return TestResult{}, err
+	}
+	sample, err := groupedSample(dt, valueCol, groupCol)
+	if err != nil {
+		return TestResult{}, err
+	}
+	if len(sample.levels) < 2 {
+		return TestResult{}, invalidArgument("grouping column %q must have at least 2 groups, found %d", sample.groupName, len(sample.levels)).
+			WithDetail("col", groupCol).
+			WithDetail("groups", sample.levels)
+	}
+
+	groups := make([]insyra.IDataList, len(sample.groups))
+	for i, g := range sample.groups {
+		groups[i] = insyra.NewDataList(g)
+	}
+	var r *stats.OneWayANOVAResult
+	messages := captureInsyra(func() { r = stats.OneWayANOVA(groups...) })
+	if r == nil {
+		return TestResult{}, errTestFailed(TestOneWayANOVA, messages)
+	}
+
+	// omega² = (SSB - dfB·MSW) / (SST + MSW)
+	msw := r.Within.SumOfSquares / float64(r.Within.DF)
+	omega := (r.Factor.SumOfSquares - float64(r.Factor.DF)*msw) / (r.TotalSS + msw)
+	return TestResult{
+		Test:          TestOneWayANOVA,
+		Title:         fmt.Sprintf("One-way ANOVA (%s by %s)", sample.valueName, sample.groupName),
+		Columns:       []string{sample.valueName, sample.groupName},
+		StatisticName: "F",
+		Statistic:     finite(r.Factor.F),
+		DF:            finite(float64(r.Factor.DF)),
+		DF2:           finite(float64(r.Within.DF)),
+		PValue:        finite(r.Factor.P),
+		EffectSizes: []EffectSize{
+			{Type: "eta_squared", Value: finite(r.Factor.EtaSquared)},
+			{Type: "omega_squared", Value: finite(omega)},
+		},
+		Groups:   sample.summaries(),
+		N:        sample.n,
+		Excluded: sample.excluded,
+	}, nil
+}
+
+// ChiSquareIndependence 卡方獨立性檢定：檢定兩個類別欄位是否獨立
+func (s *StatisticsService) ChiSquareIndependence(tableID string, col1 int, col2 int) (TestResult, error) {
+	s.data.lock()
+	defer s.data.unlock()
+	dt, _, err := s.prepareTest(tableID, 0)
+	if err != nil {
+		return TestResult{}, err
+	}
+	sample, err := categoricalSample(dt, []int{col1, col2})
+	if err != nil {
+		return TestResult{}, err
+	}
+	rows, cols := sample.columns[0], sample.columns[1]
+
+	var r *stats.ChiSquareTestResult
+	messages := captureInsyra(func() {
+		r = stats.ChiSquareIndependenceTest(insyra.NewDataList(rows), insyra.NewDataList(cols))
+	})
+	if r == nil || r.DF == nil {
+		return TestResult{}, errTestFailed(TestChiSquareIndp, messages)
+	}
+
+	// Cramér's V = sqrt(chi2 / (n·(min(r, c) - 1)))
+	k := min(countLevels(rows), countLevels(cols)) - 1
+	cramersV := math.Sqrt(r.Statistic / (float64(sample.n) * float64(k)))
+	return TestResult{
+		Test:          TestChiSquareIndp,
+		Title:         fmt.Sprintf("Chi-square test of independence (%s, %s)", sample.names[0], sample.names[1]),
+		Columns:       slices.Clone(sample.names),
+		StatisticName: "chi2",
+		Statistic:     finite(r.Statistic),
+		DF:            finite(*r.DF),
+		PValue:        finite(r.PValue),
+		EffectSizes:   []EffectSize{{Type: "cramers_v", Value: finite(cramersV)}},
+		Groups:        []GroupSummary{},
+		N:             sample.n,
+		Excluded:      sample.excluded,
+	}, nil
+}
+
+// CreateTestResultTable 將檢定結果寫成新的資料表（標籤頁），回傳新資料表的ID
+func (s *StatisticsService) CreateTestResultTable(result TestResult) (string, error) {
+	s.data.lock()
+	defer s.data.unlock()
+	if result.Test == "" {
+		return "", invalidArgument("test result is empty")
+	}
+	var items, values []any
+	add := func(item string, value any) {
+		items = append(items, item)
+		values = append(values, value)
+	}
+	addNumber := func(item string, value *float64) {
+		if value != nil {
+			add(item, *value)
+		}
+	}
+
+	add("test", result.Test)
+	add("variables", strings.Join(result.Columns, ", "))
+	add("n", result.N)
+	add("excluded", result.Excluded)
+	addNumber(result.StatisticName, result.Statistic)
+	addNumber("df", result.DF)
+	addNumber("df2", result.DF2)
+	addNumber("p", result.PValue)
+	if result.CI != nil && result.ConfidenceLevel != nil {
+		percent := *result.ConfidenceLevel * 100
+		addNumber(fmt.Sprintf("ci%g_lower", percent), result.CI.Lower)
+		addNumber(fmt.Sprintf("ci%g_upper", percent), result.CI.Upper)
+	}
+	for _, e := range result.EffectSizes {
+		addNumber(e.Type, e.Value)
+	}
+	for _, g := range result.Groups {
+		add(g.Name+" n", g.N)
+		addNumber(g.Name+" mean", g.Mean)
+		addNumber(g.Name+" std", g.Std)
+	}
+
+	dt := insyra.NewDataTable(
+		insyra.NewDataList(items...).SetName("Item"),
+		insyra.NewDataList(values...).SetName("Value"),
+	)
+	dt.SetName(result.Title)
+	return s.data.appendTable(dt), nil
+}
+
+// prepareTest 取得資料表並檢查信賴水準；confidenceLevel 為 0 時使用預設值
+func (s *StatisticsService) prepareTest(tableID string, confidenceLevel float64) (*insyra.DataTable, float64, error) {
+	dt := s.data.getTableByID(tableID)
+	if dt == nil {
+		return nil, 0, errTableNotFound(tableID)
+	}
+	if confidenceLevel == 0 {
+		confidenceLevel = defaultConfidenceLevel
+	}
+	if confidenceLevel <= 0 || confidenceLevel >= 1 {
+		return nil, 0, invalidArgument("confidence level must be between 0 and 1, got %g", confidenceLevel).
+			WithDetail("confidenceLevel", confidenceLevel)
+	}
+	return dt, confidenceLevel, nil
+}
+
+// tTestResult 將 insyra 的 t 檢定結果轉為 TestResult
+func tTestResult(test string, r *stats.TTestResult, cl float64, sample rowSample) TestResult {
+	result := TestResult{
+		Test:            test,
+		Columns:         slices.Clone(sample.names),
+		StatisticName:   "t",
+		Statistic:       finite(r.Statistic),
+		PValue:          finite(r.PValue),
+		ConfidenceLevel: &cl,
+		EffectSizes:     make([]EffectSize, len(r.EffectSizes)),
+		N:               sample.n,
+		Excluded:        sample.excluded,
+	}
+	if r.DF != nil {
+		result.DF = finite(*r.DF)
+	}
+	if r.CI != nil {
+		result.CI = &ConfidenceInterval{Lower: finite(r.CI[0]), Upper: finite(r.CI[1])}
+	}
+	for i, e := range r.EffectSizes {
+		result.EffectSizes[i] = EffectSize{Type: e.Type, Value: finite(e.Value)}
+	}
+	return result
+}
+
+// errTestFailed insyra 無法完成檢定（例如樣本數不足）
+func errTestFailed(test string, messages []InsyraMessage) *ServiceError {
+	e := newError(ErrCodeInsyra, "errors.test_failed", "%s test could not be computed", test).
+		WithDetail("test", test)
+	if len(messages) > 0 {
+		e.Message += ": " + messages[len(messages)-1].Message
+		e.WithDetail("insyra", messages)
+	}
+	return e
+}
+
+// errColumnNotNumeric 數值欄位中含有非數值的值
+func errColumnNotNumeric(col int, name string, row int, value any) *ServiceError {
+	return newError(ErrCodeInvalidArgument, "errors.column_not_numeric",
+		"column %q has a non-numeric value %q at row %d", name, fmt.Sprint(value), row).
+		WithDetail("col", col).
+		WithDetail("row", row)
+}
+
+// ----- 取出樣本 -----
+
+// rowSample 依列取出的多個欄位，已排除任一欄缺失的列
+type rowSample struct {
+	names    []string
+	columns  [][]any
+	rows     []int // 各列在資料表中的索引
+	n        int
+	excluded int
+}
+
+// completeRows 取出各欄位中所有欄皆非缺失的列
+func completeRows(dt *insyra.DataTable, cols []int) (rowSample, error) {
+	rowCount, colCount := dt.Size()
+	data := make([][]any, len(cols))
+	sample := rowSample{names: make([]string, len(cols)), columns: make([][]any, len(cols))}
+	for k, c := range cols {
+		if c < 0 || c >= colCount {
+			return rowSample{}, errColumnOutOfRange(c, colCount)
+		}
+		col := dt.GetColByNumber(c)
+		sample.names[k] = col.GetName()
+		data[k] = col.Data()
+	}
+	for i := range rowCount {
+		complete := true
+		for k := range cols {
+			if i >= len(data[k]) || isMissingValue(data[k][i]) {
+				complete = false
+				break
+			}
+		}
+		if !complete {
+			sample.excluded++
+			continue
+		}
+		for k := range cols {
+			sample.columns[k] = append(sample.columns[k], data[k][i])
+		}
+		sample.rows = append(sample.rows, i)
+		sample.n++
+	}
+	return sample, nil
+}
+
+// numericRows 依列取出的數值欄位
+type numericRows struct {
+	rowSample
+	columns [][]float64
+}
+
+// numericSample 取出數值欄位中所有欄皆非缺失的列
+func numericSample(dt *insyra.DataTable, cols []int) (numericRows, error) {
+	sample, err := completeRows(dt, cols)
+	if err != nil {
+		return numericRows{}, err
+	}
+	result := numericRows{rowSample: sample, columns: make([][]float64, len(cols))}
+	for k, values := range sample.columns {
+		result.columns[k] = make([]float64, len(values))
+		for i, v := range values {
+			f, ok := toNumber(v)
+			if !ok {
+				return numericRows{}, errColumnNotNumeric(cols[k], sample.names[k], sample.rows[i], v)
+			}
+			result.columns[k][i] = f
+		}
+	}
+	return result, nil
+}
+
+// categoricalSample 取出類別欄位，各值以文字表示
+func categoricalSample(dt *insyra.DataTable, cols []int) (rowSample, error) {
+	sample, err := completeRows(dt, cols)
+	if err != nil {
+		return rowSample{}, err
+	}
+	for _, values := range sample.columns {
+		for i, v := range values {
+			values[i] = fmt.Sprint(v)
+		}
+	}
+	return sample, nil
+}
+
+// groupedRows 依組別欄分組的數值欄
+type groupedRows struct {
+	rowSample
+	valueName string
+	groupName string
+	levels    []string    // 依數值或文字排序的組別
+	groups    [][]float64 // 與 levels 對應的各組數值
+}
+
+// groupedSample 依 groupCol 的值將 valueCol 分組
+func groupedSample(dt *insyra.DataTable, valueCol int, groupCol int) (groupedRows, error) {
+	sample, err := completeRows(dt, []int{valueCol, groupCol})
+	if err != nil {
+		return groupedRows{}, err
+	}
+	result := groupedRows{rowSample: sample, valueName: sample.names[0], groupName: sample.names[1]}
+	byLevel := make(map[string][]float64)
+	for i, v := range sample.columns[0] {
+		f, ok := toNumber(v)
+		if !ok {
+			return groupedRows{}, errColumnNotNumeric(valueCol, result.valueName, sample.rows[i], v)
+		}
+		level := fmt.Sprint(sample.columns[1][i])
+		byLevel[level] = append(byLevel[level], f)
+	}
+	for level := range byLevel {
+		result.levels = append(result.levels, level)
+	}
+	slices.SortFunc(result.levels, compareLevels)
+	for _, level := range result.levels {
+		result.groups = append(result.groups, byLevel[level])
+	}
+	return result, nil
+}
+
+// summaries 各組的摘要
+func (g groupedRows) summaries() []GroupSummary {
+	summaries := make([]GroupSummary, len(g.levels))
+	for i, level := range g.levels {
+		summaries[i] = summarize(level, g.groups[i])
+	}
+	return summaries
+}
+
+// summarize 計算一組數值的個數、平均數與標準差
+func summarize(name string, values []float64) GroupSummary {
+	summary := GroupSummary{Name: name, N: len(values)}
+	captureInsyra(func() {
+		dl := insyra.NewDataList(values)
+		summary.Mean = finite(dl.Mean())
+		summary.Std = finite(dl.Stdev())
+	})
+	return summary
+}
+
+// compareLevels 排序組別：都是數值時依數值大小，否則依文字
+func compareLevels(a, b string) int {
+	fa, okA := toNumber(a)
+	fb, okB := toNumber(b)
+	if okA && okB {
+		return cmp.Compare(fa, fb)
+	}
+	return strings.Compare(a, b)
+}
+
+// countLevels 計算不重複值的個數
+func countLevels(values []any) int {
+	seen := make(map[any]bool)
+	for _, v := range values {
+		seen[v] = true
+	}
+	return len(seen)
+}
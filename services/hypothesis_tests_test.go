@@ -0,0 +1,162 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+// 參考值由 testdata/stats_reference.py 依公式獨立計算，p 值以不完全 beta／gamma 函數求得
+
+// newStatisticsTestTable 建立只含指定欄位的資料表
+func newStatisticsTestTable(t *testing.T, columns ...*insyra.DataList) (*StatisticsService, string) {
+	t.Helper()
+	ConfigureInsyra()
+	data := NewDataTableService()
+	data.lock()
+	id := data.appendTable(insyra.NewDataTable(columns...))
+	data.unlock()
+	return NewStatisticsService(data), id
+}
+
+// checkClose 比對數值與參考值，容許 1e-9 的相對誤差
+func checkClose(t *testing.T, name string, got *float64, want float64) {
+	t.Helper()
+	if got == nil {
+		t.Errorf("%s = null, want %v", name, want)
+		return
+	}
+	if math.Abs(*got-want) > 1e-9*math.Max(1, math.Abs(want)) {
+		t.Errorf("%s = %v, want %v", name, *got, want)
+	}
+}
+
+func checkEffectSize(t *testing.T, result TestResult, kind string, want float64) {
+	t.Helper()
+	for _, e := range result.EffectSizes {
+		if e.Type == kind {
+			checkClose(t, kind, e.Value, want)
+			return
+		}
+	}
+	t.Errorf("effect sizes %+v have no %s", result.EffectSizes, kind)
+}
+
+func TestOneSampleTTest(t *testing.T) {
+	s, id := newStatisticsTestTable(t, insyra.NewDataList(5.1, 4.9, 5.6, 5.8, 6.0, 5.3, 5.7, 6.1, nil).SetName("x"))
+	r, err := s.OneSampleTTest(id, 0, 5, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.N != 8 || r.Excluded != 1 {
+		t.Errorf("n = %d, excluded = %d; want 8, 1", r.N, r.Excluded)
+	}
+	checkClose(t, "t", r.Statistic, 3.722405806106751)
+	checkClose(t, "df", r.DF, 7)
+	checkClose(t, "p", r.PValue, 0.007432530122421497)
+	checkClose(t, "ci lower", r.CI.Lower, 5.205177018357628)
+	checkClose(t, "ci upper", r.CI.Upper, 5.919822981642372)
+	checkEffectSize(t, r, "cohen_d", 1.3160691939131302)
+
+	if _, err := s.OneSampleTTest(id, 0, 5, 1.5); err == nil {
+		t.Error("confidence level 1.5: want an error")
+	}
+}
+
+func TestPairedTTest(t *testing.T) {
+	s, id := newStatisticsTestTable(t,
+		insyra.NewDataList(12.1, 11.4, 13.2, 10.8, 12.7, 11.9).SetName("before"),
+		insyra.NewDataList(11.3, 11.0, 12.1, 10.9, 11.8, 11.2).SetName("after"),
+	)
+	r, err := s.PairedTTest(id, 0, 1, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClose(t, "t", r.Statistic, 3.629763419529136)
+	checkClose(t, "df", r.DF, 5)
+	checkClose(t, "p", r.PValue, 0.015064298997667907)
+	checkClose(t, "ci lower", r.CI.Lower, 0.18480956624416622)
+	checkClose(t, "ci upper", r.CI.Upper, 1.0818571004225002)
+}
+
+func TestIndependentTTest(t *testing.T) {
+	s, id := newStatisticsTestTable(t,
+		insyra.NewDataList(20.1, 22.3, 19.8, 21.5, 23.0, 20.7, 18.2, 19.5, 17.9, 20.1, 18.8).SetName("score"),
+		insyra.NewDataList("a", "a", "a", "a", "a", "a", "b", "b", "b", "b", "b").SetName("group"),
+	)
+	pooled, err := s.IndependentTTest(id, 0, 1, true, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClose(t, "pooled t", pooled.Statistic, 3.4460512591200043)
+	checkClose(t, "pooled df", pooled.DF, 9)
+	checkClose(t, "pooled p", pooled.PValue, 0.007320211626219312)
+	checkClose(t, "pooled ci lower", pooled.CI.Lower, 0.8016188240884228)
+	checkClose(t, "pooled ci upper", pooled.CI.Upper, 3.8650478425782486)
+	if len(pooled.Groups) != 2 || pooled.Groups[0].Name != "a" || pooled.Groups[0].N != 6 || pooled.Groups[1].N != 5 {
+		t.Errorf("groups = %+v", pooled.Groups)
+	}
+
+	welch, err := s.IndependentTTest(id, 0, 1, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClose(t, "welch t", welch.Statistic, 3.5578403348241032)
+	checkClose(t, "welch df", welch.DF, 8.867147567338757)
+	checkClose(t, "welch p", welch.PValue, 0.00628760056376712)
+	checkClose(t, "welch ci lower", welch.CI.Lower, 0.8463507932090133)
+	checkClose(t, "welch ci upper", welch.CI.Upper, 3.820315873457658)
+}
+
+func TestOneWayANOVAResult(t *testing.T) {
+	s, id := newStatisticsTestTable(t,
+		insyra.NewDataList(4.2, 4.8, 5.1, 4.5, 5.9, 6.3, 5.5, 6.1, 6.0, 4.9, 5.2, 5.0, 5.6).SetName("yield"),
+		insyra.NewDataList(1, 1, 1, 1, 2, 2, 2, 2, 2, 3, 3, 3, 3).SetName("plot"),
+	)
+	r, err := s.OneWayANOVA(id, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClose(t, "F", r.Statistic, 18.023970063896616)
+	checkClose(t, "df", r.DF, 2)
+	checkClose(t, "df2", r.DF2, 10)
+	checkClose(t, "p", r.PValue, 0.00048300203673135527)
+	checkEffectSize(t, r, "eta_squared", 0.7828350199325359)
+	checkEffectSize(t, r, "omega_squared", 0.7236860962522704)
+
+	// 只有一組時無法比較
+	s, id = newStatisticsTestTable(t,
+		insyra.NewDataList(1, 2, 3).SetName("y"),
+		insyra.NewDataList("g", "g", "g").SetName("group"),
+	)
+	if _, err := s.OneWayANOVA(id, 0, 1); err == nil {
+		t.Error("single group: want an error")
+	}
+}
+
+func TestChiSquareIndependence(t *testing.T) {
+	// 列聯表 [[10, 20, 15], [20, 10, 25]] 展開為逐列的資料
+	var rows, cols []any
+	counts := [][]int{{10, 20, 15}, {20, 10, 25}}
+	for i, row := range counts {
+		for j, n := range row {
+			for range n {
+				rows = append(rows, []string{"A", "B"}[i])
+				cols = append(cols, []string{"X", "Y", "Z"}[j])
+			}
+		}
+	}
+	s, id := newStatisticsTestTable(t, insyra.NewDataList(rows...).SetName("r"), insyra.NewDataList(cols...).SetName("c"))
+	r, err := s.ChiSquareIndependence(id, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.N != 100 {
+		t.Errorf("n = %d, want 100", r.N)
+	}
+	checkClose(t, "chi2", r.Statistic, 8.24915824915825)
+	checkClose(t, "df", r.DF, 2)
+	checkClose(t, "p", r.PValue, 0.0161702988375237)
+	checkEffectSize(t, r, "cramers_v", 0.2872134789517764)
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+// ===== 收集 insyra 的錯誤 =====
+//
+// insyra 的警告與錯誤不會回傳，而是經由非同步的佇列放入全域緩衝區。
+// 呼叫前後各放入一個標記訊息：開始標記之前的訊息與這次呼叫無關而捨棄，
+// 等結束標記出現在緩衝區時，即可確定兩個標記之間的訊息都已到齊。
+//
+// 代價：每次收集都要等 insyra 的佇列處理到結束標記，期間持有 insyraCaptureMu
+// （呼叫者通常也持有服務的 mu）。佇列正常時只需數十微秒；等待以漸增的間隔輪詢，
+// 最多等 insyraFlushTimeout，佇列異常時不會讓服務卡住更久。
+
+const (
+	insyraMarkerPackage = "insyra-insights"
+	insyraMarkerFunc    = "captureInsyra"
+	insyraBeginMarker   = "begin"
+	insyraEndMarker     = "end"
+	// insyraFlushTimeout 等待結束標記的上限
+	insyraFlushTimeout = 100 * time.Millisecond
+	// insyraPollInterval 輪詢緩衝區的最長間隔
+	insyraPollInterval = time.Millisecond
+)
+
+var (
+	insyraCaptureMu     sync.Mutex // 避免多個收集同時進行而互相取走訊息
+	configureInsyraOnce sync.Once
+)
+
+// InsyraMessage insyra 記錄的一則警告或錯誤
+type InsyraMessage struct {
+	Level    string `json:"level"` // "warning" 或 "fatal"
+	Package  string `json:"package"`
+	Function string `json:"function"`
+	Message  string `json:"message"`
+}
+
+// ConfigureInsyra 設定 insyra 的錯誤處理：不因錯誤結束程式，警告由服務層收集後回傳給前端，
+// 主控台仍會記錄標記以外的警告；insyra 的背景工作會讀取這些設定，因此只在第一次呼叫時設定
+func ConfigureInsyra() {
+	configureInsyraOnce.Do(func() {
+		insyra.Config.SetDontPanic(true)
+		// 關閉 insyra 自己的警告輸出，改由下方的處理函式記錄，以略過標記訊息
+		insyra.Config.SetLogLevel(insyra.LogLevelFatal)
+		insyra.Config.SetDefaultErrHandlingFunc(func(level insyra.LogLevel, pkg, fn, msg string) {
+			// 致命錯誤已由 insyra 輸出
+			if level == insyra.LogLevelFatal || (pkg == insyraMarkerPackage && fn == insyraMarkerFunc) {
+				return
+			}
+			log.Printf("[insyra - %s] %s.%s: %s", insyraLevelName(level), pkg, fn, msg)
+		})
+	})
+}
+
+// captureInsyra 執行 fn 並回傳期間 insyra 記錄的所有警告與錯誤
+func captureInsyra(fn func()) []InsyraMessage {
+	insyraCaptureMu.Lock()
+	defer insyraCaptureMu.Unlock()
+
+	insyra.LogWarning(insyraMarkerPackage, insyraMarkerFunc, insyraBeginMarker)
+	fn()
+	insyra.LogWarning(insyraMarkerPackage, insyraMarkerFunc, insyraEndMarker)
+	return collectInsyraMessages()
+}
+
+// collectInsyraMessages 取出緩衝區中的訊息直到結束標記，回傳開始標記之後的訊息
+func collectInsyraMessages() []InsyraMessage {
+	var messages []InsyraMessage
+	begun, done := false, false
+	deadline := time.Now().Add(insyraFlushTimeout)
+	for wait := 10 * time.Microsecond; ; wait = min(2*wait, insyraPollInterval) {
+		for !done && insyra.GetErrorCount() > 0 {
+			insyra.PopErrorAndCallback(insyra.ErrPoppingModeFIFO, func(level insyra.LogLevel, pkg, fn, msg string) {
+				if pkg == insyraMarkerPackage && fn == insyraMarkerFunc {
+					switch msg {
+					case insyraBeginMarker:
+						// 開始標記之前的訊息與這次呼叫無關
+						begun, messages = true, messages[:0]
+					case insyraEndMarker:
+						// 先前逾時的收集留下的結束標記不算
+						done = begun
+					}
+					return
+				}
+				messages = append(messages, InsyraMessage{
+					Level:    insyraLevelName(level),
+					Package:  pkg,
+					Function: fn,
+					Message:  msg,
+				})
+			})
+		}
+		if done || time.Now().After(deadline) {
+			return messages
+		}
+		time.Sleep(wait)
+	}
+}
+
+// insyraLevelName 將 insyra 的記錄層級轉為文字
+func insyraLevelName(level insyra.LogLevel) string {
+	switch level {
+	case insyra.LogLevelFatal:
+		return "fatal"
+	case insyra.LogLevelWarning:
+		return "warning"
+	case insyra.LogLevelInfo:
+		return "info"
+	}
+	return "debug"
+}
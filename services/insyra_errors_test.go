@@ -0,0 +1,57 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+func TestCaptureInsyraCollectsOnlyMessagesFromCall(t *testing.T) {
+	ConfigureInsyra()
+	// 呼叫之前的訊息不屬於這次收集
+	insyra.LogWarning("test", "before", "stale")
+	start := time.Now()
+	messages := captureInsyra(func() {
+		insyra.LogWarning("test", "during", "first")
+		insyra.LogWarning("test", "during", "second")
+	})
+	if elapsed := time.Since(start); elapsed >= insyraFlushTimeout {
+		t.Errorf("captureInsyra() took %v, the end marker was not found", elapsed)
+	}
+	if len(messages) != 2 || messages[0].Message != "first" || messages[1].Message != "second" {
+		t.Fatalf("messages = %+v", messages)
+	}
+	if messages[0].Level != "warning" || messages[0].Function != "during" {
+		t.Errorf("message = %+v", messages[0])
+	}
+	if again := captureInsyra(func() {}); len(again) != 0 {
+		t.Errorf("second capture = %+v, want none", again)
+	}
+}
+
+func TestInsyraWarningsReachServiceErrorDetails(t *testing.T) {
+	ConfigureInsyra()
+	data := NewDataTableService()
+	dt := insyra.NewDataTable(
+		insyra.NewDataList(1, 2, 3).SetName("A"),
+		insyra.NewDataList(5, nil, nil).SetName("B"),
+	)
+	data.lock()
+	id := data.appendTable(dt)
+	data.unlock()
+
+	// 只有一列同時有兩欄的值，insyra 無法計算成對 t 檢定並記錄警告
+	_, err := NewStatisticsService(data).PairedTTest(id, 0, 1, 0)
+	se := AsServiceError(err)
+	if se == nil || se.Code != ErrCodeInsyra {
+		t.Fatalf("PairedTTest() error = %v, want an insyra error", err)
+	}
+	messages, ok := se.Details["insyra"].([]InsyraMessage)
+	if !ok || len(messages) == 0 {
+		t.Fatalf("details = %+v, want insyra messages", se.Details)
+	}
+	if messages[len(messages)-1].Function != "PairedTTest" {
+		t.Errorf("messages = %+v", messages)
+	}
+}
@@ -0,0 +1,340 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"insyra-insights/keypath"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+// ===== JSON 匯入 =====
+
+// JSONImportError JSON 匯入失敗時的詳細資訊
+type JSONImportError struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Record  int    `json:"record"` // 出錯的資料列（從 0 開始），與位置無關時為 -1
+	Message string `json:"message"`
+}
+
+func (e *JSONImportError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("json import: line %d column %d: %s", e.Line, e.Column, e.Message)
+	}
+	if e.Record >= 0 {
+		return fmt.Sprintf("json import: record %d: %s", e.Record, e.Message)
+	}
+	return "json import: " + e.Message
+}
+
+// jsonObject 保留鍵順序的 JSON 物件
+type jsonObject struct {
+	keys   []string
+	values map[string]any
+}
+
+func (o *jsonObject) Keys() []string {
+	return o.keys
+}
+
+func (o *jsonObject) Get(key string) any {
+	return o.values[key]
+}
+
+// OpenJSONFile 開啟JSON檔案並創建新的資料表
+// 支援物件陣列、欄位導向物件、NDJSON 與巢狀物件（以點號展開為欄名）
+func (s *DataTableService) OpenJSONFile(filePath string) (string, error) {
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("read json file: %w", err)
+	}
+	columns, err := parseJSONTable(raw)
+	if err != nil {
+		return "", err
+	}
+
+	dt := newTableFromColumns(columns)
+	dt.SetName(tableNameFromPath(filePath))
+	return s.appendImportedTable(dt), nil
+}
+
+// parseJSONTable 解析 JSON 內容並轉為欄位，依序判斷 NDJSON、物件陣列、欄位導向物件與單一物件
+func parseJSONTable(raw []byte) ([]*insyra.DataList, error) {
+	raw = bytes.TrimPrefix(raw, []byte{0xEF, 0xBB, 0xBF})
+	values, err := decodeJSONValues(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, &JSONImportError{Record: -1, Message: "file contains no JSON value"}
+	}
+
+	if len(values) > 1 {
+		records, err := recordsFromValues(values)
+		if err != nil {
+			return nil, err
+		}
+		return recordsToColumns(records), nil
+	}
+
+	switch v := values[0].(type) {
+	case []any:
+		records, err := recordsFromValues(v)
+		if err != nil {
+			return nil, err
+		}
+		return recordsToColumns(records), nil
+	case *jsonObject:
+		if isColumnOriented(v) {
+			return columnsFromObject(v), nil
+		}
+		// {"data": [{...}, ...]} 這類只包一層的記錄陣列
+		if len(v.keys) == 1 {
+			if arr, ok := v.values[v.keys[0]].([]any); ok && len(arr) > 0 {
+				if _, ok := arr[0].(*jsonObject); ok {
+					records, err := recordsFromValues(arr)
+					if err != nil {
+						return nil, err
+					}
+					return recordsToColumns(records), nil
+				}
+			}
+		}
+		return recordsToColumns([]*jsonObject{flattenJSONObject(v)}), nil
+	default:
+		return nil, &JSONImportError{Record: -1, Message: fmt.Sprintf("top-level value must be an array or object, got %s", jsonTypeName(v))}
+	}
+}
+
+// decodeJSONValues 依序解析所有最上層的 JSON 值（單一文件或 NDJSON）
+func decodeJSONValues(raw []byte) ([]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var values []any
+	for {
+		v, err := decodeOrderedJSON(decoder)
+		if err == io.EOF {
+			return values, nil
+		}
+		if err != nil {
+			return nil, jsonErrorAt(raw, decoder.InputOffset(), err)
+		}
+		values = append(values, v)
+	}
+}
+
+// decodeOrderedJSON 以 token 方式解析，物件以 jsonObject 保留鍵的順序
+func decodeOrderedJSON(decoder *json.Decoder) (any, error) {
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			obj := &jsonObject{values: make(map[string]any)}
+			for decoder.More() {
+				keyTok, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				key := keyTok.(string)
+				value, err := decodeOrderedJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				if _, exists := obj.values[key]; !exists {
+					obj.keys = append(obj.keys, key)
+				}
+				obj.values[key] = value
+			}
+			if _, err := decoder.Token(); err != nil {
+				return nil, err
+			}
+			return obj, nil
+		case '[':
+			arr := make([]any, 0)
+			for decoder.More() {
+				value, err := decodeOrderedJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, value)
+			}
+			if _, err := decoder.Token(); err != nil {
+				return nil, err
+			}
+			return arr, nil
+		default:
+			return nil, fmt.Errorf("unexpected delimiter %q", t)
+		}
+	case json.Number:
+		return jsonNumberValue(t), nil
+	default:
+		return t, nil
+	}
+}
+
+// jsonErrorAt 將解析錯誤轉為帶行列位置的 JSONImportError
+func jsonErrorAt(raw []byte, offset int64, err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		offset = syntaxErr.Offset
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		offset = int64(len(raw))
+	}
+	offset = min(max(offset, 0), int64(len(raw)))
+	line := 1 + bytes.Count(raw[:offset], []byte{'\n'})
+	column := int(offset) - bytes.LastIndexByte(raw[:offset], '\n')
+	return &JSONImportError{Line: line, Column: column, Record: -1, Message: err.Error()}
+}
+
+// recordsFromValues 確認每個值都是物件並將巢狀物件展開
+func recordsFromValues(values []any) ([]*jsonObject, error) {
+	records := make([]*jsonObject, len(values))
+	for i, value := range values {
+		obj, ok := value.(*jsonObject)
+		if !ok {
+			return nil, &JSONImportError{Record: i, Message: fmt.Sprintf("expected object, got %s", jsonTypeName(value))}
+		}
+		records[i] = flattenJSONObject(obj)
+	}
+	return records, nil
+}
+
+// isColumnOriented 判斷物件是否為欄位導向：所有值都是不含物件的陣列
+func isColumnOriented(obj *jsonObject) bool {
+	if len(obj.keys) == 0 {
+		return false
+	}
+	for _, key := range obj.keys {
+		arr, ok := obj.values[key].([]any)
+		if !ok {
+			return false
+		}
+		for _, elem := range arr {
+			if _, isObj := elem.(*jsonObject); isObj {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// columnsFromObject 將欄位導向物件轉為欄位
+func columnsFromObject(obj *jsonObject) []*insyra.DataList {
+	columns := make([]*insyra.DataList, len(obj.keys))
+	for j, key := range obj.keys {
+		arr := obj.values[key].([]any)
+		values := make([]any, len(arr))
+		for i, elem := range arr {
+			values[i] = jsonCellValue(elem)
+		}
+		columns[j] = insyra.NewDataList(values...).SetName(key)
+	}
+	return columns
+}
+
+// recordsToColumns 以鍵第一次出現的順序建立欄位，缺少的鍵填入 nil
+func recordsToColumns(records []*jsonObject) []*insyra.DataList {
+	var names []string
+	seen := make(map[string]bool)
+	for _, record := range records {
+		for _, key := range record.keys {
+			if !seen[key] {
+				seen[key] = true
+				names = append(names, key)
+			}
+		}
+	}
+
+	columns := make([]*insyra.DataList, len(names))
+	for j, name := range names {
+		values := make([]any, len(records))
+		for i, record := range records {
+			values[i] = jsonCellValue(record.values[name])
+		}
+		columns[j] = insyra.NewDataList(values...).SetName(name)
+	}
+	return columns
+}
+
+// flattenJSONObject 將巢狀物件展開為以點號連接的鍵，例如 {"a":{"b":1}} → {"a.b":1}
+func flattenJSONObject(obj *jsonObject) *jsonObject {
+	out := &jsonObject{values: make(map[string]any)}
+	keypath.Flatten(obj, "", func(key string, value any) {
+		if _, exists := out.values[key]; !exists {
+			out.keys = append(out.keys, key)
+		}
+		out.values[key] = value
+	})
+	return out
+}
+
+// jsonCellValue 將 JSON 值轉為儲存格的值；陣列與物件以 JSON 文字保存
+func jsonCellValue(v any) any {
+	switch val := v.(type) {
+	case []any, *jsonObject:
+		data, _ := json.Marshal(plainJSON(val))
+		return string(data)
+	default:
+		return val
+	}
+}
+
+// plainJSON 將 jsonObject 還原為一般的 map 以便重新編碼
+func plainJSON(v any) any {
+	switch val := v.(type) {
+	case *jsonObject:
+		m := make(map[string]any, len(val.values))
+		for k, elem := range val.values {
+			m[k] = plainJSON(elem)
+		}
+		return m
+	case []any:
+		out := make([]any, len(val))
+		for i, elem := range val {
+			out[i] = plainJSON(elem)
+		}
+		return out
+	default:
+		return val
+	}
+}
+
+// jsonNumberValue 整數轉為 int，其餘轉為 float64
+func jsonNumberValue(n json.Number) any {
+	if i, err := n.Int64(); err == nil {
+		return int(i)
+	}
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+	return n.String()
+}
+
+func jsonTypeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case *jsonObject:
+		return "object"
+	default:
+		return "number"
+	}
+}
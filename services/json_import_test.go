@@ -0,0 +1,23 @@
+package services
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseJSONTableFlattensNestedObjectsInOrder(t *testing.T) {
+	columns, err := parseJSONTable([]byte(`[{"id":1,"user":{"name":"a","geo":{"lat":1.5}}},{"id":2,"user":{"name":"b"},"extra":true}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.GetName()
+	}
+	if want := []string{"id", "user.name", "user.geo.lat", "extra"}; !slices.Equal(names, want) {
+		t.Fatalf("columns = %v, want %v", names, want)
+	}
+	if got := columns[2].Data(); got[0] != 1.5 || got[1] != nil {
+		t.Fatalf("user.geo.lat = %v", got)
+	}
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+// ===== 缺失值 =====
+//
+// 缺失值一律以 nil 儲存。缺失值設定列出哪些文字（NA 記號）代表缺失值：
+// 輸入、匯入與變更設定時，符合記號的值轉為 nil；匯出文字格式時，nil 寫成 ExportToken。
+// 專案有一份預設設定，個別欄位可以另外指定，以欄位 ID 為鍵保存。
+
+// MissingPolicy 缺失值設定
+type MissingPolicy struct {
+	Tokens      []string `json:"tokens"`      // 視為缺失值的文字，例如 "."、"NA"、"NULL"、"-999"；空白一律視為缺失值
+	ExportToken string   `json:"exportToken"` // 匯出 CSV 與 Excel 時缺失值寫成的文字
+}
+
+// ColumnMissingPolicy 欄位實際使用的缺失值設定
+type ColumnMissingPolicy struct {
+	Policy MissingPolicy `json:"policy"`
+	Custom bool          `json:"custom"` // 為 false 時沿用專案的設定
+}
+
+// MissingCount 單一欄位的缺失值數量
+type MissingCount struct {
+	Col     int    `json:"col"`
+	Name    string `json:"name"`
+	Count   int    `json:"count"`   // 非缺失值的數量
+	Missing int    `json:"missing"` // 缺失值的數量
+}
+
+// MissingPattern 缺失模式：各列依哪些欄缺失分組，每一組為矩陣的一列
+type MissingPattern struct {
+	RowCount int                 `json:"rowCount"`
+	Columns  []MissingCount      `json:"columns"`
+	Patterns []MissingPatternRow `json:"patterns"` // 依缺失欄數由少到多、列數由多到少排列
+}
+
+// MissingPatternRow 一種缺失模式
+type MissingPatternRow struct {
+	Missing      []bool `json:"missing"`      // 依欄位順序，true 表示該欄缺失
+	Rows         int    `json:"rows"`         // 符合此模式的列數
+	MissingCount int    `json:"missingCount"` // 此模式中缺失的欄數
+}
+
+// DefaultMissingPolicy 預設的缺失值設定：只有 "." 代表缺失值，匯出時寫成空白
+func DefaultMissingPolicy() MissingPolicy {
+	return MissingPolicy{Tokens: []string{"."}}
+}
+
+// normalized 去除記號前後的空白並移除空白與重複的記號
+func (p MissingPolicy) normalized() MissingPolicy {
+	tokens := make([]string, 0, len(p.Tokens))
+	for _, token := range p.Tokens {
+		token = strings.TrimSpace(token)
+		if token != "" && !slices.Contains(tokens, token) {
+			tokens = append(tokens, token)
+		}
+	}
+	return MissingPolicy{Tokens: tokens, ExportToken: p.ExportToken}
+}
+
+// isMissing 判斷值是否為缺失值或符合任一 NA 記號；數值依其文字形式比對，因此 -999 符合 "-999"
+func (p MissingPolicy) isMissing(v any) bool {
+	if isMissingValue(v) {
+		return true
+	}
+	text := strings.TrimSpace(formatValue(v))
+	return slices.Contains(p.Tokens, text)
+}
+
+// missingPoliciesOf 取得資料表各欄 ID 自訂的缺失值設定，不存在時建立
+func (s *DataTableService) missingPoliciesOf(dt *insyra.DataTable) map[int]MissingPolicy {
+	policies, ok := s.missingPolicies[dt]
+	if !ok {
+		policies = make(map[int]MissingPolicy)
+		s.missingPolicies[dt] = policies
+	}
+	return policies
+}
+
+// missingPolicyOf 取得第 col 欄實際使用的缺失值設定
+func (s *DataTableService) missingPolicyOf(dt *insyra.DataTable, col int) ColumnMissingPolicy {
+	if policy, ok := s.missingPoliciesOf(dt)[s.columnIDsOf(dt)[col]]; ok {
+		return ColumnMissingPolicy{Policy: policy, Custom: true}
+	}
+	return ColumnMissingPolicy{Policy: s.project.missingPolicy}
+}
+
+// missingPolicyTexts 取得各欄自訂的缺失值設定，以欄位索引為鍵
+func (s *DataTableService) missingPolicyTexts(dt *insyra.DataTable) map[int]MissingPolicy {
+	texts := make(map[int]MissingPolicy)
+	policies := s.missingPoliciesOf(dt)
+	for j, id := range s.columnIDsOf(dt) {
+		if policy, ok := policies[id]; ok {
+			texts[j] = policy
+		}
+	}
+	return texts
+}
+
+// setMissingPolicyTexts 以欄位索引設定各欄自訂的缺失值設定
+func (s *DataTableService) setMissingPolicyTexts(dt *insyra.DataTable, texts map[int]MissingPolicy) {
+	policies := s.missingPoliciesOf(dt)
+	ids := s.columnIDsOf(dt)
+	for j, policy := range texts {
+		if j >= 0 && j < len(ids) {
+			policies[ids[j]] = policy.normalized()
+		}
+	}
+}
+
+// applyMissingPolicy 將第 cols 欄中符合缺失值設定的值轉為 nil，回傳轉換前的欄位內容（沒有變更的欄省略）
+func (s *DataTableService) applyMissingPolicy(dt *insyra.DataTable, cols []int) map[int][]any {
+	changed := make(map[int][]any)
+	for _, j := range cols {
+		if s.formulas[dt][j] != nil {
+			continue
+		}
+		policy := s.missingPolicyOf(dt, j).Policy
+		column := dt.GetColByNumber(j)
+		values := column.Data()
+		var converted []any
+		for i, v := range values {
+			if v == nil || !policy.isMissing(v) {
+				continue
+			}
+			if converted == nil {
+				converted = slices.Clone(values)
+			}
+			converted[i] = nil
+		}
+		if converted != nil {
+			dt.UpdateColByNumber(j, insyra.NewDataList(converted...).SetName(column.GetName()))
+			changed[j] = values
+		}
+	}
+	return changed
+}
+
+// recordMissingPolicy 記錄套用缺失值設定的復原步驟；復原時還原被轉為 nil 的值與欄位的設定
+func (s *DataTableService) recordMissingPolicy(dt *insyra.DataTable, before map[int][]any, undoPolicy, redoPolicy func()) {
+	if len(before) == 0 && undoPolicy == nil {
+		return
+	}
+	after := make(map[int][]any, len(before))
+	for j := range before {
+		after[j] = dt.GetColByNumber(j).Data()
+	}
+	restore := func(dt *insyra.DataTable, values map[int][]any) {
+		for j, v := range values {
+			dt.UpdateColByNumber(j, insyra.NewDataList(v...).SetName(dt.GetColNameByNumber(j)))
+		}
+	}
+	s.recomputeColumns(dt, slices.Collect(maps.Keys(before)), false)
+	s.record(dt, "set missing policy",
+		func(dt *insyra.DataTable) {
+			restore(dt, before)
+			if undoPolicy != nil {
+				undoPolicy()
+			}
+		},
+		func(dt *insyra.DataTable) {
+			restore(dt, after)
+			if redoPolicy != nil {
+				redoPolicy()
+			}
+		},
+	)
+}
+
+// GetMissingPolicy 取得專案的缺失值設定
+func (s *DataTableService) GetMissingPolicy() MissingPolicy {
+	s.lock()
+	defer s.unlock()
+	return s.project.missingPolicy
+}
+
+// SetMissingPolicy 設定專案的缺失值設定，並將沒有自訂設定的欄位中符合 NA 記號的值轉為缺失值；
+// 每個有變更的資料表各記錄一個復原步驟，復原只還原資料，不還原設定
+func (s *DataTableService) SetMissingPolicy(policy MissingPolicy) error {
+	s.lock()
+	defer s.unlock()
+	s.project.missingPolicy = policy.normalized()
+	s.markProjectModified()
+	for _, dt := range s.orderedTables() {
+		_, colCount := dt.Size()
+		policies := s.missingPoliciesOf(dt)
+		cols := make([]int, 0, colCount)
+		for j, id := range s.columnIDsOf(dt) {
+			if _, custom := policies[id]; !custom {
+				cols = append(cols, j)
+			}
+		}
+		s.recordMissingPolicy(dt, s.applyMissingPolicy(dt, cols), nil, nil)
+	}
+	return nil
+}
+
+// GetColumnMissingPolicy 取得欄位實際使用的缺失值設定
+func (s *DataTableService) GetColumnMissingPolicy(tableID string, colIndex int) (ColumnMissingPolicy, error) {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return ColumnMissingPolicy{}, errTableNotFound(tableID)
+	}
+	if _, colCount := dt.Size(); colIndex < 0 || colIndex >= colCount {
+		return ColumnMissingPolicy{}, errColumnOutOfRange(colIndex, colCount)
+	}
+	return s.missingPolicyOf(dt, colIndex), nil
+}
+
+// SetColumnMissingPolicy 設定欄位自訂的缺失值設定並將符合 NA 記號的值轉為缺失值；policy 為 nil 時改回沿用專案的設定
+func (s *DataTableService) SetColumnMissingPolicy(tableID string, colIndex int, policy *MissingPolicy) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
+	}
+	if _, colCount := dt.Size(); colIndex < 0 || colIndex >= colCount {
+		return errColumnOutOfRange(colIndex, colCount)
+	}
+
+	id := s.columnIDsOf(dt)[colIndex]
+	oldPolicy, hadPolicy := s.missingPoliciesOf(dt)[id]
+	set := func(policy *MissingPolicy) {
+		if policy == nil {
+			delete(s.missingPoliciesOf(dt), id)
+		} else {
+			s.missingPoliciesOf(dt)[id] = *policy
+		}
+	}
+	var newPolicy *MissingPolicy
+	if policy != nil {
+		normalized := policy.normalized()
+		newPolicy = &normalized
+	}
+	set(newPolicy)
+	undo := func() { set(nil) }
+	if hadPolicy {
+		undo = func() { set(&oldPolicy) }
+	}
+	s.recordMissingPolicy(dt, s.applyMissingPolicy(dt, []int{colIndex}), undo, func() { set(newPolicy) })
+	return nil
+}
+
+// GetMissingCounts 取得資料表各欄的缺失值數量
+func (s *StatisticsService) GetMissingCounts(tableID string) ([]MissingCount, error) {
+	s.data.lock()
+	defer s.data.unlock()
+	dt := s.data.getTableByID(tableID)
+	if dt == nil {
+		return nil, errTableNotFound(tableID)
+	}
+	counts, _ := missingMatrix(dt)
+	return counts, nil
+}
+
+// GetMissingPattern 取得資料表的缺失模式矩陣
+func (s *StatisticsService) GetMissingPattern(tableID string) (MissingPattern, error) {
+	s.data.lock()
+	defer s.data.unlock()
+	dt := s.data.getTableByID(tableID)
+	if dt == nil {
+		return MissingPattern{}, errTableNotFound(tableID)
+	}
+	counts, missing := missingMatrix(dt)
+	result := MissingPattern{RowCount: len(missing), Columns: counts, Patterns: []MissingPatternRow{}}
+
+	index := make(map[string]int)
+	key := make([]byte, len(counts))
+	for _, row := range missing {
+		for j, m := range row {
+			key[j] = '0'
+			if m {
+				key[j] = '1'
+			}
+		}
+		if p, ok := index[string(key)]; ok {
+			result.Patterns[p].Rows++
+			continue
+		}
+		index[string(key)] = len(result.Patterns)
+		n := 0
+		for _, m := range row {
+			if m {
+				n++
+			}
+		}
+		result.Patterns = append(result.Patterns, MissingPatternRow{Missing: row, Rows: 1, MissingCount: n})
+	}
+	slices.SortStableFunc(result.Patterns, func(a, b MissingPatternRow) int {
+		return cmp.Or(cmp.Compare(a.MissingCount, b.MissingCount), cmp.Compare(b.Rows, a.Rows))
+	})
+	return result, nil
+}
+
+// missingMatrix 取得各欄的缺失值數量與各列各欄是否缺失；較短的欄位中不存在的列也算缺失
+func missingMatrix(dt *insyra.DataTable) ([]MissingCount, [][]bool) {
+	rowCount, colCount := dt.Size()
+	counts := make([]MissingCount, colCount)
+	missing := make([][]bool, rowCount)
+	for i := range missing {
+		missing[i] = make([]bool, colCount)
+	}
+	for j := range colCount {
+		data := dt.GetColByNumber(j).Data()
+		counts[j] = MissingCount{Col: j, Name: dt.GetColNameByNumber(j)}
+		for i := range rowCount {
+			if i >= len(data) || isMissingValue(data[i]) {
+				missing[i][j] = true
+				counts[j].Missing++
+			} else {
+				counts[j].Count++
+			}
+		}
+	}
+	return counts, missing
+}
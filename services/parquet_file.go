@@ -0,0 +1,1085 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ===== Parquet 匯入與匯出 =====
+//
+// 讀取只包含頂層基本型別欄位的 Parquet 檔案（巢狀或重複欄位會列出但無法匯入），
+// 支援 PLAIN、字典、RLE、DELTA_* 與 BYTE_STREAM_SPLIT 編碼，以及 Snappy、Gzip、Zstd、LZ4 壓縮。
+// 讀取時逐個 row group 只讀入選取的欄；寫出時每個 row group 的每一欄寫成一個資料頁面。
+
+// Parquet 的實體型別
+const (
+	parquetBoolean = iota
+	parquetInt32
+	parquetInt64
+	parquetInt96
+	parquetFloat
+	parquetDouble
+	parquetByteArray
+	parquetFixed
+)
+
+var parquetTypeNames = []string{"BOOLEAN", "INT32", "INT64", "INT96", "FLOAT", "DOUBLE", "BYTE_ARRAY", "FIXED_LEN_BYTE_ARRAY"}
+
+// parquetConvertedNames 舊版的型別標註（converted type）
+var parquetConvertedNames = map[int64]string{
+	0: "UTF8", 1: "MAP", 2: "MAP_KEY_VALUE", 3: "LIST", 4: "ENUM", 5: "DECIMAL", 6: "DATE", 7: "TIME_MILLIS",
+	8: "TIME_MICROS", 9: "TIMESTAMP_MILLIS", 10: "TIMESTAMP_MICROS", 11: "UINT_8", 12: "UINT_16", 13: "UINT_32",
+	14: "UINT_64", 15: "INT_8", 16: "INT_16", 17: "INT_32", 18: "INT_64", 19: "JSON", 20: "BSON", 21: "INTERVAL",
+}
+
+// parquetLogicalNames 新版的型別標註（logical type），以 union 的欄位 ID 為鍵
+var parquetLogicalNames = map[int16]string{
+	1: "STRING", 2: "MAP", 3: "LIST", 4: "ENUM", 5: "DECIMAL", 6: "DATE", 7: "TIME", 8: "TIMESTAMP",
+	10: "INTEGER", 11: "UNKNOWN", 12: "JSON", 13: "BSON", 14: "UUID", 15: "FLOAT16",
+}
+
+// Parquet 的編碼
+const (
+	parquetPlain           = 0
+	parquetPlainDict       = 2
+	parquetRLE             = 3
+	parquetBitPacked       = 4
+	parquetDeltaBinary     = 5
+	parquetDeltaLength     = 6
+	parquetDeltaByteArray  = 7
+	parquetRLEDict         = 8
+	parquetByteStreamSplit = 9
+)
+
+// parquetCodecs Parquet 的壓縮方式代碼
+var parquetCodecs = map[string]int32{CompressionNone: 0, CompressionSnappy: 1, CompressionGzip: 2, CompressionZstd: 6}
+
+const parquetMagic = "PAR1"
+
+// 讀取時值的轉換方式
+const (
+	parquetAsNumber = iota
+	parquetAsUnsigned
+	parquetAsDate
+	parquetAsTimestamp
+	parquetAsTime
+	parquetAsDecimal
+	parquetAsString
+	parquetAsEnum
+	parquetAsBinary
+	parquetAsUUID
+	parquetAsFloat16
+)
+
+// OpenParquetFile 開啟 Parquet 檔案的所有欄並創建新的資料表
+func (s *DataTableService) OpenParquetFile(filePath string) (string, error) {
+	return s.OpenParquetFileWithOptions(filePath, nil)
+}
+
+// OpenParquetFileWithOptions 只讀取選取的欄；逐個 row group 讀取，不需要一次載入整個檔案
+func (s *DataTableService) OpenParquetFileWithOptions(filePath string, options *ColumnarImportOptions) (string, error) {
+	r, err := openParquet(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer r.file.Close()
+	return s.openColumnar(filePath, "parquet", options,
+		func() (ColumnarFileInfo, error) { return r.info(), nil },
+		r.read)
+}
+
+// DescribeParquetFile 取得 Parquet 檔案的列數、row group 數與各欄型別，供選擇要匯入的欄
+func (s *DataTableService) DescribeParquetFile(filePath string) (ColumnarFileInfo, error) {
+	r, err := openParquet(filePath)
+	if err != nil {
+		return ColumnarFileInfo{}, err
+	}
+	defer r.file.Close()
+	return r.info(), nil
+}
+
+// ExportTableAsParquet 以預設設定（Snappy 壓縮）將資料表匯出為 Parquet
+func (s *DataTableService) ExportTableAsParquet(tableID string, filePath string) error {
+	return s.ExportTableAsParquetWithOptions(tableID, filePath, DefaultParquetExportOptions())
+}
+
+// ExportTableAsParquetWithOptions 以指定的壓縮方式與 row group 大小將資料表匯出為 Parquet
+func (s *DataTableService) ExportTableAsParquetWithOptions(tableID string, filePath string, options ColumnarExportOptions) error {
+	options, err := normalizeExportOptions(options, CompressionNone, CompressionSnappy, CompressionGzip, CompressionZstd)
+	if err != nil {
+		return err
+	}
+	_, columns, err := s.columnarColumns(tableID)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("create parquet file: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	if err := writeParquet(w, columns, options); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ----- 讀取 -----
+
+// parquetReader 已開啟的 Parquet 檔案與其結構
+type parquetReader struct {
+	file      *os.File
+	size      int64
+	meta      thriftValues
+	columns   []*parquetColumn
+	rowGroups []thriftValues
+}
+
+// parquetColumn 檔案中的一個葉節點欄位，順序與每個 row group 中的 column chunk 相同
+type parquetColumn struct {
+	info       ColumnarColumnInfo
+	physical   int64
+	typeLength int
+	maxDef     int
+	convert    int
+	unit       time.Duration // 時間與時間戳記的單位
+	scale      int           // decimal 的小數位數
+}
+
+// openParquet 開啟檔案並讀取結尾的檔案結構
+func openParquet(filePath string) (*parquetReader, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("open parquet file: %w", err)
+	}
+	r := &parquetReader{file: f}
+	if err := r.readFooter(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *parquetReader) readFooter() error {
+	stat, err := r.file.Stat()
+	if err != nil {
+		return fmt.Errorf("open parquet file: %w", err)
+	}
+	r.size = stat.Size()
+	tail := make([]byte, 8)
+	if r.size < 12 {
+		return errFileInvalid("parquet", "file is too small")
+	}
+	if _, err := r.file.ReadAt(tail, r.size-8); err != nil {
+		return fmt.Errorf("read parquet file: %w", err)
+	}
+	if string(tail[4:]) != parquetMagic {
+		if string(tail[4:]) == "PARE" {
+			return errFileUnsupported("parquet", "encrypted footer")
+		}
+		return errFileInvalid("parquet", "not a parquet file")
+	}
+	n := int64(binary.LittleEndian.Uint32(tail))
+	if n > r.size-12 {
+		return errFileInvalid("parquet", "footer is truncated")
+	}
+	footer := make([]byte, n)
+	if _, err := r.file.ReadAt(footer, r.size-8-n); err != nil {
+		return fmt.Errorf("read parquet file: %w", err)
+	}
+	if r.meta, _, err = decodeThrift(footer); err != nil {
+		return errFileInvalid("parquet", "footer is corrupt")
+	}
+	for _, g := range r.meta.list(4) {
+		group, _ := g.(thriftValues)
+		r.rowGroups = append(r.rowGroups, group)
+	}
+	return r.readSchema()
+}
+
+// readSchema 將深度優先排列的結構轉為葉節點欄位；巢狀群組內的欄位以點連接名稱，標示為無法匯入
+func (r *parquetReader) readSchema() error {
+	var elements []thriftValues
+	for _, e := range r.meta.list(2) {
+		element, _ := e.(thriftValues)
+		elements = append(elements, element)
+	}
+	if len(elements) == 0 {
+		return errFileInvalid("parquet", "schema is empty")
+	}
+	pos := 1
+	var walk func(prefix string, count int, nested bool) error
+	walk = func(prefix string, count int, nested bool) error {
+		for range count {
+			if pos >= len(elements) {
+				return errFileInvalid("parquet", "schema is truncated")
+			}
+			e := elements[pos]
+			pos++
+			name := prefix + e.text(4)
+			repeated := e.int(3) == 2
+			if children := int(e.int(5)); children > 0 {
+				if err := walk(name+".", children, true); err != nil {
+					return err
+				}
+				continue
+			}
+			col := newParquetColumn(name, e)
+			if nested || repeated {
+				col.info.Type = ""
+			}
+			r.columns = append(r.columns, col)
+		}
+		return nil
+	}
+	return walk("", int(elements[0].int(5)), false)
+}
+
+// newParquetColumn 依實體型別與型別標註決定欄位型別與值的轉換方式
+func newParquetColumn(name string, e thriftValues) *parquetColumn {
+	c := &parquetColumn{
+		physical:   e.int(1),
+		typeLength: int(e.int(2)),
+		scale:      int(e.int(7)),
+		unit:       time.Millisecond,
+	}
+	if e.int(3) == 1 {
+		c.maxDef = 1
+	}
+	if c.physical < 0 || int(c.physical) >= len(parquetTypeNames) {
+		c.info = ColumnarColumnInfo{Name: name, FileType: fmt.Sprintf("type %d", c.physical)}
+		return c
+	}
+	annotation := ""
+	converted := int64(-1)
+	if e.has(6) {
+		converted = e.int(6)
+		annotation = parquetConvertedNames[converted]
+	}
+	logical := e.sub(10)
+	var logicalID int16
+	for id := range logical {
+		logicalID = id
+		annotation = parquetLogicalNames[id]
+	}
+
+	c.convert = parquetAsNumber
+	dataType := DataTypeNumeric
+	switch {
+	case logicalID == 1 || converted == 0 || logicalID == 12 || converted == 19:
+		c.convert, dataType = parquetAsString, DataTypeString
+	case logicalID == 4 || converted == 4:
+		c.convert, dataType = parquetAsEnum, DataTypeCategorical
+	case logicalID == 5 || converted == 5:
+		c.convert = parquetAsDecimal
+		if d := logical.sub(5); d != nil {
+			c.scale = int(d.int(1))
+		}
+	case logicalID == 6 || converted == 6:
+		c.convert, dataType = parquetAsDate, DataTypeDateTime
+	case logicalID == 7 || converted == 7 || converted == 8:
+		c.convert, dataType = parquetAsTime, DataTypeString
+		c.unit = parquetTimeUnit(logical.sub(7).sub(2), converted == 8)
+	case logicalID == 8 || converted == 9 || converted == 10:
+		c.convert, dataType = parquetAsTimestamp, DataTypeDateTime
+		c.unit = parquetTimeUnit(logical.sub(8).sub(2), converted == 10)
+	case logicalID == 14:
+		c.convert, dataType = parquetAsUUID, DataTypeString
+	case logicalID == 15:
+		c.convert = parquetAsFloat16
+	case logicalID == 10 && !logical.sub(10).bool(2), converted >= 11 && converted <= 14:
+		c.convert, dataType = parquetAsUnsigned, DataTypeInteger
+	}
+	switch c.physical {
+	case parquetBoolean:
+		dataType = DataTypeBoolean
+	case parquetInt32, parquetInt64:
+		if c.convert == parquetAsNumber {
+			dataType = DataTypeInteger
+		}
+	case parquetInt96:
+		c.convert, dataType = parquetAsTimestamp, DataTypeDateTime
+	case parquetByteArray, parquetFixed:
+		if c.convert == parquetAsNumber {
+			c.convert, dataType = parquetAsBinary, DataTypeString
+		}
+	}
+
+	fileType := parquetTypeNames[c.physical]
+	if annotation != "" {
+		fileType += " (" + annotation + ")"
+	}
+	c.info = ColumnarColumnInfo{Name: name, FileType: fileType, Type: dataType}
+	return c
+}
+
+// parquetTimeUnit 讀取 TimeUnit union（1 毫秒、2 微秒、3 奈秒）；沒有 logical type 時依 converted type 決定
+func parquetTimeUnit(unit thriftValues, micros bool) time.Duration {
+	switch {
+	case unit.has(2):
+		return time.Microsecond
+	case unit.has(3):
+		return time.Nanosecond
+	case unit.has(1):
+		return time.Millisecond
+	case micros:
+		return time.Microsecond
+	}
+	return time.Millisecond
+}
+
+// info 檔案的列數、row group 數與各欄資訊
+func (r *parquetReader) info() ColumnarFileInfo {
+	info := ColumnarFileInfo{
+		RowCount: r.meta.int(3),
+		Groups:   len(r.rowGroups),
+		Columns:  make([]ColumnarColumnInfo, len(r.columns)),
+	}
+	for j, col := range r.columns {
+		info.Columns[j] = col.info
+	}
+	return info
+}
+
+// read 逐個 row group 讀取選取的欄，每讀完一組就交給 appendGroup，不保留已讀過的組
+func (r *parquetReader) read(selected []int, appendGroup func(group [][]any)) error {
+	for _, group := range r.rowGroups {
+		chunks := group.list(1)
+		rows := int(group.int(3))
+		values := make([][]any, len(selected))
+		for k, j := range selected {
+			if j >= len(chunks) {
+				return errFileInvalid("parquet", "row group is missing columns")
+			}
+			chunk, _ := chunks[j].(thriftValues)
+			column, err := r.readChunk(r.columns[j], chunk, rows)
+			if err != nil {
+				return err
+			}
+			values[k] = column
+		}
+		appendGroup(values)
+	}
+	return nil
+}
+
+// readChunk 讀取一個 column chunk 的所有頁面
+func (r *parquetReader) readChunk(c *parquetColumn, chunk thriftValues, rows int) ([]any, error) {
+	if chunk.text(1) != "" {
+		return nil, errFileUnsupported("parquet", "column data in external file "+chunk.text(1))
+	}
+	meta := chunk.sub(3)
+	codec := meta.int(4)
+	total := int(meta.int(5))
+	start := meta.int(9)
+	if dict := meta.int(11); dict > 0 && dict < start {
+		start = dict
+	}
+	size := meta.int(7)
+	if start < 4 || size < 0 || start+size > r.size || total < 0 || total > rows || rows > columnarMaxBuffer {
+		return nil, errFileInvalid("parquet", "column chunk of "+c.info.Name+" is out of range")
+	}
+	buf := make([]byte, size)
+	if _, err := r.file.ReadAt(buf, start); err != nil {
+		return nil, fmt.Errorf("read parquet file: %w", err)
+	}
+
+	// 預先配置的容量以實際讀到的位元組為上限，避免損毀的列數造成過大的配置
+	out := make([]any, 0, min(total, 8*len(buf)))
+	var dict []any
+	pos := 0
+	for len(out) < total {
+		if pos >= len(buf) {
+			return nil, errFileInvalid("parquet", "column chunk of "+c.info.Name+" is truncated")
+		}
+		header, n, err := decodeThrift(buf[pos:])
+		if err != nil {
+			return nil, errFileInvalid("parquet", "page header is corrupt")
+		}
+		pos += n
+		compressed, uncompressed := int(header.int(3)), int(header.int(2))
+		if compressed < 0 || compressed > len(buf)-pos {
+			return nil, errFileInvalid("parquet", "page is truncated")
+		}
+		page := buf[pos : pos+compressed]
+		pos += compressed
+
+		switch header.int(1) {
+		case 2:
+			data, err := parquetDecompress(codec, page, uncompressed)
+			if err != nil {
+				return nil, err
+			}
+			dph := header.sub(7)
+			count := int(dph.int(1))
+			// PLAIN 編碼的每個值至少佔一個位元
+			if count < 0 || count > 8*len(data) {
+				return nil, errFileInvalid("parquet", "dictionary page of "+c.info.Name+" is corrupt")
+			}
+			if dict, err = c.decodeValues(parquetPlain, data, count, nil); err != nil {
+				return nil, err
+			}
+		case 0:
+			data, err := parquetDecompress(codec, page, uncompressed)
+			if err != nil {
+				return nil, err
+			}
+			dph := header.sub(5)
+			count := int(dph.int(1))
+			if count < 0 || count > total-len(out) {
+				return nil, errFileInvalid("parquet", "page of "+c.info.Name+" has too many values")
+			}
+			var levels []uint32
+			if c.maxDef > 0 {
+				if len(data) < 4 {
+					return nil, errFileInvalid("parquet", "definition levels are truncated")
+				}
+				n := int(binary.LittleEndian.Uint32(data))
+				if n > len(data)-4 {
+					return nil, errFileInvalid("parquet", "definition levels are truncated")
+				}
+				if dph.int(3) == parquetBitPacked {
+					return nil, errFileUnsupported("parquet", "bit-packed definition levels")
+				}
+				if levels, err = decodeHybrid(data[4:4+n], bits.Len(uint(c.maxDef)), count); err != nil {
+					return nil, err
+				}
+				data = data[4+n:]
+			}
+			if out, err = c.appendPage(out, int(dph.int(2)), data, count, levels, dict); err != nil {
+				return nil, err
+			}
+		case 3:
+			dph := header.sub(8)
+			count := int(dph.int(1))
+			if count < 0 || count > total-len(out) {
+				return nil, errFileInvalid("parquet", "page of "+c.info.Name+" has too many values")
+			}
+			defLen, repLen := int(dph.int(5)), int(dph.int(6))
+			if defLen < 0 || repLen < 0 || defLen+repLen > len(page) {
+				return nil, errFileInvalid("parquet", "definition levels are truncated")
+			}
+			var levels []uint32
+			if c.maxDef > 0 {
+				if levels, err = decodeHybrid(page[repLen:repLen+defLen], bits.Len(uint(c.maxDef)), count); err != nil {
+					return nil, err
+				}
+			}
+			data := page[repLen+defLen:]
+			if !dph.has(7) || dph.bool(7) {
+				if data, err = parquetDecompress(codec, data, uncompressed-defLen-repLen); err != nil {
+					return nil, err
+				}
+			}
+			if out, err = c.appendPage(out, int(dph.int(4)), data, count, levels, dict); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
+
+// appendPage 解碼一個資料頁面，依定義層級在缺失的位置放入 nil
+func (c *parquetColumn) appendPage(out []any, encoding int, data []byte, count int, levels []uint32, dict []any) ([]any, error) {
+	present := count
+	if levels != nil {
+		present = 0
+		for _, level := range levels {
+			if int(level) == c.maxDef {
+				present++
+			}
+		}
+	}
+	values, err := c.decodeValues(encoding, data, present, dict)
+	if err != nil {
+		return nil, err
+	}
+	if levels == nil {
+		return append(out, values...), nil
+	}
+	k := 0
+	for _, level := range levels {
+		if int(level) == c.maxDef {
+			out = append(out, values[k])
+			k++
+		} else {
+			out = append(out, nil)
+		}
+	}
+	return out, nil
+}
+
+// parquetDecompress 依 Parquet 的壓縮方式代碼解壓縮頁面
+func parquetDecompress(codec int64, src []byte, size int) ([]byte, error) {
+	if size < 0 || size > columnarMaxBuffer {
+		return nil, errFileInvalid("parquet", "page size is out of range")
+	}
+	var out []byte
+	var err error
+	switch codec {
+	case 0:
+		return src, nil
+	case 1:
+		out, err = decompress(CompressionSnappy, src, size)
+	case 2:
+		out, err = decompress(CompressionGzip, src, size)
+	case 6:
+		out, err = decompress(CompressionZstd, src, size)
+	case 5:
+		// 舊的 LZ4 以 Hadoop 的區塊格式包裝，不符合時視為單一區塊
+		if out, err = lz4DecodeHadoop(src); err != nil {
+			out, err = lz4DecodeBlock(nil, src)
+		}
+	case 7:
+		out, err = lz4DecodeBlock(nil, src)
+	default:
+		name := map[int64]string{3: "LZO", 4: "Brotli"}[codec]
+		if name == "" {
+			name = fmt.Sprintf("codec %d", codec)
+		}
+		return nil, errFileUnsupported("parquet", name+" compression")
+	}
+	if err == nil && len(out) != size {
+		err = errCorruptBlock
+	}
+	if err != nil {
+		return nil, errFileInvalid("parquet", "page cannot be decompressed")
+	}
+	return out, nil
+}
+
+// lz4DecodeHadoop 解壓縮 Hadoop 格式的 LZ4：每段以大端序的解壓縮後長度與壓縮長度開頭
+func lz4DecodeHadoop(src []byte) ([]byte, error) {
+	var out []byte
+	for len(src) > 0 {
+		if len(src) < 8 {
+			return nil, errCorruptBlock
+		}
+		size := int(binary.BigEndian.Uint32(src))
+		n := int(binary.BigEndian.Uint32(src[4:]))
+		if n > len(src)-8 {
+			return nil, errCorruptBlock
+		}
+		before := len(out)
+		var err error
+		if out, err = lz4DecodeBlock(out, src[8:8+n]); err != nil || len(out)-before != size {
+			return nil, errCorruptBlock
+		}
+		src = src[8+n:]
+	}
+	return out, nil
+}
+
+// decodeValues 解碼 n 個非缺失值並轉換為欄位的值
+func (c *parquetColumn) decodeValues(encoding int, data []byte, n int, dict []any) ([]any, error) {
+	if n < 0 || n > columnarMaxBuffer {
+		return nil, errFileInvalid("parquet", "value count is out of range")
+	}
+	out := make([]any, 0, n)
+	corrupt := errFileInvalid("parquet", "values of "+c.info.Name+" are truncated")
+	switch encoding {
+	case parquetPlainDict, parquetRLEDict:
+		if dict == nil {
+			return nil, errFileInvalid("parquet", "dictionary page is missing")
+		}
+		if len(data) == 0 {
+			if n == 0 {
+				return out, nil
+			}
+			return nil, corrupt
+		}
+		indices, err := decodeHybrid(data[1:], int(data[0]), n)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range indices {
+			if int(i) >= len(dict) {
+				return nil, errFileInvalid("parquet", "dictionary index is out of range")
+			}
+			out = append(out, dict[i])
+		}
+		return out, nil
+	case parquetRLE:
+		if c.physical != parquetBoolean || len(data) < 4 {
+			break
+		}
+		levels, err := decodeHybrid(data[4:], 1, n)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range levels {
+			out = append(out, v == 1)
+		}
+		return out, nil
+	case parquetDeltaBinary:
+		if c.physical != parquetInt32 && c.physical != parquetInt64 {
+			break
+		}
+		ints, _, err := decodeDeltaBinary(data, n)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range ints {
+			out = append(out, c.fromInt(v))
+		}
+		return out, nil
+	case parquetDeltaLength, parquetDeltaByteArray:
+		if c.physical != parquetByteArray && c.physical != parquetFixed {
+			break
+		}
+		values, err := decodeDeltaBytes(encoding, data, n)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range values {
+			out = append(out, c.fromBytes(b))
+		}
+		return out, nil
+	case parquetByteStreamSplit:
+		size := map[int64]int{parquetInt32: 4, parquetInt64: 8, parquetFloat: 4, parquetDouble: 8, parquetFixed: c.typeLength}[c.physical]
+		if size == 0 {
+			break
+		}
+		if len(data) < size*n {
+			return nil, corrupt
+		}
+		// 第 k 個位元組串流包含每個值的第 k 個位元組，重組後以 PLAIN 解碼
+		plain := make([]byte, size*n)
+		for i := range n {
+			for k := range size {
+				plain[i*size+k] = data[k*n+i]
+			}
+		}
+		return c.decodeValues(parquetPlain, plain, n, nil)
+	case parquetPlain:
+		return c.decodePlain(data, n, out, corrupt)
+	}
+	return nil, errFileUnsupported("parquet", fmt.Sprintf("encoding %d for %s", encoding, c.info.FileType))
+}
+
+// decodePlain 解碼 PLAIN 編碼的值
+func (c *parquetColumn) decodePlain(data []byte, n int, out []any, corrupt error) ([]any, error) {
+	size := map[int64]int{parquetInt32: 4, parquetInt64: 8, parquetInt96: 12, parquetFloat: 4, parquetDouble: 8, parquetFixed: c.typeLength}[c.physical]
+	switch {
+	case c.physical == parquetBoolean:
+		if len(data)*8 < n {
+			return nil, corrupt
+		}
+		for i := range n {
+			out = append(out, data[i>>3]>>(i&7)&1 == 1)
+		}
+	case c.physical == parquetByteArray:
+		pos := 0
+		for range n {
+			if pos+4 > len(data) {
+				return nil, corrupt
+			}
+			length := int(binary.LittleEndian.Uint32(data[pos:]))
+			pos += 4
+			if length > len(data)-pos {
+				return nil, corrupt
+			}
+			out = append(out, c.fromBytes(data[pos:pos+length]))
+			pos += length
+		}
+	case size > 0:
+		if len(data) < size*n {
+			return nil, corrupt
+		}
+		for i := range n {
+			b := data[i*size : (i+1)*size]
+			switch c.physical {
+			case parquetInt32:
+				out = append(out, c.fromInt(int64(int32(binary.LittleEndian.Uint32(b)))))
+			case parquetInt64:
+				out = append(out, c.fromInt(int64(binary.LittleEndian.Uint64(b))))
+			case parquetFloat:
+				out = append(out, float64(math.Float32frombits(binary.LittleEndian.Uint32(b))))
+			case parquetDouble:
+				out = append(out, math.Float64frombits(binary.LittleEndian.Uint64(b)))
+			default:
+				out = append(out, c.fromBytes(b))
+			}
+		}
+	default:
+		return nil, errFileInvalid("parquet", "fixed length of "+c.info.Name+" is invalid")
+	}
+	return out, nil
+}
+
+// fromInt 將 INT32 或 INT64 的值轉為欄位的值
+func (c *parquetColumn) fromInt(v int64) any {
+	switch c.convert {
+	case parquetAsUnsigned:
+		if c.physical == parquetInt32 {
+			return int(uint32(v))
+		}
+		if v < 0 {
+			return float64(uint64(v))
+		}
+	case parquetAsDate:
+		return time.Unix(v*86400, 0).UTC()
+	case parquetAsTimestamp:
+		return timeFromUnit(v, c.unit)
+	case parquetAsTime:
+		return timeOfDayText(v, c.unit)
+	case parquetAsDecimal:
+		return float64(v) / math.Pow10(c.scale)
+	}
+	return int(v)
+}
+
+// fromBytes 將 BYTE_ARRAY、FIXED_LEN_BYTE_ARRAY 或 INT96 的值轉為欄位的值
+func (c *parquetColumn) fromBytes(b []byte) any {
+	switch {
+	case c.physical == parquetInt96:
+		// 一天中的奈秒數（8 個位元組）與儒略日（4 個位元組）
+		nanos := int64(binary.LittleEndian.Uint64(b))
+		day := int64(binary.LittleEndian.Uint32(b[8:]))
+		return time.Unix((day-2440588)*86400, nanos).UTC()
+	case c.convert == parquetAsDecimal:
+		return decimalValue(b, c.scale)
+	case c.convert == parquetAsUUID && len(b) == 16:
+		return uuid.UUID(b).String()
+	case c.convert == parquetAsFloat16 && len(b) == 2:
+		return float16Value(binary.LittleEndian.Uint16(b))
+	case c.convert == parquetAsString, c.convert == parquetAsEnum:
+		return string(b)
+	}
+	return binaryText(b)
+}
+
+// ----- 編碼的解碼 -----
+
+// decodeHybrid 解碼 RLE 與 bit-packing 混合編碼的 n 個值
+func decodeHybrid(data []byte, width int, n int) ([]uint32, error) {
+	if width < 0 || width > 32 {
+		return nil, errFileInvalid("parquet", "bit width is out of range")
+	}
+	if n < 0 || n > columnarMaxBuffer {
+		return nil, errFileInvalid("parquet", "value count is out of range")
+	}
+	out := make([]uint32, 0, n)
+	pos := 0
+	for len(out) < n {
+		header, k := binary.Uvarint(data[pos:])
+		if k <= 0 {
+			return nil, errFileInvalid("parquet", "levels are truncated")
+		}
+		pos += k
+		if header&1 == 1 {
+			// bit-packed：每組 8 個值
+			count := int(header>>1) * 8
+			size := int(header>>1) * width
+			if size > len(data)-pos || count < 0 {
+				return nil, errFileInvalid("parquet", "levels are truncated")
+			}
+			out = appendUnpacked(out, data[pos:pos+size], width, min(count, n-len(out)))
+			pos += size
+			continue
+		}
+		count := int(header >> 1)
+		size := (width + 7) / 8
+		if size > len(data)-pos || count < 0 {
+			return nil, errFileInvalid("parquet", "levels are truncated")
+		}
+		var v uint32
+		for i := range size {
+			v |= uint32(data[pos+i]) << (8 * i)
+		}
+		pos += size
+		for range min(count, n-len(out)) {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+// appendUnpacked 從低位元開始讀取 count 個寬度為 width 的值
+func appendUnpacked(out []uint32, data []byte, width int, count int) []uint32 {
+	bit := 0
+	for range count {
+		var v uint32
+		for i := range width {
+			if data[(bit+i)>>3]>>((bit+i)&7)&1 == 1 {
+				v |= 1 << i
+			}
+		}
+		bit += width
+		out = append(out, v)
+	}
+	return out
+}
+
+// decodeDeltaBinary 解碼 DELTA_BINARY_PACKED 的 n 個整數，回傳值與使用的位元組數
+func decodeDeltaBinary(data []byte, n int) ([]int64, int, error) {
+	corrupt := errFileInvalid("parquet", "delta encoded values are truncated")
+	pos := 0
+	uvarint := func() (uint64, bool) {
+		v, k := binary.Uvarint(data[pos:])
+		pos += max(k, 0)
+		return v, k > 0
+	}
+	blockSize, ok1 := uvarint()
+	miniblocks, ok2 := uvarint()
+	total, ok3 := uvarint()
+	first, ok4 := uvarint()
+	// 每個 miniblock 必須佔整數個位元組；總數不會超過要讀取的值數
+	if !ok1 || !ok2 || !ok3 || !ok4 || miniblocks == 0 || blockSize%miniblocks != 0 || blockSize > 1<<20 ||
+		blockSize/miniblocks%8 != 0 || total > uint64(n) {
+		return nil, 0, corrupt
+	}
+	perMini := int(blockSize / miniblocks)
+	out := make([]int64, 0, total)
+	value := int64(first>>1) ^ -int64(first&1)
+	if total > 0 {
+		out = append(out, value)
+	}
+	for uint64(len(out)) < total {
+		raw, ok := uvarint()
+		if !ok || pos+int(miniblocks) > len(data) {
+			return nil, 0, corrupt
+		}
+		minDelta := int64(raw>>1) ^ -int64(raw&1)
+		widths := data[pos : pos+int(miniblocks)]
+		pos += int(miniblocks)
+		for _, width := range widths {
+			if uint64(len(out)) >= total {
+				break
+			}
+			size := perMini * int(width) / 8
+			if width > 64 || size > len(data)-pos {
+				return nil, 0, corrupt
+			}
+			mini := data[pos : pos+size]
+			pos += size
+			for i := range perMini {
+				if uint64(len(out)) >= total {
+					break
+				}
+				var delta uint64
+				for b := range int(width) {
+					bit := i*int(width) + b
+					if mini[bit>>3]>>(bit&7)&1 == 1 {
+						delta |= 1 << b
+					}
+				}
+				value += minDelta + int64(delta)
+				out = append(out, value)
+			}
+		}
+	}
+	if len(out) < n {
+		return nil, 0, corrupt
+	}
+	return out[:n], pos, nil
+}
+
+// decodeDeltaBytes 解碼 DELTA_LENGTH_BYTE_ARRAY 或 DELTA_BYTE_ARRAY 的 n 個值
+func decodeDeltaBytes(encoding int, data []byte, n int) ([][]byte, error) {
+	corrupt := errFileInvalid("parquet", "delta encoded values are truncated")
+	var prefixes []int64
+	if encoding == parquetDeltaByteArray {
+		var used int
+		var err error
+		if prefixes, used, err = decodeDeltaBinary(data, n); err != nil {
+			return nil, err
+		}
+		data = data[used:]
+	}
+	lengths, used, err := decodeDeltaBinary(data, n)
+	if err != nil {
+		return nil, err
+	}
+	data = data[used:]
+	out := make([][]byte, n)
+	var prev []byte
+	for i, length := range lengths {
+		if length < 0 || int(length) > len(data) {
+			return nil, corrupt
+		}
+		value := data[:length]
+		data = data[length:]
+		if prefixes != nil {
+			p := prefixes[i]
+			if p < 0 || int(p) > len(prev) {
+				return nil, corrupt
+			}
+			value = append(bytes.Clone(prev[:p]), value...)
+		}
+		out[i] = value
+		prev = value
+	}
+	return out, nil
+}
+
+// ----- 寫出 -----
+
+// writeParquet 將各欄寫成 Parquet 檔案；每個 row group 的每一欄寫成一個 PLAIN 編碼的 v1 資料頁面
+func writeParquet(w io.Writer, columns []statColumn, options ColumnarExportOptions) error {
+	names := columnarNames(columns)
+	kinds := make([]columnarKind, len(columns))
+	for j, col := range columns {
+		kinds[j] = columnarKindOf(col)
+	}
+	rows := 0
+	if len(columns) > 0 {
+		rows = len(columns[0].values)
+	}
+	codec := parquetCodecs[options.Compression]
+
+	offset := int64(0)
+	write := func(b []byte) error {
+		n, err := w.Write(b)
+		offset += int64(n)
+		return err
+	}
+	if err := write([]byte(parquetMagic)); err != nil {
+		return err
+	}
+	var groups []any
+	for start := 0; start < rows; start += options.GroupRows {
+		end := min(start+options.GroupRows, rows)
+		var chunks []any
+		var groupSize, groupCompressed int64
+		for j, col := range columns {
+			page, nulls := encodeParquetPage(kinds[j], col.values[start:end])
+			data := compress(options.Compression, page)
+			header := encodeThrift(nil, []thriftField{
+				{1, int32(0)},
+				{2, int32(len(page))},
+				{3, int32(len(data))},
+				{5, []thriftField{{1, int32(end - start)}, {2, int32(parquetPlain)}, {3, int32(parquetRLE)}, {4, int32(parquetRLE)}}},
+			})
+			pageOffset := offset
+			if err := write(header); err != nil {
+				return err
+			}
+			if err := write(data); err != nil {
+				return err
+			}
+			uncompressed := int64(len(header) + len(page))
+			compressed := int64(len(header) + len(data))
+			groupSize += uncompressed
+			groupCompressed += compressed
+			chunks = append(chunks, []thriftField{
+				{2, pageOffset},
+				{3, []thriftField{
+					{1, int32(parquetPhysicalOf(kinds[j]))},
+					{2, thriftListOf{thriftI32, []any{int32(parquetPlain), int32(parquetRLE)}}},
+					{3, thriftListOf{thriftBinary, []any{names[j]}}},
+					{4, codec},
+					{5, int64(end - start)},
+					{6, uncompressed},
+					{7, compressed},
+					{9, pageOffset},
+					{12, []thriftField{{3, int64(nulls)}}},
+				}},
+			})
+		}
+		groups = append(groups, []thriftField{
+			{1, thriftListOf{thriftStruct, chunks}},
+			{2, groupSize},
+			{3, int64(end - start)},
+			{6, groupCompressed},
+		})
+	}
+
+	schema := []any{[]thriftField{{4, "schema"}, {5, int32(len(columns))}}}
+	for j := range columns {
+		schema = append(schema, parquetSchemaElement(names[j], kinds[j]))
+	}
+	footer := encodeThrift(nil, []thriftField{
+		{1, int32(1)},
+		{2, thriftListOf{thriftStruct, schema}},
+		{3, int64(rows)},
+		{4, thriftListOf{thriftStruct, groups}},
+		{6, "insyra-insights"},
+	})
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(len(footer)))
+	return write(append(footer, parquetMagic...))
+}
+
+// parquetPhysicalOf 儲存型別對應的實體型別
+func parquetPhysicalOf(kind columnarKind) int {
+	switch kind {
+	case kindInt64, kindTimestamp:
+		return parquetInt64
+	case kindDouble:
+		return parquetDouble
+	case kindBool:
+		return parquetBoolean
+	}
+	return parquetByteArray
+}
+
+// parquetSchemaElement 寫出一欄的結構：皆為可缺失（OPTIONAL），並附上 converted type 與 logical type
+func parquetSchemaElement(name string, kind columnarKind) []thriftField {
+	fields := []thriftField{{1, int32(parquetPhysicalOf(kind))}, {3, int32(1)}, {4, name}}
+	switch kind {
+	case kindInt64:
+		fields = append(fields, thriftField{6, int32(18)},
+			thriftField{10, []thriftField{{10, []thriftField{{1, int8(64)}, {2, true}}}}})
+	case kindTimestamp:
+		fields = append(fields, thriftField{6, int32(10)},
+			thriftField{10, []thriftField{{8, []thriftField{{1, true}, {2, []thriftField{{2, []thriftField{}}}}}}}})
+	case kindString:
+		fields = append(fields, thriftField{6, int32(0)},
+			thriftField{10, []thriftField{{1, []thriftField{}}}})
+	}
+	return fields
+}
+
+// encodeParquetPage 以 PLAIN 編碼一頁的值，前面加上長度與 bit-packed 的定義層級；回傳頁面與缺失值數
+func encodeParquetPage(kind columnarKind, values []any) ([]byte, int) {
+	levels := make([]byte, (len(values)+7)/8)
+	var data []byte
+	var flags []bool
+	nulls := 0
+	for i, v := range values {
+		v = columnarValue(kind, v)
+		if v == nil {
+			nulls++
+			continue
+		}
+		levels[i>>3] |= 1 << (i & 7)
+		switch val := v.(type) {
+		case int64:
+			data = binary.LittleEndian.AppendUint64(data, uint64(val))
+		case float64:
+			data = binary.LittleEndian.AppendUint64(data, math.Float64bits(val))
+		case bool:
+			flags = append(flags, val)
+		case string:
+			data = binary.LittleEndian.AppendUint32(data, uint32(len(val)))
+			data = append(data, val...)
+		}
+	}
+	if kind == kindBool {
+		data = make([]byte, (len(flags)+7)/8)
+		for i, b := range flags {
+			if b {
+				data[i>>3] |= 1 << (i & 7)
+			}
+		}
+	}
+	hybrid := binary.AppendUvarint(nil, uint64(len(levels))<<1|1)
+	hybrid = append(hybrid, levels...)
+	page := binary.LittleEndian.AppendUint32(nil, uint32(len(hybrid)))
+	page = append(page, hybrid...)
+	return append(page, data...), nulls
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+// ===== .insa 專案檔案格式 =====
+
+const (
+	// projectFormatName 專案檔案的格式識別字串
+	projectFormatName = "insyra-insights-project"
+	// projectFormatVersion 目前寫出的專案檔案版本，載入時接受不大於此值的版本。
+	// 每次調整檔案結構都要加一，舊版程式才會拒絕開啟它看不懂的檔案：
+	//   - 1：資料表名稱與儲存格
+	//   - 2：計算欄位公式（formula）
+	//   - 3：欄位 ID（columns[].id）
+	//   - 4：資料表 ID（tables[].id）
+	//   - 5：欄位型別（type）
+	//   - 6：缺失值設定（missingPolicy、columns[].missing）
+	//   - 7：變數資訊（variable）
+	projectFormatVersion = 7
+)
+
+// ErrProjectNotFound 專案檔案不存在
+var ErrProjectNotFound = errors.New("project file not found")
+
+// ErrProjectCorrupt 專案檔案內容無法解析或結構不正確
+var ErrProjectCorrupt = errors.New("project file is corrupt")
+
+// ErrProjectTooNew 專案檔案由較新版本的程式寫出，目前的版本無法讀取
+var ErrProjectTooNew = errors.New("project file was saved by a newer version")
+
+// projectFile 專案檔案的最上層結構
+type projectFile struct {
+	Format  string         `json:"format"`
+	Version int            `json:"version"`
+	SavedAt time.Time      `json:"savedAt"`
+	Tables  []projectTable `json:"tables"`
+	// MissingPolicy 專案的缺失值設定；舊版檔案沒有此欄，載入時使用預設設定
+	MissingPolicy *MissingPolicy `json:"missingPolicy,omitempty"`
+}
+
+// projectTable 單一資料表（標籤頁），在 Tables 中的順序即為標籤頁順序
+type projectTable struct {
+	ID      string          `json:"id,omitempty"` // 資料表ID；舊版檔案沒有此欄，載入時重新產生
+	Name    string          `json:"name"`
+	Columns []projectColumn `json:"columns"`
+}
+
+// projectColumn 單一欄位及其所有儲存格
+type projectColumn struct {
+	ID       int               `json:"id,omitempty"` // 欄位 ID；舊版檔案沒有此欄，載入時重新配發
+	Name     string            `json:"name"`
+	Formula  string            `json:"formula,omitempty"`  // 計算欄位的 CCL 公式
+	Type     string            `json:"type,omitempty"`     // 欄位型別；舊版檔案沒有此欄，載入時依值推斷
+	Missing  *MissingPolicy    `json:"missing,omitempty"`  // 欄位自訂的缺失值設定
+	Variable *VariableMetadata `json:"variable,omitempty"` // 變數資訊（標籤、數值標籤等）
+	Values   []any             `json:"values"`
+}
+
+// projectTableState 資料表及需要一併儲存的欄位設定
+type projectTableState struct {
+	id              string
+	dt              *insyra.DataTable
+	formulas        map[int]string           // 計算欄位的公式，以欄位索引為鍵
+	columnIDs       []int                    // 各欄的 ID，0 表示尚未配發
+	columnTypes     map[int]string           // 各欄的型別，以欄位索引為鍵
+	missingPolicies map[int]MissingPolicy    // 各欄自訂的缺失值設定，以欄位索引為鍵
+	variables       map[int]VariableMetadata // 各欄的變數資訊，以欄位索引為鍵
+}
+
+// projectTables 取得所有資料表及其欄位設定，依標籤頁順序排列
+func (s *DataTableService) projectTables() []projectTableState {
+	tables := make([]projectTableState, len(s.tabOrder))
+	for i, tableID := range s.tabOrder {
+		dt := s.tables[tableID]
+		tables[i] = projectTableState{id: tableID, dt: dt, formulas: s.formulaTexts(dt), columnIDs: s.columnIDsOf(dt), columnTypes: s.columnTypeTexts(dt), missingPolicies: s.missingPolicyTexts(dt), variables: s.variableTexts(dt)}
+	}
+	return tables
+}
+
+// encodeProject 將專案的缺失值設定與所有資料表轉換為專案檔案結構
+func encodeProject(missingPolicy MissingPolicy, tables []projectTableState) *projectFile {
+	project := &projectFile{
+		Format:        projectFormatName,
+		Version:       projectFormatVersion,
+		SavedAt:       time.Now().UTC(),
+		Tables:        make([]projectTable, 0, len(tables)),
+		MissingPolicy: &missingPolicy,
+	}
+	for _, state := range tables {
+		dt := state.dt
+		if dt == nil {
+			continue
+		}
+		_, colCount := dt.Size()
+		table := projectTable{
+			ID:      state.id,
+			Name:    dt.GetName(),
+			Columns: make([]projectColumn, colCount),
+		}
+		for j := range colCount {
+			col := dt.GetColByNumber(j)
+			data := col.Data()
+			values := make([]any, len(data))
+			for i, v := range data {
+				values[i] = encodeCell(v)
+			}
+			var id int
+			if j < len(state.columnIDs) {
+				id = state.columnIDs[j]
+			}
+			table.Columns[j] = projectColumn{
+				ID:      id,
+				Name:    col.GetName(),
+				Formula: state.formulas[j],
+				Type:    state.columnTypes[j],
+				Values:  values,
+			}
+			if policy, ok := state.missingPolicies[j]; ok {
+				table.Columns[j].Missing = &policy
+			}
+			if meta, ok := state.variables[j]; ok {
+				table.Columns[j].Variable = &meta
+			}
+		}
+		project.Tables = append(project.Tables, table)
+	}
+	return project
+}
+
+// decodeProject 將專案檔案結構還原為資料表
+func decodeProject(project *projectFile) ([]projectTableState, error) {
+	tables := make([]projectTableState, 0, len(project.Tables))
+	for t, table := range project.Tables {
+		columns := make([]*insyra.DataList, len(table.Columns))
+		formulas := make(map[int]string)
+		columnIDs := make([]int, len(table.Columns))
+		columnTypes := make(map[int]string)
+		missingPolicies := make(map[int]MissingPolicy)
+		variables := make(map[int]VariableMetadata)
+		for j, column := range table.Columns {
+			if column.Variable != nil {
+				// 數值標籤的值以 UseNumber 解析為 json.Number，需還原為數值才能對應儲存格
+				meta := *column.Variable
+				for k, vl := range meta.ValueLabels {
+					v, err := decodeCell(vl.Value)
+					if err != nil {
+						return nil, fmt.Errorf("%w: table %d column %q value label %d: %v", ErrProjectCorrupt, t, column.Name, k, err)
+					}
+					meta.ValueLabels[k].Value = v
+				}
+				variables[j] = meta
+			}
+			columnIDs[j] = column.ID
+			if column.Missing != nil {
+				missingPolicies[j] = *column.Missing
+			}
+			if column.Type != "" {
+				columnTypes[j] = column.Type
+			}
+			if column.Formula != "" {
+				formulas[j] = column.Formula
+			}
+			values := make([]any, len(column.Values))
+			for i, raw := range column.Values {
+				v, err := decodeCell(raw)
+				if err != nil {
+					return nil, fmt.Errorf("%w: table %d column %q row %d: %v", ErrProjectCorrupt, t, column.Name, i, err)
+				}
+				values[i] = v
+			}
+			columns[j] = insyra.NewDataList(values...).SetName(column.Name)
+		}
+		dt := newTableFromColumns(columns)
+		dt.SetName(table.Name)
+		tables = append(tables, projectTableState{id: table.ID, dt: dt, formulas: formulas, columnIDs: columnIDs, columnTypes: columnTypes, missingPolicies: missingPolicies, variables: variables})
+	}
+	return tables, nil
+}
+
+// writeProjectFile 將專案寫入檔案；先寫入暫存檔再改名，避免寫到一半時損毀原檔
+func writeProjectFile(filePath string, project *projectFile) error {
+	data, err := json.Marshal(project)
+	if err != nil {
+		return fmt.Errorf("encode project: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create project file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("write project file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("write project file: %w", err)
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("write project file: %w", err)
+	}
+	return nil
+}
+
+// readProjectFile 讀取並驗證專案檔案
+func readProjectFile(filePath string) (*projectFile, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrProjectNotFound, filePath)
+		}
+		return nil, fmt.Errorf("read project file: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var project projectFile
+	if err := decoder.Decode(&project); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProjectCorrupt, err)
+	}
+	if project.Format != projectFormatName {
+		return nil, fmt.Errorf("%w: unknown format %q", ErrProjectCorrupt, project.Format)
+	}
+	if project.Version > projectFormatVersion {
+		return nil, fmt.Errorf("%w: version %d, supported up to %d", ErrProjectTooNew, project.Version, projectFormatVersion)
+	}
+	if project.Version < 1 {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrProjectCorrupt, project.Version)
+	}
+	return &project, nil
+}
+
+// encodeCell 將儲存格的值轉為可保留型別的 JSON 值
+//   - 整數寫成不含小數點的數字，浮點數一定帶有小數點或指數
+//   - NaN、Inf 與時間以 {"float": ...}、{"time": ...} 物件表示
+func encodeCell(v any) any {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case bool, string:
+		return val
+	case int:
+		return json.Number(strconv.FormatInt(int64(val), 10))
+	case int8:
+		return json.Number(strconv.FormatInt(int64(val), 10))
+	case int16:
+		return json.Number(strconv.FormatInt(int64(val), 10))
+	case int32:
+		return json.Number(strconv.FormatInt(int64(val), 10))
+	case int64:
+		return json.Number(strconv.FormatInt(val, 10))
+	case uint:
+		return json.Number(strconv.FormatUint(uint64(val), 10))
+	case uint8:
+		return json.Number(strconv.FormatUint(uint64(val), 10))
+	case uint16:
+		return json.Number(strconv.FormatUint(uint64(val), 10))
+	case uint32:
+		return json.Number(strconv.FormatUint(uint64(val), 10))
+	case uint64:
+		return json.Number(strconv.FormatUint(val, 10))
+	case float32:
+		return encodeFloat(float64(val))
+	case float64:
+		return encodeFloat(val)
+	case time.Time:
+		return map[string]string{"time": val.Format(time.RFC3339Nano)}
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func encodeFloat(f float64) any {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return map[string]string{"float": strconv.FormatFloat(f, 'g', -1, 64)}
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return json.Number(s)
+}
+
+// decodeCell 是 encodeCell 的反向操作，raw 需以 json.Decoder.UseNumber 解析
+func decodeCell(raw any) (any, error) {
+	switch val := raw.(type) {
+	case nil, bool, string:
+		return val, nil
+	case json.Number:
+		s := val.String()
+		if strings.ContainsAny(s, ".eE") {
+			return val.Float64()
+		}
+		i, err := val.Int64()
+		if err != nil {
+			// 超出 int64 範圍的整數以浮點數保存
+			return val.Float64()
+		}
+		return int(i), nil
+	case map[string]any:
+		if s, ok := val["time"].(string); ok && len(val) == 1 {
+			return time.Parse(time.RFC3339Nano, s)
+		}
+		if s, ok := val["float"].(string); ok && len(val) == 1 {
+			return strconv.ParseFloat(s, 64)
+		}
+		return nil, fmt.Errorf("unknown cell object %v", val)
+	default:
+		return nil, fmt.Errorf("unsupported cell value %T", raw)
+	}
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadProjectFileRejectsNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "future.insa")
+	data := `{"format":"insyra-insights-project","version":999,"tables":[]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := readProjectFile(path)
+	if !errors.Is(err, ErrProjectTooNew) {
+		t.Fatalf("readProjectFile() error = %v, want ErrProjectTooNew", err)
+	}
+	if se := AsServiceError(err); se.MessageKey != "errors.project_too_new" {
+		t.Fatalf("message key = %q, want errors.project_too_new", se.MessageKey)
+	}
+}
+
+func TestReadProjectFileAcceptsOlderVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.insa")
+	data := `{"format":"insyra-insights-project","version":1,"tables":[{"name":"t","columns":[{"name":"A","values":[1,2.5,"x"]}]}]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	project, err := readProjectFile(path)
+	if err != nil {
+		t.Fatalf("readProjectFile() error = %v", err)
+	}
+	tables, err := decodeProject(project)
+	if err != nil {
+		t.Fatalf("decodeProject() error = %v", err)
+	}
+	if got := tables[0].dt.GetColByNumber(0).Data(); len(got) != 3 || got[0] != 1 || got[1] != 2.5 || got[2] != "x" {
+		t.Fatalf("values = %v", got)
+	}
+}
+
+// 變數資訊隨欄位 ID 存入專案，搬移欄位後儲存再載入仍對應到原本的欄
+func TestProjectRoundTripKeepsVariableMetadata(t *testing.T) {
+	s, id, _ := newStructureTestTable(t)
+	metas := map[int]VariableMetadata{
+		0: {
+			Label:       "Satisfaction",
+			Description: "1 到 3 分",
+			Measure:     MeasureOrdinal,
+			ValueLabels: []ValueLabel{{Value: 3, Label: "High"}, {Value: 1, Label: "Low"}, {Value: 2.5, Label: "Mid"}},
+			Format:      "F8.2",
+			Width:       12,
+		},
+		1: {Label: "Region", ValueLabels: []ValueLabel{{Value: "a", Label: "North"}, {Value: "b", Label: "South"}}},
+	}
+	for col, meta := range metas {
+		if err := s.SetVariableMetadata(id, col, meta); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A 移到最後：C、B、A 的順序與索引都和設定時不同
+	if err := s.MoveColumnsByID(id, 0, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	want, err := s.GetVariableView(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want[2].Name != "A" || want[2].Label != "Satisfaction" {
+		t.Fatalf("before saving, column 2 = %+v", want[2])
+	}
+
+	path := filepath.Join(t.TempDir(), "vars.insa")
+	if err := s.SaveProject(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewDataTableService()
+	if err := loaded.LoadProject(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loaded.GetVariableView(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("after loading:\n got %#v\nwant %#v", got, want)
+	}
+
+	// 無法還原的數值標籤視為損壞的專案
+	bad := filepath.Join(t.TempDir(), "bad.insa")
+	data := `{"format":"insyra-insights-project","version":1,"tables":[{"name":"t","columns":[{"name":"A","values":[1],"variable":{"valueLabels":[{"value":{"x":1},"label":"?"}]}}]}]}`
+	if err := os.WriteFile(bad, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.LoadProject(bad); !errors.Is(err, ErrProjectCorrupt) {
+		t.Errorf("LoadProject() error = %v, want ErrProjectCorrupt", err)
+	}
+}
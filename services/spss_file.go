@@ -0,0 +1,972 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// ===== SPSS .sav 匯入與匯出 =====
+//
+// 讀取未壓縮或以位元組碼壓縮的系統檔案（$FL2），寫出未壓縮、以 UTF-8 編碼的系統檔案。
+// 系統缺失值讀為 nil；離散的使用者缺失值成為該欄的缺失值設定，落在缺失值範圍內的值讀為 nil。
+
+const (
+	// spssBias 位元組碼壓縮中數值 1–251 代表 code - bias
+	spssBias = 100
+	// spssSegment 超長文字每一段實際存放的位元組數
+	spssSegment = 252
+	// spssMaxStr 文字變數的最大位元組數
+	spssMaxStr = 32767
+	// spssMaxLabel 數值標籤的最大位元組數
+	spssMaxLabel = 120
+)
+
+// SPSS 的浮點數特殊值：系統缺失值、最大值與最小值（用於缺失值範圍的 HI 與 LO）
+var (
+	spssSysmis  = math.Float64frombits(0xffefffffffffffff)
+	spssHighest = math.Float64frombits(0x7fefffffffffffff)
+	spssLowest  = math.Float64frombits(0xffeffffffffffffe)
+)
+
+// spssEpoch SPSS 日期時間的起點，以秒為單位
+var spssEpoch = time.Date(1582, 10, 14, 0, 0, 0, 0, time.UTC)
+
+// spssFormats SPSS 顯示格式代碼與名稱
+var spssFormats = map[int]string{
+	1: "A", 2: "AHEX", 3: "COMMA", 4: "DOLLAR", 5: "F", 6: "IB", 7: "PIBHEX", 8: "P", 9: "PIB", 10: "PK",
+	11: "RB", 12: "RBHEX", 15: "Z", 16: "N", 17: "E", 20: "DATE", 21: "TIME", 22: "DATETIME", 23: "ADATE",
+	24: "JDATE", 25: "DTIME", 26: "WKDAY", 27: "MONTH", 28: "MOYR", 29: "QYR", 30: "WKYR", 31: "PCT",
+	32: "DOT", 33: "CCA", 34: "CCB", 35: "CCC", 36: "CCD", 37: "CCE", 38: "EDATE", 39: "SDATE",
+	40: "MTIME", 41: "YMDHMS",
+}
+
+// spssDateFormats 表示時間點（而非時間長度）的顯示格式代碼
+var spssDateFormats = map[int]bool{20: true, 22: true, 23: true, 24: true, 28: true, 29: true, 30: true, 38: true, 39: true, 41: true}
+
+// spssReserved SPSS 保留的變數名稱（不分大小寫）
+var spssReserved = map[string]bool{
+	"ALL": true, "AND": true, "BY": true, "EQ": true, "GE": true, "GT": true, "LE": true,
+	"LT": true, "NE": true, "NOT": true, "OR": true, "TO": true, "WITH": true,
+}
+
+// spssFormatPattern 文字形式的顯示格式，例如 "F8.2"、"A20"
+var spssFormatPattern = regexp.MustCompile(`^([A-Z]+)(\d+)(?:\.(\d+))?$`)
+
+// OpenSPSSFile 開啟 SPSS .sav 檔案並創建新的資料表，一併讀入變數標籤、數值標籤與缺失值定義
+func (s *DataTableService) OpenSPSSFile(filePath string) (string, error) {
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("read spss file: %w", err)
+	}
+	columns, err := readSPSS(raw)
+	if err != nil {
+		return "", err
+	}
+	return s.appendStatTable(tableNameFromPath(filePath), columns), nil
+}
+
+// ExportTableAsSPSS 將資料表匯出為 SPSS .sav 檔案，包含變數標籤、數值標籤與數值的缺失值定義
+func (s *DataTableService) ExportTableAsSPSS(tableID string, filePath string) error {
+	s.lock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		s.unlock()
+		return errTableNotFound(tableID)
+	}
+	name, columns := dt.GetName(), s.statColumns(dt)
+	s.unlock()
+
+	data, err := writeSPSS(name, columns)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
+
+// ----- 讀取 -----
+
+// spssVariable 字典中的一個變數記錄；超長文字的每一段各是一個記錄
+type spssVariable struct {
+	name     []byte
+	width    int // 文字的位元組數，0 表示數值
+	slot     int // 在每筆觀察值中的第一個 8 位元組位置
+	format   int // 顯示格式（type<<16 | width<<8 | decimals）
+	label    []byte
+	missing  [][]byte // 離散的缺失值
+	lo, hi   float64  // 缺失值範圍
+	hasRange bool
+	labels   [][2][]byte // 數值標籤：原始的 8 位元組值與標籤
+	measure  string
+	display  int
+	segments int // 超長文字合併後的段數
+}
+
+// slots 變數佔用的 8 位元組數
+func (v *spssVariable) slots() int {
+	if v.width == 0 {
+		return 1
+	}
+	return (v.width + 7) / 8
+}
+
+// spssReader 依序讀取 .sav 檔案的內容
+type spssReader struct {
+	statReader
+	sysmis  float64
+	decoder *encoding.Decoder
+}
+
+// readSPSS 解析 .sav 檔案
+func readSPSS(raw []byte) ([]statColumn, error) {
+	r := &spssReader{statReader: statReader{data: raw, format: "spss"}, sysmis: spssSysmis}
+	switch {
+	case bytes.HasPrefix(raw, []byte("$FL3")):
+		return nil, errFileUnsupported("spss", "zlib compression")
+	case !bytes.HasPrefix(raw, []byte("$FL2")) || len(raw) < 176:
+		return nil, errFileInvalid("spss", "not an spss system file")
+	}
+	r.order = binary.LittleEndian
+	if layout := binary.LittleEndian.Uint32(raw[64:]); layout != 2 && layout != 3 {
+		r.order = binary.BigEndian
+	}
+	r.skip(72)
+	compression := int32(r.u32())
+	r.skip(4)
+	cases := int32(r.u32())
+	bias := math.Float64frombits(r.u64())
+	r.skip(9 + 8 + 64 + 3)
+	if compression > 1 {
+		return nil, errFileUnsupported("spss", "zlib compression")
+	}
+
+	vars, extensions, encodingName := r.readDictionary()
+	if r.err != nil {
+		return nil, r.err
+	}
+	r.decoder = spssDecoder(extensions[3], encodingName, r.order)
+	values := r.readCases(vars, int(cases), compression == 1, bias)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.finishColumns(vars, values, extensions), nil
+}
+
+// readDictionary 讀取字典中的變數、數值標籤與擴充記錄，直到 999 記錄為止
+func (r *spssReader) readDictionary() ([]*spssVariable, map[int][]byte, string) {
+	var vars []*spssVariable
+	bySlot := make(map[int]*spssVariable)
+	slot := 0
+	extensions := make(map[int][]byte)
+	encodingName := ""
+	for r.err == nil {
+		switch kind := int32(r.u32()); kind {
+		case 2:
+			width := int32(r.u32())
+			hasLabel := r.u32()
+			missing := int32(r.u32())
+			format := int(r.u32())
+			r.skip(4)
+			name := bytes.TrimRight(r.bytes(8), " ")
+			// 變數記錄的寬度最多 255，超長文字分成多個記錄
+			if width < -1 || width > 255 {
+				r.fail(fmt.Sprintf("invalid variable width %d", width))
+				break
+			}
+			if missing < -3 || missing > 3 {
+				r.fail(fmt.Sprintf("invalid missing value count %d", missing))
+				break
+			}
+			v := &spssVariable{name: name, width: int(width), slot: slot, format: format}
+			if hasLabel != 0 {
+				n := int(r.u32())
+				v.label = r.bytes(n)
+				r.skip((4 - n%4) % 4)
+			}
+			if missing == -2 || missing == -3 {
+				v.hasRange = true
+				v.lo = math.Float64frombits(r.u64())
+				v.hi = math.Float64frombits(r.u64())
+				missing = -missing - 2
+			}
+			for range max(missing, 0) {
+				v.missing = append(v.missing, r.bytes(8))
+			}
+			slot++
+			if width < 0 {
+				// 前一個文字變數的延續
+				continue
+			}
+			vars = append(vars, v)
+			bySlot[v.slot] = v
+		case 3:
+			// 每個數值標籤至少佔 16 個位元組
+			n := int(r.u32())
+			if n < 0 || n > (len(r.data)-r.pos)/16 {
+				r.fail("value labels are truncated")
+				break
+			}
+			labels := make([][2][]byte, 0, n)
+			for range n {
+				value := r.bytes(8)
+				size := int(r.u8())
+				label := r.bytes(size)
+				r.skip((8 - (size+1)%8) % 8)
+				labels = append(labels, [2][]byte{value, label})
+			}
+			if r.u32() != 4 {
+				r.fail("value labels are not followed by a variable index record")
+				break
+			}
+			count := int(r.u32())
+			if count < 0 || count > (len(r.data)-r.pos)/4 {
+				r.fail("variable index record is truncated")
+				break
+			}
+			for range count {
+				if v := bySlot[int(r.u32())-1]; v != nil {
+					v.labels = append(v.labels, labels...)
+				}
+			}
+		case 6:
+			r.skip(int(r.u32()) * 80)
+		case 7:
+			subtype := int(r.u32())
+			size := int(r.u32())
+			count := int(r.u32())
+			if size < 0 || count < 0 || (size > 0 && count > (len(r.data)-r.pos)/size) {
+				r.fail("extension record is truncated")
+				break
+			}
+			content := r.bytes(size * count)
+			extensions[subtype] = content
+			if subtype == 20 {
+				encodingName = string(content)
+			}
+		case 999:
+			r.skip(4)
+			return vars, extensions, encodingName
+		default:
+			r.fail(fmt.Sprintf("unknown record type %d", kind))
+		}
+	}
+	return vars, extensions, encodingName
+}
+
+// spssDecoder 依字元編碼記錄決定文字的解碼方式，nil 表示 UTF-8
+func spssDecoder(info []byte, name string, order binary.ByteOrder) *encoding.Decoder {
+	if name != "" {
+		if strings.EqualFold(name, "UTF-8") {
+			return nil
+		}
+		if enc, err := htmlindex.Get(name); err == nil {
+			return enc.NewDecoder()
+		}
+	}
+	if len(info) >= 32 {
+		switch code := int32(order.Uint32(info[28:])); code {
+		case 65001:
+			return nil
+		case 2, 3, 1252:
+			return charmap.Windows1252.NewDecoder()
+		default:
+			if enc, err := htmlindex.Get("windows-" + strconv.Itoa(int(code))); err == nil {
+				return enc.NewDecoder()
+			}
+		}
+	}
+	return charmap.Windows1252.NewDecoder()
+}
+
+// text 解碼文字並去除結尾的空白
+func (r *spssReader) text(b []byte) string {
+	if r.decoder != nil {
+		if decoded, err := r.decoder.Bytes(b); err == nil {
+			b = decoded
+		}
+	}
+	return strings.TrimRight(string(b), " \x00")
+}
+
+// readCases 讀取所有觀察值，回傳每個 8 位元組位置的原始內容；cases 為 -1 時讀到資料結束為止
+func (r *spssReader) readCases(vars []*spssVariable, cases int, compressed bool, bias float64) [][][]byte {
+	width := 0
+	if len(vars) > 0 {
+		last := vars[len(vars)-1]
+		width = last.slot + last.slots()
+	}
+	if width == 0 {
+		return nil
+	}
+	var rows [][][]byte
+	var commands []byte
+	// 壓縮碼 1–251 與 255 對應的 8 位元組數值
+	var numbers [256][]byte
+	for code := range numbers {
+		f := float64(code) - bias
+		if code == 255 {
+			f = r.sysmis
+		}
+		numbers[code] = make([]byte, 8)
+		r.order.PutUint64(numbers[code], math.Float64bits(f))
+	}
+	spaces := []byte("        ")
+	for cases < 0 || len(rows) < cases {
+		if r.pos >= len(r.data) {
+			break
+		}
+		row := make([][]byte, 0, width)
+		ended := false
+		// 讀取失敗時 r.bytes 不會前進，必須立即停止
+		for len(row) < width && !ended && r.err == nil {
+			if !compressed {
+				row = append(row, r.bytes(8))
+				continue
+			}
+			if len(commands) == 0 {
+				if r.pos >= len(r.data) {
+					ended = true
+					break
+				}
+				commands = r.bytes(8)
+			}
+			code := commands[0]
+			commands = commands[1:]
+			switch code {
+			case 0:
+			case 252:
+				ended = true
+			case 253:
+				row = append(row, r.bytes(8))
+			case 254:
+				row = append(row, spaces)
+			default:
+				row = append(row, numbers[code])
+			}
+		}
+		if r.err != nil {
+			return nil
+		}
+		if ended {
+			if len(row) > 0 {
+				r.fail("data section is truncated")
+			}
+			break
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// finishColumns 合併超長文字的各段，並將原始內容轉為欄位的值、型別、變數資訊與缺失值設定
+func (r *spssReader) finishColumns(vars []*spssVariable, rows [][][]byte, extensions map[int][]byte) []statColumn {
+	r.applyDisplay(vars, extensions[11])
+	vars = r.mergeLongStrings(vars, extensions[14])
+	longNames := r.longNames(extensions[13])
+	longLabels := r.longStringLabels(extensions[21])
+
+	columns := make([]statColumn, len(vars))
+	for j, v := range vars {
+		name := r.text(v.name)
+		if long, ok := longNames[strings.ToUpper(name)]; ok {
+			name = long
+		}
+		col := statColumn{
+			name:   name,
+			values: make([]any, len(rows)),
+			meta:   VariableMetadata{Label: r.text(v.label), Measure: v.measure, Width: v.display},
+		}
+		formatType, decimals := v.format>>16&0xff, v.format&0xff
+		if formatName, ok := spssFormats[formatType]; ok {
+			formatWidth := v.format >> 8 & 0xff
+			if v.width > 255 {
+				// 超長文字的格式記錄在第一段，寬度只到 255
+				formatWidth = v.width
+			}
+			col.meta.Format = formatName + strconv.Itoa(formatWidth)
+			if decimals > 0 {
+				col.meta.Format += "." + strconv.Itoa(decimals)
+			}
+		}
+		for i, row := range rows {
+			col.values[i] = r.cell(v, row)
+		}
+		labels := longLabels[strings.ToUpper(name)]
+		for _, vl := range v.labels {
+			labels = append(labels, ValueLabel{Value: r.cell(&spssVariable{width: v.width}, [][]byte{vl[0]}), Label: r.text(vl[1])})
+		}
+		seen := make(map[string]int)
+		for _, label := range labels {
+			if label.Value == nil {
+				continue
+			}
+			// 重複的數值以後面的標籤為準
+			key := formatValue(label.Value)
+			if k, ok := seen[key]; ok {
+				col.meta.ValueLabels[k] = label
+				continue
+			}
+			seen[key] = len(col.meta.ValueLabels)
+			col.meta.ValueLabels = append(col.meta.ValueLabels, label)
+		}
+		var tokens []string
+		for _, m := range v.missing {
+			if value := r.cell(&spssVariable{width: v.width}, [][]byte{m}); value != nil {
+				tokens = append(tokens, formatValue(value))
+			}
+		}
+		if len(tokens) > 0 {
+			col.missing = &MissingPolicy{Tokens: tokens, ExportToken: tokens[0]}
+		}
+
+		switch {
+		case v.width > 0:
+			col.dataType = DataTypeString
+		case spssDateFormats[formatType]:
+			col.dataType = DataTypeDateTime
+			for i, value := range col.values {
+				if f, ok := value.(float64); ok {
+					sec, frac := math.Modf(f)
+					col.values[i] = time.Unix(spssEpoch.Unix()+int64(sec), int64(math.Round(frac*1e9))).UTC()
+				}
+			}
+		default:
+			if v.hasRange {
+				for i, value := range col.values {
+					if f, ok := value.(float64); ok && f >= v.lo && f <= v.hi {
+						col.values[i] = nil
+					}
+				}
+			}
+			col.dataType = DataTypeNumeric
+			if decimals == 0 && integralValues(col.values) {
+				col.dataType = DataTypeInteger
+			}
+		}
+		if col.meta.Measure == "" && len(col.meta.ValueLabels) > 0 {
+			col.meta.Measure = MeasureNominal
+		}
+		columns[j] = col
+	}
+	return columns
+}
+
+// cell 將變數在一筆觀察值中的原始內容轉為值：數值的系統缺失值與空白文字為 nil
+func (r *spssReader) cell(v *spssVariable, row [][]byte) any {
+	if v.width == 0 {
+		if v.slot >= len(row) {
+			return nil
+		}
+		f := math.Float64frombits(r.order.Uint64(row[v.slot]))
+		if f == r.sysmis || math.IsNaN(f) {
+			return nil
+		}
+		return f
+	}
+	var b []byte
+	segments := max(v.segments, 1)
+	slot := v.slot
+	for seg := range segments {
+		// 超長文字除了最後一段外，每段佔 255 個位元組（32 個位置），只使用前 252 個位元組
+		size := v.width
+		if segments > 1 {
+			size = min(v.width-seg*spssSegment, 255)
+		}
+		n := (size + 7) / 8
+		var chunk []byte
+		for k := slot; k < slot+n && k < len(row); k++ {
+			chunk = append(chunk, row[k]...)
+		}
+		used := min(size, len(chunk))
+		if seg < segments-1 {
+			used = min(spssSegment, used)
+		}
+		b = append(b, chunk[:used]...)
+		slot += n
+	}
+	if text := r.text(b); text != "" {
+		return text
+	}
+	return nil
+}
+
+// applyDisplay 套用各變數記錄的測量尺度與顯示寬度（擴充記錄 11）
+func (r *spssReader) applyDisplay(vars []*spssVariable, content []byte) {
+	if len(content) != 12*len(vars) {
+		return
+	}
+	for j, v := range vars {
+		switch r.order.Uint32(content[12*j:]) {
+		case 1:
+			v.measure = MeasureNominal
+		case 2:
+			v.measure = MeasureOrdinal
+		case 3:
+			v.measure = MeasureScale
+		}
+		v.display = int(int32(r.order.Uint32(content[12*j+4:])))
+	}
+}
+
+// mergeLongStrings 將超長文字（擴充記錄 14）的各段合併為一個變數
+func (r *spssReader) mergeLongStrings(vars []*spssVariable, content []byte) []*spssVariable {
+	widths := make(map[string]int)
+	for _, entry := range strings.Split(string(content), "\t") {
+		name, width, ok := strings.Cut(strings.Trim(entry, "\x00"), "=")
+		if n, err := strconv.Atoi(strings.TrimSpace(width)); ok && err == nil {
+			widths[strings.ToUpper(name)] = n
+		}
+	}
+	merged := make([]*spssVariable, 0, len(vars))
+	for j := 0; j < len(vars); j++ {
+		v := vars[j]
+		merged = append(merged, v)
+		width, ok := widths[strings.ToUpper(r.text(v.name))]
+		if !ok || width <= 255 {
+			continue
+		}
+		v.segments = (width + spssSegment - 1) / spssSegment
+		v.width = width
+		j += v.segments - 1
+	}
+	return merged
+}
+
+// longNames 解析長變數名稱（擴充記錄 13），以大寫的短名稱為鍵
+func (r *spssReader) longNames(content []byte) map[string]string {
+	names := make(map[string]string)
+	for _, entry := range strings.Split(r.text(content), "\t") {
+		if short, long, ok := strings.Cut(entry, "="); ok && long != "" {
+			names[strings.ToUpper(short)] = long
+		}
+	}
+	return names
+}
+
+// longStringLabels 解析長文字變數的數值標籤（擴充記錄 21），以大寫的變數名稱為鍵
+func (r *spssReader) longStringLabels(content []byte) map[string][]ValueLabel {
+	labels := make(map[string][]ValueLabel)
+	sub := &statReader{data: content, order: r.order, format: r.format}
+	for sub.err == nil && sub.pos < len(content) {
+		name := strings.ToUpper(r.text(sub.bytes(int(sub.u32()))))
+		sub.skip(4)
+		n := int(sub.u32())
+		for i := 0; i < n && sub.err == nil; i++ {
+			value := r.text(sub.bytes(int(sub.u32())))
+			label := r.text(sub.bytes(int(sub.u32())))
+			if sub.err == nil && value != "" {
+				labels[name] = append(labels[name], ValueLabel{Value: value, Label: label})
+			}
+		}
+	}
+	return labels
+}
+
+// ----- 寫出 -----
+
+// spssOutput 寫出時的一個變數
+type spssOutput struct {
+	column   statColumn
+	record   int // 第一個變數記錄的序號，用於產生短名稱
+	long     string
+	width    int // 文字的位元組數，0 表示數值
+	format   int
+	date     bool
+	segments []int // 各段的寬度；一般變數只有一段
+	slot     int
+}
+
+// spssWriter 寫出 .sav 檔案
+type spssWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *spssWriter) i32(v int) { w.buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(int32(v)))) }
+func (w *spssWriter) f64(v float64) {
+	w.buf.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(v)))
+}
+
+// text 寫出長度為 n、以空白補齊的文字
+func (w *spssWriter) text(s string, n int) {
+	s = truncateBytes(s, n)
+	w.buf.WriteString(s)
+	w.buf.WriteString(strings.Repeat(" ", n-len(s)))
+}
+
+// extension 寫出擴充記錄
+func (w *spssWriter) extension(subtype int, size int, content []byte) {
+	w.i32(7)
+	w.i32(subtype)
+	w.i32(size)
+	w.i32(len(content) / size)
+	w.buf.Write(content)
+}
+
+// writeSPSS 將變數寫成未壓縮的 .sav 檔案
+func writeSPSS(name string, columns []statColumn) ([]byte, error) {
+	names := make([]string, len(columns))
+	for j, col := range columns {
+		names[j] = col.name
+	}
+	names = statVariableNames(names, isSPSSIdentRune, 64, true, spssReserved)
+	outputs := make([]*spssOutput, len(columns))
+	slot, records := 0, 0
+	for j, col := range columns {
+		out := spssOutputOf(col)
+		out.long = names[j]
+		out.slot = slot
+		out.record = records
+		for _, width := range out.segments {
+			slot += max((width+7)/8, 1)
+			records++
+		}
+		outputs[j] = out
+	}
+	n := 0
+	if len(columns) > 0 {
+		n = len(columns[0].values)
+	}
+	if n > math.MaxInt32 {
+		return nil, invalidArgument("spss files support at most %d cases, got %d", math.MaxInt32, n)
+	}
+
+	w := &spssWriter{}
+	now := time.Now()
+	w.buf.WriteString("$FL2")
+	w.text("@(#) SPSS DATA FILE insyra-insights", 60)
+	w.i32(2)
+	w.i32(slot)
+	w.i32(0)
+	w.i32(0)
+	w.i32(n)
+	w.f64(spssBias)
+	w.text(now.Format("02 Jan 06"), 9)
+	w.text(now.Format("15:04:05"), 8)
+	w.text(name, 64)
+	w.buf.Write(make([]byte, 3))
+
+	for _, out := range outputs {
+		w.writeVariable(out)
+	}
+	for _, out := range outputs {
+		w.writeValueLabels(out)
+	}
+	w.writeExtensions(outputs)
+	w.i32(999)
+	w.i32(0)
+
+	for i := range n {
+		for _, out := range outputs {
+			w.writeCell(out, out.column.values[i])
+		}
+	}
+	return w.buf.Bytes(), nil
+}
+
+// spssOutputOf 依欄位型別決定變數的寬度與顯示格式
+func spssOutputOf(col statColumn) *spssOutput {
+	out := &spssOutput{column: col}
+	switch {
+	case col.dataType == DataTypeDateTime:
+		out.date = true
+		out.format = 22<<16 | 20<<8
+		if allMidnight(col.values) {
+			out.format = 20<<16 | 11<<8
+		}
+	case numericStatColumn(col):
+		digits, decimals := 1, 0
+		for _, v := range col.values {
+			f, ok := statNumber(v)
+			if !ok {
+				continue
+			}
+			whole, frac, _ := strings.Cut(strconv.FormatFloat(f, 'f', -1, 64), ".")
+			digits = max(digits, len(whole))
+			decimals = max(decimals, min(len(frac), 6))
+		}
+		width := digits + 1
+		if decimals > 0 {
+			width += decimals + 1
+		}
+		out.format = 5<<16 | min(max(width, 8), 40)<<8 | decimals
+	default:
+		out.width = 1
+		for _, v := range col.values {
+			if v != nil {
+				out.width = max(out.width, len(formatValue(v)))
+			}
+		}
+		out.width = min(out.width, spssMaxStr)
+		out.format = 1<<16 | min(out.width, 255)<<8
+	}
+	// 文字變數的格式寬度必須等於變數寬度，只有數值變數沿用變數資訊中的格式
+	if format, ok := parseSPSSFormat(col.meta.Format); ok && out.width == 0 && format>>16 > 2 && spssDateFormats[format>>16] == out.date {
+		out.format = format
+	}
+
+	out.segments = []int{0}
+	if out.width > 0 {
+		out.segments = []int{out.width}
+	}
+	if out.width > 255 {
+		out.segments = nil
+		for rest := out.width; rest > 0; rest -= spssSegment {
+			if rest > spssSegment {
+				out.segments = append(out.segments, 255)
+			} else {
+				out.segments = append(out.segments, rest)
+			}
+		}
+	}
+	return out
+}
+
+// parseSPSSFormat 解析文字形式的顯示格式，例如 "F8.2"
+func parseSPSSFormat(text string) (int, bool) {
+	m := spssFormatPattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(text)))
+	if m == nil {
+		return 0, false
+	}
+	for code, name := range spssFormats {
+		if name != m[1] {
+			continue
+		}
+		width, _ := strconv.Atoi(m[2])
+		decimals, _ := strconv.Atoi(m[3])
+		if width < 1 || width > 255 || decimals > 16 || decimals >= width {
+			return 0, false
+		}
+		return code<<16 | width<<8 | decimals, true
+	}
+	return 0, false
+}
+
+// writeVariable 寫出變數記錄；文字每 8 個位元組之後以延續記錄表示，超長文字的每一段各寫一組
+func (w *spssWriter) writeVariable(out *spssOutput) {
+	for seg, width := range out.segments {
+		w.i32(2)
+		w.i32(width)
+		label := ""
+		if seg == 0 {
+			label = truncateBytes(out.column.meta.Label, 255)
+		}
+		w.i32(min(len(label), 1))
+		var missing []float64
+		if seg == 0 && out.width == 0 && !out.date && out.column.missing != nil {
+			for _, token := range out.column.missing.Tokens {
+				if f, err := strconv.ParseFloat(token, 64); err == nil && len(missing) < 3 {
+					missing = append(missing, f)
+				}
+			}
+		}
+		w.i32(len(missing))
+		format := out.format
+		if seg > 0 {
+			format = 1<<16 | width<<8
+		}
+		w.i32(format)
+		w.i32(format)
+		w.text(spssShortName(out.record+seg), 8)
+		if label != "" {
+			w.i32(len(label))
+			w.buf.WriteString(label)
+			w.buf.Write(make([]byte, (4-len(label)%4)%4))
+		}
+		for _, f := range missing {
+			w.f64(f)
+		}
+		for range (width+7)/8 - 1 {
+			w.i32(2)
+			w.i32(-1)
+			w.i32(0)
+			w.i32(0)
+			w.i32(1<<16 | 1<<8)
+			w.i32(1<<16 | 1<<8)
+			w.text("", 8)
+		}
+	}
+}
+
+// writeValueLabels 寫出變數的數值標籤；文字變數只有寬度不超過 8 個位元組時才能有數值標籤
+func (w *spssWriter) writeValueLabels(out *spssOutput) {
+	if out.date || out.width > 8 {
+		return
+	}
+	type entry struct {
+		value []byte
+		label string
+	}
+	var entries []entry
+	for _, vl := range out.column.meta.ValueLabels {
+		var value []byte
+		if out.width == 0 {
+			f, ok := statNumber(vl.Value)
+			if !ok {
+				continue
+			}
+			value = binary.LittleEndian.AppendUint64(nil, math.Float64bits(f))
+		} else {
+			text := formatValue(vl.Value)
+			if len(text) > out.width {
+				continue
+			}
+			value = []byte(text + strings.Repeat(" ", 8-len(text)))
+		}
+		entries = append(entries, entry{value, truncateBytes(vl.Label, spssMaxLabel)})
+	}
+	if len(entries) == 0 {
+		return
+	}
+	w.i32(3)
+	w.i32(len(entries))
+	for _, e := range entries {
+		w.buf.Write(e.value)
+		w.buf.WriteByte(byte(len(e.label)))
+		w.buf.WriteString(e.label)
+		w.buf.Write(make([]byte, (8-(len(e.label)+1)%8)%8))
+	}
+	w.i32(4)
+	w.i32(1)
+	w.i32(out.slot + 1)
+}
+
+// writeExtensions 寫出機器資訊、浮點數資訊、顯示參數、長變數名稱、超長文字與字元編碼的擴充記錄
+func (w *spssWriter) writeExtensions(outputs []*spssOutput) {
+	var ints []byte
+	for _, v := range []int{1, 0, 0, -1, 1, 1, 2, 65001} {
+		ints = binary.LittleEndian.AppendUint32(ints, uint32(int32(v)))
+	}
+	w.extension(3, 4, ints)
+	var floats []byte
+	for _, f := range []float64{spssSysmis, spssHighest, spssLowest} {
+		floats = binary.LittleEndian.AppendUint64(floats, math.Float64bits(f))
+	}
+	w.extension(4, 8, floats)
+
+	var display []byte
+	var longNames, longStrings []string
+	var longLabels []byte
+	for _, out := range outputs {
+		measure := map[string]int{MeasureNominal: 1, MeasureOrdinal: 2, MeasureScale: 3}[out.column.meta.Measure]
+		if measure == 0 {
+			measure = map[string]int{MeasureNominal: 1, MeasureScale: 3}[defaultMeasure(out.column.dataType)]
+		}
+		width := out.column.meta.Width
+		if width == 0 {
+			width = max(min(out.width, 40), 8)
+		}
+		align := 1
+		if out.width > 0 {
+			align = 0
+		}
+		for range out.segments {
+			for _, v := range []int{measure, width, align} {
+				display = binary.LittleEndian.AppendUint32(display, uint32(v))
+			}
+		}
+		longNames = append(longNames, spssShortName(out.record)+"="+out.long)
+		if out.width > 255 {
+			longStrings = append(longStrings, fmt.Sprintf("%s=%05d\x00", spssShortName(out.record), out.width))
+		}
+		if out.width > 8 {
+			longLabels = append(longLabels, spssLongStringLabels(out)...)
+		}
+	}
+	if len(display) > 0 {
+		w.extension(11, 4, display)
+	}
+	if len(longNames) > 0 {
+		w.extension(13, 1, []byte(strings.Join(longNames, "\t")))
+	}
+	if len(longStrings) > 0 {
+		w.extension(14, 1, []byte(strings.Join(longStrings, "\t")+"\t"))
+	}
+	w.extension(20, 1, []byte("UTF-8"))
+	if len(longLabels) > 0 {
+		w.extension(21, 1, longLabels)
+	}
+}
+
+// spssLongStringLabels 寬度超過 8 個位元組的文字變數的數值標籤，寫在擴充記錄 21
+func spssLongStringLabels(out *spssOutput) []byte {
+	var content []byte
+	appendText := func(text string) {
+		content = binary.LittleEndian.AppendUint32(content, uint32(len(text)))
+		content = append(content, text...)
+	}
+	n := 0
+	var entries []byte
+	for _, vl := range out.column.meta.ValueLabels {
+		value := formatValue(vl.Value)
+		if len(value) > out.width {
+			continue
+		}
+		entries = binary.LittleEndian.AppendUint32(entries, uint32(out.width))
+		entries = append(entries, value+strings.Repeat(" ", out.width-len(value))...)
+		label := truncateBytes(vl.Label, spssMaxLabel)
+		entries = binary.LittleEndian.AppendUint32(entries, uint32(len(label)))
+		entries = append(entries, label...)
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	appendText(out.long)
+	content = binary.LittleEndian.AppendUint32(content, uint32(out.width))
+	content = binary.LittleEndian.AppendUint32(content, uint32(n))
+	return append(content, entries...)
+}
+
+// writeCell 寫出一個儲存格
+func (w *spssWriter) writeCell(out *spssOutput, value any) {
+	if out.width == 0 {
+		f, ok := statNumber(value)
+		if t, isTime := value.(time.Time); isTime && out.date {
+			f, ok = float64(t.Unix()-spssEpoch.Unix())+float64(t.Nanosecond())/1e9, true
+		}
+		if !ok {
+			f = spssSysmis
+		}
+		w.f64(f)
+		return
+	}
+	text := ""
+	if value != nil {
+		text = truncateBytes(formatValue(value), out.width)
+	}
+	// 超長文字依位元組切段，讀取時再接回，因此可以切斷 UTF-8 字元
+	for seg, width := range out.segments {
+		chunk := text
+		if seg < len(out.segments)-1 {
+			chunk = text[:min(spssSegment, len(text))]
+		}
+		text = text[len(chunk):]
+		w.buf.WriteString(chunk)
+		w.buf.WriteString(strings.Repeat(" ", (width+7)/8*8-len(chunk)))
+	}
+}
+
+// spssShortName 第 record 個變數記錄（從 0 起算）的短名稱
+func spssShortName(record int) string {
+	return "V" + strconv.Itoa(record+1)
+}
+
+// isSPSSIdentRune 判斷字元是否可用於 SPSS 的變數名稱：開頭為字母或 @，之後可以是字母、數字、底線、@、# 或 $
+func isSPSSIdentRune(r rune, first bool) bool {
+	if unicode.IsLetter(r) || r == '@' {
+		return true
+	}
+	return !first && (unicode.IsDigit(r) || slices.Contains([]rune{'_', '#', '$'}, r))
+}
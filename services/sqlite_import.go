@@ -0,0 +1,400 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HazelnutParadise/insyra"
+	_ "modernc.org/sqlite"
+)
+
+// ===== SQLite 瀏覽與匯入 =====
+
+// SQLite 欄位的型別親和性（type affinity）
+const (
+	AffinityInteger = "INTEGER"
+	AffinityReal    = "REAL"
+	AffinityText    = "TEXT"
+	AffinityBlob    = "BLOB"
+	AffinityNumeric = "NUMERIC"
+)
+
+// SQLiteColumn SQLite 資料表的欄位結構
+type SQLiteColumn struct {
+	Name         string `json:"name"`
+	DeclaredType string `json:"declaredType"`
+	Affinity     string `json:"affinity"`
+	NotNull      bool   `json:"notNull"`
+	PrimaryKey   bool   `json:"primaryKey"`
+}
+
+// SQLiteTableInfo SQLite 中的資料表或檢視表
+type SQLiteTableInfo struct {
+	Name     string         `json:"name"`
+	Type     string         `json:"type"` // "table" 或 "view"
+	RowCount int64          `json:"rowCount"`
+	Columns  []SQLiteColumn `json:"columns"`
+}
+
+// sqliteTimeLayouts SQLite 常見的日期時間文字格式
+var sqliteTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// openSQLiteReadOnly 以唯讀模式開啟 SQLite 檔案
+func openSQLiteReadOnly(filePath string) (*sql.DB, error) {
+	if _, err := os.Stat(filePath); err != nil {
+		return nil, fmt.Errorf("open sqlite file: %w", err)
+	}
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite file: %w", err)
+	}
+	path := filepath.ToSlash(abs)
+	if !strings.HasPrefix(path, "/") {
+		// Windows 磁碟路徑需寫成 file:///C:/...
+		path = "/" + path
+	}
+	dsn := (&url.URL{
+		Scheme:   "file",
+		Path:     path,
+		RawQuery: "mode=ro&_pragma=query_only(1)",
+	}).String()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite file: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open sqlite file: %w", err)
+	}
+	return db, nil
+}
+
+// GetSQLiteTables 取得SQLite檔案中的表格與檢視表名稱
+func (s *DataTableService) GetSQLiteTables(filePath string) ([]string, error) {
+	db, err := openSQLiteReadOnly(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	objects, err := listSQLiteObjects(db)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(objects))
+	for i, obj := range objects {
+		names[i] = obj.Name
+	}
+	return names, nil
+}
+
+// DescribeSQLiteTables 取得SQLite檔案中所有表格與檢視表的列數與欄位結構
+func (s *DataTableService) DescribeSQLiteTables(filePath string) ([]SQLiteTableInfo, error) {
+	db, err := openSQLiteReadOnly(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	objects, err := listSQLiteObjects(db)
+	if err != nil {
+		return nil, err
+	}
+	for i := range objects {
+		if objects[i].Columns, err = sqliteColumns(db, objects[i].Name); err != nil {
+			return nil, err
+		}
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + quoteSQLiteIdent(objects[i].Name)).Scan(&objects[i].RowCount); err != nil {
+			return nil, fmt.Errorf("count rows of %s: %w", objects[i].Name, err)
+		}
+	}
+	return objects, nil
+}
+
+// OpenSQLiteFile 開啟SQLite檔案中的指定表格或檢視表並創建新的資料表
+func (s *DataTableService) OpenSQLiteFile(filePath string, tableName string) (string, error) {
+	db, err := openSQLiteReadOnly(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+
+	objects, err := listSQLiteObjects(db)
+	if err != nil {
+		return "", err
+	}
+	found := false
+	for _, obj := range objects {
+		if obj.Name == tableName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return "", newError(ErrCodeNotFound, "errors.sqlite_table_not_found", "sqlite table %q not found", tableName).
+			WithDetail("tableName", tableName)
+	}
+
+	columns, err := sqliteColumns(db, tableName)
+	if err != nil {
+		return "", err
+	}
+	affinities := make([]string, len(columns))
+	declared := make([]string, len(columns))
+	for i, col := range columns {
+		affinities[i] = col.Affinity
+		declared[i] = col.DeclaredType
+	}
+
+	rows, err := db.Query("SELECT * FROM " + quoteSQLiteIdent(tableName))
+	if err != nil {
+		return "", fmt.Errorf("query sqlite table %s: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	dt, err := sqliteRowsToDataTable(rows, declared, affinities)
+	if err != nil {
+		return "", err
+	}
+	dt.SetName(tableName)
+	return s.appendImportedTable(dt), nil
+}
+
+// OpenSQLiteQuery 以唯讀方式執行 SELECT 查詢，並將結果創建為新的資料表
+func (s *DataTableService) OpenSQLiteQuery(filePath string, query string) (string, error) {
+	query = strings.TrimSpace(query)
+	query = strings.TrimSpace(strings.TrimSuffix(query, ";"))
+	if !isReadOnlySQL(query) {
+		return "", newError(ErrCodeInvalidArgument, "errors.sql_select_only", "only a single SELECT statement is allowed").
+			WithDetail("query", query)
+	}
+
+	db, err := openSQLiteReadOnly(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(query)
+	if err != nil {
+		e := newError(ErrCodeParse, "errors.sql_query_failed", "run sqlite query").WithDetail("query", query)
+		e.Err = err
+		return "", e
+	}
+	defer rows.Close()
+
+	dt, err := sqliteRowsToDataTable(rows, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	dt.SetName(tableNameFromPath(filePath) + " (query)")
+	return s.appendImportedTable(dt), nil
+}
+
+// listSQLiteObjects 從 sqlite_master 列出使用者定義的表格與檢視表
+func listSQLiteObjects(db *sql.DB) ([]SQLiteTableInfo, error) {
+	rows, err := db.Query(`SELECT name, type FROM sqlite_master
+		WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%'
+		ORDER BY type, name`)
+	if err != nil {
+		return nil, fmt.Errorf("list sqlite tables: %w", err)
+	}
+	defer rows.Close()
+
+	var objects []SQLiteTableInfo
+	for rows.Next() {
+		var info SQLiteTableInfo
+		if err := rows.Scan(&info.Name, &info.Type); err != nil {
+			return nil, fmt.Errorf("list sqlite tables: %w", err)
+		}
+		objects = append(objects, info)
+	}
+	return objects, rows.Err()
+}
+
+// sqliteColumns 讀取表格的欄位結構
+func sqliteColumns(db *sql.DB, tableName string) ([]SQLiteColumn, error) {
+	rows, err := db.Query(`SELECT name, type, "notnull", pk FROM pragma_table_info(?)`, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("read schema of %s: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	var columns []SQLiteColumn
+	for rows.Next() {
+		var col SQLiteColumn
+		var notNull, pk int
+		if err := rows.Scan(&col.Name, &col.DeclaredType, &notNull, &pk); err != nil {
+			return nil, fmt.Errorf("read schema of %s: %w", tableName, err)
+		}
+		col.NotNull = notNull != 0
+		col.PrimaryKey = pk != 0
+		col.Affinity = sqliteAffinity(col.DeclaredType)
+		columns = append(columns, col)
+	}
+	return columns, rows.Err()
+}
+
+// sqliteRowsToDataTable 讀取查詢結果；affinities 為 nil 時由結果的欄位型別推斷
+func sqliteRowsToDataTable(rows *sql.Rows, declared []string, affinities []string) (*insyra.DataTable, error) {
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("read sqlite result: %w", err)
+	}
+	if affinities == nil || len(affinities) != len(names) {
+		declared = make([]string, len(names))
+		affinities = make([]string, len(names))
+		if types, err := rows.ColumnTypes(); err == nil {
+			for i, ct := range types {
+				declared[i] = ct.DatabaseTypeName()
+				affinities[i] = sqliteAffinity(declared[i])
+			}
+		}
+	}
+
+	columnData := make([][]any, len(names))
+	scanned := make([]any, len(names))
+	ptrs := make([]any, len(names))
+	for i := range scanned {
+		ptrs[i] = &scanned[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("read sqlite result: %w", err)
+		}
+		for j, v := range scanned {
+			columnData[j] = append(columnData[j], sqliteCellValue(v, declared[j], affinities[j]))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read sqlite result: %w", err)
+	}
+
+	columns := make([]*insyra.DataList, len(names))
+	for j, name := range names {
+		columns[j] = insyra.NewDataList(columnData[j]...).SetName(name)
+	}
+	return newTableFromColumns(columns), nil
+}
+
+// sqliteAffinity 依 SQLite 官方規則由宣告型別決定型別親和性
+func sqliteAffinity(declaredType string) string {
+	t := strings.ToUpper(declaredType)
+	switch {
+	case strings.Contains(t, "INT"):
+		return AffinityInteger
+	case strings.Contains(t, "CHAR"), strings.Contains(t, "CLOB"), strings.Contains(t, "TEXT"):
+		return AffinityText
+	case t == "", strings.Contains(t, "BLOB"):
+		return AffinityBlob
+	case strings.Contains(t, "REAL"), strings.Contains(t, "FLOA"), strings.Contains(t, "DOUB"):
+		return AffinityReal
+	default:
+		return AffinityNumeric
+	}
+}
+
+// sqliteCellValue 依欄位型別將驅動程式回傳的值轉為儲存格的值
+func sqliteCellValue(v any, declaredType string, affinity string) any {
+	if v == nil {
+		return nil
+	}
+	declaredType = strings.ToUpper(declaredType)
+	if b, ok := v.([]byte); ok {
+		if affinity == AffinityBlob {
+			return "0x" + hex.EncodeToString(b)
+		}
+		v = string(b)
+	}
+
+	switch {
+	case strings.Contains(declaredType, "BOOL"):
+		switch val := v.(type) {
+		case int64:
+			return val != 0
+		case string:
+			if b, err := strconv.ParseBool(val); err == nil {
+				return b
+			}
+		}
+	case strings.Contains(declaredType, "DATE"), strings.Contains(declaredType, "TIME"):
+		if s, ok := v.(string); ok {
+			for _, layout := range sqliteTimeLayouts {
+				if t, err := time.Parse(layout, s); err == nil {
+					return t
+				}
+			}
+		}
+	}
+
+	switch val := v.(type) {
+	case int64:
+		if affinity == AffinityReal {
+			return float64(val)
+		}
+		return int(val)
+	case float64:
+		if affinity == AffinityInteger && val == float64(int64(val)) {
+			return int(val)
+		}
+		return val
+	case string:
+		if affinity == AffinityText {
+			return val
+		}
+		// NUMERIC/INTEGER/REAL 欄位中以文字保存的數值
+		if n, ok := parseNumber(strings.TrimSpace(val), "."); ok && affinity != AffinityBlob {
+			if affinity == AffinityReal {
+				return insyra.ToFloat64(n)
+			}
+			return n
+		}
+		return val
+	default:
+		return val
+	}
+}
+
+// isReadOnlySQL 粗略檢查是否為單一的唯讀查詢；實際的防護由唯讀連線負責
+func isReadOnlySQL(query string) bool {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "SELECT", "WITH", "VALUES":
+	default:
+		return false
+	}
+	// 不允許以分號串接多個敘述（字串常值中的分號除外）
+	inString := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			inString = !inString
+		case r == ';' && !inString:
+			return false
+		}
+	}
+	return true
+}
+
+// quoteSQLiteIdent 以雙引號跳脫 SQLite 識別字
+func quoteSQLiteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+// ===== 統計軟體檔案（SPSS、Stata）共用 =====
+//
+// 這些格式除了資料之外還帶有變數標籤、數值標籤與缺失值定義，
+// 讀取時轉為欄位型別、變數資訊與欄位的缺失值設定，寫出時再從這些設定還原。
+
+// statColumn 統計軟體檔案中的一個變數
+type statColumn struct {
+	name     string
+	values   []any
+	dataType string           // 欄位型別，空字串表示依值推斷
+	meta     VariableMetadata // 變數資訊
+	missing  *MissingPolicy   // 檔案中定義的缺失值，nil 表示沿用專案的設定
+}
+
+// appendStatTable 取得 mu 後將讀入的變數建立為新的資料表，並以 importTable 套用各欄的型別、變數資訊與缺失值設定，回傳新資料表的ID。
+// 各欄的值建立欄位後就從 columns 中移除，建立資料表時只多佔用一欄的副本
+func (s *DataTableService) appendStatTable(name string, columns []statColumn) string {
+	lists := make([]*insyra.DataList, len(columns))
+	for j := range columns {
+		lists[j] = newColumn(columns[j].name, columns[j].values)
+		columns[j].values = nil
+	}
+	dt := newTableFromColumns(lists)
+	dt.SetName(name)
+
+	s.lock()
+	defer s.unlock()
+	types := make(map[int]string)
+	variables := make(map[int]VariableMetadata)
+	policies := make(map[int]MissingPolicy)
+	for j, col := range columns {
+		if col.dataType != DataTypeUnset {
+			types[j] = col.dataType
+		}
+		variables[j] = col.meta
+		if col.missing != nil {
+			policies[j] = *col.missing
+		}
+	}
+	s.setMissingPolicyTexts(dt, policies)
+	s.setVariableTexts(dt, variables)
+	return s.importTable(-1, dt, types)
+}
+
+// statColumns 取得資料表所有欄位的值、型別、變數資訊與缺失值設定，供寫出統計軟體檔案
+func (s *DataTableService) statColumns(dt *insyra.DataTable) []statColumn {
+	rowCount, colCount := dt.Size()
+	variables := s.variablesOf(dt)
+	ids := s.columnIDsOf(dt)
+	columns := make([]statColumn, colCount)
+	for j := range colCount {
+		// 複製一份，寫出檔案時不需要持有 mu
+		values := slices.Clone(dt.GetColByNumber(j).Data())
+		// 較短的欄位以 nil 補齊
+		for len(values) < rowCount {
+			values = append(values, nil)
+		}
+		policy := s.missingPolicyOf(dt, j)
+		columns[j] = statColumn{
+			name:     dt.GetColNameByNumber(j),
+			values:   values,
+			dataType: s.columnTypeOf(dt, j),
+			meta:     variables[ids[j]],
+			missing:  &policy.Policy,
+		}
+	}
+	return columns
+}
+
+// statVariableNames 將欄名轉為統計軟體可接受且不重複的變數名稱：valid 判斷字元能否出現在名稱中（first 表示開頭），
+// 不可用的字元改為底線、不能作為開頭時加上 "v"；maxLen 為名稱的位元組上限；foldCase 表示名稱不分大小寫
+func statVariableNames(names []string, valid func(r rune, first bool) bool, maxLen int, foldCase bool, reserved map[string]bool) []string {
+	key := func(name string) string {
+		if foldCase {
+			return strings.ToUpper(name)
+		}
+		return name
+	}
+	result := make([]string, len(names))
+	used := make(map[string]bool, len(names))
+	for j, name := range names {
+		var b strings.Builder
+		for _, r := range strings.TrimSpace(name) {
+			if !valid(r, false) {
+				r = '_'
+			}
+			if b.Len() == 0 && !valid(r, true) {
+				b.WriteByte('v')
+			}
+			b.WriteRune(r)
+		}
+		base := truncateBytes(b.String(), maxLen)
+		if base == "" {
+			base = "var" + strconv.Itoa(j+1)
+		} else if reserved[key(base)] {
+			base = truncateBytes(base, maxLen-1) + "_"
+		}
+		unique := base
+		for n := 1; used[key(unique)]; n++ {
+			suffix := "_" + strconv.Itoa(n)
+			unique = truncateBytes(base, maxLen-len(suffix)) + suffix
+		}
+		used[key(unique)] = true
+		result[j] = unique
+	}
+	return result
+}
+
+// truncateBytes 將文字截斷為最多 n 個位元組，不切斷 UTF-8 字元
+func truncateBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// isASCIIIdentRune 判斷字元是否可用於只接受 ASCII 的變數名稱
+func isASCIIIdentRune(r rune, first bool) bool {
+	if r > unicode.MaxASCII {
+		return false
+	}
+	if r == '_' || unicode.IsLetter(r) {
+		return true
+	}
+	return !first && unicode.IsDigit(r)
+}
+
+// integralValues 判斷所有非缺失值是否都是可以用 int 表示的整數
+func integralValues(values []any) bool {
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		f, ok := v.(float64)
+		if !ok || f != math.Trunc(f) || math.Abs(f) > 1<<53 {
+			return false
+		}
+	}
+	return true
+}
+
+// statNumber 將欄位的值轉為寫入檔案的數值；無法轉換的值視為缺失值
+func statNumber(v any) (float64, bool) {
+	switch val := v.(type) {
+	case nil:
+		return 0, false
+	case bool:
+		return boolNumber(val), true
+	case time.Time:
+		return 0, false
+	}
+	f, ok := toNumber(v)
+	if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+// numericStatColumn 判斷欄位是否以數值變數寫出：數值、整數、布林欄，以及所有值都是數值代碼的類別欄
+func numericStatColumn(col statColumn) bool {
+	switch col.dataType {
+	case DataTypeNumeric, DataTypeInteger, DataTypeBoolean:
+		return true
+	case DataTypeCategorical:
+		for _, v := range col.values {
+			if _, ok := statNumber(v); v != nil && !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// statReader 依序讀取二進位檔案的內容；發生錯誤後的讀取都回傳零值，由呼叫者最後檢查 err
+type statReader struct {
+	data   []byte
+	pos    int
+	order  binary.ByteOrder
+	format string // 錯誤訊息中的檔案格式
+	err    error
+}
+
+func (r *statReader) bytes(n int) []byte {
+	if r.err != nil || n < 0 || n > len(r.data)-r.pos {
+		r.fail("unexpected end of file")
+		return make([]byte, min(max(n, 0), 8))
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *statReader) fail(reason string) {
+	if r.err == nil {
+		r.err = errFileInvalid(r.format, reason)
+	}
+}
+
+func (r *statReader) u8() uint8   { return r.bytes(1)[0] }
+func (r *statReader) u16() uint16 { return r.order.Uint16(r.bytes(2)) }
+func (r *statReader) u32() uint32 { return r.order.Uint32(r.bytes(4)) }
+func (r *statReader) u64() uint64 { return r.order.Uint64(r.bytes(8)) }
+func (r *statReader) skip(n int)  { r.bytes(n) }
+
+func (r *statReader) peek(tag string) bool {
+	return r.err == nil && bytes.HasPrefix(r.data[r.pos:], []byte(tag))
+}
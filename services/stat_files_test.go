@@ -0,0 +1,273 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testdata 中的 .sav 與 .dta 由 gen_stat_fixtures.py 依格式說明逐位元組產生，不經過本套件的寫出程式
+
+// statSnapshot 資料表的欄名、值與變數檢視（不含欄位 ID），用來比較匯出再匯入的結果
+type statSnapshot struct {
+	Names     []string
+	Columns   [][]any
+	Variables []VariableInfo
+}
+
+// openStatFixture 以 open 開啟檔案，回傳新資料表的快照
+func openStatFixture(t *testing.T, s *DataTableService, open func(string) (string, error), path string) (string, statSnapshot) {
+	t.Helper()
+	id, err := open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", filepath.Base(path), err)
+	}
+	view, err := s.GetVariableView(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j := range view {
+		view[j].ID = 0
+	}
+	names, columns := snapshotColumns(s.getTableByID(id))
+	return id, statSnapshot{Names: names, Columns: columns, Variables: view}
+}
+
+// roundTrip 匯出資料表再以 open 匯入，回傳匯入後的快照
+func roundTrip(t *testing.T, s *DataTableService, id string, export func(string, string) error, open func(string) (string, error), ext string) statSnapshot {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "roundtrip"+ext)
+	if err := export(id, path); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	_, snapshot := openStatFixture(t, s, open, path)
+	return snapshot
+}
+
+func TestSPSSFixturesRoundTrip(t *testing.T) {
+	ConfigureInsyra()
+	wantColumns := [][]any{
+		{1, 2, 3, 4, 5},
+		// -99 與 -98 是離散的使用者缺失值，nil 是系統缺失值
+		{1.5, nil, 3.25, nil, nil},
+		// 8–9 是缺失值範圍
+		{1, 2, nil, nil, 1},
+		// 900–999 是缺失值範圍，-1 是離散的使用者缺失值
+		{1200, nil, nil, 40000, 120},
+		{"Taipei", "Kaohsiung", nil, "Tainan", "Hsinchu city"},
+	}
+	for _, name := range []string{"survey.sav", "survey_compressed.sav"} {
+		t.Run(name, func(t *testing.T) {
+			s := NewDataTableService()
+			id, got := openStatFixture(t, s, s.OpenSPSSFile, filepath.Join("testdata", name))
+			if want := []string{"id", "score", "gender", "household_income", "city"}; !reflect.DeepEqual(got.Names, want) {
+				t.Fatalf("names = %q, want %q", got.Names, want)
+			}
+			if !reflect.DeepEqual(got.Columns, wantColumns) {
+				t.Errorf("columns = %v, want %v", got.Columns, wantColumns)
+			}
+			var types []string
+			for _, v := range got.Variables {
+				types = append(types, v.Type)
+			}
+			if want := []string{DataTypeInteger, DataTypeNumeric, DataTypeInteger, DataTypeInteger, DataTypeString}; !reflect.DeepEqual(types, want) {
+				t.Errorf("types = %q, want %q", types, want)
+			}
+			score, gender, income := got.Variables[1], got.Variables[2], got.Variables[3]
+			if score.Label != "Test score" || score.Format != "F8.2" {
+				t.Errorf("score label = %q, format = %q", score.Label, score.Format)
+			}
+			if want := []ValueLabel{{Value: 1.0, Label: "Male"}, {Value: 2.0, Label: "Female"}}; !reflect.DeepEqual(gender.ValueLabels, want) || gender.Measure != MeasureNominal {
+				t.Errorf("gender value labels = %v, measure = %q", gender.ValueLabels, gender.Measure)
+			}
+			if want := []string{"-99", "-98"}; !score.Missing.Custom || !reflect.DeepEqual(score.Missing.Policy.Tokens, want) {
+				t.Errorf("score missing = %+v, want tokens %q", score.Missing, want)
+			}
+			if want := []string{"-1"}; !income.Missing.Custom || !reflect.DeepEqual(income.Missing.Policy.Tokens, want) {
+				t.Errorf("household_income missing = %+v, want tokens %q", income.Missing, want)
+			}
+			if gender.Missing.Custom {
+				t.Errorf("gender missing = %+v, want the project policy", gender.Missing)
+			}
+
+			again := roundTrip(t, s, id, s.ExportTableAsSPSS, s.OpenSPSSFile, ".sav")
+			if !reflect.DeepEqual(again, got) {
+				t.Errorf("after export and import:\n got %+v\nwant %+v", again, got)
+			}
+		})
+	}
+}
+
+func TestStataFixturesRoundTrip(t *testing.T) {
+	ConfigureInsyra()
+	tests := []struct {
+		file    string
+		names   []string
+		columns [][]any
+		types   []string
+		labels  map[string][]ValueLabel
+	}{
+		{
+			file:  "panel_118.dta",
+			names: []string{"id", "income", "ratio", "weight", "sex", "city", "note", "visit"},
+			columns: [][]any{
+				{1, 2, 3, 4},
+				{52000, nil, 31000, 2147483620},
+				// .a 與 . 一樣讀為缺失值
+				{0.25, nil, -1.5, 3.125},
+				{1.5, 2.0, nil, 0.25},
+				{1, 2, 2, nil},
+				{"Taipei", nil, "Tainan", "Kaohsiun"},
+				{"short", nil, strings.Repeat("長文字", 20), "short"},
+				{
+					time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC),
+					time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+					nil,
+					time.Date(1959, 1, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			types: []string{DataTypeInteger, DataTypeInteger, DataTypeNumeric, DataTypeNumeric, DataTypeInteger,
+				DataTypeString, DataTypeString, DataTypeDateTime},
+			labels: map[string][]ValueLabel{"sex": {{Value: 1.0, Label: "male"}, {Value: 2.0, Label: "female"}, {Value: 9.0, Label: "unknown"}}},
+		},
+		{
+			file:    "legacy_114.dta",
+			names:   []string{"id", "grp", "x", "place"},
+			columns: [][]any{{1, 2, 3}, {1, 2, nil}, {0.5, nil, 2.75}, {"café", nil, "naïve"}},
+			types:   []string{DataTypeInteger, DataTypeInteger, DataTypeNumeric, DataTypeString},
+			labels:  map[string][]ValueLabel{"grp": {{Value: 1.0, Label: "contrôle"}, {Value: 2.0, Label: "treated"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			s := NewDataTableService()
+			id, got := openStatFixture(t, s, s.OpenStataFile, filepath.Join("testdata", tt.file))
+			if !reflect.DeepEqual(got.Names, tt.names) {
+				t.Fatalf("names = %q, want %q", got.Names, tt.names)
+			}
+			if !reflect.DeepEqual(got.Columns, tt.columns) {
+				t.Errorf("columns = %v, want %v", got.Columns, tt.columns)
+			}
+			for j, v := range got.Variables {
+				if v.Type != tt.types[j] {
+					t.Errorf("%s type = %q, want %q", v.Name, v.Type, tt.types[j])
+				}
+				if want := tt.labels[v.Name]; len(want) > 0 && (!reflect.DeepEqual(v.ValueLabels, want) || v.Measure != MeasureNominal) {
+					t.Errorf("%s value labels = %v, measure = %q, want %v", v.Name, v.ValueLabels, v.Measure, want)
+				}
+			}
+
+			again := roundTrip(t, s, id, s.ExportTableAsStata, s.OpenStataFile, ".dta")
+			if !reflect.DeepEqual(again.Names, got.Names) || !reflect.DeepEqual(again.Columns, got.Columns) {
+				t.Errorf("after export and import:\n got %v %v\nwant %v %v", again.Names, again.Columns, got.Names, got.Columns)
+			}
+			for j, v := range again.Variables {
+				want := got.Variables[j]
+				if v.Type != want.Type || v.Label != want.Label || !reflect.DeepEqual(v.ValueLabels, want.ValueLabels) {
+					t.Errorf("%s after export and import = %+v, want %+v", v.Name, v, want)
+				}
+			}
+		})
+	}
+}
+
+// mutatedInputs 產生 raw 的損毀版本：截斷在每個位置，以及逐一將每個位元組改為 0x00、0x7f、0x80 與 0xff
+func mutatedInputs(raw []byte) (names []string, inputs [][]byte) {
+	for n := range len(raw) {
+		names = append(names, fmt.Sprintf("cut at %d", n))
+		inputs = append(inputs, raw[:n:n])
+	}
+	for i := range raw {
+		for _, b := range []byte{0x00, 0x7f, 0x80, 0xff} {
+			if raw[i] == b {
+				continue
+			}
+			mutated := bytes.Clone(raw)
+			mutated[i] = b
+			names = append(names, fmt.Sprintf("byte %d = %#x", i, b))
+			inputs = append(inputs, mutated)
+		}
+	}
+	return names, inputs
+}
+
+// checkMalformed 以 parse 讀取 raw 的每個損毀版本，不可 panic 也不可停滯；回傳錯誤或部分結果都可以
+func checkMalformed(t *testing.T, raw []byte, parse func(data []byte) error) {
+	t.Helper()
+	names, inputs := mutatedInputs(raw)
+	for k, data := range inputs {
+		done := make(chan string, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					done <- fmt.Sprintf("%v\n%s", p, debug.Stack())
+				}
+				close(done)
+			}()
+			parse(data)
+		}()
+		select {
+		case p := <-done:
+			if p != "" {
+				t.Fatalf("%s: panic: %s", names[k], p)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("%s: still reading after 10s", names[k])
+		}
+	}
+}
+
+func TestStatReadersRejectMalformedFiles(t *testing.T) {
+	tests := []struct {
+		file  string
+		parse func([]byte) error
+	}{
+		{"survey.sav", func(b []byte) error { _, err := readSPSS(b); return err }},
+		{"survey_compressed.sav", func(b []byte) error { _, err := readSPSS(b); return err }},
+		{"panel_118.dta", func(b []byte) error { _, err := readStata(b); return err }},
+		{"legacy_114.dta", func(b []byte) error { _, err := readStata(b); return err }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			raw, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkMalformed(t, raw, tt.parse)
+		})
+	}
+}
+
+// 壓縮的 .sav 在最後一個指令區塊中截斷時回傳錯誤，而不是一直讀取
+func TestReadSPSSTruncatedCommandBlock(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("testdata", "survey_compressed.sav"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int{880, 881, 885} {
+		if _, err := readSPSS(raw[:n]); err == nil {
+			t.Errorf("cut at %d: want an error", n)
+		}
+	}
+}
+
+// 觀察值數損毀時在配置之前回傳錯誤
+func TestReadStataRejectsHugeObservationCount(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("testdata", "panel_118.dta"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw = bytes.Clone(raw)
+	at := bytes.Index(raw, []byte("<N>")) + 3
+	binary.LittleEndian.PutUint64(raw[at:], 1<<40)
+	if _, err := readStata(raw); err == nil {
+		t.Fatal("want an error for a corrupt observation count")
+	}
+}
@@ -0,0 +1,787 @@
+package services
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// ===== Stata .dta 匯入與匯出 =====
+//
+// 讀取 113–115 版（Stata 8–12）與 117–119 版（Stata 13 以後）的檔案，寫出 118 版（Stata 14 以後）。
+// Stata 的缺失值（. 與 .a–.z）一律讀為 nil；%td 與 %tc 格式的數值讀為日期時間。
+
+// Stata 的變數型別代碼（117 版以後）；1–2045 為固定長度文字
+const (
+	stataStrL   = 32768
+	stataDouble = 65526
+	stataFloat  = 65527
+	stataLong   = 65528
+	stataInt    = 65529
+	stataByte   = 65530
+)
+
+const (
+	// stataMaxStr 固定長度文字變數的最大位元組數，更長的文字以 strL 寫出
+	stataMaxStr = 2045
+	// stataMissingLong 與 stataMissingByte 為寫出的系統缺失值 "."
+	stataMissingLong = 2147483621
+	stataMissingByte = 101
+)
+
+// stataMissingDouble 寫出的系統缺失值 "."，也是 double 缺失值的下限
+var stataMissingDouble = math.Float64frombits(0x7fe0000000000000)
+
+// stataEpoch Stata 日期的起點
+var stataEpoch = time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// stataReserved Stata 保留的變數名稱
+var stataReserved = map[string]bool{
+	"_all": true, "_b": true, "byte": true, "_coef": true, "_cons": true, "double": true, "float": true,
+	"if": true, "in": true, "int": true, "long": true, "_n": true, "_N": true, "_pi": true, "_pred": true,
+	"_rc": true, "_skip": true, "strL": true, "using": true, "with": true,
+}
+
+// OpenStataFile 開啟 Stata .dta 檔案並創建新的資料表，一併讀入變數標籤與數值標籤
+func (s *DataTableService) OpenStataFile(filePath string) (string, error) {
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("read stata file: %w", err)
+	}
+	columns, err := readStata(raw)
+	if err != nil {
+		return "", err
+	}
+	return s.appendStatTable(tableNameFromPath(filePath), columns), nil
+}
+
+// ExportTableAsStata 將資料表匯出為 Stata .dta 檔案（118 版），包含變數標籤與整數的數值標籤
+func (s *DataTableService) ExportTableAsStata(tableID string, filePath string) error {
+	s.lock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		s.unlock()
+		return errTableNotFound(tableID)
+	}
+	name, columns := dt.GetName(), s.statColumns(dt)
+	s.unlock()
+
+	data, err := writeStata(name, columns)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
+
+// ----- 讀取 -----
+
+// stataReader 依序讀取 .dta 檔案的內容
+type stataReader struct {
+	statReader
+	release int
+}
+
+// expect 讀取固定的標籤文字
+func (r *stataReader) expect(tag string) {
+	if r.err == nil && !r.peek(tag) {
+		r.fail("missing " + tag)
+	}
+	r.skip(len(tag))
+}
+
+// text 讀取固定長度、以 0 結尾的文字；118 版以後為 UTF-8，之前為 Latin-1
+func (r *stataReader) text(n int) string {
+	b := r.bytes(n)
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	if r.release < 118 {
+		decoded, err := charmap.Windows1252.NewDecoder().Bytes(b)
+		if err == nil {
+			return string(decoded)
+		}
+	}
+	return string(b)
+}
+
+// stataVariables 檔案開頭描述的各變數資訊
+type stataVariables struct {
+	types, names, formats, labelNames, labels []string
+	codes                                     []int
+}
+
+// readStata 解析 .dta 檔案
+func readStata(raw []byte) ([]statColumn, error) {
+	r := &stataReader{statReader: statReader{data: raw, format: "stata"}}
+	if bytes.HasPrefix(raw, []byte("<stata_dta>")) {
+		return r.readTagged()
+	}
+	// 舊版的檔案以版本號與位元組順序（1 或 2）開頭
+	if len(raw) < 2 || (raw[1] != 1 && raw[1] != 2) {
+		return nil, errFileInvalid("stata", "not a stata file")
+	}
+	switch {
+	case raw[0] >= 113 && raw[0] <= 115:
+		return r.readLegacy()
+	case raw[0] >= 102 && raw[0] < 113:
+		// Stata 7 以前的版本
+		return nil, errFileUnsupported("stata", fmt.Sprintf("release %d", raw[0]))
+	}
+	return nil, errFileInvalid("stata", "not a stata file")
+}
+
+// readTagged 解析 117–119 版以 XML 風格標籤分段的檔案
+func (r *stataReader) readTagged() ([]statColumn, error) {
+	r.expect("<stata_dta><header><release>")
+	text := string(r.bytes(3))
+	release, err := strconv.Atoi(text)
+	if err != nil || release < 117 || release > 119 {
+		return nil, errFileUnsupported("stata", "release "+text)
+	}
+	r.release = release
+	r.expect("</release><byteorder>")
+	switch string(r.bytes(3)) {
+	case "LSF":
+		r.order = binary.LittleEndian
+	case "MSF":
+		r.order = binary.BigEndian
+	default:
+		r.fail("unknown byte order")
+		return nil, r.err
+	}
+	r.expect("</byteorder><K>")
+	var k, n int
+	if release == 119 {
+		k = int(r.u32())
+	} else {
+		k = int(r.u16())
+	}
+	if k > len(r.data)-r.pos {
+		r.fail("variable count is out of range")
+	}
+	r.expect("</K><N>")
+	if release == 117 {
+		n = int(r.u32())
+	} else {
+		n = int(r.u64())
+	}
+	r.expect("</N><label>")
+	if release == 117 {
+		r.skip(int(r.u8()))
+	} else {
+		r.skip(int(r.u16()))
+	}
+	r.expect("</label><timestamp>")
+	r.skip(int(r.u8()))
+	r.expect("</timestamp></header><map>")
+	r.skip(14 * 8)
+	r.expect("</map>")
+
+	nameLen, formatLen, labelLen := 129, 57, 321
+	if release == 117 {
+		nameLen, formatLen, labelLen = 33, 49, 81
+	}
+	vars := stataVariables{codes: make([]int, k)}
+	r.expect("<variable_types>")
+	for j := range k {
+		vars.codes[j] = int(r.u16())
+	}
+	r.expect("</variable_types><varnames>")
+	vars.names = r.texts(k, nameLen)
+	r.expect("</varnames><sortlist>")
+	if release == 119 {
+		r.skip((k + 1) * 4)
+	} else {
+		r.skip((k + 1) * 2)
+	}
+	r.expect("</sortlist><formats>")
+	vars.formats = r.texts(k, formatLen)
+	r.expect("</formats><value_label_names>")
+	vars.labelNames = r.texts(k, nameLen)
+	r.expect("</value_label_names><variable_labels>")
+	vars.labels = r.texts(k, labelLen)
+	r.expect("</variable_labels><characteristics>")
+	for r.peek("<ch>") {
+		r.expect("<ch>")
+		r.skip(int(r.u32()))
+		r.expect("</ch>")
+	}
+	r.expect("</characteristics><data>")
+	columns := r.readData(vars, n)
+	r.expect("</data><strls>")
+	strls := make(map[[2]uint64]string)
+	for r.peek("GSO") {
+		r.expect("GSO")
+		v := uint64(r.u32())
+		var o uint64
+		if release == 117 {
+			o = uint64(r.u32())
+		} else {
+			o = r.u64()
+		}
+		t := r.u8()
+		content := r.bytes(int(r.u32()))
+		if t == 130 {
+			content = bytes.TrimSuffix(content, []byte{0})
+		}
+		strls[[2]uint64{v, o}] = string(content)
+	}
+	r.expect("</strls><value_labels>")
+	tables := make(map[string][]ValueLabel)
+	for r.peek("<lbl>") {
+		r.expect("<lbl>")
+		size := int(r.u32())
+		name := r.text(nameLen)
+		r.skip(3)
+		tables[name] = r.valueLabelTable(size)
+		r.expect("</lbl>")
+	}
+	r.expect("</value_labels></stata_dta>")
+	if r.err != nil {
+		return nil, r.err
+	}
+	r.resolveStrLs(columns, strls)
+	return finishStataColumns(vars, columns, tables), nil
+}
+
+// readLegacy 解析 113–115 版的檔案
+func (r *stataReader) readLegacy() ([]statColumn, error) {
+	r.release = int(r.u8())
+	switch r.u8() {
+	case 1:
+		r.order = binary.BigEndian
+	case 2:
+		r.order = binary.LittleEndian
+	default:
+		return nil, errFileInvalid("stata", "unknown byte order")
+	}
+	r.skip(2)
+	k := int(r.u16())
+	n := int(r.u32())
+	r.skip(81 + 18)
+
+	vars := stataVariables{codes: make([]int, k)}
+	for j, code := range r.bytes(k) {
+		switch {
+		case code >= 251:
+			// 251–255 依序為 byte、int、long、float、double
+			vars.codes[j] = stataByte - int(code-251)
+		default:
+			vars.codes[j] = int(code)
+		}
+	}
+	vars.names = r.texts(k, 33)
+	r.skip((k + 1) * 2)
+	if r.release == 113 {
+		vars.formats = r.texts(k, 12)
+	} else {
+		vars.formats = r.texts(k, 49)
+	}
+	vars.labelNames = r.texts(k, 33)
+	vars.labels = r.texts(k, 81)
+	for {
+		kind, size := r.u8(), r.u32()
+		if r.err != nil || (kind == 0 && size == 0) {
+			break
+		}
+		r.skip(int(size))
+	}
+	columns := r.readData(vars, n)
+	tables := make(map[string][]ValueLabel)
+	for r.err == nil && r.pos < len(r.data) {
+		size := int(r.u32())
+		name := r.text(33)
+		r.skip(3)
+		tables[name] = r.valueLabelTable(size)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return finishStataColumns(vars, columns, tables), nil
+}
+
+// texts 讀取 k 個長度為 n 的文字
+func (r *stataReader) texts(k int, n int) []string {
+	texts := make([]string, k)
+	for j := range texts {
+		texts[j] = r.text(n)
+	}
+	return texts
+}
+
+// readData 讀取 n 筆觀察值；strL 先以 [2]uint64{v, o} 暫存，讀完 strls 後再取代
+func (r *stataReader) readData(vars stataVariables, n int) [][]any {
+	columns := make([][]any, len(vars.codes))
+	rowSize := 0
+	for j, code := range vars.codes {
+		size := stataTypeSize(code)
+		if size == 0 {
+			r.fail(fmt.Sprintf("unknown type %d of variable %q", code, vars.names[j]))
+			return columns
+		}
+		rowSize += size
+	}
+	// 先以剩下的位元組數檢查觀察值數，再依 n 配置，損毀的檔案不會造成過大的配置
+	if r.err != nil || n < 0 || (rowSize > 0 && n > (len(r.data)-r.pos)/rowSize) {
+		r.fail("data section is truncated")
+		return columns
+	}
+	if rowSize == 0 {
+		return columns
+	}
+	for j := range columns {
+		columns[j] = make([]any, 0, n)
+	}
+	for range n {
+		for j, code := range vars.codes {
+			columns[j] = append(columns[j], r.value(code))
+		}
+	}
+	return columns
+}
+
+// value 讀取一個儲存格；缺失值回傳 nil
+func (r *stataReader) value(code int) any {
+	switch code {
+	case stataByte:
+		if v := int8(r.u8()); v <= 100 {
+			return float64(v)
+		}
+	case stataInt:
+		if v := int16(r.u16()); v <= 32740 {
+			return float64(v)
+		}
+	case stataLong:
+		if v := int32(r.u32()); v <= 2147483620 {
+			return float64(v)
+		}
+	case stataFloat:
+		if v := math.Float32frombits(r.u32()); v < math.Float32frombits(0x7f000000) {
+			return float64(v)
+		}
+	case stataDouble:
+		if v := math.Float64frombits(r.u64()); v < stataMissingDouble {
+			return v
+		}
+	case stataStrL:
+		b := r.bytes(8)
+		switch r.release {
+		case 117:
+			return [2]uint64{uint64(r.order.Uint32(b)), uint64(r.order.Uint32(b[4:]))}
+		case 118:
+			// v 佔 2 個位元組、o 佔 6 個位元組
+			ref := r.order.Uint64(b)
+			if r.order == binary.BigEndian {
+				return [2]uint64{ref >> 48, ref & (1<<48 - 1)}
+			}
+			return [2]uint64{ref & 0xFFFF, ref >> 16}
+		default:
+			// v 佔 3 個位元組、o 佔 5 個位元組
+			ref := r.order.Uint64(b)
+			if r.order == binary.BigEndian {
+				return [2]uint64{ref >> 40, ref & (1<<40 - 1)}
+			}
+			return [2]uint64{ref & 0xFFFFFF, ref >> 24}
+		}
+	default:
+		if s := r.text(code); s != "" {
+			return s
+		}
+	}
+	return nil
+}
+
+// resolveStrLs 以 strls 區段的內容取代 strL 儲存格的參照
+func (r *stataReader) resolveStrLs(columns [][]any, strls map[[2]uint64]string) {
+	for _, values := range columns {
+		for i, v := range values {
+			if ref, ok := v.([2]uint64); ok {
+				values[i] = nil
+				if s := strls[ref]; s != "" {
+					values[i] = s
+				}
+			}
+		}
+	}
+}
+
+// valueLabelTable 解析一個數值標籤表，依數值排序
+func (r *stataReader) valueLabelTable(size int) []ValueLabel {
+	table := r.bytes(size)
+	if r.err != nil || size < 8 {
+		r.fail("value label table is truncated")
+		return nil
+	}
+	n := int(r.order.Uint32(table))
+	textLen := int(r.order.Uint32(table[4:]))
+	if n < 0 || 8+8*n+textLen > size {
+		r.fail("value label table is truncated")
+		return nil
+	}
+	txt := table[8+8*n : 8+8*n+textLen]
+	labels := make([]ValueLabel, 0, n)
+	for i := range n {
+		off := int(r.order.Uint32(table[8+4*i:]))
+		value := int32(r.order.Uint32(table[8+4*n+4*i:]))
+		if off >= len(txt) {
+			continue
+		}
+		label := txt[off:]
+		if end := bytes.IndexByte(label, 0); end >= 0 {
+			label = label[:end]
+		}
+		text := string(label)
+		if r.release < 118 {
+			if decoded, err := charmap.Windows1252.NewDecoder().Bytes(label); err == nil {
+				text = string(decoded)
+			}
+		}
+		labels = append(labels, ValueLabel{Value: float64(value), Label: text})
+	}
+	slices.SortFunc(labels, func(a, b ValueLabel) int {
+		return cmp.Compare(a.Value.(float64), b.Value.(float64))
+	})
+	return labels
+}
+
+// finishStataColumns 依變數型別與格式決定欄位型別，並附上變數標籤與數值標籤
+func finishStataColumns(vars stataVariables, data [][]any, tables map[string][]ValueLabel) []statColumn {
+	columns := make([]statColumn, len(vars.codes))
+	for j, code := range vars.codes {
+		col := statColumn{
+			name:   vars.names[j],
+			values: data[j],
+			meta: VariableMetadata{
+				Label:       vars.labels[j],
+				Format:      vars.formats[j],
+				ValueLabels: tables[vars.labelNames[j]],
+			},
+		}
+		format := strings.TrimPrefix(vars.formats[j], "%")
+		switch {
+		case code <= stataMaxStr || code == stataStrL:
+			col.dataType = DataTypeString
+		case strings.HasPrefix(format, "td") || strings.HasPrefix(format, "d"):
+			col.dataType = DataTypeDateTime
+			convertStataTimes(col.values, 24*time.Hour)
+		case strings.HasPrefix(format, "tc") || strings.HasPrefix(format, "tC"):
+			col.dataType = DataTypeDateTime
+			convertStataTimes(col.values, time.Millisecond)
+		case code == stataByte || code == stataInt || code == stataLong:
+			col.dataType = DataTypeInteger
+		default:
+			col.dataType = DataTypeNumeric
+		}
+		if len(col.meta.ValueLabels) > 0 {
+			col.meta.Measure = MeasureNominal
+		}
+		columns[j] = col
+	}
+	return columns
+}
+
+// convertStataTimes 將自 1960-01-01 起算的單位數轉為時間
+func convertStataTimes(values []any, unit time.Duration) {
+	for i, v := range values {
+		if f, ok := v.(float64); ok {
+			values[i] = stataEpoch.Add(time.Duration(math.Round(f * float64(unit))))
+		}
+	}
+}
+
+// stataTypeSize 變數型別在每筆觀察值中佔用的位元組數，未知的型別回傳 0
+func stataTypeSize(code int) int {
+	switch {
+	case code >= 1 && code <= stataMaxStr:
+		return code
+	case code == stataStrL, code == stataDouble:
+		return 8
+	case code == stataFloat, code == stataLong:
+		return 4
+	case code == stataInt:
+		return 2
+	case code == stataByte:
+		return 1
+	}
+	return 0
+}
+
+// ----- 寫出 -----
+
+// stataWriter 依序寫出 .dta 檔案，並記錄各區段的位置供 <map> 使用
+type stataWriter struct {
+	buf      bytes.Buffer
+	sections []uint64
+}
+
+func (w *stataWriter) section(tag string) {
+	w.sections = append(w.sections, uint64(w.buf.Len()))
+	w.buf.WriteString(tag)
+}
+
+func (w *stataWriter) u8(v uint8)   { w.buf.WriteByte(v) }
+func (w *stataWriter) u16(v uint16) { w.buf.Write(binary.LittleEndian.AppendUint16(nil, v)) }
+func (w *stataWriter) u32(v uint32) { w.buf.Write(binary.LittleEndian.AppendUint32(nil, v)) }
+func (w *stataWriter) u64(v uint64) { w.buf.Write(binary.LittleEndian.AppendUint64(nil, v)) }
+
+// text 寫出固定長度 n、以 0 補齊的文字
+func (w *stataWriter) text(s string, n int) {
+	s = truncateBytes(s, n-1)
+	w.buf.WriteString(s)
+	w.buf.Write(make([]byte, n-len(s)))
+}
+
+// stataVariable 寫出時各變數的型別、格式與數值標籤
+type stataVariable struct {
+	code   int
+	format string
+	unit   time.Duration // 日期時間變數的單位
+	labels []ValueLabel  // 可以寫出的整數數值標籤
+}
+
+// writeStata 將變數寫成 118 版的 .dta 檔案
+func writeStata(name string, columns []statColumn) ([]byte, error) {
+	if len(columns) > math.MaxUint16 {
+		return nil, invalidArgument("stata files support at most %d variables, got %d", math.MaxUint16, len(columns))
+	}
+	names := make([]string, len(columns))
+	for j, col := range columns {
+		names[j] = col.name
+	}
+	names = statVariableNames(names, isASCIIIdentRune, 32, false, stataReserved)
+	vars := make([]stataVariable, len(columns))
+	for j, col := range columns {
+		vars[j] = stataVariableOf(col)
+	}
+	n := 0
+	if len(columns) > 0 {
+		n = len(columns[0].values)
+	}
+
+	w := &stataWriter{}
+	w.buf.WriteString("<stata_dta><header><release>118</release><byteorder>LSF</byteorder><K>")
+	w.u16(uint16(len(columns)))
+	w.buf.WriteString("</K><N>")
+	w.u64(uint64(n))
+	w.buf.WriteString("</N><label>")
+	label := truncateBytes(name, 80)
+	w.u16(uint16(len(label)))
+	w.buf.WriteString(label)
+	w.buf.WriteString("</label><timestamp>")
+	timestamp := time.Now().Format("02 Jan 2006 15:04")
+	w.u8(uint8(len(timestamp)))
+	w.buf.WriteString(timestamp)
+	w.buf.WriteString("</timestamp></header>")
+
+	w.sections = []uint64{0}
+	w.section("<map>")
+	mapOffset := w.buf.Len()
+	w.buf.Write(make([]byte, 14*8))
+	w.buf.WriteString("</map>")
+	w.section("<variable_types>")
+	for _, v := range vars {
+		w.u16(uint16(v.code))
+	}
+	w.buf.WriteString("</variable_types>")
+	w.section("<varnames>")
+	for _, name := range names {
+		w.text(name, 129)
+	}
+	w.buf.WriteString("</varnames>")
+	w.section("<sortlist>")
+	w.buf.Write(make([]byte, (len(columns)+1)*2))
+	w.buf.WriteString("</sortlist>")
+	w.section("<formats>")
+	for _, v := range vars {
+		w.text(v.format, 57)
+	}
+	w.buf.WriteString("</formats>")
+	w.section("<value_label_names>")
+	for j, v := range vars {
+		if len(v.labels) > 0 {
+			w.text(names[j], 129)
+		} else {
+			w.text("", 129)
+		}
+	}
+	w.buf.WriteString("</value_label_names>")
+	w.section("<variable_labels>")
+	for _, col := range columns {
+		w.text(col.meta.Label, 321)
+	}
+	w.buf.WriteString("</variable_labels>")
+	w.section("<characteristics>")
+	w.buf.WriteString("</characteristics>")
+
+	w.section("<data>")
+	var strls bytes.Buffer
+	for i := range n {
+		for j, v := range vars {
+			w.writeValue(v, columns[j].values[i], j, i, &strls)
+		}
+	}
+	w.buf.WriteString("</data>")
+	w.section("<strls>")
+	w.buf.Write(strls.Bytes())
+	w.buf.WriteString("</strls>")
+	w.section("<value_labels>")
+	for j, v := range vars {
+		if len(v.labels) > 0 {
+			w.writeValueLabels(names[j], v.labels)
+		}
+	}
+	w.buf.WriteString("</value_labels>")
+	w.section("</stata_dta>")
+	w.sections = append(w.sections, uint64(w.buf.Len()))
+
+	data := w.buf.Bytes()
+	for i, offset := range w.sections {
+		binary.LittleEndian.PutUint64(data[mapOffset+8*i:], offset)
+	}
+	return data, nil
+}
+
+// stataVariableOf 依欄位型別決定變數的 Stata 型別與顯示格式
+func stataVariableOf(col statColumn) stataVariable {
+	var v stataVariable
+	switch {
+	case col.dataType == DataTypeDateTime:
+		v.code, v.format, v.unit = stataDouble, "%tc", time.Millisecond
+		if allMidnight(col.values) {
+			v.format, v.unit = "%td", 24*time.Hour
+		}
+		return v
+	case numericStatColumn(col):
+		v.code, v.format = stataDouble, "%10.0g"
+		if col.dataType == DataTypeBoolean {
+			v.code, v.format = stataByte, "%8.0g"
+		} else if fitsStataLong(col.values) {
+			v.code, v.format = stataLong, "%12.0g"
+		}
+		for _, vl := range col.meta.ValueLabels {
+			if f, ok := vl.Value.(float64); ok && f == math.Trunc(f) && f >= math.MinInt32 && f <= math.MaxInt32 {
+				v.labels = append(v.labels, vl)
+			}
+		}
+	default:
+		width := 1
+		for _, value := range col.values {
+			if value != nil {
+				width = max(width, len(formatValue(value)))
+			}
+		}
+		v.code = min(width, stataMaxStr)
+		if width > stataMaxStr {
+			v.code = stataStrL
+		}
+		v.format = "%" + strconv.Itoa(min(width, 244)) + "s"
+	}
+	if strings.HasPrefix(col.meta.Format, "%") && !strings.HasPrefix(col.meta.Format, "%t") && len(col.meta.Format) < 57 {
+		v.format = col.meta.Format
+	}
+	return v
+}
+
+// writeValue 寫出一個儲存格；strL 的內容寫入 strls
+func (w *stataWriter) writeValue(v stataVariable, value any, j int, i int, strls *bytes.Buffer) {
+	switch v.code {
+	case stataByte, stataLong, stataDouble:
+		f, ok := statNumber(value)
+		if t, isTime := value.(time.Time); isTime && v.unit > 0 {
+			f, ok = float64((t.UnixMilli()-stataEpoch.UnixMilli())/v.unit.Milliseconds()), true
+		}
+		switch {
+		case v.code == stataByte && ok:
+			w.u8(uint8(int8(f)))
+		case v.code == stataByte:
+			w.u8(stataMissingByte)
+		case v.code == stataLong && ok:
+			w.u32(uint32(int32(f)))
+		case v.code == stataLong:
+			w.u32(stataMissingLong)
+		case ok:
+			w.u64(math.Float64bits(f))
+		default:
+			w.u64(math.Float64bits(stataMissingDouble))
+		}
+	case stataStrL:
+		if value == nil {
+			w.u64(0)
+			return
+		}
+		// v 佔 2 個位元組、o 佔 6 個位元組，皆從 1 起算
+		ref := [2]uint64{uint64(j + 1), uint64(i + 1)}
+		w.u64(ref[0] | ref[1]<<16)
+		content := formatValue(value)
+		strls.WriteString("GSO")
+		strls.Write(binary.LittleEndian.AppendUint32(nil, uint32(ref[0])))
+		strls.Write(binary.LittleEndian.AppendUint64(nil, ref[1]))
+		strls.WriteByte(130)
+		strls.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(content)+1)))
+		strls.WriteString(content)
+		strls.WriteByte(0)
+	default:
+		text := ""
+		if value != nil {
+			text = formatValue(value)
+		}
+		text = truncateBytes(text, v.code)
+		w.buf.WriteString(text)
+		w.buf.Write(make([]byte, v.code-len(text)))
+	}
+}
+
+// writeValueLabels 寫出一個數值標籤表
+func (w *stataWriter) writeValueLabels(name string, labels []ValueLabel) {
+	var txt bytes.Buffer
+	offsets := make([]uint32, len(labels))
+	for i, vl := range labels {
+		offsets[i] = uint32(txt.Len())
+		txt.WriteString(strings.ReplaceAll(truncateBytes(vl.Label, 32000), "\x00", ""))
+		txt.WriteByte(0)
+	}
+	w.buf.WriteString("<lbl>")
+	w.u32(uint32(8 + 8*len(labels) + txt.Len()))
+	w.text(name, 129)
+	w.buf.Write(make([]byte, 3))
+	w.u32(uint32(len(labels)))
+	w.u32(uint32(txt.Len()))
+	for _, off := range offsets {
+		w.u32(off)
+	}
+	for _, vl := range labels {
+		w.u32(uint32(int32(vl.Value.(float64))))
+	}
+	w.buf.Write(txt.Bytes())
+	w.buf.WriteString("</lbl>")
+}
+
+// fitsStataLong 判斷所有值是否都是 long 可以表示的整數
+func fitsStataLong(values []any) bool {
+	for _, v := range values {
+		f, ok := statNumber(v)
+		if ok && (f != math.Trunc(f) || f < -2147483647 || f > 2147483620) {
+			return false
+		}
+	}
+	return true
+}
+
+// allMidnight 判斷所有時間是否都在午夜，也就是只有日期
+func allMidnight(values []any) bool {
+	for _, v := range values {
+		if t, ok := v.(time.Time); ok && !t.Equal(t.Truncate(24*time.Hour)) {
+			return false
+		}
+	}
+	return true
+}
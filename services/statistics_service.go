@@ -0,0 +1,239 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/HazelnutParadise/insyra"
+	"github.com/HazelnutParadise/insyra/stats"
+)
+
+// ===== 描述統計 =====
+
+// StatisticsService 計算資料表的描述統計
+type StatisticsService struct {
+	data *DataTableService
+}
+
+// NewStatisticsService 創建一個新的 StatisticsService 實例，讀取 data 中的資料表
+func NewStatisticsService(data *DataTableService) *StatisticsService {
+	return &StatisticsService{data: data}
+}
+
+// ColumnStatistics 單一欄位的描述統計；無法計算的數值（例如文字欄的平均數）為 null
+type ColumnStatistics struct {
+	Col      int      `json:"col"`
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`    // 欄位的宣告型別（DataType* 常數）；數值與整數欄才計算數值統計
+	Count    int      `json:"count"`   // 非缺失值的個數
+	Missing  int      `json:"missing"` // 缺失值的個數
+	Unique   int      `json:"unique"`  // 不重複的非缺失值個數
+	Mode     []any    `json:"mode"`    // 眾數；所有值出現次數相同時為空
+	Mean     *float64 `json:"mean"`
+	Median   *float64 `json:"median"`
+	Std      *float64 `json:"std"`      // 樣本標準差
+	Variance *float64 `json:"variance"` // 樣本變異數
+	Min      *float64 `json:"min"`
+	Max      *float64 `json:"max"`
+	Q1       *float64 `json:"q1"`
+	Q3       *float64 `json:"q3"`
+	Skewness *float64 `json:"skewness"`
+	Kurtosis *float64 `json:"kurtosis"` // 超額峰度
+}
+
+// TableStatistics 資料表的摘要與各欄的描述統計
+type TableStatistics struct {
+	RowCount       int                `json:"rowCount"`
+	ColumnCount    int                `json:"columnCount"`
+	CellCount      int                `json:"cellCount"`
+	MissingCells   int                `json:"missingCells"`
+	NumericColumns int                `json:"numericColumns"` // 宣告為數值或整數的欄數
+	Columns        []ColumnStatistics `json:"columns"`
+}
+
+// GetTableStatistics 計算資料表每一欄的描述統計
+func (s *StatisticsService) GetTableStatistics(tableID string) (TableStatistics, error) {
+	s.data.lock()
+	defer s.data.unlock()
+	dt := s.data.getTableByID(tableID)
+	if dt == nil {
+		return TableStatistics{}, errTableNotFound(tableID)
+	}
+	rowCount, colCount := dt.Size()
+	result := TableStatistics{
+		RowCount:    rowCount,
+		ColumnCount: colCount,
+		CellCount:   rowCount * colCount,
+		Columns:     make([]ColumnStatistics, colCount),
+	}
+	// insyra 對沒有眾數等情況會記錄警告，這裡直接捨棄
+	types := make([]string, colCount)
+	for j := range colCount {
+		types[j] = s.data.columnTypeOf(dt, j)
+	}
+	captureInsyra(func() {
+		for j := range colCount {
+			result.Columns[j] = describeColumn(dt, j, rowCount, types[j])
+		}
+	})
+	for _, col := range result.Columns {
+		result.MissingCells += col.Missing
+		if isNumericType(col.Type) {
+			result.NumericColumns++
+		}
+	}
+	return result, nil
+}
+
+// GetColumnStatistics 計算資料表單一欄位的描述統計
+func (s *StatisticsService) GetColumnStatistics(tableID string, colIndex int) (ColumnStatistics, error) {
+	s.data.lock()
+	defer s.data.unlock()
+	dt := s.data.getTableByID(tableID)
+	if dt == nil {
+		return ColumnStatistics{}, errTableNotFound(tableID)
+	}
+	rowCount, colCount := dt.Size()
+	if colIndex < 0 || colIndex >= colCount {
+		return ColumnStatistics{}, errColumnOutOfRange(colIndex, colCount)
+	}
+	var result ColumnStatistics
+	dataType := s.data.columnTypeOf(dt, colIndex)
+	captureInsyra(func() { result = describeColumn(dt, colIndex, rowCount, dataType) })
+	return result, nil
+}
+
+// describeColumn 計算第 j 欄的描述統計；rowCount 為資料表的列數，dataType 為欄位的宣告型別
+func describeColumn(dt *insyra.DataTable, j int, rowCount int, dataType string) ColumnStatistics {
+	col := dt.GetColByNumber(j)
+	data := col.Data()
+	result := ColumnStatistics{
+		Col:  j,
+		Name: col.GetName(),
+		Type: dataType,
+		// 較短的欄位中不存在的列也算缺失
+		Missing: max(rowCount-len(data), 0),
+		Mode:    []any{},
+	}
+
+	values := make([]any, 0, len(data))
+	for _, v := range data {
+		if isMissingValue(v) {
+			result.Missing++
+			continue
+		}
+		values = append(values, v)
+	}
+	result.Count = len(values)
+	if len(values) == 0 {
+		return result
+	}
+
+	numbers, numeric := numericValues(values)
+	if !isNumericType(dataType) || !numeric {
+		result.Unique, result.Mode = textFrequencies(values)
+		return result
+	}
+
+	dl := insyra.NewDataList(numbers)
+	result.Unique = len(dl.Counter())
+	modes := dl.Mode()
+	slices.Sort(modes)
+	for _, m := range modes {
+		result.Mode = append(result.Mode, m)
+	}
+	result.Mean = finite(dl.Mean())
+	result.Median = finite(dl.Median())
+	result.Std = finite(dl.Stdev())
+	result.Variance = finite(dl.Var())
+	result.Min = finite(dl.Min())
+	result.Max = finite(dl.Max())
+	result.Q1 = finite(dl.Quartile(1))
+	result.Q3 = finite(dl.Quartile(3))
+	result.Skewness = finite(stats.Skewness(dl))
+	result.Kurtosis = finite(stats.Kurtosis(dl))
+	return result
+}
+
+// isNumericType 判斷宣告型別是否計算數值統計
+func isNumericType(dataType string) bool {
+	return dataType == DataTypeNumeric || dataType == DataTypeInteger
+}
+
+// isMissingValue 判斷儲存格是否為缺失值：nil、空白字串或 NaN
+func isMissingValue(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(val) == ""
+	case float64:
+		return math.IsNaN(val)
+	case float32:
+		return math.IsNaN(float64(val))
+	}
+	return false
+}
+
+// numericValues 將非缺失值轉為數值；任一個值不是數值（或可解析為數值的文字）時回傳 false
+func numericValues(values []any) ([]float64, bool) {
+	numbers := make([]float64, len(values))
+	for i, v := range values {
+		f, ok := toNumber(v)
+		if !ok {
+			return nil, false
+		}
+		numbers[i] = f
+	}
+	return numbers, true
+}
+
+// toNumber 將數值或可解析為數值的文字轉為 float64
+func toNumber(v any) (float64, bool) {
+	if s, ok := v.(string); ok {
+		parsed, ok := parseNumber(strings.TrimSpace(s), ".")
+		if !ok {
+			return 0, false
+		}
+		v = parsed
+	}
+	return insyra.ToFloat64Safe(v)
+}
+
+// textFrequencies 計算文字欄不重複值的個數與眾數（依文字排序）
+func textFrequencies(values []any) (int, []any) {
+	counts := make(map[string]int)
+	for _, v := range values {
+		counts[fmt.Sprint(v)]++
+	}
+	maxCount := 0
+	for _, n := range counts {
+		maxCount = max(maxCount, n)
+	}
+	var modes []string
+	for text, n := range counts {
+		if n == maxCount {
+			modes = append(modes, text)
+		}
+	}
+	// 與 insyra 的 Mode 一致：所有值出現次數相同時沒有眾數
+	if len(modes) == len(counts) {
+		return len(counts), []any{}
+	}
+	slices.Sort(modes)
+	result := make([]any, len(modes))
+	for i, m := range modes {
+		result[i] = m
+	}
+	return len(counts), result
+}
+
+// finite 將 NaN 與 Inf 轉為 nil，讓結果可以編碼為 JSON
+func finite(f float64) *float64 {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	return &f
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+func TestTableStatisticsUseDeclaredTypes(t *testing.T) {
+	ConfigureInsyra()
+	data := NewDataTableService()
+	dt := insyra.NewDataTable(
+		insyra.NewDataList(1.5, 2.5, nil).SetName("x"),
+		insyra.NewDataList(1, 2, 2).SetName("code"),
+		insyra.NewDataList("a", "b", "b").SetName("label"),
+		insyra.NewDataList(nil, nil, nil).SetName("blank"),
+	)
+	data.lock()
+	id := data.appendTable(dt)
+	data.unlock()
+	// 以數字表示的代碼宣告為文字後，不再計算數值統計
+	if err := data.SetColumnType(id, 1, DataTypeString, false); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewStatisticsService(data).GetTableStatistics(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantTypes := []string{DataTypeNumeric, DataTypeString, DataTypeString, DataTypeUnset}
+	for j, want := range wantTypes {
+		if got := result.Columns[j].Type; got != want {
+			t.Errorf("column %d type = %q, want %q", j, got, want)
+		}
+	}
+	if result.NumericColumns != 1 {
+		t.Errorf("NumericColumns = %d, want 1", result.NumericColumns)
+	}
+	if x := result.Columns[0]; x.Mean == nil || *x.Mean != 2 || x.Missing != 1 {
+		t.Errorf("x statistics = %+v", x)
+	}
+	if code := result.Columns[1]; code.Mean != nil || code.Unique != 2 || len(code.Mode) != 1 || code.Mode[0] != "2" {
+		t.Errorf("code statistics = %+v", code)
+	}
+	if result.MissingCells != 4 {
+		t.Errorf("MissingCells = %d, want 4", result.MissingCells)
+	}
+}
@@ -0,0 +1,452 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// ===== 資料表匯出 =====
+//
+// CSV 與 Excel 的缺失值依各欄的缺失值設定寫成 ExportToken；JSON 一律寫成 null。
+
+// JSON 匯出的資料排列方式
+const (
+	JSONOrientRecords = "records" // [{"a":1,"b":2}, ...]
+	JSONOrientColumns = "columns" // {"a":[1,...],"b":[2,...]}
+)
+
+// excelMaxSheetName Excel 工作表名稱的長度上限
+const excelMaxSheetName = 31
+
+// CSVExportOptions CSV 匯出設定
+type CSVExportOptions struct {
+	Delimiter        string `json:"delimiter"`
+	Quote            string `json:"quote"`
+	QuoteAll         bool   `json:"quoteAll"` // 為 false 時只在必要時加引號
+	Encoding         string `json:"encoding"`
+	NAToken          string `json:"naToken"` // 缺失值輸出的文字；空字串時使用各欄缺失值設定的 ExportToken
+	OmitHeader       bool   `json:"omitHeader"`
+	DecimalSeparator string `json:"decimalSeparator"`
+}
+
+// JSONExportOptions JSON 匯出設定
+type JSONExportOptions struct {
+	Orientation string `json:"orientation"` // "records" 或 "columns"
+	Indent      bool   `json:"indent"`
+}
+
+// DefaultCSVExportOptions 預設的 CSV 匯出設定：逗號分隔、雙引號、UTF-8 含 BOM（方便 Excel 開啟）
+func DefaultCSVExportOptions() CSVExportOptions {
+	return CSVExportOptions{
+		Delimiter:        ",",
+		Quote:            `"`,
+		Encoding:         EncodingUTF8BOM,
+		DecimalSeparator: ".",
+	}
+}
+
+// ExportTableAsCSV 以預設設定將指定資料表匯出為 CSV
+func (s *DataTableService) ExportTableAsCSV(tableID string, filePath string) error {
+	return s.ExportTableAsCSVWithOptions(tableID, filePath, DefaultCSVExportOptions())
+}
+
+// ExportTableAsCSVWithOptions 以指定設定將資料表匯出為 CSV
+func (s *DataTableService) ExportTableAsCSVWithOptions(tableID string, filePath string, options CSVExportOptions) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
+	}
+	opts, err := normalizeCSVExportOptions(options)
+	if err != nil {
+		return err
+	}
+
+	names, columns := snapshotColumns(dt)
+	columnOpts := make([]CSVExportOptions, len(columns))
+	for j := range columns {
+		columnOpts[j] = opts
+		if opts.NAToken == "" {
+			columnOpts[j].NAToken = s.missingPolicyOf(dt, j).Policy.ExportToken
+		}
+	}
+	var buf bytes.Buffer
+	if !opts.OmitHeader {
+		writeCSVRecord(&buf, names, opts)
+	}
+	record := make([]string, len(columns))
+	for i := range columnLength(columns) {
+		for j, col := range columns {
+			record[j] = formatCSVCell(col[i], columnOpts[j])
+		}
+		writeCSVRecord(&buf, record, opts)
+	}
+
+	data, err := encodeText(buf.Bytes(), opts.Encoding)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
+
+// ExportTableAsJSON 以物件陣列格式將指定資料表匯出為 JSON
+func (s *DataTableService) ExportTableAsJSON(tableID string, filePath string) error {
+	return s.ExportTableAsJSONWithOptions(tableID, filePath, JSONExportOptions{Orientation: JSONOrientRecords, Indent: true})
+}
+
+// ExportTableAsJSONWithOptions 以指定排列方式將資料表匯出為 JSON
+func (s *DataTableService) ExportTableAsJSONWithOptions(tableID string, filePath string, options JSONExportOptions) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
+	}
+
+	names, columns := snapshotColumns(dt)
+	keys := uniqueKeys(names)
+	var buf bytes.Buffer
+	switch options.Orientation {
+	case JSONOrientRecords, "":
+		buf.WriteByte('[')
+		for i := range columnLength(columns) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('{')
+			for j, col := range columns {
+				writeJSONMember(&buf, j, keys[j], col[i])
+			}
+			buf.WriteByte('}')
+		}
+		buf.WriteByte(']')
+	case JSONOrientColumns:
+		buf.WriteByte('{')
+		for j, col := range columns {
+			key, _ := json.Marshal(keys[j])
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(key)
+			buf.WriteString(":[")
+			for i, v := range col {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				writeJSONValue(&buf, v)
+			}
+			buf.WriteByte(']')
+		}
+		buf.WriteByte('}')
+	default:
+		return invalidArgument("unsupported json orientation %q", options.Orientation).
+			WithDetail("orientation", options.Orientation)
+	}
+
+	data := buf.Bytes()
+	if options.Indent {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, data, "", "  "); err != nil {
+			return err
+		}
+		data = indented.Bytes()
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
+
+// ExportTableAsExcel 將指定資料表匯出為 Excel
+func (s *DataTableService) ExportTableAsExcel(tableID string, filePath string) error {
+	return s.ExportTablesAsExcel([]string{tableID}, filePath)
+}
+
+// ExportTablesAsExcel 將多個資料表匯出為同一個 Excel 檔案，每個資料表一個工作表
+func (s *DataTableService) ExportTablesAsExcel(tableIDs []string, filePath string) error {
+	s.lock()
+	defer s.unlock()
+	if len(tableIDs) == 0 {
+		return invalidArgument("no table to export")
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+
+	usedNames := make(map[string]bool)
+	for n, tableID := range tableIDs {
+		dt := s.getTableByID(tableID)
+		if dt == nil {
+			return errTableNotFound(tableID)
+		}
+		sheet := excelSheetName(dt.GetName(), n, usedNames)
+		if n == 0 {
+			if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+				return err
+			}
+		} else if _, err := f.NewSheet(sheet); err != nil {
+			return err
+		}
+
+		sw, err := f.NewStreamWriter(sheet)
+		if err != nil {
+			return err
+		}
+		names, columns := snapshotColumns(dt)
+		header := make([]any, len(names))
+		for j, name := range names {
+			header[j] = excelize.Cell{StyleID: headerStyle, Value: name}
+		}
+		if err := sw.SetRow("A1", header); err != nil {
+			return err
+		}
+		naValues := make([]any, len(columns))
+		for j := range columns {
+			naValues[j] = missingExportValue(s.missingPolicyOf(dt, j).Policy.ExportToken)
+		}
+		row := make([]any, len(columns))
+		for i := range columnLength(columns) {
+			for j, col := range columns {
+				if isMissingValue(col[i]) {
+					row[j] = naValues[j]
+				} else {
+					row[j] = excelCellValue(col[i])
+				}
+			}
+			cell, _ := excelize.CoordinatesToCellName(1, i+2)
+			if err := sw.SetRow(cell, row); err != nil {
+				return err
+			}
+		}
+		if err := sw.Flush(); err != nil {
+			return err
+		}
+	}
+	return f.SaveAs(filePath)
+}
+
+// normalizeCSVExportOptions 檢查並補齊 CSV 匯出設定
+func normalizeCSVExportOptions(opts CSVExportOptions) (CSVExportOptions, error) {
+	defaults := DefaultCSVExportOptions()
+	if opts.Delimiter == "" {
+		opts.Delimiter = defaults.Delimiter
+	}
+	if opts.Encoding == "" {
+		opts.Encoding = defaults.Encoding
+	}
+	if opts.DecimalSeparator == "" {
+		opts.DecimalSeparator = defaults.DecimalSeparator
+	}
+	if utf8.RuneCountInString(opts.Delimiter) != 1 {
+		return opts, invalidArgument("csv delimiter must be a single character, got %q", opts.Delimiter).
+			WithDetail("delimiter", opts.Delimiter)
+	}
+	if utf8.RuneCountInString(opts.Quote) > 1 {
+		return opts, invalidArgument("csv quote must be at most one character, got %q", opts.Quote).
+			WithDetail("quote", opts.Quote)
+	}
+	if opts.QuoteAll && opts.Quote == "" {
+		return opts, invalidArgument("csv quoteAll requires a quote character")
+	}
+	if opts.DecimalSeparator == opts.Delimiter {
+		return opts, invalidArgument("csv decimal separator must differ from the delimiter").
+			WithDetail("decimalSeparator", opts.DecimalSeparator)
+	}
+	return opts, nil
+}
+
+// writeCSVRecord 寫出一列；欄位含分隔符號、引號或換行時加上引號
+func writeCSVRecord(buf *bytes.Buffer, fields []string, opts CSVExportOptions) {
+	for j, field := range fields {
+		if j > 0 {
+			buf.WriteString(opts.Delimiter)
+		}
+		needQuote := opts.Quote != "" && (opts.QuoteAll ||
+			strings.Contains(field, opts.Delimiter) ||
+			strings.Contains(field, opts.Quote) ||
+			strings.ContainsAny(field, "\r\n") ||
+			strings.TrimSpace(field) != field)
+		if !needQuote {
+			buf.WriteString(field)
+			continue
+		}
+		buf.WriteString(opts.Quote)
+		buf.WriteString(strings.ReplaceAll(field, opts.Quote, opts.Quote+opts.Quote))
+		buf.WriteString(opts.Quote)
+	}
+	buf.WriteString("\r\n")
+}
+
+// formatCSVCell 將儲存格的值轉為 CSV 文字
+func formatCSVCell(v any, opts CSVExportOptions) string {
+	switch val := v.(type) {
+	case nil:
+		return opts.NAToken
+	case string:
+		return val
+	case float32:
+		return formatCSVFloat(float64(val), opts)
+	case float64:
+		return formatCSVFloat(val, opts)
+	case time.Time:
+		return val.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func formatCSVFloat(f float64, opts CSVExportOptions) string {
+	if math.IsNaN(f) {
+		return opts.NAToken
+	}
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if opts.DecimalSeparator != "." {
+		s = strings.Replace(s, ".", opts.DecimalSeparator, 1)
+	}
+	return s
+}
+
+// encodeText 將 UTF-8 文字轉為指定編碼
+func encodeText(data []byte, encoding string) ([]byte, error) {
+	switch strings.ToLower(encoding) {
+	case EncodingUTF8, "utf8", "":
+		return data, nil
+	case EncodingUTF8BOM:
+		return append([]byte{0xEF, 0xBB, 0xBF}, data...), nil
+	case EncodingUTF16LE:
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().Bytes(data)
+	case EncodingUTF16BE:
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewEncoder().Bytes(data)
+	case EncodingBig5:
+		out, err := traditionalchinese.Big5.NewEncoder().Bytes(data)
+		if err != nil {
+			return nil, wrapError(ErrCodeInvalidArgument, "errors.encode_failed", err, "encode big5")
+		}
+		return out, nil
+	default:
+		return nil, errUnsupportedEncoding(encoding)
+	}
+}
+
+// uniqueKeys 產生不重複的 JSON 鍵；空白欄名使用欄位字母，重複的欄名加上流水號
+func uniqueKeys(names []string) []string {
+	keys := make([]string, len(names))
+	used := make(map[string]bool, len(names))
+	for j, name := range names {
+		if name == "" {
+			name = indexToLetters(j)
+		}
+		key := name
+		for n := 2; used[key]; n++ {
+			key = fmt.Sprintf("%s_%d", name, n)
+		}
+		used[key] = true
+		keys[j] = key
+	}
+	return keys
+}
+
+func writeJSONMember(buf *bytes.Buffer, index int, key string, v any) {
+	if index > 0 {
+		buf.WriteByte(',')
+	}
+	k, _ := json.Marshal(key)
+	buf.Write(k)
+	buf.WriteByte(':')
+	writeJSONValue(buf, v)
+}
+
+// writeJSONValue 寫出儲存格的值；NaN 與無限大輸出為 null，時間輸出為 RFC 3339 文字
+func writeJSONValue(buf *bytes.Buffer, v any) {
+	switch val := v.(type) {
+	case float64:
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			buf.WriteString("null")
+			return
+		}
+	case float32:
+		if math.IsNaN(float64(val)) || math.IsInf(float64(val), 0) {
+			buf.WriteString("null")
+			return
+		}
+	case time.Time:
+		v = val.Format(time.RFC3339Nano)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(data)
+}
+
+// excelCellValue 將儲存格的值轉為 Excel 可接受的型別，數值保留為數值儲存格
+func excelCellValue(v any) any {
+	switch val := v.(type) {
+	case float64:
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			return nil
+		}
+	case float32:
+		if math.IsNaN(float64(val)) || math.IsInf(float64(val), 0) {
+			return nil
+		}
+	case nil, string, bool, time.Time,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+	default:
+		return fmt.Sprint(val)
+	}
+	return v
+}
+
+// missingExportValue 將缺失值的匯出文字轉為 Excel 儲存格的值：空字串為空白儲存格，數值記號（例如 -999）寫成數值
+func missingExportValue(token string) any {
+	if token == "" {
+		return nil
+	}
+	if n, ok := parseNumber(token, "."); ok {
+		return n
+	}
+	return token
+}
+
+// excelSheetName 產生合法且不重複的工作表名稱
+func excelSheetName(name string, index int, used map[string]bool) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.Trim(name, "'"))
+	if strings.TrimSpace(name) == "" {
+		name = fmt.Sprintf("Sheet%d", index+1)
+	}
+	base := truncateRunes(name, excelMaxSheetName)
+	name = base
+	for n := 2; used[strings.ToLower(name)]; n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		name = truncateRunes(base, excelMaxSheetName-len(suffix)) + suffix
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
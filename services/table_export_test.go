@@ -0,0 +1,180 @@
+package services
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/HazelnutParadise/insyra"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// newExportTestTable 建立含特殊字元、缺失值與 NaN 的資料表
+func newExportTestTable(t *testing.T, names ...string) (*DataTableService, string) {
+	t.Helper()
+	ConfigureInsyra()
+	s := NewDataTableService()
+	if len(names) == 0 {
+		names = []string{"name", "value"}
+	}
+	dt := insyra.NewDataTable(
+		insyra.NewDataList("plain", "a,b", `say "hi"`, "line\nbreak", " padded", nil).SetName(names[0]),
+		insyra.NewDataList(1.5, 2, math.NaN(), nil, -3.25, 0).SetName(names[1]),
+	)
+	s.lock()
+	id := s.appendTable(dt)
+	s.unlock()
+	return s, id
+}
+
+func exportCSV(t *testing.T, s *DataTableService, id string, options CSVExportOptions) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "out.csv")
+	if err := s.ExportTableAsCSVWithOptions(id, path, options); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestExportCSVQuotingAndNAToken(t *testing.T) {
+	s, id := newExportTestTable(t)
+	tests := []struct {
+		name    string
+		options CSVExportOptions
+		want    string
+	}{
+		{
+			name:    "defaults",
+			options: DefaultCSVExportOptions(),
+			want:    "\ufeffname,value\r\nplain,1.5\r\n\"a,b\",2\r\n\"say \"\"hi\"\"\",\r\n\"line\nbreak\",\r\n\" padded\",-3.25\r\n,0\r\n",
+		},
+		{
+			name:    "na token",
+			options: CSVExportOptions{Quote: `"`, Encoding: EncodingUTF8, NAToken: "NA", OmitHeader: true},
+			want:    "plain,1.5\r\n\"a,b\",2\r\n\"say \"\"hi\"\"\",NA\r\n\"line\nbreak\",NA\r\n\" padded\",-3.25\r\nNA,0\r\n",
+		},
+		{
+			name:    "semicolon and decimal comma",
+			options: CSVExportOptions{Delimiter: ";", Quote: "'", Encoding: EncodingUTF8, DecimalSeparator: ","},
+			want:    "name;value\r\nplain;1,5\r\na,b;2\r\nsay \"hi\";\r\n'line\nbreak';\r\n' padded';-3,25\r\n;0\r\n",
+		},
+		{
+			name:    "quote all",
+			options: CSVExportOptions{Quote: `"`, QuoteAll: true, Encoding: EncodingUTF8, OmitHeader: true},
+			want:    "\"plain\",\"1.5\"\r\n\"a,b\",\"2\"\r\n\"say \"\"hi\"\"\",\"\"\r\n\"line\nbreak\",\"\"\r\n\" padded\",\"-3.25\"\r\n\"\",\"0\"\r\n",
+		},
+	}
+	for _, tt := range tests {
+		if got := exportCSV(t, s, id, tt.options); got != tt.want {
+			t.Errorf("%s:\n got %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+
+	// 沒有指定 NAToken 時，缺失值依各欄的缺失值設定寫出
+	if err := s.SetColumnMissingPolicy(id, 1, &MissingPolicy{ExportToken: "-999"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "plain,1.5\r\n\"a,b\",2\r\n\"say \"\"hi\"\"\",-999\r\n\"line\nbreak\",-999\r\n\" padded\",-3.25\r\n,0\r\n"
+	if got := exportCSV(t, s, id, CSVExportOptions{Quote: `"`, Encoding: EncodingUTF8, OmitHeader: true}); got != want {
+		t.Errorf("column export token:\n got %q\nwant %q", got, want)
+	}
+
+	for _, options := range []CSVExportOptions{
+		{Delimiter: ";;"},
+		{Quote: `""`},
+		{QuoteAll: true},
+		{Delimiter: ",", DecimalSeparator: ","},
+		{Encoding: "latin-9"},
+	} {
+		if err := s.ExportTableAsCSVWithOptions(id, filepath.Join(t.TempDir(), "bad.csv"), options); err == nil {
+			t.Errorf("options %+v: want an error", options)
+		}
+	}
+}
+
+func TestExportCSVEncodings(t *testing.T) {
+	s, id := newExportTestTable(t, "名稱", "數值")
+	plain := exportCSV(t, s, id, CSVExportOptions{Encoding: EncodingUTF8})
+	if len(plain) < 6 || plain[:6] != "名稱" {
+		t.Fatalf("utf-8 output starts with %q", plain[:min(len(plain), 6)])
+	}
+
+	big5 := exportCSV(t, s, id, CSVExportOptions{Encoding: EncodingBig5})
+	decoded, err := traditionalchinese.Big5.NewDecoder().String(big5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded != plain {
+		t.Errorf("big5 output decodes to %q, want %q", decoded, plain)
+	}
+
+	for _, tt := range []struct {
+		encoding string
+		prefix   []byte
+	}{
+		{EncodingUTF8BOM, []byte{0xEF, 0xBB, 0xBF}},
+		{EncodingUTF16LE, []byte{0xFF, 0xFE, 0x0D, 0x54}}, // BOM 與「名」(U+540D)
+		{EncodingUTF16BE, []byte{0xFE, 0xFF, 0x54, 0x0D}},
+	} {
+		got := exportCSV(t, s, id, CSVExportOptions{Encoding: tt.encoding})
+		if !bytes.HasPrefix([]byte(got), tt.prefix) {
+			t.Errorf("%s output starts with % x, want % x", tt.encoding, []byte(got)[:min(len(got), len(tt.prefix))], tt.prefix)
+		}
+	}
+}
+
+func TestExportJSONOrientations(t *testing.T) {
+	// 空白的欄名以欄位字母為鍵
+	s, id := newExportTestTable(t, "x", "")
+	dt := s.getTableByID(id)
+	dt.AppendCols(insyra.NewDataList(true, false, true, false, true, false))
+	export := func(options JSONExportOptions) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "out.json")
+		if err := s.ExportTableAsJSONWithOptions(id, path, options); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	records := `[{"x":"plain","B":1.5,"C":true},{"x":"a,b","B":2,"C":false},` +
+		`{"x":"say \"hi\"","B":null,"C":true},{"x":"line\nbreak","B":null,"C":false},` +
+		`{"x":" padded","B":-3.25,"C":true},{"x":null,"B":0,"C":false}]`
+	if got := export(JSONExportOptions{Orientation: JSONOrientRecords}); got != records {
+		t.Errorf("records:\n got %s\nwant %s", got, records)
+	}
+	if got := export(JSONExportOptions{}); got != records {
+		t.Errorf("default orientation:\n got %s\nwant %s", got, records)
+	}
+	columns := `{"x":["plain","a,b","say \"hi\"","line\nbreak"," padded",null],` +
+		`"B":[1.5,2,null,null,-3.25,0],"C":[true,false,true,false,true,false]}`
+	if got := export(JSONExportOptions{Orientation: JSONOrientColumns}); got != columns {
+		t.Errorf("columns:\n got %s\nwant %s", got, columns)
+	}
+	if got := export(JSONExportOptions{Orientation: JSONOrientColumns, Indent: true}); got[:8] != "{\n  \"x\":" {
+		t.Errorf("indented output starts with %q", got[:8])
+	}
+
+	if err := s.ExportTableAsJSONWithOptions(id, filepath.Join(t.TempDir(), "bad.json"), JSONExportOptions{Orientation: "index"}); err == nil {
+		t.Error("orientation index: want an error")
+	}
+}
+
+func TestUniqueKeys(t *testing.T) {
+	got := uniqueKeys([]string{"a", "a", "", "B", "a_2"})
+	want := []string{"a", "a_2", "C", "B", "a_2_2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("uniqueKeys() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,434 @@
+package services
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+// ===== 列與欄的結構編輯 =====
+//
+// 列的編輯只改寫各欄受影響的部分，欄的編輯只搬移欄位本身，不複製整張資料表。
+// 復原步驟只保存插入或刪除的片段與搬移的位置：刪除時保存被刪除的列或欄，搬移時反向搬移。
+
+// structureEdit 一次結構編輯：apply 套用（重做時再次呼叫），revert 還原
+type structureEdit struct {
+	apply  func(dt *insyra.DataTable)
+	revert func(dt *insyra.DataTable)
+	// origin 編輯後各欄在編輯前的索引，新增的欄為 -1，用來搬移計算欄位的公式與欄位 ID；nil 表示欄位不變
+	origin []int
+}
+
+// applyStructureEdit 套用結構編輯並記錄為一個復原步驟；計算欄位的公式與欄位 ID 隨欄位搬移
+func (s *DataTableService) applyStructureEdit(dt *insyra.DataTable, label string, edit structureEdit) {
+	if edit.origin == nil {
+		edit.apply(dt)
+		s.record(dt, label, edit.revert, edit.apply)
+		return
+	}
+	_, colCount := dt.Size()
+	beforeFormulas := s.formulaTexts(dt)
+	afterFormulas := remapFormulas(beforeFormulas, edit.origin, colCount)
+	beforeIDs := slices.Clone(s.columnIDsOf(dt))
+	afterIDs := s.remapColumnIDs(beforeIDs, edit.origin)
+	// 複製出的欄沿用原欄的設定；刪除的欄保留其設定，復原時隨 ID 一併恢復
+	for j, from := range edit.origin {
+		if from >= 0 && afterIDs[j] != beforeIDs[from] {
+			s.copyColumnSettings(dt, beforeIDs[from], afterIDs[j])
+		}
+	}
+	edit.apply(dt)
+	s.setFormulaTexts(dt, afterFormulas)
+	s.setColumnIDs(dt, afterIDs)
+	s.record(dt, label,
+		func(dt *insyra.DataTable) {
+			edit.revert(dt)
+			s.setFormulaTexts(dt, beforeFormulas)
+			s.setColumnIDs(dt, beforeIDs)
+		},
+		func(dt *insyra.DataTable) {
+			edit.apply(dt)
+			s.setFormulaTexts(dt, afterFormulas)
+			s.setColumnIDs(dt, afterIDs)
+		},
+	)
+}
+
+// newColumn 以 values 建立欄位；values 直接成為欄位的內容，呼叫者之後不可再修改
+func newColumn(name string, values []any) *insyra.DataList {
+	col := insyra.NewDataList().SetName(name)
+	// Append 不像 NewDataList 會逐一檢查並展開每個值
+	col.Append(values...)
+	return col
+}
+
+// ownedColumn 取得第 j 欄；insyra 回傳的是內容的副本，可以直接修改後再以 UpdateColByNumber 放回。
+// 較短的欄以 nil 補齊為 rowCount 列
+func ownedColumn(dt *insyra.DataTable, j int, rowCount int) *insyra.DataList {
+	col := dt.GetColByNumber(j)
+	if n := col.Len(); n < rowCount {
+		col.Append(make([]any, rowCount-n)...)
+	}
+	return col
+}
+
+// insertRows 在 index 之前插入 count 列；values 依欄位順序提供各欄插入的值，nil 表示插入空白列
+func insertRows(dt *insyra.DataTable, index int, count int, values [][]any) {
+	rowCount, colCount := dt.Size()
+	for j := range colCount {
+		col := ownedColumn(dt, j, rowCount)
+		col.Append(make([]any, count)...)
+		data := col.Data()
+		copy(data[index+count:], data[index:rowCount])
+		block := data[index : index+count]
+		clear(block)
+		if values != nil {
+			copy(block, values[j])
+		}
+		dt.UpdateColByNumber(j, col)
+	}
+}
+
+// copyRows 取得從 start 開始的 count 列，依欄位順序排列
+func copyRows(dt *insyra.DataTable, start int, count int) [][]any {
+	_, colCount := dt.Size()
+	rows := make([][]any, colCount)
+	for j := range colCount {
+		rows[j] = make([]any, count)
+		for i := range count {
+			rows[j][i] = dt.GetElementByNumberIndex(start+i, j)
+		}
+	}
+	return rows
+}
+
+// removeRows 刪除從 start 開始的 count 列，回傳被刪除的值（依欄位順序排列）
+func removeRows(dt *insyra.DataTable, start int, count int) [][]any {
+	rowCount, colCount := dt.Size()
+	removed := make([][]any, colCount)
+	for j := range colCount {
+		col := ownedColumn(dt, j, rowCount)
+		data := col.Data()
+		removed[j] = slices.Clone(data[start : start+count])
+		rest := slices.Delete(data, start, start+count)
+		dt.UpdateColByNumber(j, newColumn(col.GetName(), slices.Clip(rest)))
+	}
+	return removed
+}
+
+// moveRows 將從 start 開始的 count 列移動到 target
+func moveRows(dt *insyra.DataTable, start int, count int, target int) {
+	rowCount, colCount := dt.Size()
+	for j := range colCount {
+		col := ownedColumn(dt, j, rowCount)
+		data := col.Data()
+		block := slices.Clone(data[start : start+count])
+		if target < start {
+			copy(data[target+count:start+count], data[target:start])
+		} else {
+			copy(data[start:target], data[start+count:target+count])
+		}
+		copy(data[target:], block)
+		dt.UpdateColByNumber(j, col)
+	}
+}
+
+// permuteColumns 重新排列欄位，order[j] 為新的第 j 欄原本的索引；只交換欄位，不複製內容
+func permuteColumns(dt *insyra.DataTable, order []int) {
+	at := make([]int, len(order))  // 目前第 k 欄原本的索引
+	pos := make([]int, len(order)) // 原本第 i 欄目前的位置
+	for k := range order {
+		at[k], pos[k] = k, k
+	}
+	for j, want := range order {
+		k := pos[want]
+		if k == j {
+			continue
+		}
+		dt.SwapColsByNumber(j, k)
+		at[j], at[k] = at[k], at[j]
+		pos[at[j]], pos[at[k]] = j, k
+	}
+}
+
+// identity 回傳 0 到 n-1 的索引
+func identity(n int) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+// moveColumns 將從 start 開始的 count 個欄移動到 target
+func moveColumns(dt *insyra.DataTable, start int, count int, target int) {
+	_, colCount := dt.Size()
+	permuteColumns(dt, moveBlock(identity(colCount), start, count, target))
+}
+
+// insertColumns 在 index 之前插入欄位；欄位內容會先複製，原本的 columns 可以重複使用。
+// 與現有欄位重複的欄名以 uniqueColumnNames 加上流水號
+func insertColumns(dt *insyra.DataTable, index int, columns []*insyra.DataList) {
+	rowCount, colCount := dt.Size()
+	names := make([]string, 0, colCount+len(columns))
+	for j := range colCount {
+		names = append(names, dt.GetColNameByNumber(j))
+	}
+	for _, col := range columns {
+		names = append(names, col.GetName())
+	}
+	names = uniqueColumnNames(names)[colCount:]
+	added := make([]*insyra.DataList, len(columns))
+	for j, col := range columns {
+		data := slices.Clone(col.Data())
+		if len(data) < rowCount {
+			data = append(data, make([]any, rowCount-len(data))...)
+		}
+		added[j] = newColumn(names[j], data)
+	}
+	dt.AppendCols(added...)
+	moveColumns(dt, colCount, len(columns), index)
+}
+
+// copyColumns 取得從 start 開始的 count 個欄的副本
+func copyColumns(dt *insyra.DataTable, start int, count int) []*insyra.DataList {
+	columns := make([]*insyra.DataList, count)
+	for j := range columns {
+		columns[j] = dt.GetColByNumber(start + j)
+	}
+	return columns
+}
+
+// dropColumns 刪除從 start 開始的 count 個欄
+func dropColumns(dt *insyra.DataTable, start int, count int) {
+	dt.DropColsByNumber(identity(count + start)[start:]...)
+}
+
+// checkRange 檢查 [start, start+count) 是否位於 [0, size) 之內
+func checkRange(kind string, start, count, size int) error {
+	if count <= 0 {
+		return invalidArgument("%s count must be positive, got %d", kind, count).WithDetail("count", count)
+	}
+	if start < 0 || start+count > size {
+		return errRangeOutOfBounds(kind, start, count, size)
+	}
+	return nil
+}
+
+// errRangeOutOfBounds 列或欄範圍超出資料表
+func errRangeOutOfBounds(kind string, start, count, size int) *ServiceError {
+	return newError(ErrCodeOutOfRange, "errors.range_out_of_range",
+		"%s range [%d, %d) is out of bounds (size %d)", kind, start, start+count, size).
+		WithDetail("kind", kind).WithDetail("start", start).WithDetail("count", count)
+}
+
+// errIndexOutOfBounds 插入或移動的目標位置不在 [0, limit] 之內
+func errIndexOutOfBounds(kind string, index, limit int) *ServiceError {
+	return newError(ErrCodeOutOfRange, "errors.index_out_of_range",
+		"%s index %d is out of bounds (0 to %d)", kind, index, limit).
+		WithDetail("kind", kind).WithDetail("index", index)
+}
+
+// moveBlock 將 [start, start+count) 移到剩餘元素中的 target 位置之前
+func moveBlock[T any](items []T, start, count, target int) []T {
+	block := slices.Clone(items[start : start+count])
+	rest := slices.Delete(slices.Clone(items), start, start+count)
+	return slices.Insert(rest, target, block...)
+}
+
+// InsertRowsByID 在 index 之前插入 count 列空白列；index 等於列數時附加到末尾
+func (s *DataTableService) InsertRowsByID(tableID string, index int, count int) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
+	}
+	rowCount, colCount := dt.Size()
+	if count <= 0 {
+		return invalidArgument("row count must be positive, got %d", count).WithDetail("count", count)
+	}
+	if index < 0 || index > rowCount {
+		return errIndexOutOfBounds("row", index, rowCount)
+	}
+	if colCount == 0 {
+		// 沒有欄位時先建立預設欄位，與 AddRowByID 相同
+		s.applyStructureEdit(dt, "insert rows", structureEdit{
+			apply:  func(dt *insyra.DataTable) { dt.AppendCols(newColumn("Column1", make([]any, count))) },
+			revert: func(dt *insyra.DataTable) { dropColumns(dt, 0, 1) },
+			origin: []int{-1},
+		})
+		return nil
+	}
+	s.applyStructureEdit(dt, "insert rows", structureEdit{
+		apply:  func(dt *insyra.DataTable) { insertRows(dt, index, count, nil) },
+		revert: func(dt *insyra.DataTable) { removeRows(dt, index, count) },
+	})
+	// 新的空白列需要計算各計算欄位的值
+	for row := index; row < index+count && len(s.formulas[dt]) > 0; row++ {
+		s.recomputeRow(dt, slices.Collect(maps.Keys(s.formulas[dt])), true, row)
+	}
+	return nil
+}
+
+// DeleteRowsByID 刪除從 start 開始的 count 列
+func (s *DataTableService) DeleteRowsByID(tableID string, start int, count int) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
+	}
+	rowCount, _ := dt.Size()
+	if err := checkRange("row", start, count, rowCount); err != nil {
+		return err
+	}
+	var removed [][]any
+	s.applyStructureEdit(dt, "delete rows", structureEdit{
+		apply:  func(dt *insyra.DataTable) { removed = removeRows(dt, start, count) },
+		revert: func(dt *insyra.DataTable) { insertRows(dt, start, count, removed) },
+	})
+	return nil
+}
+
+// DuplicateRowsByID 複製從 start 開始的 count 列，並插入在原範圍之後
+func (s *DataTableService) DuplicateRowsByID(tableID string, start int, count int) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
+	}
+	rowCount, _ := dt.Size()
+	if err := checkRange("row", start, count, rowCount); err != nil {
+		return err
+	}
+	s.applyStructureEdit(dt, "duplicate rows", structureEdit{
+		apply:  func(dt *insyra.DataTable) { insertRows(dt, start+count, count, copyRows(dt, start, count)) },
+		revert: func(dt *insyra.DataTable) { removeRows(dt, start+count, count) },
+	})
+	return nil
+}
+
+// MoveRowsByID 將從 start 開始的 count 列移動到 target；target 為移動後第一列的位置
+func (s *DataTableService) MoveRowsByID(tableID string, start int, count int, target int) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
+	}
+	rowCount, _ := dt.Size()
+	if err := checkRange("row", start, count, rowCount); err != nil {
+		return err
+	}
+	if target < 0 || target > rowCount-count {
+		return errIndexOutOfBounds("row", target, rowCount-count)
+	}
+	s.applyStructureEdit(dt, "move rows", structureEdit{
+		apply:  func(dt *insyra.DataTable) { moveRows(dt, start, count, target) },
+		revert: func(dt *insyra.DataTable) { moveRows(dt, target, count, start) },
+	})
+	return nil
+}
+
+// InsertColumnsByID 在 index 之前插入 count 個空白欄；index 等於欄數時附加到末尾
+func (s *DataTableService) InsertColumnsByID(tableID string, index int, count int) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
+	}
+	_, colCount := dt.Size()
+	if count <= 0 {
+		return invalidArgument("column count must be positive, got %d", count).WithDetail("count", count)
+	}
+	if index < 0 || index > colCount {
+		return errIndexOutOfBounds("column", index, colCount)
+	}
+	// 新增的欄不給名字，與貼上時自動擴張的欄一致
+	blank := make([]*insyra.DataList, count)
+	for j := range blank {
+		blank[j] = insyra.NewDataList()
+	}
+	newOrigin := make([]int, count)
+	for j := range newOrigin {
+		newOrigin[j] = -1
+	}
+	s.applyStructureEdit(dt, "insert columns", structureEdit{
+		apply:  func(dt *insyra.DataTable) { insertColumns(dt, index, blank) },
+		revert: func(dt *insyra.DataTable) { dropColumns(dt, index, count) },
+		origin: slices.Insert(identity(colCount), index, newOrigin...),
+	})
+	return nil
+}
+
+// DeleteColumnsByID 刪除從 start 開始的 count 個欄
+func (s *DataTableService) DeleteColumnsByID(tableID string, start int, count int) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
+	}
+	_, colCount := dt.Size()
+	if err := checkRange("column", start, count, colCount); err != nil {
+		return err
+	}
+	var removed []*insyra.DataList
+	s.applyStructureEdit(dt, "delete columns", structureEdit{
+		apply: func(dt *insyra.DataTable) {
+			removed = copyColumns(dt, start, count)
+			dropColumns(dt, start, count)
+		},
+		revert: func(dt *insyra.DataTable) { insertColumns(dt, start, removed) },
+		origin: slices.Delete(identity(colCount), start, start+count),
+	})
+	return nil
+}
+
+// DuplicateColumnsByID 複製從 start 開始的 count 個欄，並插入在原範圍之後；複本的欄名加上流水號，例如 "x" 的複本為 "x_1"
+func (s *DataTableService) DuplicateColumnsByID(tableID string, start int, count int) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
+	}
+	_, colCount := dt.Size()
+	if err := checkRange("column", start, count, colCount); err != nil {
+		return err
+	}
+	order := identity(colCount)
+	s.applyStructureEdit(dt, "duplicate columns", structureEdit{
+		apply:  func(dt *insyra.DataTable) { insertColumns(dt, start+count, copyColumns(dt, start, count)) },
+		revert: func(dt *insyra.DataTable) { dropColumns(dt, start+count, count) },
+		origin: slices.Insert(order, start+count, order[start:start+count]...),
+	})
+	return nil
+}
+
+// MoveColumnsByID 將從 start 開始的 count 個欄移動到 target；target 為移動後第一欄的位置
+func (s *DataTableService) MoveColumnsByID(tableID string, start int, count int, target int) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
+	}
+	_, colCount := dt.Size()
+	if err := checkRange("column", start, count, colCount); err != nil {
+		return err
+	}
+	if target < 0 || target > colCount-count {
+		return errIndexOutOfBounds("column", target, colCount-count)
+	}
+	s.applyStructureEdit(dt, "move columns", structureEdit{
+		apply:  func(dt *insyra.DataTable) { moveColumns(dt, start, count, target) },
+		revert: func(dt *insyra.DataTable) { moveColumns(dt, target, count, start) },
+		origin: moveBlock(identity(colCount), start, count, target),
+	})
+	return nil
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+// newStructureTestTable 建立含兩欄資料與一個計算欄位（C = A*10）的資料表
+func newStructureTestTable(t *testing.T) (*DataTableService, string, *insyra.DataTable) {
+	t.Helper()
+	ConfigureInsyra()
+	s := NewDataTableService()
+	dt := insyra.NewDataTable(
+		insyra.NewDataList(1, 2, 3, 4).SetName("A"),
+		insyra.NewDataList("a", "b", "c", "d").SetName("B"),
+	)
+	s.lock()
+	id := s.appendTable(dt)
+	s.unlock()
+	if err := s.AddCalculatedColumnByID(id, "C", "A*10"); err != nil {
+		t.Fatal(err)
+	}
+	return s, id, dt
+}
+
+type tableSnapshot struct {
+	Names    []string
+	Columns  [][]any
+	IDs      []int
+	Formulas map[int]string
+}
+
+func snapshotTable(s *DataTableService, dt *insyra.DataTable) tableSnapshot {
+	names, columns := snapshotColumns(dt)
+	return tableSnapshot{Names: names, Columns: columns, IDs: append([]int(nil), s.columnIDsOf(dt)...), Formulas: s.formulaTexts(dt)}
+}
+
+func TestStructureEditsUndoRedo(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(s *DataTableService, id string) error
+		check func(t *testing.T, got tableSnapshot, before tableSnapshot)
+	}{
+		{
+			name: "insert rows",
+			edit: func(s *DataTableService, id string) error { return s.InsertRowsByID(id, 1, 2) },
+			check: func(t *testing.T, got tableSnapshot, _ tableSnapshot) {
+				want := [][]any{{1, nil, nil, 2, 3, 4}, {"a", nil, nil, "b", "c", "d"}}
+				if !reflect.DeepEqual(got.Columns[:2], want) {
+					t.Errorf("columns = %v, want %v", got.Columns[:2], want)
+				}
+			},
+		},
+		{
+			name: "delete rows",
+			edit: func(s *DataTableService, id string) error { return s.DeleteRowsByID(id, 1, 2) },
+			check: func(t *testing.T, got tableSnapshot, _ tableSnapshot) {
+				want := [][]any{{1, 4}, {"a", "d"}}
+				if !reflect.DeepEqual(got.Columns[:2], want) {
+					t.Errorf("columns = %v, want %v", got.Columns[:2], want)
+				}
+			},
+		},
+		{
+			name: "duplicate rows",
+			edit: func(s *DataTableService, id string) error { return s.DuplicateRowsByID(id, 0, 2) },
+			check: func(t *testing.T, got tableSnapshot, _ tableSnapshot) {
+				want := [][]any{{1, 2, 1, 2, 3, 4}, {"a", "b", "a", "b", "c", "d"}}
+				if !reflect.DeepEqual(got.Columns[:2], want) {
+					t.Errorf("columns = %v, want %v", got.Columns[:2], want)
+				}
+			},
+		},
+		{
+			name: "move rows",
+			edit: func(s *DataTableService, id string) error { return s.MoveRowsByID(id, 0, 2, 2) },
+			check: func(t *testing.T, got tableSnapshot, _ tableSnapshot) {
+				want := [][]any{{3, 4, 1, 2}, {"c", "d", "a", "b"}}
+				if !reflect.DeepEqual(got.Columns[:2], want) {
+					t.Errorf("columns = %v, want %v", got.Columns[:2], want)
+				}
+			},
+		},
+		{
+			name: "insert columns",
+			edit: func(s *DataTableService, id string) error { return s.InsertColumnsByID(id, 0, 1) },
+			check: func(t *testing.T, got tableSnapshot, before tableSnapshot) {
+				if want := []string{"", "A", "B", "C"}; !reflect.DeepEqual(got.Names, want) {
+					t.Errorf("names = %v, want %v", got.Names, want)
+				}
+				if !reflect.DeepEqual(got.IDs[1:], before.IDs) {
+					t.Errorf("ids = %v, want %v after the new column", got.IDs, before.IDs)
+				}
+				if got.Formulas[3] != "B*10" {
+					t.Errorf("formula = %q, want B*10", got.Formulas[3])
+				}
+			},
+		},
+		{
+			name: "delete columns",
+			edit: func(s *DataTableService, id string) error { return s.DeleteColumnsByID(id, 1, 1) },
+			check: func(t *testing.T, got tableSnapshot, before tableSnapshot) {
+				if want := []string{"A", "C"}; !reflect.DeepEqual(got.Names, want) {
+					t.Errorf("names = %v, want %v", got.Names, want)
+				}
+				if want := []int{before.IDs[0], before.IDs[2]}; !reflect.DeepEqual(got.IDs, want) {
+					t.Errorf("ids = %v, want %v", got.IDs, want)
+				}
+				if got.Formulas[1] != "A*10" {
+					t.Errorf("formula = %q, want A*10", got.Formulas[1])
+				}
+			},
+		},
+		{
+			name: "duplicate columns",
+			edit: func(s *DataTableService, id string) error { return s.DuplicateColumnsByID(id, 0, 2) },
+			check: func(t *testing.T, got tableSnapshot, before tableSnapshot) {
+				if len(got.Names) != 5 || !reflect.DeepEqual(got.Columns[2], before.Columns[0]) || !reflect.DeepEqual(got.Columns[3], before.Columns[1]) {
+					t.Errorf("names = %v, columns = %v", got.Names, got.Columns)
+				}
+				if got.IDs[2] == before.IDs[0] || got.IDs[4] != before.IDs[2] {
+					t.Errorf("ids = %v (before %v)", got.IDs, before.IDs)
+				}
+			},
+		},
+		{
+			name: "move columns",
+			edit: func(s *DataTableService, id string) error { return s.MoveColumnsByID(id, 0, 1, 2) },
+			check: func(t *testing.T, got tableSnapshot, before tableSnapshot) {
+				if want := []string{"B", "C", "A"}; !reflect.DeepEqual(got.Names, want) {
+					t.Errorf("names = %v, want %v", got.Names, want)
+				}
+				if want := []int{before.IDs[1], before.IDs[2], before.IDs[0]}; !reflect.DeepEqual(got.IDs, want) {
+					t.Errorf("ids = %v, want %v", got.IDs, want)
+				}
+				if got.Formulas[1] != "C*10" {
+					t.Errorf("formula = %q, want C*10", got.Formulas[1])
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, id, dt := newStructureTestTable(t)
+			before := snapshotTable(s, dt)
+			if err := tt.edit(s, id); err != nil {
+				t.Fatal(err)
+			}
+			after := snapshotTable(s, dt)
+			tt.check(t, after, before)
+
+			if ok, err := s.Undo(id); !ok || err != nil {
+				t.Fatalf("Undo() = %v, %v", ok, err)
+			}
+			if got := snapshotTable(s, dt); !reflect.DeepEqual(got, before) {
+				t.Errorf("after undo = %+v, want %+v", got, before)
+			}
+			if ok, err := s.Redo(id); !ok || err != nil {
+				t.Fatalf("Redo() = %v, %v", ok, err)
+			}
+			if got := snapshotTable(s, dt); !reflect.DeepEqual(got, after) {
+				t.Errorf("after redo = %+v, want %+v", got, after)
+			}
+		})
+	}
+}
+
+func TestInsertRowsComputesFormulas(t *testing.T) {
+	s, id, dt := newStructureTestTable(t)
+	if err := s.InsertRowsByID(id, 4, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateCellValueByID(id, 4, 0, "5"); err != nil {
+		t.Fatal(err)
+	}
+	if got := dt.GetElementByNumberIndex(4, 2); got != 50 && got != 50.0 {
+		t.Fatalf("C[4] = %#v, want 50", got)
+	}
+}
+
+func TestStructureEditRangeErrors(t *testing.T) {
+	s, id, _ := newStructureTestTable(t)
+	if err := s.DeleteRowsByID(id, 3, 2); AsServiceError(err).Code != ErrCodeOutOfRange {
+		t.Errorf("DeleteRowsByID() error = %v", err)
+	}
+	if err := s.MoveColumnsByID(id, 0, 1, 3); AsServiceError(err).Code != ErrCodeOutOfRange {
+		t.Errorf("MoveColumnsByID() error = %v", err)
+	}
+	if err := s.InsertColumnsByID(id, 0, 0); AsServiceError(err).Code != ErrCodeInvalidArgument {
+		t.Errorf("InsertColumnsByID() error = %v", err)
+	}
+}
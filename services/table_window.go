@@ -0,0 +1,77 @@
+package services
+
+import (
+	"math"
+	"strconv"
+)
+
+// ===== 分頁讀取 =====
+//
+// 大型資料表不一次傳送所有儲存格，前端捲動時只讀取可見範圍。
+
+// TableWindow 資料表中一個矩形範圍的儲存格
+type TableWindow struct {
+	RowCount  int           `json:"rowCount"` // 資料表的總列數
+	ColCount  int           `json:"colCount"` // 資料表的總欄數
+	RowOffset int           `json:"rowOffset"`
+	ColOffset int           `json:"colOffset"`
+	Columns   []TableColumn `json:"columns"` // 範圍內各欄的欄位資訊，與 GetTableDataByID 相同
+	Rows      [][]any       `json:"rows"`    // 依列排列，Rows[i][j] 為第 RowOffset+i 列、第 ColOffset+j 欄
+}
+
+// GetTableWindow 取得從 (rowOffset, colOffset) 開始、最多 rowLimit 列 × colLimit 欄的儲存格；
+// 超出資料表的部分不回傳，因此回傳的列數與欄數可能小於 limit
+func (s *DataTableService) GetTableWindow(tableID string, rowOffset int, rowLimit int, colOffset int, colLimit int) (TableWindow, error) {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return TableWindow{}, errTableNotFound(tableID)
+	}
+	if rowOffset < 0 || colOffset < 0 {
+		return TableWindow{}, invalidArgument("window offset must not be negative, got (%d, %d)", rowOffset, colOffset)
+	}
+	if rowLimit <= 0 || colLimit <= 0 {
+		return TableWindow{}, invalidArgument("window limit must be positive, got (%d, %d)", rowLimit, colLimit)
+	}
+
+	rowCount, colCount := dt.Size()
+	rowEnd := min(rowOffset+rowLimit, rowCount)
+	colStart, colEnd := min(colOffset, colCount), min(colOffset+colLimit, colCount)
+	window := TableWindow{
+		RowCount:  rowCount,
+		ColCount:  colCount,
+		RowOffset: rowOffset,
+		ColOffset: colOffset,
+		Columns:   []TableColumn{},
+		Rows:      [][]any{},
+	}
+	ids := s.columnIDsOf(dt)
+	for j := colStart; j < colEnd; j++ {
+		window.Columns = append(window.Columns, s.tableColumn(dt, ids, j))
+	}
+	// 逐格讀取，只存取範圍內的欄位，成本與範圍大小成正比
+	for i := rowOffset; i < rowEnd; i++ {
+		row := make([]any, colEnd-colStart)
+		for j := range row {
+			row[j] = jsonCell(dt.GetElementByNumberIndex(i, colStart+j))
+		}
+		window.Rows = append(window.Rows, row)
+	}
+	return window, nil
+}
+
+// jsonCell 將無法以 JSON 表示的 NaN 與 Inf 轉為文字
+func jsonCell(v any) any {
+	switch val := v.(type) {
+	case float64:
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			return strconv.FormatFloat(val, 'g', -1, 64)
+		}
+	case float32:
+		if f := float64(val); math.IsNaN(f) || math.IsInf(f, 0) {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	}
+	return v
+}
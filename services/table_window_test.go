@@ -0,0 +1,55 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestGetTableWindowReadsColumnRange(t *testing.T) {
+	s, id, dt := newStructureTestTable(t)
+	dt.UpdateElement(0, "A", math.NaN())
+
+	data, err := s.GetTableDataByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	window, err := s.GetTableWindow(id, 2, 5, 1, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if window.RowCount != 4 || window.ColCount != 3 || window.RowOffset != 2 || window.ColOffset != 1 {
+		t.Fatalf("window bounds = %+v", window)
+	}
+	// 欄位資訊與 GetTableDataByID 相同
+	if want := data["columns"].([]TableColumn)[1:]; !reflect.DeepEqual(window.Columns, want) {
+		t.Errorf("columns = %+v, want %+v", window.Columns, want)
+	}
+	wantRows := [][]any{{"c", 30.0}, {"d", 40.0}}
+	if !reflect.DeepEqual(window.Rows, wantRows) {
+		t.Errorf("rows = %#v, want %#v", window.Rows, wantRows)
+	}
+
+	first, err := s.GetTableWindow(id, 0, 1, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := first.Rows[0][0]; got != "NaN" {
+		t.Errorf("NaN cell = %#v, want \"NaN\"", got)
+	}
+
+	// 超出資料表的範圍回傳空的結果
+	empty, err := s.GetTableWindow(id, 10, 5, 7, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(empty.Columns) != 0 || len(empty.Rows) != 0 {
+		t.Errorf("window past the end = %+v", empty)
+	}
+	if _, err := s.GetTableWindow(id, -1, 5, 0, 5); err == nil {
+		t.Error("negative offset accepted")
+	}
+	if _, err := s.GetTableWindow(id, 0, 0, 0, 5); err == nil {
+		t.Error("zero limit accepted")
+	}
+}
@@ -0,0 +1,206 @@
+// columnargen 以 Apache Arrow 的 Go 實作（arrow-go）產生 services/testdata 中的 Parquet 與 Arrow 測試檔案，
+// 讓測試讀取的檔案不是由本套件自己的寫出程式產生。在此目錄執行：
+//
+//	go run . ..
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+)
+
+// 每個 row group 或 record batch 的列數；10 列分成 3 組
+const groupRows = 4
+
+var (
+	ids    = []int64{1001, 1008, 995, 1200, -40, 1201, 1202, 1300, 7, 1000000}
+	smalls = []any{int32(3), nil, int32(-7), int32(12), int32(12), nil, int32(0), int32(5), int32(6), int32(2147483647)}
+	scores = []any{1.5, -0.25, nil, 3.125, 100, nil, 2.5, -8, 0, 1e10}
+	names  = []any{"alpha", "beta", "alpha", nil, "gamma", "beta", "alpha", "資料", nil, "gamma"}
+	notes  = []any{"n1", "", "longer note", "n4", nil, "n6", "n7", "note eight", "n9", "最後"}
+	flags  = []any{true, false, nil, true, true, false, nil, false, true, true}
+	days   = []any{"2024-01-01", "1970-01-01", "1969-12-31", nil, "2000-02-29", "2024-12-31", "1999-06-15", "2010-10-10", nil, "2038-01-19"}
+	stamps = []any{"2024-01-01T12:30:45.123456Z", nil, "1970-01-01T00:00:00Z", "1960-06-01T08:00:00Z", "2024-02-29T23:59:59.999999Z",
+		"2001-09-09T01:46:40Z", nil, "2024-07-04T00:00:00.5Z", "1999-12-31T23:59:59Z", "2020-05-05T05:05:05.000005Z"}
+)
+
+func record(mem memory.Allocator, schema *arrow.Schema, from int, to int) arrow.Record {
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+	for i := from; i < to; i++ {
+		for j, f := range schema.Fields() {
+			fb := b.Field(j)
+			var v any
+			switch f.Name {
+			case "id":
+				fb.(*array.Int64Builder).Append(ids[i])
+				continue
+			case "small":
+				v = smalls[i]
+			case "score":
+				v = scores[i]
+			case "name":
+				v = names[i]
+			case "note":
+				v = notes[i]
+			case "flag":
+				v = flags[i]
+			case "day":
+				v = days[i]
+			case "ts":
+				v = stamps[i]
+			}
+			if v == nil {
+				fb.AppendNull()
+				continue
+			}
+			switch fb := fb.(type) {
+			case *array.Int32Builder:
+				fb.Append(v.(int32))
+			case *array.Float64Builder:
+				switch x := v.(type) {
+				case float64:
+					fb.Append(x)
+				case int:
+					fb.Append(float64(x))
+				}
+			case *array.StringBuilder:
+				fb.Append(v.(string))
+			case *array.BinaryDictionaryBuilder:
+				if err := fb.AppendString(v.(string)); err != nil {
+					log.Fatal(err)
+				}
+			case *array.BooleanBuilder:
+				fb.Append(v.(bool))
+			case *array.Date32Builder:
+				t, _ := time.Parse(time.DateOnly, v.(string))
+				fb.Append(arrow.Date32FromTime(t))
+			case *array.TimestampBuilder:
+				t, _ := time.Parse(time.RFC3339Nano, v.(string))
+				fb.Append(arrow.Timestamp(t.UnixMicro()))
+			}
+		}
+	}
+	return b.NewRecord()
+}
+
+func fields(dictName bool) []arrow.Field {
+	name := arrow.Field{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true}
+	if dictName {
+		name.Type = &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: arrow.BinaryTypes.String}
+	}
+	return []arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "small", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		{Name: "score", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		name,
+		{Name: "note", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "flag", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+		{Name: "day", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+		{Name: "ts", Type: &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}, Nullable: true},
+	}
+}
+
+func writeParquet(dir string, file string, props ...parquet.WriterProperty) {
+	mem := memory.DefaultAllocator
+	schema := arrow.NewSchema(fields(false), nil)
+	f, err := os.Create(filepath.Join(dir, file))
+	if err != nil {
+		log.Fatal(err)
+	}
+	props = append([]parquet.WriterProperty{parquet.WithMaxRowGroupLength(groupRows), parquet.WithCreatedBy("arrow-go v18.4.1")}, props...)
+	w, err := pqarrow.NewFileWriter(schema, f, parquet.NewWriterProperties(props...), pqarrow.DefaultWriterProps())
+	if err != nil {
+		log.Fatal(err)
+	}
+	rec := record(mem, schema, 0, len(ids))
+	defer rec.Release()
+	if err := w.WriteBuffered(rec); err != nil {
+		log.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeArrow(dir string, file string, stream bool, opts ...ipc.Option) {
+	mem := memory.DefaultAllocator
+	schema := arrow.NewSchema(fields(true), nil)
+	f, err := os.Create(filepath.Join(dir, file))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	opts = append(opts, ipc.WithSchema(schema), ipc.WithAllocator(mem))
+	var w interface {
+		Write(arrow.Record) error
+		Close() error
+	}
+	if stream {
+		w = ipc.NewWriter(f, opts...)
+	} else if w, err = ipc.NewFileWriter(f, opts...); err != nil {
+		log.Fatal(err)
+	}
+	// 各 record batch 由同一個 record 切出，共用同一個字典
+	all := record(mem, schema, 0, len(ids))
+	defer all.Release()
+	for from := 0; from < len(ids); from += groupRows {
+		rec := all.NewSlice(int64(from), int64(min(from+groupRows, len(ids))))
+		if err := w.Write(rec); err != nil {
+			log.Fatal(err)
+		}
+		rec.Release()
+	}
+	if err := w.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func main() {
+	dir := "."
+	if len(os.Args) > 1 {
+		dir = os.Args[1]
+	}
+	// 資料頁面 v1 與字典編碼，每種壓縮方式一個檔案
+	for file, codec := range map[string]compress.Compression{
+		"v1_dict_none.parquet":   compress.Codecs.Uncompressed,
+		"v1_dict_snappy.parquet": compress.Codecs.Snappy,
+		"v1_dict_gzip.parquet":   compress.Codecs.Gzip,
+		"v1_dict_zstd.parquet":   compress.Codecs.Zstd,
+		"v1_dict_lz4raw.parquet": compress.Codecs.Lz4Raw,
+	} {
+		writeParquet(dir, file, parquet.WithCompression(codec))
+	}
+	// 舊版格式：字典頁面以 PLAIN_DICTIONARY 標示
+	writeParquet(dir, "v1_plain_dictionary.parquet", parquet.WithVersion(parquet.V1_0), parquet.WithCompression(compress.Codecs.Snappy))
+	// 資料頁面 v2 與字典編碼
+	writeParquet(dir, "v2_dict_zstd.parquet", parquet.WithDataPageVersion(parquet.DataPageV2), parquet.WithCompression(compress.Codecs.Zstd))
+	// 資料頁面 v2 與 DELTA_*、BYTE_STREAM_SPLIT 編碼
+	writeParquet(dir, "v2_delta_snappy.parquet",
+		parquet.WithDataPageVersion(parquet.DataPageV2),
+		parquet.WithCompression(compress.Codecs.Snappy),
+		parquet.WithDictionaryDefault(false),
+		parquet.WithEncodingFor("id", parquet.Encodings.DeltaBinaryPacked),
+		parquet.WithEncodingFor("small", parquet.Encodings.DeltaBinaryPacked),
+		parquet.WithEncodingFor("day", parquet.Encodings.DeltaBinaryPacked),
+		parquet.WithEncodingFor("ts", parquet.Encodings.DeltaBinaryPacked),
+		parquet.WithEncodingFor("name", parquet.Encodings.DeltaByteArray),
+		parquet.WithEncodingFor("note", parquet.Encodings.DeltaLengthByteArray),
+		parquet.WithEncodingFor("score", parquet.Encodings.ByteStreamSplit),
+	)
+
+	writeArrow(dir, "batches.arrow", false)
+	writeArrow(dir, "batches_lz4.arrow", false, ipc.WithLZ4())
+	writeArrow(dir, "batches_zstd.arrow", false, ipc.WithZstd())
+	writeArrow(dir, "batches.arrows", true)
+}
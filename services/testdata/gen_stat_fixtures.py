@@ -0,0 +1,286 @@
+#!/usr/bin/env python3
+"""產生此目錄中的 SPSS 與 Stata 測試檔案。
+
+依 PSPP 的 "System File Format" 與 Stata 的 dta 格式說明逐位元組寫出，
+讓測試讀取的檔案不是由本套件自己的寫出程式產生。
+
+    python3 gen_stat_fixtures.py
+"""
+import struct
+
+SYSMIS = -1.7976931348623157e308
+
+# ----- SPSS -----
+
+# 短名稱、長名稱、寬度、顯示格式（型別、寬度、小數位數）、變數標籤、
+# 數值標籤、離散的缺失值、缺失值範圍、測量尺度、各觀察值的值
+SAV_VARS = [
+    ("ID", "id", 0, (5, 8, 0), "", [], [], None, 3, [1, 2, 3, 4, 5]),
+    ("SCORE", "score", 0, (5, 8, 2), "Test score", [], [-99, -98], None, 3,
+     [1.5, -99, 3.25, None, -98]),
+    ("GENDER", "gender", 0, (5, 1, 0), "Gender", [(1, "Male"), (2, "Female")], [], (8, 9), 1,
+     [1, 2, 9, 8, 1]),
+    ("INCOME", "household_income", 0, (5, 10, 0), "Household income", [], [-1], (900, 999), 3,
+     [1200, -1, 950, 40000, 120]),
+    ("CITY", "city", 12, (1, 12, 0), "City", [], [], None, 1,
+     ["Taipei", "Kaohsiung", "", "Tainan", "Hsinchu city"]),
+]
+
+
+def sav_slots(width):
+    return 1 if width == 0 else (width + 7) // 8
+
+
+def sav_dictionary(nslots, ncases, compression):
+    out = bytearray()
+    out += b"$FL2"
+    out += b"@(#) SPSS DATA FILE fixture".ljust(60)
+    out += struct.pack("<iiiiid", 2, nslots, compression, 0, ncases, 100.0)
+    out += b"17 Oct 26" + b"12:00:00" + b"fixture".ljust(64) + b"\0" * 3
+
+    slot = 0
+    for name, _, width, (ftype, fwidth, fdec), label, _, missing, rng, _, _ in SAV_VARS:
+        fmt = ftype << 16 | fwidth << 8 | fdec
+        nmissing = len(missing)
+        if rng:
+            nmissing = -3 if missing else -2
+        out += struct.pack("<iiiiii", 2, width, 1 if label else 0, nmissing, fmt, fmt)
+        out += name.encode().ljust(8)
+        if label:
+            raw = label.encode()
+            out += struct.pack("<i", len(raw)) + raw + b"\0" * ((4 - len(raw) % 4) % 4)
+        if rng:
+            out += struct.pack("<dd", *rng)
+        for m in missing:
+            out += struct.pack("<d", m)
+        for _ in range(sav_slots(width) - 1):
+            out += struct.pack("<iiiiii", 2, -1, 0, 0, 0, 0) + b" " * 8
+        slot += sav_slots(width)
+
+    slot = 0
+    for _, _, width, _, _, labels, _, _, _, _ in SAV_VARS:
+        if labels:
+            out += struct.pack("<ii", 3, len(labels))
+            for value, text in labels:
+                raw = text.encode()
+                out += struct.pack("<dB", value, len(raw)) + raw
+                out += b"\0" * ((8 - (len(raw) + 1) % 8) % 8)
+            out += struct.pack("<iii", 4, 1, slot + 1)
+        slot += sav_slots(width)
+
+    # 機器整數資訊、浮點數資訊、顯示設定、長變數名稱與字元編碼
+    out += struct.pack("<iiii", 7, 3, 4, 8) + struct.pack("<8i", 20, 0, 0, -1, 1, 1, 2, 65001)
+    out += struct.pack("<iiii", 7, 4, 8, 3) + struct.pack("<3d", SYSMIS, 1.7976931348623157e308, -1.7976931348623155e308)
+    out += struct.pack("<iiii", 7, 11, 4, 3 * len(SAV_VARS))
+    for _, _, width, (_, fwidth, _), _, _, _, _, measure, _ in SAV_VARS:
+        out += struct.pack("<iii", measure, fwidth, 1 if width else 0)
+    names = "\t".join(f"{v[0]}={v[1]}" for v in SAV_VARS).encode()
+    out += struct.pack("<iiii", 7, 13, 1, len(names)) + names
+    out += struct.pack("<iiii", 7, 20, 1, 5) + b"UTF-8"
+    out += struct.pack("<ii", 999, 0)
+    return out
+
+
+def sav_cells(i):
+    """第 i 筆觀察值每個 8 位元組位置的內容"""
+    cells = []
+    for _, _, width, _, _, _, _, _, _, values in SAV_VARS:
+        value = values[i]
+        if width == 0:
+            cells.append(("num", SYSMIS if value is None else float(value)))
+            continue
+        raw = value.encode().ljust(sav_slots(width) * 8)
+        cells += [("str", raw[k:k + 8]) for k in range(0, len(raw), 8)]
+    return cells
+
+
+def write_sav(path, compressed):
+    ncases = len(SAV_VARS[0][9])
+    nslots = sum(sav_slots(v[2]) for v in SAV_VARS)
+    out = sav_dictionary(nslots, ncases, 1 if compressed else 0)
+    if not compressed:
+        for i in range(ncases):
+            for kind, cell in sav_cells(i):
+                out += struct.pack("<d", cell) if kind == "num" else cell
+    else:
+        # 位元組碼壓縮：每 8 個指令之後接著指令 253 的原始內容
+        commands, pending = [], []
+
+        def flush():
+            out.extend(bytes(commands).ljust(8, b"\0"))
+            for raw in pending:
+                out.extend(raw)
+            commands.clear()
+            pending.clear()
+
+        for i in range(ncases):
+            for kind, cell in sav_cells(i):
+                if kind == "num" and cell == SYSMIS:
+                    commands.append(255)
+                elif kind == "num" and cell == int(cell) and -99 <= cell <= 151:
+                    commands.append(int(cell) + 100)
+                elif kind == "str" and cell == b" " * 8:
+                    commands.append(254)
+                else:
+                    commands.append(253)
+                    pending.append(struct.pack("<d", cell) if kind == "num" else cell)
+                if len(commands) == 8:
+                    flush()
+        commands.append(252)
+        flush()
+    with open(path, "wb") as f:
+        f.write(out)
+
+
+# ----- Stata -----
+
+DOUBLE_MISSING = struct.unpack("<d", struct.pack("<Q", 0x7FE0000000000000))[0]
+DOUBLE_MISSING_A = struct.unpack("<d", struct.pack("<Q", 0x7FE0010000000000))[0]
+
+# 名稱、型別代碼、顯示格式、數值標籤名稱、變數標籤、各觀察值的值
+DTA_VARS = [
+    ("id", 65530, "%8.0g", "", "Identifier", [1, 2, 3, 4]),
+    ("income", 65528, "%12.0g", "", "Income", [52000, None, 31000, 2147483620]),
+    ("ratio", 65526, "%9.3f", "", "Ratio", [0.25, DOUBLE_MISSING_A, -1.5, 3.125]),
+    ("weight", 65527, "%9.0g", "", "", [1.5, 2.0, None, 0.25]),
+    ("sex", 65529, "%8.0g", "sexlbl", "Sex", [1, 2, 2, None]),
+    ("city", 8, "%9s", "", "City", ["Taipei", "", "Tainan", "Kaohsiun"]),
+    ("note", 32768, "%9s", "", "Note", ["short", "", "長文字" * 20, "short"]),
+    ("visit", 65528, "%td", "", "Visit date", [0, 23376, None, -365]),
+]
+DTA_LABELS = {"sexlbl": [(1, "male"), (2, "female"), (9, "unknown")]}
+
+
+def dta_value(code, value, j, i, gso):
+    if code == 65530:
+        return struct.pack("<b", 101 if value is None else value)
+    if code == 65529:
+        return struct.pack("<h", 32741 if value is None else value)
+    if code == 65528:
+        return struct.pack("<i", 2147483621 if value is None else value)
+    if code == 65527:
+        return struct.pack("<I", 0x7F000000) if value is None else struct.pack("<f", value)
+    if code == 65526:
+        return struct.pack("<d", DOUBLE_MISSING if value is None else value)
+    if code == 32768:
+        if value == "":
+            return b"\0" * 8
+        raw = value.encode() + b"\0"
+        gso.extend(b"GSO" + struct.pack("<IQBI", j + 1, i + 1, 130, len(raw)) + raw)
+        return struct.pack("<Q", (j + 1) | (i + 1) << 16)
+    return value.encode().ljust(code, b"\0")
+
+
+def dta_label_table(labels, name_len):
+    txt, offsets = bytearray(), []
+    for _, text in labels:
+        offsets.append(len(txt))
+        txt += text.encode() + b"\0"
+    table = struct.pack("<II", len(labels), len(txt))
+    table += struct.pack(f"<{len(labels)}I", *offsets)
+    table += struct.pack(f"<{len(labels)}i", *(v for v, _ in labels)) + txt
+    return table
+
+
+def write_dta118(path):
+    k, n = len(DTA_VARS), len(DTA_VARS[0][5])
+    out = bytearray(b"<stata_dta><header><release>118</release><byteorder>LSF</byteorder><K>")
+    out += struct.pack("<H", k) + b"</K><N>" + struct.pack("<Q", n) + b"</N><label>"
+    out += struct.pack("<H", 7) + b"fixture" + b"</label><timestamp>"
+    out += struct.pack("<B", 17) + b"17 Oct 2026 12:00" + b"</timestamp></header>"
+    offsets = [0]
+
+    def section(tag):
+        offsets.append(len(out))
+        out.extend(tag)
+
+    section(b"<map>")
+    map_at = len(out)
+    out += b"\0" * 14 * 8 + b"</map>"
+    section(b"<variable_types>")
+    out += b"".join(struct.pack("<H", v[1]) for v in DTA_VARS) + b"</variable_types>"
+    section(b"<varnames>")
+    out += b"".join(v[0].encode().ljust(129, b"\0") for v in DTA_VARS) + b"</varnames>"
+    section(b"<sortlist>")
+    out += b"\0" * 2 * (k + 1) + b"</sortlist>"
+    section(b"<formats>")
+    out += b"".join(v[2].encode().ljust(57, b"\0") for v in DTA_VARS) + b"</formats>"
+    section(b"<value_label_names>")
+    out += b"".join(v[3].encode().ljust(129, b"\0") for v in DTA_VARS) + b"</value_label_names>"
+    section(b"<variable_labels>")
+    out += b"".join(v[4].encode().ljust(321, b"\0") for v in DTA_VARS) + b"</variable_labels>"
+    section(b"<characteristics>")
+    note = b"fixture note\0"
+    out += b"<ch>" + struct.pack("<I", 129 * 2 + len(note)) + b"_dta".ljust(129, b"\0")
+    out += b"note1".ljust(129, b"\0") + note + b"</ch></characteristics>"
+    section(b"<data>")
+    gso = bytearray()
+    for i in range(n):
+        for j, (_, code, _, _, _, values) in enumerate(DTA_VARS):
+            out += dta_value(code, values[i], j, i, gso)
+    out += b"</data>"
+    section(b"<strls>")
+    out += gso + b"</strls>"
+    section(b"<value_labels>")
+    for name, labels in DTA_LABELS.items():
+        table = dta_label_table(labels, 129)
+        out += b"<lbl>" + struct.pack("<I", len(table)) + name.encode().ljust(129, b"\0")
+        out += b"\0" * 3 + table + b"</lbl>"
+    out += b"</value_labels>"
+    section(b"</stata_dta>")
+    offsets.append(len(out))
+    struct.pack_into("<14Q", out, map_at, *offsets)
+    with open(path, "wb") as f:
+        f.write(out)
+
+
+# 114 版：typlist 以 251–255 表示 byte、int、long、float、double，文字為 Latin-1
+DTA114_VARS = [
+    ("id", 251, "%8.0g", "", "Identifier", [1, 2, 3]),
+    ("grp", 252, "%8.0g", "grplbl", "Group", [1, 2, None]),
+    ("x", 255, "%9.2f", "", "Measure", [0.5, None, 2.75]),
+    ("place", 6, "%9s", "", "Place", ["caf\xe9", "", "na\xefve"]),
+]
+DTA114_LABELS = {"grplbl": [(1, "contr\xf4le"), (2, "treated")]}
+
+
+def write_dta114(path):
+    k, n = len(DTA114_VARS), len(DTA114_VARS[0][5])
+    out = bytearray(struct.pack("<BBBBHI", 114, 2, 1, 0, k, n))
+    out += b"fixture".ljust(81, b"\0") + b"17 Oct 2026 12:00".ljust(18, b"\0")
+    out += bytes(v[1] for v in DTA114_VARS)
+    out += b"".join(v[0].encode().ljust(33, b"\0") for v in DTA114_VARS)
+    out += b"\0" * 2 * (k + 1)
+    out += b"".join(v[2].encode().ljust(49, b"\0") for v in DTA114_VARS)
+    out += b"".join(v[3].encode().ljust(33, b"\0") for v in DTA114_VARS)
+    out += b"".join(v[4].encode("latin-1").ljust(81, b"\0") for v in DTA114_VARS)
+    out += b"\0" * 5
+    for i in range(n):
+        for _, code, _, _, _, values in DTA114_VARS:
+            value = values[i]
+            if code == 251:
+                out += struct.pack("<b", value)
+            elif code == 252:
+                out += struct.pack("<h", 32741 if value is None else value)
+            elif code == 255:
+                out += struct.pack("<d", DOUBLE_MISSING if value is None else value)
+            else:
+                out += value.encode("latin-1").ljust(code, b"\0")
+    for name, labels in DTA114_LABELS.items():
+        txt, offsets = bytearray(), []
+        for _, text in labels:
+            offsets.append(len(txt))
+            txt += text.encode("latin-1") + b"\0"
+        table = struct.pack("<II", len(labels), len(txt))
+        table += struct.pack(f"<{len(labels)}I", *offsets)
+        table += struct.pack(f"<{len(labels)}i", *(v for v, _ in labels)) + txt
+        out += struct.pack("<I", len(table)) + name.encode().ljust(33, b"\0") + b"\0" * 3 + table
+    with open(path, "wb") as f:
+        f.write(out)
+
+
+if __name__ == "__main__":
+    write_sav("survey.sav", compressed=False)
+    write_sav("survey_compressed.sav", compressed=True)
+    write_dta118("panel_118.dta")
+    write_dta114("legacy_114.dta")
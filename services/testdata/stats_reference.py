@@ -0,0 +1,143 @@
+#!/usr/bin/env python3
+"""計算 hypothesis_tests_test.go 與 correlation_regression_test.go 使用的參考值。
+
+只用標準函式庫依教科書公式計算，不經過 insyra 與 gonum：
+p 值以不完全 beta 與 gamma 函數（Numerical Recipes 的連分數）求得，迴歸以分數精確求解。
+
+    python3 stats_reference.py
+"""
+import math
+from fractions import Fraction as F
+
+
+# ----- 分佈函數 -----
+
+def betacf(a,b,x):
+    MAXIT=300; EPS=1e-16; FPMIN=1e-300
+    qab=a+b; qap=a+1; qam=a-1; c=1.0; d=1-qab*x/qap
+    if abs(d)<FPMIN: d=FPMIN
+    d=1/d; h=d
+    for m in range(1,MAXIT+1):
+        m2=2*m; aa=m*(b-m)*x/((qam+m2)*(a+m2))
+        d=1+aa*d; d=FPMIN if abs(d)<FPMIN else d
+        c=1+aa/c; c=FPMIN if abs(c)<FPMIN else c
+        d=1/d; h*=d*c
+        aa=-(a+m)*(qab+m)*x/((a+m2)*(qap+m2))
+        d=1+aa*d; d=FPMIN if abs(d)<FPMIN else d
+        c=1+aa/c; c=FPMIN if abs(c)<FPMIN else c
+        d=1/d; de=d*c; h*=de
+        if abs(de-1)<EPS: break
+    return h
+# 正規化的不完全 beta 函數
+def ibeta(a,b,x):
+    if x<=0: return 0.0
+    if x>=1: return 1.0
+    bt=math.exp(math.lgamma(a+b)-math.lgamma(a)-math.lgamma(b)+a*math.log(x)+b*math.log(1-x))
+    if x<(a+1)/(a+b+2): return bt*betacf(a,b,x)/a
+    return 1-bt*betacf(b,a,1-x)/b
+# t 分佈的雙尾 p 值
+def t_sf2(t,df):
+    return ibeta(df/2,0.5,df/(df+t*t))
+def t_cdf(t,df):
+    p=0.5*ibeta(df/2,0.5,df/(df+t*t))
+    return 1-p if t>0 else p
+# t 分佈的分位數，以二分法求 t_cdf 的反函數
+def t_q(p,df):
+    lo,hi=-100.0,100.0
+    for _ in range(200):
+        mid=(lo+hi)/2
+        if t_cdf(mid,df)<p: lo=mid
+        else: hi=mid
+    return (lo+hi)/2
+def f_sf(f,d1,d2):
+    return ibeta(d2/2,d1/2,d2/(d2+d1*f))
+# 正規化的上不完全 gamma 函數，卡方分佈的 p 值為 gammq(df/2, x/2)
+def gammq(a,x):
+    if x<a+1:
+        ap=a; s=1/a; de=s
+        for _ in range(1000):
+            ap+=1; de*=x/ap; s+=de
+            if abs(de)<abs(s)*1e-17: break
+        return 1-s*math.exp(-x+a*math.log(x)-math.lgamma(a))
+    b=x+1-a; c=1/1e-300; d=1/b; h=d
+    for i in range(1,1000):
+        an=-i*(i-a); b+=2
+        d=an*d+b; d=1e-300 if abs(d)<1e-300 else d
+        c=b+an/c; c=1e-300 if abs(c)<1e-300 else c
+        d=1/d; de=d*c; h*=de
+        if abs(de-1)<1e-17: break
+    return math.exp(-x+a*math.log(x)-math.lgamma(a))*h
+def mean(v): return sum(v)/len(v)
+def var(v):
+    m=mean(v); return sum((x-m)**2 for x in v)/(len(v)-1)
+
+# ----- 參考值 -----
+
+def g(x): return repr(float(x))
+
+print("== one-sample")
+x=[5.1,4.9,5.6,5.8,6.0,5.3,5.7,6.1]; mu=5
+n=len(x); m=mean(x); sd=math.sqrt(var(x)); se=sd/math.sqrt(n); t=(m-mu)/se; df=n-1
+q=t_q(0.975,df)
+print("t",g(t),"df",df,"p",g(t_sf2(t,df)),"ci",g(m-q*se),g(m+q*se),"d",g((m-mu)/sd))
+print("== paired")
+a=[12.1,11.4,13.2,10.8,12.7,11.9]; b=[11.3,11.0,12.1,10.9,11.8,11.2]
+d=[p-q_ for p,q_ in zip(a,b)]; n=len(d); m=mean(d); sd=math.sqrt(var(d)); se=sd/math.sqrt(n); t=m/se; df=n-1
+q=t_q(0.975,df)
+print("t",g(t),"df",df,"p",g(t_sf2(t,df)),"ci",g(m-q*se),g(m+q*se),"d",g(m/sd))
+print("== independent")
+a=[20.1,22.3,19.8,21.5,23.0,20.7]; b=[18.2,19.5,17.9,20.1,18.8]
+n1,n2=len(a),len(b); m1,m2=mean(a),mean(b); v1,v2=var(a),var(b)
+sp=math.sqrt(((n1-1)*v1+(n2-1)*v2)/(n1+n2-2)); se=sp*math.sqrt(1/n1+1/n2); t=(m1-m2)/se; df=n1+n2-2
+q=t_q(0.975,df)
+print("pooled t",g(t),"df",df,"p",g(t_sf2(t,df)),"ci",g(m1-m2-q*se),g(m1-m2+q*se),"d",g((m1-m2)/sp))
+se=math.sqrt(v1/n1+v2/n2); t=(m1-m2)/se; df=(v1/n1+v2/n2)**2/((v1/n1)**2/(n1-1)+(v2/n2)**2/(n2-1))
+q=t_q(0.975,df)
+print("welch t",g(t),"df",g(df),"p",g(t_sf2(t,df)),"ci",g(m1-m2-q*se),g(m1-m2+q*se))
+print("== anova")
+gs=[[4.2,4.8,5.1,4.5],[5.9,6.3,5.5,6.1,6.0],[4.9,5.2,5.0,5.6]]
+allv=[v for gg in gs for v in gg]; gm=mean(allv)
+ssb=sum(len(gg)*(mean(gg)-gm)**2 for gg in gs); ssw=sum(sum((v-mean(gg))**2 for v in gg) for gg in gs)
+dfb=len(gs)-1; dfw=len(allv)-len(gs); Fv=(ssb/dfb)/(ssw/dfw); msw=ssw/dfw; sst=ssb+ssw
+print("F",g(Fv),"df",dfb,dfw,"p",g(f_sf(Fv,dfb,dfw)),"eta",g(ssb/sst),"omega",g((ssb-dfb*msw)/(sst+msw)))
+print("== chi2")
+tab=[[10,20,15],[20,10,25]]
+N=sum(map(sum,tab)); rs=[sum(r) for r in tab]; cs=[sum(c) for c in zip(*tab)]
+chi=sum((tab[i][j]-rs[i]*cs[j]/N)**2/(rs[i]*cs[j]/N) for i in range(2) for j in range(3))
+df=(2-1)*(3-1)
+print("chi2",g(chi),"df",df,"p",g(gammq(df/2,chi/2)),"V",g(math.sqrt(chi/(N*1))))
+print("== ols")
+x1=[1,2,3,4,5,6,7,8]; x2=[2,1,4,3,6,5,8,9]; y=[3.1,3.9,6.2,6.8,9.9,10.1,13.8,15.2]
+X=[[F(1),F(a),F(b)] for a,b in zip(x1,x2)]; Y=[F(str(v)) for v in y]
+k=3; n=len(Y)
+XtX=[[sum(X[r][i]*X[r][j] for r in range(n)) for j in range(k)] for i in range(k)]
+XtY=[sum(X[r][i]*Y[r] for r in range(n)) for i in range(k)]
+# 以高斯-喬登消去法求 X'X 的反矩陣
+M=[row[:]+[F(int(i==j)) for j in range(k)] for i,row in enumerate(XtX)]
+for c in range(k):
+    p=next(r for r in range(c,k) if M[r][c]!=0); M[c],M[p]=M[p],M[c]
+    pv=M[c][c]; M[c]=[v/pv for v in M[c]]
+    for r in range(k):
+        if r!=c:
+            f=M[r][c]; M[r]=[a-f*b for a,b in zip(M[r],M[c])]
+inv=[row[k:] for row in M]
+beta=[sum(inv[i][j]*XtY[j] for j in range(k)) for i in range(k)]
+fit=[sum(X[r][i]*beta[i] for i in range(k)) for r in range(n)]
+sse=sum((Y[r]-fit[r])**2 for r in range(n)); ym=sum(Y)/n; sst=sum((v-ym)**2 for v in Y)
+dfr=n-k; mse=sse/dfr; r2=1-sse/sst; Fv=((sst-sse)/(k-1))/mse
+print("r2",g(r2),"adj",g(1-(1-r2)*(n-1)/dfr),"F",g(Fv),"Fp",g(f_sf(float(Fv),k-1,dfr)),"rse",g(math.sqrt(mse)))
+q=t_q(0.975,dfr)
+for i in range(k):
+    se=math.sqrt(float(mse*inv[i][i])); b=float(beta[i]); t=b/se
+    print("coef",i,g(b),"se",g(se),"t",g(t),"p",g(t_sf2(t,dfr)),"ci",g(b-q*se),g(b+q*se))
+print("fitted0",g(fit[0]),"resid0",g(Y[0]-fit[0]))
+print("== pearson")
+# 第 9 列的 y 為缺失值：x1 與 x2 使用 9 列，其餘配對使用 8 列
+def pearson(a,b):
+    ma,mb=mean(a),mean(b)
+    sab=sum((p-ma)*(q_-mb) for p,q_ in zip(a,b))
+    r=sab/math.sqrt(sum((p-ma)**2 for p in a)*sum((q_-mb)**2 for q_ in b))
+    df=len(a)-2
+    return r,t_sf2(r*math.sqrt(df/(1-r*r)),df)
+for name,a,b in [("x1~x2",x1+[9],x2+[10]),("x1~y",x1,y),("x2~y",x2,y)]:
+    r,p=pearson(a,b); print(name,"r",g(r),"p",g(p))
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// ===== Thrift compact protocol =====
+//
+// Parquet 的檔案結構與頁面標頭以 Thrift compact protocol 編碼。
+// 這裡只實作讀寫 Parquet 所需的部分：讀取時解碼為以欄位 ID 為鍵的通用結構，寫出時由 thriftField 組成。
+
+// Thrift compact protocol 的型別代碼
+const (
+	thriftTrue   = 1
+	thriftFalse  = 2
+	thriftByte   = 3
+	thriftI16    = 4
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftDouble = 7
+	thriftBinary = 8
+	thriftList   = 9
+	thriftSet    = 10
+	thriftMap    = 11
+	thriftStruct = 12
+)
+
+// thriftMaxDepth 巢狀結構的最大深度，避免損毀的檔案造成無限遞迴
+const thriftMaxDepth = 64
+
+var errThriftInvalid = errors.New("invalid thrift data")
+
+// thriftValues 解碼後的結構，以欄位 ID 為鍵；值為 int64、float64、bool、[]byte、thriftValues 或 []any
+type thriftValues map[int16]any
+
+func (t thriftValues) int(id int16) int64 {
+	v, _ := t[id].(int64)
+	return v
+}
+
+func (t thriftValues) has(id int16) bool {
+	_, ok := t[id]
+	return ok
+}
+
+func (t thriftValues) bool(id int16) bool {
+	v, _ := t[id].(bool)
+	return v
+}
+
+func (t thriftValues) text(id int16) string {
+	v, _ := t[id].([]byte)
+	return string(v)
+}
+
+func (t thriftValues) sub(id int16) thriftValues {
+	v, _ := t[id].(thriftValues)
+	return v
+}
+
+func (t thriftValues) list(id int16) []any {
+	v, _ := t[id].([]any)
+	return v
+}
+
+// thriftDecoder 解碼 compact protocol
+type thriftDecoder struct {
+	data []byte
+	pos  int
+}
+
+// decodeThrift 解碼一個結構，回傳結構與使用的位元組數
+func decodeThrift(data []byte) (thriftValues, int, error) {
+	d := &thriftDecoder{data: data}
+	t, err := d.readStruct(0)
+	return t, d.pos, err
+}
+
+func (d *thriftDecoder) byte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, errThriftInvalid
+	}
+	b := d.data[d.pos]
+	d.pos++
+	return b, nil
+}
+
+func (d *thriftDecoder) varint() (uint64, error) {
+	v, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, errThriftInvalid
+	}
+	d.pos += n
+	return v, nil
+}
+
+func (d *thriftDecoder) zigzag() (int64, error) {
+	v, err := d.varint()
+	return int64(v>>1) ^ -int64(v&1), err
+}
+
+func (d *thriftDecoder) readStruct(depth int) (thriftValues, error) {
+	if depth > thriftMaxDepth {
+		return nil, errThriftInvalid
+	}
+	t := make(thriftValues)
+	var id int16
+	for {
+		header, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		if header == 0 {
+			return t, nil
+		}
+		if delta := int16(header >> 4); delta != 0 {
+			id += delta
+		} else {
+			v, err := d.zigzag()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		kind := header & 0x0f
+		switch kind {
+		case thriftTrue, thriftFalse:
+			t[id] = kind == thriftTrue
+		default:
+			v, err := d.readValue(kind, depth)
+			if err != nil {
+				return nil, err
+			}
+			t[id] = v
+		}
+	}
+}
+
+func (d *thriftDecoder) readValue(kind byte, depth int) (any, error) {
+	switch kind {
+	case thriftTrue, thriftFalse:
+		// 清單中的布林值各佔一個位元組
+		b, err := d.byte()
+		return b == thriftTrue, err
+	case thriftByte:
+		b, err := d.byte()
+		return int64(int8(b)), err
+	case thriftI16, thriftI32, thriftI64:
+		return d.zigzag()
+	case thriftDouble:
+		if d.pos+8 > len(d.data) {
+			return nil, errThriftInvalid
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(d.data[d.pos:]))
+		d.pos += 8
+		return v, nil
+	case thriftBinary:
+		n, err := d.varint()
+		if err != nil || n > uint64(len(d.data)-d.pos) {
+			return nil, errThriftInvalid
+		}
+		b := d.data[d.pos : d.pos+int(n)]
+		d.pos += int(n)
+		return b, nil
+	case thriftList, thriftSet:
+		header, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		n := uint64(header >> 4)
+		if n == 15 {
+			if n, err = d.varint(); err != nil {
+				return nil, err
+			}
+		}
+		// 每個元素至少佔一個位元組
+		if n > uint64(len(d.data)-d.pos) {
+			return nil, errThriftInvalid
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = d.readValue(header&0x0f, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	case thriftMap:
+		// Parquet 不使用 map，讀取後略過
+		n, err := d.varint()
+		if err != nil || n > uint64(len(d.data)-d.pos) {
+			return nil, errThriftInvalid
+		}
+		if n == 0 {
+			return nil, nil
+		}
+		types, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		for range n {
+			if _, err := d.readValue(types>>4, depth+1); err != nil {
+				return nil, err
+			}
+			if _, err := d.readValue(types&0x0f, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case thriftStruct:
+		return d.readStruct(depth + 1)
+	}
+	return nil, errThriftInvalid
+}
+
+// ----- 編碼 -----
+
+// thriftField 寫出的一個欄位；值為 bool、int8、int32、int64、string、[]byte、[]thriftField（結構）或 thriftListOf
+type thriftField struct {
+	id    int16
+	value any
+}
+
+// thriftListOf 寫出的清單
+type thriftListOf struct {
+	kind  byte
+	items []any
+}
+
+// encodeThrift 將結構編碼後附加到 buf
+func encodeThrift(buf []byte, fields []thriftField) []byte {
+	var last int16
+	for _, f := range fields {
+		kind := thriftKindOf(f.value)
+		if b, ok := f.value.(bool); ok && !b {
+			kind = thriftFalse
+		}
+		if delta := f.id - last; delta > 0 && delta <= 15 {
+			buf = append(buf, byte(delta)<<4|kind)
+		} else {
+			buf = append(buf, kind)
+			buf = binary.AppendUvarint(buf, zigzag(int64(f.id)))
+		}
+		last = f.id
+		if kind != thriftTrue && kind != thriftFalse {
+			buf = encodeThriftValue(buf, f.value)
+		}
+	}
+	return append(buf, 0)
+}
+
+func encodeThriftValue(buf []byte, value any) []byte {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return append(buf, thriftTrue)
+		}
+		return append(buf, thriftFalse)
+	case int8:
+		return append(buf, byte(v))
+	case int32:
+		return binary.AppendUvarint(buf, zigzag(int64(v)))
+	case int64:
+		return binary.AppendUvarint(buf, zigzag(v))
+	case string:
+		buf = binary.AppendUvarint(buf, uint64(len(v)))
+		return append(buf, v...)
+	case []byte:
+		buf = binary.AppendUvarint(buf, uint64(len(v)))
+		return append(buf, v...)
+	case []thriftField:
+		return encodeThrift(buf, v)
+	case thriftListOf:
+		if len(v.items) < 15 {
+			buf = append(buf, byte(len(v.items))<<4|v.kind)
+		} else {
+			buf = append(buf, 0xf0|v.kind)
+			buf = binary.AppendUvarint(buf, uint64(len(v.items)))
+		}
+		for _, item := range v.items {
+			buf = encodeThriftValue(buf, item)
+		}
+	}
+	return buf
+}
+
+// thriftKindOf 值對應的型別代碼
+func thriftKindOf(value any) byte {
+	switch value.(type) {
+	case bool:
+		return thriftTrue
+	case int8:
+		return thriftByte
+	case int32:
+		return thriftI32
+	case int64:
+		return thriftI64
+	case string, []byte:
+		return thriftBinary
+	case []thriftField:
+		return thriftStruct
+	case thriftListOf:
+		return thriftList
+	}
+	return 0
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/HazelnutParadise/insyra"
+)
+
+// ===== 變數資訊（變數檢視） =====
+//
+// 每一欄（變數）除了欄名之外還可以有標籤、說明、測量尺度、數值標籤與顯示格式，
+// 以欄位 ID 為鍵保存，搬移或重新命名欄位後仍然有效。
+
+// 測量尺度
+const (
+	MeasureNominal = "nominal" // 名目
+	MeasureOrdinal = "ordinal" // 次序
+	MeasureScale   = "scale"   // 等距或比率
+)
+
+// measures 可以設定的測量尺度
+var measures = map[string]bool{
+	MeasureNominal: true,
+	MeasureOrdinal: true,
+	MeasureScale:   true,
+}
+
+// VariableMetadata 變數資訊
+type VariableMetadata struct {
+	Label       string       `json:"label"`
+	Description string       `json:"description"`
+	Measure     string       `json:"measure"`     // 測量尺度，空字串表示依欄位型別決定
+	ValueLabels []ValueLabel `json:"valueLabels"` // 依設定順序排列
+	Format      string       `json:"format"`      // 顯示格式，例如 "F8.2"、"A20"，空字串表示預設格式
+	Width       int          `json:"width"`       // 顯示寬度（字元數），0 表示預設寬度
+}
+
+// ValueLabel 數值標籤，例如 1 = "Male"
+type ValueLabel struct {
+	Value any    `json:"value"` // 數值或文字
+	Label string `json:"label"`
+}
+
+// VariableInfo 變數檢視中的一列
+type VariableInfo struct {
+	Col     int                 `json:"col"`
+	ID      int                 `json:"id"`
+	Name    string              `json:"name"`
+	Type    string              `json:"type"`
+	Formula string              `json:"formula"` // 計算欄位的公式，一般欄位為空字串
+	Missing ColumnMissingPolicy `json:"missing"`
+	VariableMetadata
+}
+
+// variablesOf 取得資料表各欄 ID 對應的變數資訊，不存在時建立
+func (s *DataTableService) variablesOf(dt *insyra.DataTable) map[int]VariableMetadata {
+	variables, ok := s.variables[dt]
+	if !ok {
+		variables = make(map[int]VariableMetadata)
+		s.variables[dt] = variables
+	}
+	return variables
+}
+
+// variableTexts 取得各欄的變數資訊，以欄位索引為鍵，省略沒有設定的欄
+func (s *DataTableService) variableTexts(dt *insyra.DataTable) map[int]VariableMetadata {
+	texts := make(map[int]VariableMetadata)
+	variables := s.variablesOf(dt)
+	for j, id := range s.columnIDsOf(dt) {
+		if meta, ok := variables[id]; ok {
+			texts[j] = meta
+		}
+	}
+	return texts
+}
+
+// setVariableTexts 以欄位索引設定各欄的變數資訊，無效的設定略過
+func (s *DataTableService) setVariableTexts(dt *insyra.DataTable, texts map[int]VariableMetadata) {
+	variables := s.variablesOf(dt)
+	ids := s.columnIDsOf(dt)
+	for j, meta := range texts {
+		if j < 0 || j >= len(ids) {
+			continue
+		}
+		if meta, err := normalizeVariable(meta); err == nil {
+			variables[ids[j]] = meta
+		}
+	}
+}
+
+// GetVariableView 取得資料表所有變數的資訊，依欄位順序排列
+func (s *DataTableService) GetVariableView(tableID string) ([]VariableInfo, error) {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return nil, errTableNotFound(tableID)
+	}
+	ids := s.columnIDsOf(dt)
+	variables := s.variablesOf(dt)
+	infos := make([]VariableInfo, len(ids))
+	for j, id := range ids {
+		info := VariableInfo{
+			Col:              j,
+			ID:               id,
+			Name:             dt.GetColNameByNumber(j),
+			Type:             s.columnTypeOf(dt, j),
+			Missing:          s.missingPolicyOf(dt, j),
+			VariableMetadata: variables[id],
+		}
+		if f := s.formulas[dt][j]; f != nil {
+			info.Formula = f.formula
+		}
+		if info.Measure == "" {
+			info.Measure = defaultMeasure(info.Type)
+		}
+		if info.ValueLabels == nil {
+			info.ValueLabels = []ValueLabel{}
+		}
+		infos[j] = info
+	}
+	return infos, nil
+}
+
+// SetVariableMetadata 設定變數的標籤、說明、測量尺度、數值標籤與顯示格式
+func (s *DataTableService) SetVariableMetadata(tableID string, colIndex int, meta VariableMetadata) error {
+	s.lock()
+	defer s.unlock()
+	dt := s.getTableByID(tableID)
+	if dt == nil {
+		return errTableNotFound(tableID)
+	}
+	if _, colCount := dt.Size(); colIndex < 0 || colIndex >= colCount {
+		return errColumnOutOfRange(colIndex, colCount)
+	}
+	meta, err := normalizeVariable(meta)
+	if err != nil {
+		return err
+	}
+
+	id := s.columnIDsOf(dt)[colIndex]
+	oldMeta, hadMeta := s.variablesOf(dt)[id]
+	s.variablesOf(dt)[id] = meta
+	s.record(dt, "edit variable",
+		func(dt *insyra.DataTable) {
+			if hadMeta {
+				s.variablesOf(dt)[id] = oldMeta
+			} else {
+				delete(s.variablesOf(dt), id)
+			}
+		},
+		func(dt *insyra.DataTable) { s.variablesOf(dt)[id] = meta },
+	)
+	return nil
+}
+
+// normalizeVariable 檢查變數資訊並去除文字前後的空白；整數的數值標籤統一以 float64 保存
+func normalizeVariable(meta VariableMetadata) (VariableMetadata, error) {
+	meta.Label = strings.TrimSpace(meta.Label)
+	meta.Format = strings.TrimSpace(meta.Format)
+	if meta.Measure != "" && !measures[meta.Measure] {
+		return meta, invalidArgument("unknown measurement level %q", meta.Measure).WithDetail("measure", meta.Measure)
+	}
+	if meta.Width < 0 {
+		return meta, invalidArgument("display width must not be negative, got %d", meta.Width).WithDetail("width", meta.Width)
+	}
+	labels := make([]ValueLabel, 0, len(meta.ValueLabels))
+	seen := make(map[string]bool, len(meta.ValueLabels))
+	for _, vl := range meta.ValueLabels {
+		if isMissingValue(vl.Value) {
+			return meta, invalidArgument("value label %q has no value", vl.Label).WithDetail("label", vl.Label)
+		}
+		if f, ok := insyra.ToFloat64Safe(vl.Value); ok {
+			vl.Value = f
+		}
+		key := formatValue(vl.Value)
+		if seen[key] {
+			return meta, invalidArgument("duplicate value label for %s", key).WithDetail("value", key)
+		}
+		seen[key] = true
+		labels = append(labels, vl)
+	}
+	meta.ValueLabels = labels
+	if len(labels) == 0 {
+		meta.ValueLabels = nil
+	}
+	return meta, nil
+}
+
+// defaultMeasure 依欄位型別決定預設的測量尺度：數值為等距或比率，其餘為名目
+func defaultMeasure(dataType string) string {
+	if slices.Contains([]string{DataTypeNumeric, DataTypeInteger, DataTypeDateTime}, dataType) {
+		return MeasureScale
+	}
+	return MeasureNominal
+}